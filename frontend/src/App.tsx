@@ -7,6 +7,7 @@ import { GlobalContextMenuFrame } from './components/ContextMenu';
 import { Loading } from './components/Loading';
 import { Nav } from './components/Nav';
 import { HostEdit } from './pages/host/HostEdit';
+import { HostImport } from './pages/host/HostImport';
 import { HostList } from './pages/host/HostList';
 import { HostView } from './pages/host/HostView';
 import { GroupEdit } from './pages/group/GroupEdit';
@@ -49,6 +50,7 @@ export const App: React.FC = () => {
                 <Route path="/hosts/host/:id/edit" element={<HostEdit />} />
                 <Route path="/hosts/host/:id" element={<HostView />} />
                 <Route path="/hosts/host" element={<HostEdit />} />
+                <Route path="/hosts/import" element={<HostImport />} />
                 <Route path="/hosts" element={<HostList />} />
                 <Route path="/groups/group/:id/edit" element={<GroupEdit />} />
                 <Route path="/groups/group/:id" element={<GroupView />} />
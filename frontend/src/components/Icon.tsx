@@ -28,6 +28,7 @@ import {
     faMagic,
     faMagnifyingGlass,
     faMinus,
+    faMinusCircle,
     faNetworkWired,
     faPaperclip,
     faPlayCircle,
@@ -108,6 +109,7 @@ export namespace Icon {
     export const Magic: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faMagic, options: props });
     export const MagnifyingGlass: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faMagnifyingGlass, options: props });
     export const Minus: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faMinus, options: props });
+    export const MinusCircle: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faMinusCircle, options: props });
     export const NetworkWired: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faNetworkWired, options: props });
     export const Paperclip: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faPaperclip, options: props });
     export const PlayCircle: React.FC<IconProps> = (props: IconProps) => EIcon({ icon: faPlayCircle, options: props });
@@ -0,0 +1,212 @@
+import * as React from 'react';
+import { Column, Table } from './Table';
+import { AddButton } from './Button';
+import { Icon } from './Icon';
+import { Modal, GlobalModalFrame, ModalForm } from './Modal';
+import { Input } from './input/Input';
+import { ValidationResult } from './Form';
+import { ScriptParameter } from '../types/Script';
+import { ScriptParameterType } from '../types/cbgen_enum';
+import { ContextMenuItem } from './ContextMenu';
+
+interface ScriptParameterEditProps {
+    parameters: ScriptParameter[];
+    onChange: (parameters: ScriptParameter[]) => (void);
+}
+export const ScriptParameterEdit: React.FC<ScriptParameterEditProps> = (props: ScriptParameterEditProps) => {
+    const addNewParameter = (parameter: ScriptParameter) => {
+        const parameters = props.parameters;
+        parameters.push(parameter);
+        props.onChange(parameters);
+    };
+
+    const createClick = () => {
+        GlobalModalFrame.showModal(<ScriptParameterEditModal default={{ Name: '', Type: ScriptParameterType.String }} onSave={addNewParameter} />);
+    };
+
+    const replaceParameter = (name: string) => {
+        return (parameter: ScriptParameter) => {
+            const parameters = props.parameters;
+            const idx = parameters.findIndex(p => p.Name === name);
+            parameters[idx] = parameter;
+            props.onChange(parameters);
+        };
+    };
+
+    const didEditParameter = (parameter: ScriptParameter): () => (void) => {
+        return () => {
+            GlobalModalFrame.showModal(<ScriptParameterEditModal default={parameter} onSave={replaceParameter(parameter.Name)} />);
+        };
+    };
+
+    const didDeleteParameter = (parameter: ScriptParameter): () => (void) => {
+        return () => {
+            Modal.delete('Delete Parameter', 'Are you sure you want to delete this parameter?').then(confirmed => {
+                if (!confirmed) {
+                    return;
+                }
+                const parameters = props.parameters;
+                const idx = parameters.findIndex(p => p.Name === parameter.Name);
+                parameters.splice(idx, 1);
+                props.onChange(parameters);
+            });
+        };
+    };
+
+    const tableCols: Column[] = [
+        {
+            title: 'Name',
+            value: (p: ScriptParameter) => {
+                return (<code>{p.Name}</code>);
+            },
+            sort: 'Name'
+        },
+        {
+            title: 'Type',
+            value: (p: ScriptParameter) => {
+                return (<span>{p.Type}{p.Required ? ' (required)' : ''}</span>);
+            },
+            sort: 'Type'
+        },
+        {
+            title: 'Default',
+            value: (p: ScriptParameter) => {
+                if (p.Type == ScriptParameterType.Secret && p.Default) {
+                    return (<span>******</span>);
+                }
+                return (<code>{p.Default}</code>);
+            },
+        }
+    ];
+
+    return (
+        <React.Fragment>
+            <AddButton onClick={createClick} />
+            <Table columns={tableCols} data={props.parameters} contextMenu={(p: ScriptParameter) => ParameterTableContextMenu(didEditParameter(p), didDeleteParameter(p))} defaultSort={{ ColumnIdx: 0, Ascending: true }} />
+        </React.Fragment>
+    );
+};
+
+const ParameterTableContextMenu = (didEditParameter: () => void, didDeleteParameter: () => void): (ContextMenuItem | 'separator')[] => {
+    return [
+        {
+            title: 'Edit',
+            icon: (<Icon.Edit />),
+            onClick: () => {
+                didEditParameter();
+            }
+        },
+        'separator',
+        {
+            title: 'Delete',
+            icon: (<Icon.Delete />),
+            onClick: () => {
+                didDeleteParameter();
+            }
+        },
+    ];
+};
+
+interface ScriptParameterEditModalProps {
+    default: ScriptParameter;
+    onSave: (parameter: ScriptParameter) => (void);
+}
+const ScriptParameterEditModal: React.FC<ScriptParameterEditModalProps> = (props: ScriptParameterEditModalProps) => {
+    const [parameter, setParameter] = React.useState<ScriptParameter>({ ...props.default });
+
+    const changeParameter = <K extends keyof ScriptParameter>(key: K) => {
+        return (value: ScriptParameter[K]) => {
+            setParameter(parameter => {
+                return { ...parameter, [key]: value };
+            });
+        };
+    };
+
+    const changeOptions = (value: string) => {
+        setParameter(parameter => {
+            return { ...parameter, Options: value.split('\n').filter(option => option != '') };
+        });
+    };
+
+    const onSave = (): Promise<void> => {
+        return new Promise(resolve => {
+            if (parameter.Type != ScriptParameterType.Enum) {
+                parameter.Options = [];
+            }
+            props.onSave(parameter);
+            resolve();
+        });
+    };
+
+    const validateName = (value: string): Promise<ValidationResult> => {
+        if (!/^[A-Za-z_][A-Za-z0-9_]*$/.test(value)) {
+            return Promise.resolve({
+                valid: false,
+                invalidMessage: 'Name must only contain letters, numbers and underscores',
+            });
+        }
+        if (value.startsWith('OTTO_')) {
+            return Promise.resolve({
+                valid: false,
+                invalidMessage: 'Name is reserved by the Otto system',
+            });
+        }
+        return Promise.resolve({
+            valid: true,
+        });
+    };
+
+    const title = props.default.Name != '' ? 'Edit Parameter' : 'New Parameter';
+    return (
+        <ModalForm title={title} onSubmit={onSave}>
+            <Input.Text
+                label="Name"
+                type="text"
+                defaultValue={parameter.Name}
+                onChange={changeParameter('Name')}
+                helpText="The value is passed to the script as an environment variable with this name."
+                fixedWidth
+                validate={validateName}
+                required />
+            <Input.Text
+                label="Description"
+                type="text"
+                defaultValue={parameter.Description}
+                onChange={changeParameter('Description')} />
+            <Input.Select
+                label="Type"
+                defaultValue={parameter.Type}
+                onChange={changeParameter('Type')}>
+                <option value={ScriptParameterType.String}>Text</option>
+                <option value={ScriptParameterType.Integer}>Whole Number</option>
+                <option value={ScriptParameterType.Boolean}>True or False</option>
+                <option value={ScriptParameterType.Enum}>One of a list of options</option>
+                <option value={ScriptParameterType.Secret}>Secret</option>
+            </Input.Select>
+            {parameter.Type == ScriptParameterType.Enum ? (<Input.Textarea
+                label="Options"
+                defaultValue={(parameter.Options || []).join('\n')}
+                onChange={changeOptions}
+                helpText="One option per line."
+                fixedWidth
+                required />) : null}
+            <Input.Text
+                label="Default"
+                type={parameter.Type == ScriptParameterType.Secret ? 'password' : 'text'}
+                defaultValue={parameter.Default}
+                onChange={changeParameter('Default')}
+                fixedWidth />
+            <Input.Text
+                label="Pattern"
+                type="text"
+                defaultValue={parameter.Pattern}
+                onChange={changeParameter('Pattern')}
+                helpText="Optional regular expression that the value must match."
+                fixedWidth />
+            <Input.Checkbox
+                label="Required"
+                defaultValue={parameter.Required}
+                onChange={changeParameter('Required')} />
+        </ModalForm>
+    );
+};
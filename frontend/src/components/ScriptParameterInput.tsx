@@ -0,0 +1,72 @@
+import * as React from 'react';
+import { Input } from './input/Input';
+import { ScriptParameter } from '../types/Script';
+import { ScriptParameterType } from '../types/cbgen_enum';
+
+interface ScriptParameterInputProps {
+    parameters: ScriptParameter[];
+    values: { [name: string]: string };
+    onChange: (values: { [name: string]: string }) => (void);
+}
+/**
+ * Inputs for the values of each parameter of a script
+ */
+export const ScriptParameterInput: React.FC<ScriptParameterInputProps> = (props: ScriptParameterInputProps) => {
+    const changeValue = (name: string) => {
+        return (value: string) => {
+            const values = { ...props.values };
+            if (value === '') {
+                delete values[name];
+            } else {
+                values[name] = value;
+            }
+            props.onChange(values);
+        };
+    };
+
+    const input = (parameter: ScriptParameter) => {
+        const label = parameter.Name;
+        const helpText = parameter.Description;
+        const defaultValue = props.values[parameter.Name] ?? parameter.Default ?? '';
+
+        switch (parameter.Type) {
+            case ScriptParameterType.Boolean:
+                return (<Input.Checkbox
+                    label={label}
+                    helpText={helpText}
+                    defaultValue={defaultValue == 'true'}
+                    onChange={(checked: boolean) => changeValue(parameter.Name)(checked ? 'true' : 'false')} />);
+            case ScriptParameterType.Enum:
+                return (<Input.Select
+                    label={label}
+                    helpText={helpText}
+                    defaultValue={defaultValue}
+                    onChange={changeValue(parameter.Name)}
+                    required={parameter.Required}>
+                    <option value="">Default</option>
+                    {(parameter.Options || []).map(option => (<option value={option} key={option}>{option}</option>))}
+                </Input.Select>);
+            case ScriptParameterType.Integer:
+                return (<Input.Number
+                    label={label}
+                    helpText={helpText}
+                    defaultValue={defaultValue === '' ? undefined : parseInt(defaultValue)}
+                    onChange={(value: number) => changeValue(parameter.Name)(isNaN(value) ? '' : value.toString())}
+                    required={parameter.Required} />);
+        }
+
+        return (<Input.Text
+            label={label}
+            helpText={helpText}
+            type={parameter.Type == ScriptParameterType.Secret ? 'password' : 'text'}
+            defaultValue={defaultValue}
+            onChange={changeValue(parameter.Name)}
+            required={parameter.Required} />);
+    };
+
+    return (
+        <React.Fragment>
+            {props.parameters.map(parameter => (<React.Fragment key={parameter.Name}>{input(parameter)}</React.Fragment>))}
+        </React.Fragment>
+    );
+};
@@ -0,0 +1,103 @@
+import * as React from 'react';
+import { Input } from './input/Input';
+import { SuccessCriteria } from '../types/Script';
+
+interface SuccessCriteriaEditProps {
+    defaultValue: SuccessCriteria;
+    onChange: (criteria: SuccessCriteria) => (void);
+}
+export const SuccessCriteriaEdit: React.FC<SuccessCriteriaEditProps> = (props: SuccessCriteriaEditProps) => {
+    const [criteria, setCriteria] = React.useState<SuccessCriteria>(props.defaultValue || {});
+
+    React.useEffect(() => {
+        props.onChange(criteria);
+    }, [criteria]);
+
+    const formatCodes = (codes: number[]): string => {
+        return (codes || []).join(', ');
+    };
+
+    const parseCodes = (value: string): number[] => {
+        return value.split(',').map(code => code.trim()).filter(code => code !== '').map(code => parseInt(code)).filter(code => !isNaN(code));
+    };
+
+    const changeExitCodes = (value: string) => {
+        setCriteria(criteria => {
+            criteria.ExitCodes = parseCodes(value);
+            return { ...criteria };
+        });
+    };
+
+    const changeChangedExitCodes = (value: string) => {
+        setCriteria(criteria => {
+            criteria.ChangedExitCodes = parseCodes(value);
+            return { ...criteria };
+        });
+    };
+
+    const changePattern = (key: 'StdoutMatch' | 'StdoutNotMatch' | 'StderrMatch' | 'StderrNotMatch') => {
+        return (value: string) => {
+            setCriteria(criteria => {
+                criteria[key] = value;
+                return { ...criteria };
+            });
+        };
+    };
+
+    const changeMaxDuration = (MaxDurationSeconds: number) => {
+        setCriteria(criteria => {
+            criteria.MaxDurationSeconds = isNaN(MaxDurationSeconds) ? 0 : MaxDurationSeconds;
+            return { ...criteria };
+        });
+    };
+
+    return (
+        <React.Fragment>
+            <Input.Text
+                label="Allowed Exit Codes"
+                type="text"
+                defaultValue={formatCodes(criteria.ExitCodes)}
+                onChange={changeExitCodes}
+                helpText="Comma separated list of exit codes that indicate success. If empty only 0 is allowed."
+                fixedWidth />
+            <Input.Text
+                label="Changed Exit Codes"
+                type="text"
+                defaultValue={formatCodes(criteria.ChangedExitCodes)}
+                onChange={changeChangedExitCodes}
+                helpText="Comma separated list of exit codes that indicate the script succeeded and made changes."
+                fixedWidth />
+            <Input.Text
+                label="Stdout Must Match"
+                type="text"
+                defaultValue={criteria.StdoutMatch}
+                onChange={changePattern('StdoutMatch')}
+                fixedWidth />
+            <Input.Text
+                label="Stdout Must Not Match"
+                type="text"
+                defaultValue={criteria.StdoutNotMatch}
+                onChange={changePattern('StdoutNotMatch')}
+                fixedWidth />
+            <Input.Text
+                label="Stderr Must Match"
+                type="text"
+                defaultValue={criteria.StderrMatch}
+                onChange={changePattern('StderrMatch')}
+                fixedWidth />
+            <Input.Text
+                label="Stderr Must Not Match"
+                type="text"
+                defaultValue={criteria.StderrNotMatch}
+                onChange={changePattern('StderrNotMatch')}
+                fixedWidth />
+            <Input.Number
+                label="Maximum Duration"
+                defaultValue={criteria.MaxDurationSeconds}
+                onChange={changeMaxDuration}
+                minimum={0}
+                append="Seconds"
+                helpText="The script fails if it runs longer than this. 0 for no limit." />
+        </React.Fragment>
+    );
+};
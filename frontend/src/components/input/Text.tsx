@@ -13,7 +13,7 @@ interface TextProps extends InputProps {
     /**
      * The value used in the type attribute on the input node
      */
-    type: 'text' | 'password' | 'email' | 'search';
+    type: 'text' | 'password' | 'email' | 'search' | 'datetime-local';
     /**
      * Optional placeholder text for the input
      */
@@ -18,6 +18,7 @@ export const GroupEdit: React.FC = () => {
     const [group, setGroup] = React.useState<GroupType>();
     const [isNew, setIsNew] = React.useState<boolean>();
     const [hostIDs, setHostIDs] = React.useState<string[]>();
+    const [groups, setGroups] = React.useState<GroupType[]>();
     const navigate = useNavigate();
 
     React.useEffect(() => {
@@ -25,21 +26,24 @@ export const GroupEdit: React.FC = () => {
     }, []);
 
     const loadGroup = () => {
-        if (id == null) {
-            setIsNew(true);
-            setGroup(Group.Blank());
-            setLoading(false);
-            setHostIDs([]);
-        } else {
-            Group.Get(id).then(group => {
-                Group.Hosts(group.ID).then(hostIDs => {
-                    setIsNew(false);
-                    setGroup(group);
-                    setHostIDs(hostIDs.map(host => host.ID));
-                    setLoading(false);
+        Group.List().then(groups => {
+            setGroups(groups);
+            if (id == null) {
+                setIsNew(true);
+                setGroup(Group.Blank());
+                setLoading(false);
+                setHostIDs([]);
+            } else {
+                Group.Get(id).then(group => {
+                    Group.Hosts(group.ID).then(hostIDs => {
+                        setIsNew(false);
+                        setGroup(group);
+                        setHostIDs(hostIDs.map(host => host.ID));
+                        setLoading(false);
+                    });
                 });
-            });
-        }
+            }
+        });
     };
 
     const changeName = (Name: string) => {
@@ -49,6 +53,20 @@ export const GroupEdit: React.FC = () => {
         });
     };
 
+    const changeSelector = (Selector: string) => {
+        setGroup(group => {
+            group.Selector = Selector;
+            return { ...group };
+        });
+    };
+
+    const changeParentID = (ParentID: string) => {
+        setGroup(group => {
+            group.ParentID = ParentID;
+            return { ...group };
+        });
+    };
+
     const changeEnvironment = (Environment: Variable[]) => {
         setGroup(group => {
             group.Environment = Environment;
@@ -115,6 +133,21 @@ export const GroupEdit: React.FC = () => {
                     defaultValue={group.Name}
                     onChange={changeName}
                     required />
+                <Input.Text
+                    label="Selector"
+                    type="text"
+                    placeholder="env=prod,os in (debian,ubuntu),!canary"
+                    helpText="Optional. Hosts with labels or properties that match the selector are also members of this group."
+                    defaultValue={group.Selector}
+                    onChange={changeSelector} />
+                <Input.Select
+                    label="Parent Group"
+                    helpText="Optional. The group inherits the scripts and environment variables of its parent, and its hosts are also members of the parent."
+                    defaultValue={group.ParentID}
+                    onChange={changeParentID}>
+                    <option value="">None</option>
+                    {groups.filter(g => g.ID !== group.ID).map(g => (<option key={g.ID} value={g.ID}>{g.Name}</option>))}
+                </Input.Select>
                 <Card.Card className="mt-3">
                     <Card.Header>Environment Variables</Card.Header>
                     <Card.Body>
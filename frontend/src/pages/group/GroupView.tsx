@@ -22,6 +22,7 @@ export const GroupView: React.FC = () => {
     const { id } = useParams() as URLParams;
     const [loading, setLoading] = React.useState(true);
     const [group, setGroup] = React.useState<GroupType>();
+    const [parent, setParent] = React.useState<GroupType>();
     const [hosts, setHosts] = React.useState<HostType[]>();
     const [scripts, setScripts] = React.useState<ScriptType[]>();
     const [schedules, setSchedules] = React.useState<ScheduleType[]>();
@@ -33,6 +34,9 @@ export const GroupView: React.FC = () => {
 
     const loadGroup = async () => {
         const group = await Group.Get(id);
+        if (group.ParentID) {
+            setParent(await Group.Get(group.ParentID));
+        }
         setGroup(group);
     };
 
@@ -96,6 +100,7 @@ export const GroupView: React.FC = () => {
                         <Card.Header>Host Information</Card.Header>
                         <ListGroup.List>
                             <ListGroup.TextItem title="Name">{group.Name}</ListGroup.TextItem>
+                            {parent ? (<ListGroup.TextItem title="Parent Group"><Link to={'/groups/group/' + parent.ID}>{parent.Name}</Link></ListGroup.TextItem>) : null}
                         </ListGroup.List>
                     </Card.Card>
                     <EnvironmentVariableCard variables={group.Environment} className="mb-3" />
@@ -11,6 +11,9 @@ import { GroupCheckList } from '../../components/CheckList';
 import { Card } from '../../components/Card';
 import { Notification } from '../../components/Notification';
 import { Variable } from '../../types/Variable';
+import { RadioChoice } from '../../components/input/Radio';
+import { HostConnectionMode } from '../../types/cbgen_enum';
+import { MultiInput } from '../../components/MultiInput';
 
 export const HostEdit: React.FC = () => {
     const { id } = useParams() as URLParams;
@@ -64,6 +67,13 @@ export const HostEdit: React.FC = () => {
         });
     };
 
+    const changeConnectionMode = (ConnectionMode: string) => {
+        setHost(host => {
+            host.ConnectionMode = ConnectionMode;
+            return { ...host };
+        });
+    };
+
     const enabledCheckbox = () => {
         if (isNew) {
             return null;
@@ -92,6 +102,31 @@ export const HostEdit: React.FC = () => {
         });
     };
 
+    const changeLabels = (values: string[]) => {
+        setHost(host => {
+            const labels: { [key: string]: string } = {};
+            values.forEach(value => {
+                if (value === '') {
+                    return;
+                }
+                const idx = value.indexOf('=');
+                if (idx === -1) {
+                    labels[value] = '';
+                } else {
+                    labels[value.substring(0, idx)] = value.substring(idx + 1);
+                }
+            });
+            host.Labels = labels;
+            return { ...host };
+        });
+    };
+
+    const labelValues = () => {
+        return Object.keys(host.Labels || {}).map(key => {
+            return key + '=' + host.Labels[key];
+        });
+    };
+
     const changeGroupIDs = (GroupIDs: string[]) => {
         setHost(host => {
             host.GroupIDs = GroupIDs;
@@ -137,6 +172,17 @@ export const HostEdit: React.FC = () => {
         return (<PageLoading />);
     }
 
+    const connectionModeChoices: RadioChoice[] = [
+        {
+            label: 'Direct',
+            value: HostConnectionMode.Direct
+        },
+        {
+            label: 'Reverse',
+            value: HostConnectionMode.Reverse
+        }
+    ];
+
     const breadcrumbs = [
         {
             title: 'Hosts',
@@ -173,6 +219,18 @@ export const HostEdit: React.FC = () => {
                     defaultValue={host.Port}
                     onChange={changePort}
                     required />
+                <Input.Radio
+                    label="Connection Mode"
+                    buttons
+                    choices={connectionModeChoices}
+                    defaultValue={host.ConnectionMode || HostConnectionMode.Direct}
+                    onChange={changeConnectionMode} />
+                <MultiInput
+                    label="Labels"
+                    placeholder="key=value"
+                    helpText="Labels can be matched by selectors in groups and schedules."
+                    defaultValue={labelValues()}
+                    onChange={changeLabels} />
                 <Card.Card className="mt-3">
                     <Card.Header>Environment Variables</Card.Header>
                     <Card.Body>
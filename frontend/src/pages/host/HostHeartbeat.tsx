@@ -47,6 +47,21 @@ export const HostHeartbeat: React.FC<HostHeartbeatProps> = (props: HostHeartbeat
         return (<ListGroup.TextItem title="Agent Version"><AgentVersion heartbeat={Heartbeat} /></ListGroup.TextItem>);
     };
 
+    const capabilities = (): JSX.Element => {
+        if (!Heartbeat || !Heartbeat.Capabilities || !Heartbeat.Capabilities.protocol_version) {
+            return null;
+        }
+
+        return (
+            <React.Fragment>
+                <ListGroup.TextItem title="Protocol Version">{Heartbeat.Capabilities.protocol_version}</ListGroup.TextItem>
+                <ListGroup.TextItem title="Features">{(Heartbeat.Capabilities.features || []).map((feature, idx) => {
+                    return (<code className="me-1" key={idx}>{feature}</code>);
+                })}</ListGroup.TextItem>
+            </React.Fragment>
+        );
+    };
+
     const hostProperties = (): JSX.Element => {
         if (!Heartbeat || !Heartbeat.Properties) {
             return null;
@@ -85,6 +100,7 @@ export const HostHeartbeat: React.FC<HostHeartbeatProps> = (props: HostHeartbeat
                 </ListGroup.TextItem>
                 {lastReply()}
                 {agentVersion()}
+                {capabilities()}
                 {hostProperties()}
             </ListGroup.List>
         </Card.Card>
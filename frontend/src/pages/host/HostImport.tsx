@@ -0,0 +1,125 @@
+import * as React from 'react';
+import { useNavigate } from 'react-router-dom';
+import { Page } from '../../components/Page';
+import { Input } from '../../components/input/Input';
+import { Form } from '../../components/Form';
+import { Button } from '../../components/Button';
+import { Card } from '../../components/Card';
+import { ListGroup } from '../../components/ListGroup';
+import { Icon } from '../../components/Icon';
+import { Style } from '../../components/Style';
+import { Notification } from '../../components/Notification';
+import { Inventory, InventoryImportResultType } from '../../types/Inventory';
+import { InventoryFormat, InventoryFormatConfig } from '../../types/cbgen_enum';
+
+export const HostImport: React.FC = () => {
+    const [format, setFormat] = React.useState<InventoryFormat>(InventoryFormat.CSV);
+    const [data, setData] = React.useState('');
+    const [result, setResult] = React.useState<InventoryImportResultType>();
+    const [loading, setLoading] = React.useState(false);
+    const navigate = useNavigate();
+
+    const changeFormat = (Format: string) => {
+        setFormat(Format as InventoryFormat);
+        setResult(undefined);
+    };
+
+    const changeData = (Data: string) => {
+        setData(Data);
+        setResult(undefined);
+    };
+
+    const validateClick = () => {
+        setLoading(true);
+        Inventory.Import(format, data, true).then(result => {
+            setResult(result);
+            setLoading(false);
+        }, () => {
+            setLoading(false);
+        });
+    };
+
+    const formSave = () => {
+        setLoading(true);
+        Inventory.Import(format, data, false).then(result => {
+            Notification.success('Imported ' + result.Hosts.length + ' Hosts and ' + result.Groups.length + ' Groups');
+            navigate('/hosts');
+        }, () => {
+            setLoading(false);
+        });
+    };
+
+    const breadcrumbs = [
+        {
+            title: 'Hosts',
+            href: '/hosts',
+        },
+        {
+            title: 'Import'
+        }
+    ];
+
+    return (
+        <Page title={breadcrumbs}>
+            <Form showSaveButton onSubmit={formSave} loading={loading}>
+                <Input.Select
+                    label="Format"
+                    defaultValue={format}
+                    onChange={changeFormat}
+                    required>
+                    {InventoryFormatConfig().map(f => (<option key={f.value} value={f.value}>{f.description}</option>))}
+                </Input.Select>
+                <Input.Textarea
+                    label="Inventory"
+                    helpText="Hosts and groups that already exist are not changed. Validate the inventory to see what will be added before importing it."
+                    defaultValue={data}
+                    onChange={changeData}
+                    rows={15}
+                    fixedWidth
+                    required />
+                <Button color={Style.Palette.Secondary} outline size={Style.Size.S} onClick={validateClick} disabled={loading}>
+                    <Icon.Label icon={<Icon.CheckCircle />} label="Validate" />
+                </Button>
+                <InventoryImportResult result={result} />
+            </Form>
+        </Page>
+    );
+};
+
+interface InventoryImportResultProps {
+    result?: InventoryImportResultType;
+}
+const InventoryImportResult: React.FC<InventoryImportResultProps> = (props: InventoryImportResultProps) => {
+    if (!props.result) {
+        return null;
+    }
+
+    const problems = (title: string, messages: string[], icon: JSX.Element) => {
+        if (messages.length === 0) {
+            return null;
+        }
+
+        return (
+            <Card.Card className="mt-3">
+                <Card.Header>{title}</Card.Header>
+                <ListGroup.List>
+                    {messages.map((message, idx) => (<ListGroup.Item key={idx}><Icon.Label icon={icon} label={message} /></ListGroup.Item>))}
+                </ListGroup.List>
+            </Card.Card>
+        );
+    };
+
+    return (
+        <React.Fragment>
+            {problems('Conflicts', props.result.Conflicts || [], <Icon.TimesCircle color={Style.Palette.Danger} />)}
+            {problems('Warnings', props.result.Warnings || [], <Icon.ExclamationTriangle color={Style.Palette.Warning} />)}
+            <Card.Card className="mt-3">
+                <Card.Header>Will Be Added</Card.Header>
+                <ListGroup.List>
+                    <ListGroup.TextItem title="Groups">{(props.result.Groups || []).map(group => group.Name).join(', ') || 'None'}</ListGroup.TextItem>
+                    <ListGroup.TextItem title="Hosts">{(props.result.Hosts || []).map(host => host.Name).join(', ') || 'None'}</ListGroup.TextItem>
+                </ListGroup.List>
+            </Card.Card>
+        </React.Fragment>
+    );
+};
@@ -2,7 +2,7 @@ import * as React from 'react';
 import { Host, HostType } from '../../types/Host';
 import { PageLoading } from '../../components/Loading';
 import { Page } from '../../components/Page';
-import { CreateButton } from '../../components/Button';
+import { CreateButton, ButtonLink, ButtonAnchor } from '../../components/Button';
 import { Column, Table } from '../../components/Table';
 import { Heartbeat, HeartbeatType } from '../../types/Heartbeat';
 import { Link } from 'react-router-dom';
@@ -14,6 +14,9 @@ import { AgentVersion } from '../../components/AgentVersion';
 import { ContextMenuItem } from '../../components/ContextMenu';
 import { Icon } from '../../components/Icon';
 import { Permissions, UserAction } from '../../services/Permissions';
+import { Style } from '../../components/Style';
+import { Inventory } from '../../types/Inventory';
+import { InventoryFormat } from '../../types/cbgen_enum';
 
 export const HostList: React.FC = () => {
     const [loading, setLoading] = React.useState(true);
@@ -49,6 +52,18 @@ export const HostList: React.FC = () => {
     const toolbar = (
         <React.Fragment>
             <CreateButton to="/hosts/host/" disabled={!Permissions.UserCan(UserAction.ModifyHosts)} />
+            <ButtonLink to="/hosts/import" color={Style.Palette.Primary} outline size={Style.Size.S} disabled={!Permissions.UserCan(UserAction.ModifyHosts) || !Permissions.UserCan(UserAction.ModifyGroups)}>
+                <Icon.Label label="Import" icon={<Icon.Plus />} />
+            </ButtonLink>
+            <ButtonAnchor href={Inventory.ExportURL(InventoryFormat.CSV)} color={Style.Palette.Secondary} outline download>
+                <Icon.Label label="Export CSV" icon={<Icon.Download />} />
+            </ButtonAnchor>
+            <ButtonAnchor href={Inventory.ExportURL(InventoryFormat.AnsibleINI)} color={Style.Palette.Secondary} outline download>
+                <Icon.Label label="Export Ansible INI" icon={<Icon.Download />} />
+            </ButtonAnchor>
+            <ButtonAnchor href={Inventory.ExportURL(InventoryFormat.AnsibleYAML)} color={Style.Palette.Secondary} outline download>
+                <Icon.Label label="Export Ansible YAML" icon={<Icon.Download />} />
+            </ButtonAnchor>
         </React.Fragment>
     );
 
@@ -3,8 +3,14 @@ import { Modal, ModalButton } from '../../components/Modal';
 import { RunSetup } from './RunSetup';
 import { RunScript } from './RunScript';
 import { Style } from '../../components/Style';
+import { Loading } from '../../components/Loading';
+import { Input } from '../../components/input/Input';
+import { Notification } from '../../components/Notification';
+import { Script } from '../../types/Script';
+import { Approval } from '../../types/Approval';
 
 enum RunStage {
+    Loading,
     Setup,
     Running,
     Finished,
@@ -15,14 +21,25 @@ interface RunModalProps {
     hostIDs?: string[];
 }
 export const RunModal: React.FC<RunModalProps> = (props: RunModalProps) => {
-    const [stage, setStage] = React.useState(props.hostIDs ? RunStage.Running : RunStage.Setup);
+    const [stage, setStage] = React.useState(RunStage.Loading);
+    const [requiresApproval, setRequiresApproval] = React.useState(false);
+    const [reason, setReason] = React.useState('');
     const [selectedHostIDs, setSelectedHostIDs] = React.useState(props.hostIDs);
     const [finishedHosts, setFinishedHosts] = React.useState<string[]>([]);
+    const [parameters, setParameters] = React.useState<{ [name: string]: string }>({});
 
     const onSelectHostIDs = (hostIDs: string[]) => {
         setSelectedHostIDs(hostIDs);
     };
 
+    React.useEffect(() => {
+        Script.Get(props.scriptID).then(script => {
+            setRequiresApproval(script.RequiresApproval);
+            // Scripts that require approval always show the setup so that a reason can be given
+            setStage(props.hostIDs && !script.RequiresApproval ? RunStage.Running : RunStage.Setup);
+        });
+    }, []);
+
     React.useEffect(() => {
         checkFinished();
     }, [finishedHosts]);
@@ -42,17 +59,41 @@ export const RunModal: React.FC<RunModalProps> = (props: RunModalProps) => {
     };
 
     const setup = () => {
+        if (requiresApproval) {
+            return (
+                <React.Fragment>
+                    {props.hostIDs ? null : (<RunSetup scriptID={props.scriptID} onSelectedHosts={onSelectHostIDs} parameters={parameters} onChangeParameters={setParameters} />)}
+                    <Input.Textarea
+                        label="Reason"
+                        defaultValue={reason}
+                        onChange={setReason}
+                        helpText="This script requires approval from another user before it is run." />
+                </React.Fragment>
+            );
+        }
+
         return (
-            <RunSetup scriptID={props.scriptID} onSelectedHosts={onSelectHostIDs} />
+            <RunSetup scriptID={props.scriptID} onSelectedHosts={onSelectHostIDs} parameters={parameters} onChangeParameters={setParameters} />
         );
     };
 
+    const requestApproval = () => {
+        Approval.Request({
+            ScriptID: props.scriptID,
+            HostIDs: selectedHostIDs,
+            Parameters: parameters,
+            Reason: reason,
+        }).then(() => {
+            Notification.success('Approval Requested');
+        });
+    };
+
     const running = () => {
         return (
             <div className="cards">
                 {selectedHostIDs.map(hostID => {
                     return (
-                        <RunScript scriptID={props.scriptID} hostID={hostID} key={hostID} onFinished={scriptFinished(hostID)} />
+                        <RunScript scriptID={props.scriptID} hostID={hostID} parameters={parameters} key={hostID} onFinished={scriptFinished(hostID)} />
                     );
                 })}
             </div>
@@ -60,7 +101,7 @@ export const RunModal: React.FC<RunModalProps> = (props: RunModalProps) => {
     };
 
     const buttons = (): ModalButton[] => {
-        if (stage == RunStage.Running) {
+        if (stage == RunStage.Loading || stage == RunStage.Running) {
             return [];
         }
 
@@ -77,7 +118,14 @@ export const RunModal: React.FC<RunModalProps> = (props: RunModalProps) => {
                 label: 'Cancel',
                 color: Style.Palette.Secondary,
             },
-            {
+            requiresApproval ? {
+                label: 'Request Approval',
+                onClick: () => {
+                    if (selectedHostIDs && selectedHostIDs.length > 0) {
+                        requestApproval();
+                    }
+                },
+            } : {
                 label: 'Start',
                 onClick: () => {
                     if (selectedHostIDs && selectedHostIDs.length > 0) {
@@ -90,7 +138,9 @@ export const RunModal: React.FC<RunModalProps> = (props: RunModalProps) => {
     };
 
     const content = () => {
-        if (stage == RunStage.Setup) {
+        if (stage == RunStage.Loading) {
+            return (<Loading />);
+        } else if (stage == RunStage.Setup) {
             return setup();
         } else if (stage == RunStage.Running || stage == RunStage.Finished) {
             return running();
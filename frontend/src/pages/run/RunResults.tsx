@@ -7,6 +7,7 @@ import { ListGroup } from '../../components/ListGroup';
 import { Icon } from '../../components/Icon';
 import { Style } from '../../components/Style';
 import { Formatter } from '../../services/Formatter';
+import { ScriptStatus } from '../../types/cbgen_enum';
 
 interface RunResultsProps {
     results: ScriptRun;
@@ -14,10 +15,11 @@ interface RunResultsProps {
 export const RunResults: React.FC<RunResultsProps> = (props: RunResultsProps) => {
     const error = () => {
         const errorMessage = props.results.RunError || props.results.Result.exec_error || 'Unknown Error';
+        const title = props.results.Result.timed_out ? 'Script Timed Out' : 'Error Running Script';
 
         return (
             <Card.Body>
-                <h4>Error Running Script</h4>
+                <h4>{title}</h4>
                 <strong>Details</strong>
                 <Pre>{errorMessage}</Pre>
             </Card.Body>
@@ -29,16 +31,24 @@ export const RunResults: React.FC<RunResultsProps> = (props: RunResultsProps) =>
     }
 
     let returnCodeIcon = (<Icon.CheckCircle color={Style.Palette.Success} />);
-    if (props.results.Result.code !== 0) {
+    const failed = props.results.Status ? props.results.Status === ScriptStatus.Failed : props.results.Result.code !== 0;
+    if (failed) {
         returnCodeIcon = (<Icon.ExclamationCircle color={Style.Palette.Danger} />);
     }
 
+    let failureReason: JSX.Element;
+    if (props.results.FailureReason) {
+        failureReason = (<ListGroup.TextItem title="Failure Reason">{props.results.FailureReason}</ListGroup.TextItem>);
+    }
+
     return (
         <Card.Body>
             <Card.Card>
                 <Card.Header>Details</Card.Header>
                 <ListGroup.List>
                     <ListGroup.TextItem title="Return Code">{props.results.Result.code} {returnCodeIcon}</ListGroup.TextItem>
+                    <ListGroup.TextItem title="Status">{props.results.Status}</ListGroup.TextItem>
+                    {failureReason}
                     <ListGroup.TextItem title="Duration">{Formatter.DurationNS(props.results.Duration)}</ListGroup.TextItem>
                 </ListGroup.List>
             </Card.Card>
@@ -12,6 +12,7 @@ import { Script } from '../../types/Script';
 interface RunScriptProps {
     hostID: string;
     scriptID: string;
+    parameters?: { [name: string]: string };
     onFinished: (results?: ScriptRun) => (void);
 }
 export const RunScript: React.FC<RunScriptProps> = (props: RunScriptProps) => {
@@ -22,7 +23,7 @@ export const RunScript: React.FC<RunScriptProps> = (props: RunScriptProps) => {
     const [stdout, setStdout] = React.useState<string>();
     const [stderr, setStderr] = React.useState<string>();
     // eslint-disable-next-line @typescript-eslint/no-unused-vars
-    const [scriptConnection, setScriptConnection] = React.useState<ScriptRequest>(new ScriptRequest(props.scriptID, props.hostID));
+    const [scriptConnection, setScriptConnection] = React.useState<ScriptRequest>(new ScriptRequest(props.scriptID, props.hostID, props.parameters));
 
     React.useEffect(() => {
         loadHost();
@@ -1,10 +1,11 @@
 import * as React from 'react';
-import { Script } from '../../types/Script';
+import { Script, ScriptParameter } from '../../types/Script';
 import { Loading } from '../../components/Loading';
 import { Input } from '../../components/input/Input';
 import { Form } from '../../components/Form';
 import { Card } from '../../components/Card';
 import { Alert } from '../../components/Alert';
+import { ScriptParameterInput } from '../../components/ScriptParameterInput';
 
 interface SGroup {
     ID: string;
@@ -19,6 +20,8 @@ interface SHost {
 interface RunSetupProps {
     scriptID: string;
     onSelectedHosts: (hostIDs: string[]) => (void);
+    parameters: { [name: string]: string };
+    onChangeParameters: (parameters: { [name: string]: string }) => (void);
 }
 export const RunSetup: React.FC<RunSetupProps> = (props: RunSetupProps) => {
     const [loading, setLoading] = React.useState<boolean>(true);
@@ -27,6 +30,7 @@ export const RunSetup: React.FC<RunSetupProps> = (props: RunSetupProps) => {
     const [selectedGroups, setSelectedGroups] = React.useState<{ [id: string]: number }>();
     const [selectedHosts, setSelectedHosts] = React.useState<{ [id: string]: number }>();
     const [groupMembers, setGroupMembers] = React.useState<{ [id: string]: string[] }>();
+    const [scriptParameters, setScriptParameters] = React.useState<ScriptParameter[]>([]);
 
     const loadData = () => {
         Script.Get(props.scriptID).then(script => {
@@ -58,6 +62,7 @@ export const RunSetup: React.FC<RunSetupProps> = (props: RunSetupProps) => {
                 setSelectedGroups(selectedGroups);
                 setSelectedHosts(selectedHosts);
                 setGroupMembers(groupMembership);
+                setScriptParameters(script.Parameters || []);
                 setLoading(false);
             });
         });
@@ -133,8 +138,24 @@ export const RunSetup: React.FC<RunSetupProps> = (props: RunSetupProps) => {
         </Alert.Warning>);
     }
 
+    const parametersCard = () => {
+        if (scriptParameters.length == 0) {
+            return null;
+        }
+
+        return (
+            <Card.Card className="mb-3">
+                <Card.Header>Parameters</Card.Header>
+                <Card.Body>
+                    <ScriptParameterInput parameters={scriptParameters} values={props.parameters} onChange={props.onChangeParameters} />
+                </Card.Body>
+            </Card.Card>
+        );
+    };
+
     return (
         <Form>
+            {parametersCard()}
             <Card.Card>
                 <Card.Header>Groups</Card.Header>
                 <Card.Body>
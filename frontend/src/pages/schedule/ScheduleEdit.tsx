@@ -1,5 +1,5 @@
 import * as React from 'react';
-import { Schedule, ScheduleType } from '../../types/Schedule';
+import { RetryPolicy, RolloutStrategy, Schedule, ScheduleType } from '../../types/Schedule';
 import { Link, useParams, useNavigate } from 'react-router-dom';
 import { URLParams } from '../../services/Params';
 import { PageLoading } from '../../components/Loading';
@@ -14,6 +14,9 @@ import { Alert } from '../../components/Alert';
 import { Icon } from '../../components/Icon';
 import { Checkbox } from '../../components/input/Checkbox';
 import { RadioChoice } from '../../components/input/Radio';
+import { ScriptParameterInput } from '../../components/ScriptParameterInput';
+import { CatchUpPolicy, OverlapPolicy, RetryFailure, ScheduleTrigger } from '../../types/cbgen_enum';
+import { ListGroup } from '../../components/ListGroup';
 
 export const ScheduleEdit: React.FC = () => {
     const { id } = useParams() as URLParams;
@@ -24,12 +27,25 @@ export const ScheduleEdit: React.FC = () => {
     const [runOn, setRunOn] = React.useState<'groups' | 'hosts'>();
     const [patternTemplate, setPatternTemplate] = React.useState<string>();
     const [scripts, setScripts] = React.useState<ScriptType[]>();
+    const [nextRuns, setNextRuns] = React.useState<string[]>();
     const navigate = useNavigate();
 
     React.useEffect(() => {
         loadData();
     }, []);
 
+    const pattern = schedule ? schedule.Pattern : undefined;
+    const timezone = schedule ? schedule.Timezone : undefined;
+    React.useEffect(() => {
+        if (!pattern) {
+            setNextRuns(undefined);
+            return;
+        }
+        Schedule.NextRuns(pattern, timezone || '').then(setNextRuns, () => {
+            setNextRuns(undefined);
+        });
+    }, [pattern, timezone]);
+
     const loadSchedule = () => {
         if (id == null) {
             return Promise.resolve(Schedule.Blank());
@@ -72,6 +88,13 @@ export const ScheduleEdit: React.FC = () => {
                 if (schedule.Scope.HostIDs && schedule.Scope.HostIDs.length > 0) {
                     runOn = 'hosts';
                 }
+
+                if (!schedule.Rollout.AbortPolicy) {
+                    schedule.Rollout.AbortPolicy = 'continue';
+                }
+                if (!schedule.Retry.RetryOn) {
+                    schedule.Retry.RetryOn = [];
+                }
             }
 
             setSchedule(schedule);
@@ -93,6 +116,7 @@ export const ScheduleEdit: React.FC = () => {
     const changeScriptID = (ScriptID: string) => {
         setSchedule(schedule => {
             schedule.ScriptID = ScriptID;
+            schedule.Parameters = {};
             return { ...schedule };
         });
     };
@@ -145,6 +169,149 @@ export const ScheduleEdit: React.FC = () => {
         );
     };
 
+    const nextRunsList = () => {
+        if (!nextRuns) {
+            return null;
+        }
+
+        return (
+            <Card.Card className="mb-3">
+                <Card.Header>Next Runs</Card.Header>
+                <ListGroup.List>
+                    {nextRuns.map((run, idx) => {
+                        return (<ListGroup.Item key={idx}>{run}</ListGroup.Item>);
+                    })}
+                </ListGroup.List>
+            </Card.Card>
+        );
+    };
+
+    const changeTrigger = (Trigger: string) => {
+        setSchedule(schedule => {
+            schedule.Trigger = Trigger;
+            return { ...schedule };
+        });
+    };
+
+    const changeRunAt = (RunAt: string) => {
+        setSchedule(schedule => {
+            // The input is in the local time of the browser
+            const runAt = new Date(RunAt);
+            schedule.RunAt = isNaN(runAt.getTime()) ? undefined : runAt.toISOString();
+            return { ...schedule };
+        });
+    };
+
+    const changeTriggerEvent = (TriggerEvent: string) => {
+        setSchedule(schedule => {
+            schedule.TriggerEvent = TriggerEvent;
+            return { ...schedule };
+        });
+    };
+
+    const changeEventHost = (EventHost: boolean) => {
+        setSchedule(schedule => {
+            schedule.EventHost = EventHost;
+            return { ...schedule };
+        });
+    };
+
+    const runAtValue = () => {
+        if (!schedule.RunAt) {
+            return undefined;
+        }
+        const runAt = new Date(schedule.RunAt);
+        if (runAt.getFullYear() <= 1) {
+            return undefined;
+        }
+        const pad = (n: number) => n.toString().padStart(2, '0');
+        return runAt.getFullYear() + '-' + pad(runAt.getMonth() + 1) + '-' + pad(runAt.getDate()) + 'T' + pad(runAt.getHours()) + ':' + pad(runAt.getMinutes());
+    };
+
+    const triggerInputs = () => {
+        switch (schedule.Trigger) {
+            case ScheduleTrigger.Once:
+                return (
+                    <Input.Text
+                        label="Run At"
+                        type="datetime-local"
+                        helpText="The schedule runs once at this time and is then disabled."
+                        defaultValue={runAtValue()}
+                        onChange={changeRunAt}
+                        required />
+                );
+            case ScheduleTrigger.Event:
+                return (
+                    <React.Fragment>
+                        <Input.Select
+                            label="Event"
+                            defaultValue={schedule.TriggerEvent}
+                            onChange={changeTriggerEvent}
+                            required>
+                            <option value="HostAdded">Host Added</option>
+                            <option value="HostModified">Host Modified</option>
+                            <option value="HostRegisterSuccess">Host Registered</option>
+                            <option value="HostTrustModified">Host Trust Modified</option>
+                            <option value="HostIdentityRotated">Host Identity Rotated</option>
+                            <option value="HostBecameReachable">Host Became Reachable</option>
+                        </Input.Select>
+                        <Checkbox
+                            label="Only run on the host from the event"
+                            helpText="If hosts or groups are selected below then the host must also be one of them."
+                            defaultValue={schedule.EventHost}
+                            onChange={changeEventHost} />
+                    </React.Fragment>
+                );
+        }
+
+        return (
+            <React.Fragment>
+                <Input.Select
+                    label="Run Frequency"
+                    helpText="The schedule triggers in the timezone below"
+                    defaultValue={patternTemplate}
+                    onChange={changePatternTemplate}
+                    required>
+                    <option value="0 * * * *">Every Hour</option>
+                    <option value="0 */4 * * *">Every 4 Hours</option>
+                    <option value="0 0 * * *">Every Day at Midnight</option>
+                    <option value="0 0 * * 1">Every Monday at Midnight</option>
+                    <option value="custom">Custom</option>
+                </Input.Select>
+                {cronPatternInput()}
+                <Input.Text
+                    label="Timezone"
+                    type="text"
+                    placeholder="UTC"
+                    helpText="The IANA timezone name, such as America/New_York. Leave empty for UTC."
+                    defaultValue={schedule.Timezone}
+                    onChange={changeTimezone} />
+                {nextRunsList()}
+            </React.Fragment>
+        );
+    };
+
+    const changeTimezone = (Timezone: string) => {
+        setSchedule(schedule => {
+            schedule.Timezone = Timezone;
+            return { ...schedule };
+        });
+    };
+
+    const changeCatchUp = (CatchUp: string) => {
+        setSchedule(schedule => {
+            schedule.CatchUp = CatchUp;
+            return { ...schedule };
+        });
+    };
+
+    const changeJitterSeconds = (JitterSeconds: number) => {
+        setSchedule(schedule => {
+            schedule.JitterSeconds = isNaN(JitterSeconds) ? 0 : JitterSeconds;
+            return { ...schedule };
+        });
+    };
+
     const changePattern = (Pattern: string) => {
         setSchedule(schedule => {
             schedule.Pattern = Pattern;
@@ -152,6 +319,20 @@ export const ScheduleEdit: React.FC = () => {
         });
     };
 
+    const changeSelector = (Selector: string) => {
+        setSchedule(schedule => {
+            schedule.Scope.Selector = Selector;
+            return { ...schedule };
+        });
+    };
+
+    const changeExcludeSelector = (ExcludeSelector: string) => {
+        setSchedule(schedule => {
+            schedule.Scope.ExcludeSelector = ExcludeSelector;
+            return { ...schedule };
+        });
+    };
+
     const changeRunOn = (RunOn: string) => {
         if (runOn === RunOn) {
             return;
@@ -165,6 +346,182 @@ export const ScheduleEdit: React.FC = () => {
         });
     };
 
+    const changeMaxParallelism = (MaxParallelism: number) => {
+        setSchedule(schedule => {
+            schedule.Execution.MaxParallelism = MaxParallelism;
+            return { ...schedule };
+        });
+    };
+
+    const changeBatchDelaySeconds = (BatchDelaySeconds: number) => {
+        setSchedule(schedule => {
+            schedule.Execution.BatchDelaySeconds = BatchDelaySeconds;
+            return { ...schedule };
+        });
+    };
+
+    const changeTimeoutSeconds = (TimeoutSeconds: number) => {
+        setSchedule(schedule => {
+            schedule.TimeoutSeconds = isNaN(TimeoutSeconds) ? 0 : TimeoutSeconds;
+            return { ...schedule };
+        });
+    };
+
+    const changeOverlapPolicy = (OverlapPolicy: string) => {
+        setSchedule(schedule => {
+            schedule.OverlapPolicy = OverlapPolicy;
+            return { ...schedule };
+        });
+    };
+
+    const changeRollout = (key: keyof RolloutStrategy) => {
+        return (value: number | string) => {
+            setSchedule(schedule => {
+                schedule.Rollout = { ...schedule.Rollout, [key]: value };
+                return { ...schedule };
+            });
+        };
+    };
+
+    const rolloutCard = () => {
+        return (
+            <Card.Card>
+                <Card.Header>Rollout</Card.Header>
+                <Card.Body>
+                    <Input.Number
+                        label="Canary Hosts"
+                        append="Hosts"
+                        minimum={0}
+                        helpText="The number of hosts to run the script on before any others. Set to 0 for no canary."
+                        defaultValue={schedule.Rollout.CanaryHosts}
+                        onChange={changeRollout('CanaryHosts')} />
+                    <Input.Number
+                        label="Canary Percentage"
+                        append="%"
+                        minimum={0}
+                        maximum={100}
+                        helpText="The percentage of hosts to run the script on before any others. Cannot be used with canary hosts."
+                        defaultValue={schedule.Rollout.CanaryPercent}
+                        onChange={changeRollout('CanaryPercent')} />
+                    <Input.Number
+                        label="Batch Size"
+                        append="Hosts"
+                        minimum={0}
+                        helpText="The number of hosts in each batch after the canary. Set to 0 to run all remaining hosts in one batch."
+                        defaultValue={schedule.Rollout.BatchSize}
+                        onChange={changeRollout('BatchSize')} />
+                    <Input.Number
+                        label="Maximum Failure Rate"
+                        append="%"
+                        minimum={0}
+                        maximum={100}
+                        helpText="The rollout is aborted once more than this percentage of hosts have failed."
+                        defaultValue={schedule.Rollout.MaxFailurePercent}
+                        onChange={changeRollout('MaxFailurePercent')} />
+                    <Input.Select
+                        label="Abort Policy"
+                        defaultValue={schedule.Rollout.AbortPolicy}
+                        onChange={changeRollout('AbortPolicy')}>
+                        <option value="continue">Continue the rollout</option>
+                        <option value="stop">Stop after the current batch</option>
+                        <option value="cancel">Stop and cancel running hosts</option>
+                    </Input.Select>
+                </Card.Body>
+            </Card.Card>
+        );
+    };
+
+    const changeRetry = (key: keyof RetryPolicy) => {
+        return (value: number | boolean) => {
+            setSchedule(schedule => {
+                schedule.Retry = { ...schedule.Retry, [key]: value };
+                return { ...schedule };
+            });
+        };
+    };
+
+    const changeRetryOn = (failure: RetryFailure) => {
+        return (checked: boolean) => {
+            setSchedule(schedule => {
+                const RetryOn = schedule.Retry.RetryOn.filter(f => f !== failure);
+                if (checked) {
+                    RetryOn.push(failure);
+                }
+                schedule.Retry = { ...schedule.Retry, RetryOn: RetryOn };
+                return { ...schedule };
+            });
+        };
+    };
+
+    const retryCard = () => {
+        return (
+            <Card.Card className="mt-3">
+                <Card.Header>Retry</Card.Header>
+                <Card.Body>
+                    <Input.Number
+                        label="Maximum Attempts"
+                        minimum={0}
+                        maximum={10}
+                        helpText="The maximum number of times the script is run on a host, including the first attempt. Set to 0 or 1 to never retry."
+                        defaultValue={schedule.Retry.MaxAttempts}
+                        onChange={changeRetry('MaxAttempts')} />
+                    <Input.Number
+                        label="Backoff"
+                        append="Seconds"
+                        minimum={0}
+                        helpText="How long to wait before retrying a failed host."
+                        defaultValue={schedule.Retry.BackoffSeconds}
+                        onChange={changeRetry('BackoffSeconds')} />
+                    <Checkbox
+                        label="Exponential Backoff"
+                        helpText="If checked the backoff is doubled for each retry."
+                        defaultValue={schedule.Retry.Exponential}
+                        onChange={changeRetry('Exponential')} />
+                    <Checkbox
+                        label="Retry Connection Errors"
+                        defaultValue={schedule.Retry.RetryOn.includes(RetryFailure.Connection)}
+                        onChange={changeRetryOn(RetryFailure.Connection)} />
+                    <Checkbox
+                        label="Retry Execution Errors"
+                        defaultValue={schedule.Retry.RetryOn.includes(RetryFailure.Exec)}
+                        onChange={changeRetryOn(RetryFailure.Exec)} />
+                    <Checkbox
+                        label="Retry Failed Scripts"
+                        helpText="Retry scripts that exited with a non-zero exit code or did not meet their success criteria."
+                        defaultValue={schedule.Retry.RetryOn.includes(RetryFailure.ExitCode)}
+                        onChange={changeRetryOn(RetryFailure.ExitCode)} />
+                </Card.Body>
+            </Card.Card>
+        );
+    };
+
+    const changeParameters = (Parameters: { [name: string]: string }) => {
+        setSchedule(schedule => {
+            schedule.Parameters = Parameters;
+            return { ...schedule };
+        });
+    };
+
+    const parametersCard = () => {
+        const script = scripts.find(script => script.ID == schedule.ScriptID);
+        if (!script || (script.Parameters || []).length == 0) {
+            return null;
+        }
+
+        return (
+            <Card.Card className="mb-3">
+                <Card.Header>Script Parameters</Card.Header>
+                <Card.Body>
+                    <ScriptParameterInput
+                        key={script.ID}
+                        parameters={script.Parameters}
+                        values={schedule.Parameters || {}}
+                        onChange={changeParameters} />
+                </Card.Body>
+            </Card.Card>
+        );
+    };
+
     const changeHostIDs = (HostIDs: string[]) => {
         setSchedule(schedule => {
             schedule.Scope.HostIDs = HostIDs;
@@ -285,19 +642,34 @@ export const ScheduleEdit: React.FC = () => {
                         return (<option value={script.ID} key={idx}>{script.Name}</option>);
                     })}
                 </Input.Select>
+                {parametersCard()}
                 <Input.Select
-                    label="Run Frequency"
-                    helpText="All schedules trigger in UTC/GMT time"
-                    defaultValue={patternTemplate}
-                    onChange={changePatternTemplate}
+                    label="Trigger"
+                    defaultValue={schedule.Trigger || ScheduleTrigger.Pattern}
+                    onChange={changeTrigger}
                     required>
-                    <option value="0 * * * *">Every Hour</option>
-                    <option value="0 */4 * * *">Every 4 Hours</option>
-                    <option value="0 0 * * *">Every Day at Midnight</option>
-                    <option value="0 0 * * 1">Every Monday at Midnight</option>
-                    <option value="custom">Custom</option>
+                    <option value={ScheduleTrigger.Pattern}>On a schedule</option>
+                    <option value={ScheduleTrigger.Once}>Once</option>
+                    <option value={ScheduleTrigger.Event}>When an event happens</option>
+                </Input.Select>
+                {triggerInputs()}
+                <Input.Number
+                    label="Jitter"
+                    append="Seconds"
+                    minimum={0}
+                    maximum={3600}
+                    helpText="If set, each run starts after a random delay of up to this long."
+                    defaultValue={schedule.JitterSeconds}
+                    onChange={changeJitterSeconds} />
+                <Input.Select
+                    label="Missed Runs"
+                    helpText="What happens to runs that were missed while the Otto server was not running."
+                    defaultValue={schedule.CatchUp}
+                    onChange={changeCatchUp}>
+                    <option value={CatchUpPolicy.Skip}>Skip missed runs</option>
+                    <option value={CatchUpPolicy.Once}>Run once</option>
+                    <option value={CatchUpPolicy.All}>Run every missed run</option>
                 </Input.Select>
-                {cronPatternInput()}
                 <Input.Radio
                     label="Run On"
                     onChange={changeRunOn}
@@ -305,6 +677,52 @@ export const ScheduleEdit: React.FC = () => {
                     defaultValue={runOn} />
                 {hostList()}
                 {groupList()}
+                <Input.Text
+                    label="Selector"
+                    type="text"
+                    placeholder="env=prod,os in (debian,ubuntu)"
+                    helpText="Optional. Hosts with labels or properties that match the selector are also included."
+                    defaultValue={schedule.Scope.Selector}
+                    onChange={changeSelector} />
+                <Input.Text
+                    label="Exclude Selector"
+                    type="text"
+                    placeholder="canary"
+                    helpText="Optional. Hosts that match this selector are never included."
+                    defaultValue={schedule.Scope.ExcludeSelector}
+                    onChange={changeExcludeSelector} />
+                <Input.Number
+                    label="Max Parallelism"
+                    append="Hosts"
+                    minimum={0}
+                    helpText="The maximum number of hosts to run the script on at once. Set to 0 to use the server default."
+                    defaultValue={schedule.Execution.MaxParallelism}
+                    onChange={changeMaxParallelism} />
+                <Input.Number
+                    label="Batch Delay"
+                    append="Seconds"
+                    minimum={0}
+                    helpText="If set, hosts are run in batches and Otto will wait this long after each batch before starting the next."
+                    defaultValue={schedule.Execution.BatchDelaySeconds}
+                    onChange={changeBatchDelaySeconds} />
+                <Input.Number
+                    label="Timeout"
+                    append="Seconds"
+                    minimum={0}
+                    helpText="If set, replaces the timeout of the script when it is run by this schedule."
+                    defaultValue={schedule.TimeoutSeconds}
+                    onChange={changeTimeoutSeconds} />
+                <Input.Select
+                    label="Overlapping Runs"
+                    helpText="What happens if the previous run of this schedule, or the script on a host, is still in progress."
+                    defaultValue={schedule.OverlapPolicy}
+                    onChange={changeOverlapPolicy}>
+                    <option value={OverlapPolicy.Skip}>Skip the run</option>
+                    <option value={OverlapPolicy.Queue}>Wait for the previous run to finish</option>
+                    <option value={OverlapPolicy.Allow}>Run anyway</option>
+                </Input.Select>
+                {rolloutCard()}
+                {retryCard()}
             </Form>
         </Page>
     );
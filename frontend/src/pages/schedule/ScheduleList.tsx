@@ -75,7 +75,7 @@ export const ScheduleList: React.FC = () => {
         {
             title: 'Frequency',
             value: (v: ScheduleType) => {
-                return (<SchedulePattern pattern={v.Pattern} />);
+                return (<SchedulePattern schedule={v} />);
             },
         },
         {
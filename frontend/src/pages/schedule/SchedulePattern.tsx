@@ -1,11 +1,21 @@
 import * as React from 'react';
 import { Popover } from '../../components/Popover';
+import { DateLabel } from '../../components/DateLabel';
+import { ScheduleType } from '../../types/Schedule';
+import { ScheduleTrigger } from '../../types/cbgen_enum';
 
-interface SchedulePatternProps { pattern: string; }
+interface SchedulePatternProps { schedule: ScheduleType; }
 export const SchedulePattern: React.FC<SchedulePatternProps> = (props: SchedulePatternProps) => {
     let value: string;
 
-    switch (props.pattern) {
+    switch (props.schedule.Trigger) {
+        case ScheduleTrigger.Once:
+            return (<span>Once at <DateLabel date={props.schedule.RunAt} /></span>);
+        case ScheduleTrigger.Event:
+            return (<span>On {props.schedule.TriggerEvent} event</span>);
+    }
+
+    switch (props.schedule.Pattern) {
         case '0 * * * *':
             value = 'Every Hour';
             break;
@@ -21,7 +31,7 @@ export const SchedulePattern: React.FC<SchedulePatternProps> = (props: ScheduleP
     }
 
     if (!value) {
-        return (<Popover content={props.pattern}>Custom</Popover>);
+        return (<Popover content={props.schedule.Pattern}>Custom</Popover>);
     }
 
     return (
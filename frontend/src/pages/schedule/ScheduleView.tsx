@@ -19,6 +19,7 @@ import { Style } from '../../components/Style';
 import { Nothing } from '../../components/Nothing';
 import { GlobalModalFrame, Modal } from '../../components/Modal';
 import { Permissions, UserAction } from '../../services/Permissions';
+import { ScriptStatus } from '../../types/cbgen_enum';
 
 export const ScheduleView: React.FC = () => {
     const { id } = useParams() as URLParams;
@@ -147,7 +148,7 @@ export const ScheduleView: React.FC = () => {
                         <ListGroup.List>
                             <ListGroup.TextItem title="Name">{schedule.Name}</ListGroup.TextItem>
                             <ListGroup.TextItem title="Script"><Link to={'/scripts/script/' + schedule.ScriptID}>{script.Name}</Link></ListGroup.TextItem>
-                            <ListGroup.TextItem title="Frequency"><SchedulePattern pattern={schedule.Pattern} /></ListGroup.TextItem>
+                            <ListGroup.TextItem title="Frequency"><SchedulePattern schedule={schedule} /></ListGroup.TextItem>
                             <ListGroup.TextItem title="Last Run"><DateLabel date={schedule.LastRunTime} /></ListGroup.TextItem>
                             <ListGroup.TextItem title="Enabled"><EnabledBadge value={schedule.Enabled} /></ListGroup.TextItem>
                             {groupsList()}
@@ -215,6 +216,8 @@ const ScheduleReportItem: React.FC<ScheduleReportItemProps> = (props: ScheduleRe
         resultIcon = (<Icon.ExclamationTriangle color={Style.Palette.Warning} />);
     } else if (props.report.Result == 2) {
         resultIcon = (<Icon.ExclamationCircle color={Style.Palette.Danger} />);
+    } else if (props.report.Result == 3) {
+        resultIcon = (<Icon.MinusCircle color={Style.Palette.Secondary} />);
     }
 
     const linkClick = () => {
@@ -264,11 +267,32 @@ const ScheduleReportDetails: React.FC<ScheduleReportDetailsProps> = (props: Sche
     }
 
     const hostEntry = (hostID: string) => {
-        const resultIcon = props.report.HostResult[hostID] == 0 ? (<Icon.CheckCircle color={Style.Palette.Success} />) : (<Icon.ExclamationCircle color={Style.Palette.Danger} />);
+        // Reports from before success criteria were added only have the exit code of each host
+        const status = (props.report.HostStatus || {})[hostID];
+        const failed = status ? status === ScriptStatus.Failed : props.report.HostResult[hostID] != 0;
+        let resultIcon = failed ? (<Icon.ExclamationCircle color={Style.Palette.Danger} />) : (<Icon.CheckCircle color={Style.Palette.Success} />);
+        if (props.report.HostResult[hostID] === undefined) {
+            // Host was skipped because the rollout was stopped
+            resultIcon = (<Icon.QuestionCircle color={Style.Palette.Secondary} />);
+        }
+        const skipReason = (props.report.HostSkipReason || {})[hostID];
+        let skipLabel: JSX.Element;
+        if (skipReason) {
+            resultIcon = (<Icon.MinusCircle color={Style.Palette.Secondary} />);
+            skipLabel = (<span className="text-muted ms-2">{skipReason}</span>);
+        }
+
+        const attempts = (props.report.HostAttempts || {})[hostID] || [];
+        let attemptsLabel: JSX.Element;
+        if (attempts.length > 1) {
+            attemptsLabel = (<span className="text-muted ms-2">{attempts.length} attempts</span>);
+        }
 
         return (
             <ListGroup.Item key={hostID}>
                 <Icon.Label icon={resultIcon} label={Hosts[hostID]} />
+                {attemptsLabel}
+                {skipLabel}
             </ListGroup.Item>
         );
     };
@@ -283,6 +307,9 @@ const ScheduleReportDetails: React.FC<ScheduleReportDetailsProps> = (props: Sche
                             <ListGroup.TextItem title="Started"><DateLabel date={props.report.Time.Start} /></ListGroup.TextItem>
                             <ListGroup.TextItem title="Finished"><DateLabel date={props.report.Time.Finished} /></ListGroup.TextItem>
                             <ListGroup.TextItem title="Elapsed">{props.report.Time.ElapsedSeconds} seconds</ListGroup.TextItem>
+                            {props.report.StopReason ? (<ListGroup.TextItem title="Rollout Stopped">{props.report.StopReason}</ListGroup.TextItem>) : null}
+                            {props.report.SkipReason ? (<ListGroup.TextItem title="Skipped">{props.report.SkipReason}</ListGroup.TextItem>) : null}
+                            {props.report.EventID ? (<ListGroup.TextItem title="Triggered By Event">{props.report.EventID}</ListGroup.TextItem>) : null}
                         </ListGroup.List>
                     </Card.Card>
                     <Card.Card>
@@ -1,5 +1,5 @@
 import * as React from 'react';
-import { RunAs, Script, ScriptType } from '../../types/Script';
+import { RunAs, Script, ScriptParameter, ScriptType, SuccessCriteria } from '../../types/Script';
 import { useParams, useNavigate } from 'react-router-dom';
 import { URLParams } from '../../services/Params';
 import { PageLoading } from '../../components/Loading';
@@ -7,6 +7,8 @@ import { Page } from '../../components/Page';
 import { Input } from '../../components/input/Input';
 import { Form } from '../../components/Form';
 import { EnvironmentVariableEdit } from '../../components/EnvironmentVariableEdit';
+import { ScriptParameterEdit } from '../../components/ScriptParameterEdit';
+import { SuccessCriteriaEdit } from '../../components/SuccessCriteriaEdit';
 import { GroupCheckList } from '../../components/CheckList';
 import { Card } from '../../components/Card';
 import { Notification } from '../../components/Notification';
@@ -58,6 +60,13 @@ export const ScriptEdit: React.FC = () => {
         });
     };
 
+    const changeParameters = (Parameters: ScriptParameter[]) => {
+        setScript(script => {
+            script.Parameters = Parameters;
+            return { ...script };
+        });
+    };
+
     const changeGroupIDs = (GroupIDs: string[]) => {
         setGroupIDs(GroupIDs);
     };
@@ -111,6 +120,34 @@ export const ScriptEdit: React.FC = () => {
         });
     };
 
+    const changeTemplate = (Template: boolean) => {
+        setScript(script => {
+            script.Template = Template;
+            return { ...script };
+        });
+    };
+
+    const changeRequiresApproval = (RequiresApproval: boolean) => {
+        setScript(script => {
+            script.RequiresApproval = RequiresApproval;
+            return { ...script };
+        });
+    };
+
+    const changeTimeoutSeconds = (TimeoutSeconds: number) => {
+        setScript(script => {
+            script.TimeoutSeconds = isNaN(TimeoutSeconds) ? 0 : TimeoutSeconds;
+            return { ...script };
+        });
+    };
+
+    const changeSuccessCriteria = (SuccessCriteria: SuccessCriteria) => {
+        setScript(script => {
+            script.SuccessCriteria = SuccessCriteria;
+            return { ...script };
+        });
+    };
+
     const formSave = () => {
         let promise: Promise<ScriptType>;
         if (isNew) {
@@ -185,6 +222,23 @@ export const ScriptEdit: React.FC = () => {
                     onChange={changeExecutable}
                     fixedWidth
                     required />
+                <Input.Number
+                    label="Timeout"
+                    append="Seconds"
+                    minimum={0}
+                    defaultValue={script.TimeoutSeconds}
+                    onChange={changeTimeoutSeconds}
+                    helpText="The number of seconds the script may run for before it is aborted. Set to 0 to use the default of each agent. Agents may set a lower maximum." />
+                <Input.Checkbox
+                    label="Template"
+                    defaultValue={script.Template}
+                    onChange={changeTemplate}
+                    helpText="If checked the script is rendered as a Go template for each host before it is run." />
+                <Input.Checkbox
+                    label="Requires Approval"
+                    defaultValue={script.RequiresApproval}
+                    onChange={changeRequiresApproval}
+                    helpText="If checked running the script on demand must be approved by another user." />
                 <Card.Card className="mt-3">
                     <Card.Header>Environment Variables</Card.Header>
                     <Card.Body>
@@ -193,6 +247,22 @@ export const ScriptEdit: React.FC = () => {
                             onChange={changeEnvironment} />
                     </Card.Body>
                 </Card.Card>
+                <Card.Card className="mt-3">
+                    <Card.Header>Parameters</Card.Header>
+                    <Card.Body>
+                        <ScriptParameterEdit
+                            parameters={script.Parameters || []}
+                            onChange={changeParameters} />
+                    </Card.Body>
+                </Card.Card>
+                <Card.Card className="mt-3">
+                    <Card.Header>Success Criteria</Card.Header>
+                    <Card.Body>
+                        <SuccessCriteriaEdit
+                            defaultValue={script.SuccessCriteria}
+                            onChange={changeSuccessCriteria} />
+                    </Card.Body>
+                </Card.Card>
                 <Card.Card className="mt-3">
                     <Card.Header>Groups</Card.Header>
                     <Card.Body>
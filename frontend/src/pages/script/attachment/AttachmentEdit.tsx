@@ -66,6 +66,13 @@ export const AttachmentEdit: React.FC<AttachmentEditProps> = (props: AttachmentE
         });
     };
 
+    const changeTemplate = (Template: boolean) => {
+        setAttachment(attachment => {
+            attachment.Template = Template;
+            return { ...attachment };
+        });
+    };
+
     const fileInput = () => {
         const labelText = props.attachment ? 'Replace File' : 'Select File';
         const helpText = props.attachment ? 'Select a new file to replace the existing file, otherwise the file is not changed.' : '';
@@ -80,6 +87,7 @@ export const AttachmentEdit: React.FC<AttachmentEditProps> = (props: AttachmentE
             <Input.RunAsInput inheritLabel="Specify File Owner" label="Owned By" defaultValue={attachment.Owner} onChange={changeOwner} />
             <Input.Number label="Permission / Mode" defaultValue={attachment.Mode} required onChange={changeMode} helpText="The permission value (mode) for the file" />
             <Input.Checkbox label="Upload After Script Execution" defaultValue={attachment.AfterScript} onChange={changeAfterScript} helpText="If checked the file will be uploaded once the script has completed successfully. If unchecked the file will be uploaded before the script is executed." />
+            <Input.Checkbox label="Template" defaultValue={attachment.Template} onChange={changeTemplate} helpText="If checked the file is rendered as a Go template for each host before it is uploaded. Only text files can be templates." />
         </ModalForm>
     );
 };
@@ -50,6 +50,13 @@ export const OptionsGeneral: React.FC<OptionsGeneralProps> = (props: OptionsGene
         });
     };
 
+    const changeRunRetentionDays = (RunRetentionDays: number) => {
+        setValue(value => {
+            value.RunRetentionDays = RunRetentionDays;
+            return { ...value };
+        });
+    };
+
     return (
         <div>
             <Input.Text
@@ -60,6 +67,13 @@ export const OptionsGeneral: React.FC<OptionsGeneralProps> = (props: OptionsGene
                 defaultValue={value.ServerURL}
                 onChange={changeServerURL} />
             {originWarning()}
+            <Input.Number
+                label="Run History Retention"
+                append="Days"
+                minimum={0}
+                helpText="Script runs and their output are removed after this many days. Set to 0 to keep runs forever."
+                defaultValue={value.RunRetentionDays}
+                onChange={changeRunRetentionDays} />
             <Card.Card>
                 <Card.Header>Global Environment Variables</Card.Header>
                 <Card.Body>
@@ -34,6 +34,13 @@ export const OptionsNetwork: React.FC<OptionsNetworkProps> = (props: OptionsNetw
         });
     };
 
+    const changeMaxParallelism = (MaxParallelism: number) => {
+        setValue(value => {
+            value.MaxParallelism = MaxParallelism;
+            return { ...value };
+        });
+    };
+
     const radioChoices = [
         {
             value: 'auto',
@@ -68,6 +75,14 @@ export const OptionsNetwork: React.FC<OptionsNetworkProps> = (props: OptionsNetw
                 helpText="The frequency (in minutes) to check the reachability of all Otto hosts"
                 defaultValue={value.HeartbeatFrequency}
                 onChange={changeHeartbeatFrequency} />
+            <Input.Number
+                label="Max Parallelism"
+                append="Hosts"
+                minimum={1}
+                helpText="The maximum number of hosts that Otto will run scripts on or check the reachability of at once"
+                defaultValue={value.MaxParallelism}
+                onChange={changeMaxParallelism}
+                required />
         </div>
     );
 };
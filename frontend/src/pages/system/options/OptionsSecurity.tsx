@@ -1,4 +1,5 @@
 import * as React from 'react';
+import { Input } from '../../../components/input/Input';
 import { Options } from '../../../types/Options';
 import { OptionsRotateID } from './OptionsRotateID';
 
@@ -20,9 +21,24 @@ export const OptionsSecurity: React.FC<OptionsSecurityProps> = (props: OptionsSe
         });
     };
 
+    const changeApprovalExpiryHours = (ApprovalExpiryHours: number) => {
+        setValue(value => {
+            value.ApprovalExpiryHours = ApprovalExpiryHours;
+            return { ...value };
+        });
+    };
+
     return (
         <div>
             <OptionsRotateID defaultValue={value.RotateID} onUpdate={changeRotateID} />
+            <Input.Number
+                label="Approval Requests Expire After"
+                append="Hours"
+                minimum={1}
+                defaultValue={value.ApprovalExpiryHours}
+                onChange={changeApprovalExpiryHours}
+                helpText="Requests to run scripts that require approval expire if they are not approved or rejected in this time."
+                required />
         </div>
     );
 };
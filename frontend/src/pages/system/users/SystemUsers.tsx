@@ -367,6 +367,28 @@ const UserPermissionsEdit: React.FC<UserPermissionsEditProps> = (props: UserPerm
                 });
             }
         },
+        {
+            label: 'Can Run Ad-hoc Commands',
+            value: Permissions.CanRunCommands,
+            helpText: 'Ad-hoc commands are not limited by the script run level',
+            update: (v: boolean) => {
+                SetPermissions(p => {
+                    p.CanRunCommands = v;
+                    return { ...p };
+                });
+            }
+        },
+        {
+            label: 'Can Download Files from Hosts',
+            value: Permissions.CanDownloadFiles,
+            helpText: 'Files are read with the permissions of the Otto agent, which is usually root',
+            update: (v: boolean) => {
+                SetPermissions(p => {
+                    p.CanDownloadFiles = v;
+                    return { ...p };
+                });
+            }
+        },
         {
             label: 'Can Access Event Log',
             value: Permissions.CanAccessAuditLog,
@@ -408,6 +430,17 @@ const UserPermissionsEdit: React.FC<UserPermissionsEditProps> = (props: UserPerm
                 });
             }
         },
+        {
+            label: 'Can Override Maintenance Windows',
+            value: Permissions.CanOverrideWindows,
+            helpText: 'Scripts and commands can be run on hosts that are blocked by a maintenance window',
+            update: (v: boolean) => {
+                SetPermissions(p => {
+                    p.CanOverrideWindows = v;
+                    return { ...p };
+                });
+            }
+        },
     ];
 
     const onChangeScriptRunLevel = (level: ScriptRunLevel) => {
@@ -75,6 +75,17 @@ export class API {
         return this.do(url, { method: 'POST', body: JSON.stringify(data) });
     }
 
+    /**
+     * Perform a HTTP POST request to the specified URL with the given body but do
+     * not handle any errors
+     * @param url the URL to request
+     * @param data Body data to be encoded as JSON
+     * @returns The JSON object of the results
+     */
+    public static async UnsafePOST(url: string, data: unknown): Promise<unknown> {
+        return this.do(url, { method: 'POST', body: JSON.stringify(data) }, true);
+    }
+
     /**
      * Perform a HTTP PUT request to the specified URL with the given body
      * @param url the URL to request
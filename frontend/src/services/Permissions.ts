@@ -9,10 +9,13 @@ export enum UserAction {
     ModifyGroups,
     ModifyScripts,
     ModifySchedules,
+    RunCommands,
+    DownloadFiles,
     AccessAuditLog,
     ModifyUsers,
     ModifyAutoregister,
     ModifySystem,
+    OverrideWindows,
 }
 
 /**
@@ -36,6 +39,10 @@ export class Permissions {
                 return permissions.CanModifyScripts;
             case UserAction.ModifySchedules:
                 return permissions.CanModifySchedules;
+            case UserAction.RunCommands:
+                return permissions.CanRunCommands;
+            case UserAction.DownloadFiles:
+                return permissions.CanDownloadFiles;
             case UserAction.AccessAuditLog:
                 return permissions.CanAccessAuditLog;
             case UserAction.ModifyUsers:
@@ -44,6 +51,8 @@ export class Permissions {
                 return permissions.CanModifyAutoregister;
             case UserAction.ModifySystem:
                 return permissions.CanModifySystem;
+            case UserAction.OverrideWindows:
+                return permissions.CanOverrideWindows;
         }
 
         return false;
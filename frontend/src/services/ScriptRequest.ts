@@ -18,11 +18,13 @@ interface RequestResponse {
 export class ScriptRequest {
     private scriptID: string;
     private hostID: string;
+    private parameters: { [name: string]: string };
     private socket: WebSocket;
 
-    constructor(scriptID: string, hostID: string) {
+    constructor(scriptID: string, hostID: string, parameters?: { [name: string]: string }) {
         this.scriptID = scriptID;
         this.hostID = hostID;
+        this.parameters = parameters || {};
     }
 
     public Stream(onOutput: (stdout: string, stderr: string) => (void)): Promise<ScriptRun> {
@@ -39,6 +41,7 @@ export class ScriptRequest {
                     HostID: this.hostID,
                     Action: 'run_script',
                     ScriptID: this.scriptID,
+                    Parameters: this.parameters,
                 }));
             });
 
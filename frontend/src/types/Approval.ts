@@ -0,0 +1,66 @@
+import { API } from '../services/API';
+import { ApprovalStatus } from './cbgen_enum';
+import { ExecutionOptions, RolloutStrategy } from './Schedule';
+
+export interface ApprovalRequestType {
+    ID?: string;
+    ScriptID: string;
+    ScriptRevision?: number;
+    HostIDs?: string[];
+    GroupIDs?: string[];
+    Parameters?: { [name: string]: string };
+    Execution?: ExecutionOptions;
+    Rollout?: RolloutStrategy;
+    OverrideWindows?: boolean;
+    Reason?: string;
+    Status?: ApprovalStatus;
+    RequestedBy?: string;
+    Requested?: string;
+    Expires?: string;
+    ReviewedBy?: string;
+    Reviewed?: string;
+    Comment?: string;
+    JobID?: string;
+}
+
+export class Approval {
+    /**
+     * Request approval to run a script
+     */
+    public static async Request(parameters: ApprovalRequestType): Promise<ApprovalRequestType> {
+        const data = await API.PUT('/api/approvals/approval', parameters);
+        return data as ApprovalRequestType;
+    }
+
+    /**
+     * Get the specified approval request by its id
+     */
+    public static async Get(id: string): Promise<ApprovalRequestType> {
+        const data = await API.GET('/api/approvals/approval/' + id);
+        return data as ApprovalRequestType;
+    }
+
+    /**
+     * List all approval requests, newest first
+     */
+    public static async List(): Promise<ApprovalRequestType[]> {
+        const data = await API.GET('/api/approvals');
+        return data as ApprovalRequestType[];
+    }
+
+    /**
+     * Approve the request and start running the script. Returns the ID of the job.
+     */
+    public static async Approve(id: string, comment?: string): Promise<string> {
+        const data = await API.POST('/api/approvals/approval/' + id + '/approve', { Comment: comment });
+        return (data as { ID: string }).ID;
+    }
+
+    /**
+     * Reject the request
+     */
+    public static async Reject(id: string, comment?: string): Promise<ApprovalRequestType> {
+        const data = await API.POST('/api/approvals/approval/' + id + '/reject', { Comment: comment });
+        return data as ApprovalRequestType;
+    }
+}
@@ -12,6 +12,7 @@ export interface AttachmentType {
     Mode?: number;
     Size?: number;
     AfterScript?: boolean;
+    Template?: boolean;
 }
 
 export class Attachment {
@@ -41,6 +42,7 @@ export class Attachment {
             UID: parameters.Owner.UID.toString(),
             GID: parameters.Owner.GID.toString(),
             Mode: parameters.Mode.toString(),
+            Template: parameters.Template ? 'true' : 'false',
         });
         return data as AttachmentType;
     }
@@ -55,6 +57,7 @@ export class Attachment {
             UID: parameters.Owner.UID.toString(),
             GID: parameters.Owner.GID.toString(),
             Mode: parameters.Mode.toString(),
+            Template: parameters.Template ? 'true' : 'false',
         });
         return data as AttachmentType;
     }
@@ -116,6 +119,7 @@ export interface NewAttachmentParameters {
     Owner: RunAs;
     Mode: number;
     AfterScript: boolean;
+    Template?: boolean;
 }
 
 export interface EditAttachmentParameters {
@@ -123,4 +127,5 @@ export interface EditAttachmentParameters {
     Owner: RunAs;
     Mode: number;
     AfterScript: boolean;
+    Template?: boolean;
 }
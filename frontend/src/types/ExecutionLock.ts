@@ -0,0 +1,23 @@
+import { API } from '../services/API';
+
+export interface ExecutionLockType {
+    ID: string;
+    ScheduleID?: string;
+    ScheduleName?: string;
+    HostID?: string;
+    HostName?: string;
+    ScriptID?: string;
+    ScriptName?: string;
+    Acquired: string;
+    Waiting: boolean;
+}
+
+export class ExecutionLock {
+    /**
+     * List all held locks and runs waiting for a lock
+     */
+    public static async List(): Promise<ExecutionLockType[]> {
+        const data = await API.GET('/api/locks');
+        return data as ExecutionLockType[];
+    }
+}
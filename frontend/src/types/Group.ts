@@ -11,6 +11,8 @@ export interface GroupType {
     Name?: string;
     ScriptIDs?: string[];
     Environment?: Variable[];
+    Selector?: string;
+    ParentID?: string;
 }
 
 export class Group {
@@ -1,11 +1,17 @@
 import { API } from '../services/API';
 
+export interface HeartbeatCapabilities {
+    protocol_version: number;
+    features?: string[];
+}
+
 export interface HeartbeatType {
     Address?: string;
     IsReachable: boolean;
     LastReply?: string;
     LastAttempt?: string;
     Version?: string;
+    Capabilities?: HeartbeatCapabilities;
     Properties?: { [key: string]: string };
 }
 
@@ -6,16 +6,19 @@ import { Variable } from './Variable';
 import { ScheduleType } from './Schedule';
 import { HeartbeatType } from './Heartbeat';
 import { ScriptType } from './Script';
+import { HostConnectionMode } from './cbgen_enum';
 
 export interface HostType {
     ID?: string;
     Name?: string;
     Address?: string;
     Port?: number;
+    ConnectionMode?: string;
     Trust?: TrustType;
     Enabled?: boolean;
     GroupIDs?: string[];
     Environment?: Variable[];
+    Labels?: { [key: string]: string };
 }
 
 export interface TrustType {
@@ -33,6 +36,7 @@ export class Host {
             Name: '',
             Address: '',
             Port: 12444,
+            ConnectionMode: HostConnectionMode.Direct,
             Enabled: true,
             GroupIDs: [],
             Environment: [],
@@ -170,6 +174,7 @@ export interface NewHostParameters {
     Name: string;
     Address: string;
     Port: number;
+    ConnectionMode?: string;
     GroupIDs: string[];
     Environment: Variable[];
 }
@@ -178,6 +183,7 @@ export interface EditHostParameters {
     Name: string;
     Address: string;
     Port: number;
+    ConnectionMode?: string;
     GroupIDs: string[];
     Enabled: boolean;
     Environment: Variable[];
@@ -0,0 +1,46 @@
+import { API } from '../services/API';
+import { Variable } from './Variable';
+import { InventoryFormat } from './cbgen_enum';
+
+export interface InventoryGroupType {
+    Name?: string;
+    Parent?: string;
+    Environment?: Variable[];
+}
+
+export interface InventoryHostType {
+    Name?: string;
+    Address?: string;
+    Port?: number;
+    Groups?: string[];
+    Environment?: Variable[];
+}
+
+export interface InventoryImportResultType {
+    Groups?: InventoryGroupType[];
+    Hosts?: InventoryHostType[];
+    Conflicts?: string[];
+    Warnings?: string[];
+}
+
+export class Inventory {
+    /**
+     * Import hosts and groups from an inventory
+     * @param validateOnly if true nothing is added, only the result of the import is returned
+     */
+    public static async Import(format: InventoryFormat, data: string, validateOnly: boolean): Promise<InventoryImportResultType> {
+        const response = await API.POST('/api/inventory/import', {
+            Format: format,
+            Data: data,
+            ValidateOnly: validateOnly,
+        });
+        return response as InventoryImportResultType;
+    }
+
+    /**
+     * Return the URL to download all hosts and groups as an inventory
+     */
+    public static ExportURL(format: InventoryFormat): string {
+        return '/api/inventory/export?format=' + format;
+    }
+}
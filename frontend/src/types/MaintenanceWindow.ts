@@ -0,0 +1,89 @@
+import { API } from '../services/API';
+import { Modal } from '../components/Modal';
+import { Notification } from '../components/Notification';
+import { MaintenanceWindowMode } from './cbgen_enum';
+import { ScheduleScope } from './Schedule';
+
+export interface MaintenanceWindowType {
+    ID?: string;
+    Name?: string;
+    Mode?: MaintenanceWindowMode;
+    Pattern?: string;
+    DurationMinutes?: number;
+    Start?: string;
+    End?: string;
+    Global?: boolean;
+    Scope?: ScheduleScope;
+    Enabled?: boolean;
+}
+
+export class MaintenanceWindow {
+    /**
+     * Return a blank maintenance window
+     */
+    public static Blank(): MaintenanceWindowType {
+        return {
+            Name: '',
+            Mode: MaintenanceWindowMode.Blackout,
+            Pattern: '',
+            DurationMinutes: 60,
+            Global: false,
+            Scope: {
+                HostIDs: [],
+                GroupIDs: [],
+            },
+            Enabled: true,
+        };
+    }
+
+    /**
+     * Create a new Maintenance Window
+     */
+    public static async New(parameters: MaintenanceWindowType): Promise<MaintenanceWindowType> {
+        const data = await API.PUT('/api/maintenance_windows/window', parameters);
+        return data as MaintenanceWindowType;
+    }
+
+    /**
+     * Save this maintenance window
+     */
+    public static async Save(window: MaintenanceWindowType): Promise<MaintenanceWindowType> {
+        const data = await API.POST('/api/maintenance_windows/window/' + window.ID, window);
+        return data as MaintenanceWindowType;
+    }
+
+    /**
+     * Get the specified maintenance window by its id
+     */
+    public static async Get(id: string): Promise<MaintenanceWindowType> {
+        const data = await API.GET('/api/maintenance_windows/window/' + id);
+        return data as MaintenanceWindowType;
+    }
+
+    /**
+     * List all maintenance windows
+     */
+    public static async List(): Promise<MaintenanceWindowType[]> {
+        const data = await API.GET('/api/maintenance_windows');
+        return data as MaintenanceWindowType[];
+    }
+
+    /**
+     * Show a modal to delete this maintenance window
+     */
+    public static async DeleteModal(window: MaintenanceWindowType): Promise<boolean> {
+        return new Promise(resolve => {
+            Modal.delete('Delete Maintenance Window?', 'Are you sure you want to delete this maintenance window? This can not be undone.').then(confirmed => {
+                if (!confirmed) {
+                    resolve(false);
+                    return;
+                }
+
+                API.DELETE('/api/maintenance_windows/window/' + window.ID).then(() => {
+                    Notification.success('Maintenance Window Deleted');
+                    resolve(true);
+                });
+            });
+        });
+    }
+}
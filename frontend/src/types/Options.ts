@@ -14,6 +14,7 @@ export namespace Options {
     export interface General {
         ServerURL: string;
         GlobalEnvironment: Variable[];
+        RunRetentionDays: number;
     }
 
     export interface Authentication {
@@ -25,6 +26,7 @@ export namespace Options {
         ForceIPVersion: string;
         Timeout: number;
         HeartbeatFrequency: number;
+        MaxParallelism: number;
     }
 
     export interface Register {
@@ -35,6 +37,7 @@ export namespace Options {
 
     export interface Security {
         RotateID: RotateID;
+        ApprovalExpiryHours: number;
     }
 
     export interface RotateID {
@@ -1,4 +1,5 @@
 import { Variable } from './Variable';
+import { ScriptStatus } from './cbgen_enum';
 
 export interface ScriptRun {
     ScriptID?: string;
@@ -7,6 +8,8 @@ export interface ScriptRun {
     Result?: ScriptResultDetails;
     Output?: ScriptOutput;
     RunError?: string;
+    Status?: ScriptStatus;
+    FailureReason?: string;
 }
 
 export interface ScriptResultDetails {
@@ -16,6 +19,7 @@ export interface ScriptResultDetails {
     stdout_len?: string;
     stderr_len?: string;
     duration?: number;
+    timed_out?: boolean;
 }
 
 export interface ScriptOutput {
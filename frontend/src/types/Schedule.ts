@@ -3,16 +3,61 @@ import { Modal } from '../components/Modal';
 import { Notification } from '../components/Notification';
 import { GroupType } from './Group';
 import { HostType } from './Host';
-import { ScriptType } from './Script';
+import { ExecutionPlan, ScriptType } from './Script';
+import { CatchUpPolicy, OverlapPolicy, RetryFailure, ScheduleTrigger } from './cbgen_enum';
 
 export interface ScheduleType {
     ID?: string;
     Name?: string;
     ScriptID?: string;
+    WorkflowID?: string;
     Scope?: ScheduleScope;
+    Trigger?: string;
     Pattern?: string;
+    RunAt?: string;
+    TriggerEvent?: string;
+    EventHost?: boolean;
+    Timezone?: string;
+    CatchUp?: string;
+    JitterSeconds?: number;
     Enabled?: boolean;
     LastRunTime?: string;
+    Execution?: ExecutionOptions;
+    Rollout?: RolloutStrategy;
+    Retry?: RetryPolicy;
+    TimeoutSeconds?: number;
+    Parameters?: { [name: string]: string };
+    OverlapPolicy?: string;
+}
+
+export interface ExecutionOptions {
+    MaxParallelism: number;
+    BatchDelaySeconds: number;
+}
+
+export interface RolloutStrategy {
+    CanaryHosts: number;
+    CanaryPercent: number;
+    BatchSize: number;
+    MaxFailurePercent: number;
+    AbortPolicy: string;
+}
+
+export interface RetryPolicy {
+    MaxAttempts: number;
+    BackoffSeconds: number;
+    Exponential: boolean;
+    RetryOn: RetryFailure[];
+}
+
+export interface HostAttempt {
+    Attempt: number;
+    RunID: string;
+    Start: string;
+    Result: number;
+    Status: string;
+    Failure?: RetryFailure;
+    Error?: string;
 }
 
 export class Schedule {
@@ -27,7 +72,30 @@ export class Schedule {
                 HostIDs: [],
                 GroupIDs: [],
             },
+            Trigger: ScheduleTrigger.Pattern,
             Pattern: '',
+            EventHost: false,
+            Timezone: '',
+            CatchUp: CatchUpPolicy.Skip,
+            JitterSeconds: 0,
+            Execution: {
+                MaxParallelism: 0,
+                BatchDelaySeconds: 0,
+            },
+            Rollout: {
+                CanaryHosts: 0,
+                CanaryPercent: 0,
+                BatchSize: 0,
+                MaxFailurePercent: 0,
+                AbortPolicy: 'continue',
+            },
+            Retry: {
+                MaxAttempts: 0,
+                BackoffSeconds: 0,
+                Exponential: false,
+                RetryOn: [],
+            },
+            OverlapPolicy: OverlapPolicy.Skip,
         };
     }
 
@@ -96,6 +164,26 @@ export class Schedule {
         return data as ScriptType;
     }
 
+    /**
+     * Get the plan for the next run of a schedule
+     */
+    public static async Plan(id: string): Promise<ExecutionPlan> {
+        const data = await API.GET('/api/schedules/schedule/' + id + '/plan');
+        return data as ExecutionPlan;
+    }
+
+    /**
+     * Get the next times that a pattern will run in a timezone. Returns undefined if the pattern or timezone is not valid.
+     */
+    public static async NextRuns(pattern: string, timezone: string, count?: number): Promise<string[]> {
+        const data = await API.UnsafePOST('/api/schedules/next_runs', {
+            Pattern: pattern,
+            Timezone: timezone,
+            Count: count,
+        });
+        return data as string[];
+    }
+
     /**
      * List all schedules
      */
@@ -127,11 +215,16 @@ export class Schedule {
 export interface ScheduleScope {
     HostIDs: string[];
     GroupIDs: string[];
+    Selector?: string;
+    ExcludeHostIDs?: string[];
+    ExcludeGroupIDs?: string[];
+    ExcludeSelector?: string;
 }
 
 export interface NewScheduleParameters {
     Name: string;
     ScriptID: string;
+    WorkflowID?: string;
     Scope: ScheduleScope;
     Pattern: string;
 }
@@ -146,10 +239,19 @@ export interface EditScheduleParameters {
 export interface ScheduleReport {
     ID: string;
     ScheduleID: string;
+    ScriptRevision?: number;
     HostIDs: string[];
     Time: ScheduleReportTime;
     Result: number;
     HostResult: { [HostID: string]: number };
+    HostStatus?: { [HostID: string]: string };
+    HostSkipReason?: { [HostID: string]: string };
+    HostAttempts?: { [HostID: string]: HostAttempt[] };
+    HostBatch?: { [HostID: string]: number };
+    StopReason?: string;
+    WorkflowReportID?: string;
+    SkipReason?: string;
+    EventID?: string;
 }
 
 export interface ScheduleReportTime {
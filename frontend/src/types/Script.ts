@@ -5,7 +5,7 @@ import { GroupType } from './Group';
 import { Variable } from './Variable';
 import { ScheduleType } from './Schedule';
 import { AttachmentType } from './Attachment';
-import { ScriptRunLevel } from './cbgen_enum';
+import { EnvironmentSource, ScriptParameterType, ScriptRunLevel } from './cbgen_enum';
 
 export interface ScriptType {
     ID?: string;
@@ -18,6 +18,32 @@ export interface ScriptType {
     AfterExecution?: string;
     AttachmentIDs?: string[];
     RunLevel: ScriptRunLevel;
+    Parameters?: ScriptParameter[];
+    Template?: boolean;
+    SuccessCriteria?: SuccessCriteria;
+    TimeoutSeconds?: number;
+    RequiresApproval?: boolean;
+    Revision?: number;
+}
+
+export interface SuccessCriteria {
+    ExitCodes?: number[];
+    ChangedExitCodes?: number[];
+    StdoutMatch?: string;
+    StdoutNotMatch?: string;
+    StderrMatch?: string;
+    StderrNotMatch?: string;
+    MaxDurationSeconds?: number;
+}
+
+export interface ScriptParameter {
+    Name: string;
+    Description?: string;
+    Type: ScriptParameterType;
+    Default?: string;
+    Required?: boolean;
+    Pattern?: string;
+    Options?: string[];
 }
 
 export class Script {
@@ -39,6 +65,7 @@ export class Script {
             Environment: [],
             AttachmentIDs: [],
             RunLevel: ScriptRunLevel.ReadOnly,
+            Parameters: [],
         };
     }
 
@@ -150,6 +177,53 @@ export class Script {
         return data as AttachmentType[];
     }
 
+    /**
+     * List all revisions of a script, most recent first
+     */
+    public static async Revisions(id: string): Promise<ScriptRevision[]> {
+        const data = await API.GET('/api/scripts/script/' + id + '/revisions');
+        return data as ScriptRevision[];
+    }
+
+    /**
+     * Get the differences between two revisions of a script
+     */
+    public static async Diff(id: string, from: number, to: number): Promise<ScriptRevisionDiff> {
+        const data = await API.GET('/api/scripts/script/' + id + '/diff?from=' + from + '&to=' + to);
+        return data as ScriptRevisionDiff;
+    }
+
+    /**
+     * Roll back a script to a previous revision
+     */
+    public static async Rollback(id: string, revision: number): Promise<ScriptType> {
+        const data = await API.POST('/api/scripts/script/' + id + '/revisions/' + revision + '/rollback', {});
+        return data as ScriptType;
+    }
+
+    /**
+     * Render the script for a host without running it
+     */
+    public static async Preview(id: string, hostID: string, parameters?: { [name: string]: string }): Promise<ScriptPreview> {
+        const data = await API.POST('/api/scripts/script/' + id + '/preview', {
+            HostID: hostID,
+            Parameters: parameters,
+        });
+        return data as ScriptPreview;
+    }
+
+    /**
+     * Get the plan for running the script on hosts without running it
+     */
+    public static async Plan(id: string, hostIDs: string[], groupIDs: string[], parameters?: { [name: string]: string }): Promise<ExecutionPlan> {
+        const data = await API.POST('/api/scripts/script/' + id + '/plan', {
+            HostIDs: hostIDs,
+            GroupIDs: groupIDs,
+            Parameters: parameters,
+        });
+        return data as ExecutionPlan;
+    }
+
     /**
      * Cancel a running script
      */
@@ -199,3 +273,89 @@ export interface EditScriptParameters {
     AfterExecution: string;
     AttachmentIDs: string[];
 }
+
+export interface ScriptRevision {
+    ID: string;
+    ScriptID: string;
+    Revision: number;
+    Author: string;
+    Time: string;
+    RollbackOf?: number;
+    Script: ScriptType;
+}
+
+export interface ScriptRevisionDiff {
+    ScriptID: string;
+    From: number;
+    To: number;
+    Fields: ScriptRevisionFieldChange[];
+    Lines: ScriptRevisionDiffLine[];
+}
+
+export interface ScriptRevisionFieldChange {
+    Field: string;
+    From: string;
+    To: string;
+}
+
+export interface ScriptRevisionDiffLine {
+    Op: '=' | '+' | '-';
+    Line: string;
+}
+
+export interface ScriptPreview {
+    ScriptID: string;
+    HostID: string;
+    Script: string;
+    Attachments: AttachmentPreview[];
+}
+
+export interface AttachmentPreview {
+    AttachmentID: string;
+    Path: string;
+    Length: number;
+    Checksum: string;
+    Content: string;
+}
+
+export interface ExecutionPlan {
+    ScriptID: string;
+    ScriptName: string;
+    ScriptRevision: number;
+    RunAs: RunAs;
+    TimeoutSeconds: number;
+    AfterExecution: string;
+    Attachments: PlanAttachment[];
+    Hosts: HostPlan[];
+}
+
+export interface PlanAttachment {
+    AttachmentID: string;
+    Path: string;
+    Owner: RunAs;
+    Mode: number;
+    Length: number;
+    Checksum: string;
+    AfterScript: boolean;
+    Template: boolean;
+}
+
+export interface HostPlan {
+    HostID: string;
+    HostName: string;
+    Address: string;
+    Reachable: boolean;
+    LastReply: string;
+    Blocked: string;
+    Error: string;
+    Environment: PlanVariable[];
+    AttachmentChecksums: { [attachmentID: string]: string };
+}
+
+export interface PlanVariable {
+    Key: string;
+    Value: string;
+    Secret: boolean;
+    Source: EnvironmentSource;
+    Group: string;
+}
@@ -15,10 +15,13 @@ export interface UserPermissions {
     CanModifyGroups?: boolean;
     CanModifyScripts?: boolean;
     CanModifySchedules?: boolean;
+    CanRunCommands?: boolean;
+    CanDownloadFiles?: boolean;
     CanAccessAuditLog?: boolean;
     CanModifyUsers?: boolean;
     CanModifyAutoregister?: boolean;
     CanModifySystem?: boolean;
+    CanOverrideWindows?: boolean;
 }
 
 export class User {
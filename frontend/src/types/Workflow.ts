@@ -0,0 +1,148 @@
+import { API } from '../services/API';
+import { Modal } from '../components/Modal';
+import { Notification } from '../components/Notification';
+import { ExecutionOptions, HostAttempt, RetryPolicy, RolloutStrategy, ScheduleReportTime, ScheduleScope } from './Schedule';
+
+export interface WorkflowType {
+    ID?: string;
+    Name?: string;
+    Scope?: ScheduleScope;
+    Steps?: WorkflowStep[];
+}
+
+export interface WorkflowStep {
+    Name: string;
+    ScriptID: string;
+    Scope?: ScheduleScope;
+    Parameters?: { [name: string]: string };
+    Execution?: ExecutionOptions;
+    Rollout?: RolloutStrategy;
+    Retry?: RetryPolicy;
+    OnSuccess?: string;
+    OnFailure?: string;
+    Output?: string;
+    Outputs?: string[];
+}
+
+export interface WorkflowReport {
+    ID: string;
+    WorkflowID: string;
+    ScheduleID?: string;
+    TriggeredBy?: string;
+    Status: string;
+    Time: ScheduleReportTime;
+    Result: number;
+    Steps: WorkflowStepReport[];
+}
+
+export interface WorkflowStepReport {
+    Step: string;
+    ScriptID: string;
+    ScriptRevision?: number;
+    HostIDs: string[];
+    Time: ScheduleReportTime;
+    Result: number;
+    HostResult: { [HostID: string]: number };
+    HostStatus?: { [HostID: string]: string };
+    HostSkipReason?: { [HostID: string]: string };
+    HostAttempts?: { [HostID: string]: HostAttempt[] };
+    HostBatch?: { [HostID: string]: number };
+    StopReason?: string;
+    Error?: string;
+}
+
+export class Workflow {
+    /**
+     * The name of the step that ends a workflow when used as a branch
+     */
+    public static readonly End = 'end';
+
+    /**
+     * Return a blank workflow
+     */
+    public static Blank(): WorkflowType {
+        return {
+            Name: '',
+            Scope: {
+                HostIDs: [],
+                GroupIDs: [],
+            },
+            Steps: [],
+        };
+    }
+
+    /**
+     * Create a new Workflow
+     */
+    public static async New(parameters: WorkflowType): Promise<WorkflowType> {
+        const data = await API.PUT('/api/workflows/workflow', parameters);
+        return data as WorkflowType;
+    }
+
+    /**
+     * Save this workflow
+     */
+    public static async Save(workflow: WorkflowType): Promise<WorkflowType> {
+        const data = await API.POST('/api/workflows/workflow/' + workflow.ID, workflow);
+        return data as WorkflowType;
+    }
+
+    /**
+     * Get the specified workflow by its id
+     */
+    public static async Get(id: string): Promise<WorkflowType> {
+        const data = await API.GET('/api/workflows/workflow/' + id);
+        return data as WorkflowType;
+    }
+
+    /**
+     * List all workflows
+     */
+    public static async List(): Promise<WorkflowType[]> {
+        const data = await API.GET('/api/workflows');
+        return data as WorkflowType[];
+    }
+
+    /**
+     * Get all reports for a workflow, newest first
+     */
+    public static async Reports(id: string): Promise<WorkflowReport[]> {
+        const data = await API.GET('/api/workflows/workflow/' + id + '/reports');
+        return data as WorkflowReport[];
+    }
+
+    /**
+     * Get a specific report for a workflow
+     */
+    public static async Report(id: string, reportID: string): Promise<WorkflowReport> {
+        const data = await API.GET('/api/workflows/workflow/' + id + '/reports/' + reportID);
+        return data as WorkflowReport;
+    }
+
+    /**
+     * Start running this workflow. If scope is provided it replaces the scope of the workflow.
+     */
+    public static async Run(id: string, scope?: ScheduleScope): Promise<WorkflowReport> {
+        const data = await API.POST('/api/workflows/workflow/' + id + '/run', { Scope: scope });
+        return data as WorkflowReport;
+    }
+
+    /**
+     * Show a modal to delete this workflow
+     */
+    public static async DeleteModal(workflow: WorkflowType): Promise<boolean> {
+        return new Promise(resolve => {
+            Modal.delete('Delete Workflow?', 'Are you sure you want to delete this workflow? This can not be undone.').then(confirmed => {
+                if (!confirmed) {
+                    resolve(false);
+                    return;
+                }
+
+                API.DELETE('/api/workflows/workflow/' + workflow.ID).then(() => {
+                    Notification.success('Workflow Deleted');
+                    resolve(true);
+                });
+            });
+        });
+    }
+}
@@ -62,6 +62,178 @@ export function AgentActionConfig() {
     ];
 }
 
+export enum ApprovalStatus { 
+    /** The request is waiting for approval */
+    Pending = 'pending',
+    /** The request was approved and the script was started */
+    Approved = 'approved',
+    /** The request was rejected */
+    Rejected = 'rejected',
+    /** The request was not approved or rejected in time */
+    Expired = 'expired',
+}
+
+export function ApprovalStatusAll() {
+    return [ 
+        ApprovalStatus.Pending,
+        ApprovalStatus.Approved,
+        ApprovalStatus.Rejected,
+        ApprovalStatus.Expired,
+    ];
+}
+
+export function ApprovalStatusConfig() {
+    return [
+        {
+            key: 'Pending',
+            value: 'pending',
+            description: 'The request is waiting for approval',
+        },
+        {
+            key: 'Approved',
+            value: 'approved',
+            description: 'The request was approved and the script was started',
+        },
+        {
+            key: 'Rejected',
+            value: 'rejected',
+            description: 'The request was rejected',
+        },
+        {
+            key: 'Expired',
+            value: 'expired',
+            description: 'The request was not approved or rejected in time',
+        },
+    ];
+}
+
+export enum CatchUpPolicy { 
+    /** Runs missed while the server was not running are not run */
+    Skip = 'skip',
+    /** If any runs were missed while the server was not running the schedule is run once */
+    Once = 'once',
+    /** Every run missed while the server was not running is run, one after another */
+    All = 'all',
+}
+
+export function CatchUpPolicyAll() {
+    return [ 
+        CatchUpPolicy.Skip,
+        CatchUpPolicy.Once,
+        CatchUpPolicy.All,
+    ];
+}
+
+export function CatchUpPolicyConfig() {
+    return [
+        {
+            key: 'Skip',
+            value: 'skip',
+            description: 'Runs missed while the server was not running are not run',
+        },
+        {
+            key: 'Once',
+            value: 'once',
+            description: 'If any runs were missed while the server was not running the schedule is run once',
+        },
+        {
+            key: 'All',
+            value: 'all',
+            description: 'Every run missed while the server was not running is run, one after another',
+        },
+    ];
+}
+
+export enum EnvironmentSource { 
+    /** Variables set by the otto server */
+    Server = 'server',
+    /** The global environment from the server options */
+    Global = 'global',
+    /** The environment of the script */
+    Script = 'script',
+    /** The environment of a group the host is a member of */
+    Group = 'group',
+    /** The environment of the host */
+    Host = 'host',
+    /** A script parameter value */
+    Parameter = 'parameter',
+}
+
+export function EnvironmentSourceAll() {
+    return [ 
+        EnvironmentSource.Server,
+        EnvironmentSource.Global,
+        EnvironmentSource.Script,
+        EnvironmentSource.Group,
+        EnvironmentSource.Host,
+        EnvironmentSource.Parameter,
+    ];
+}
+
+export function EnvironmentSourceConfig() {
+    return [
+        {
+            key: 'Server',
+            value: 'server',
+            description: 'Variables set by the otto server',
+        },
+        {
+            key: 'Global',
+            value: 'global',
+            description: 'The global environment from the server options',
+        },
+        {
+            key: 'Script',
+            value: 'script',
+            description: 'The environment of the script',
+        },
+        {
+            key: 'Group',
+            value: 'group',
+            description: 'The environment of a group the host is a member of',
+        },
+        {
+            key: 'Host',
+            value: 'host',
+            description: 'The environment of the host',
+        },
+        {
+            key: 'Parameter',
+            value: 'parameter',
+            description: 'A script parameter value',
+        },
+    ];
+}
+
+export enum HostConnectionMode { 
+    /** The server connects to the agent */
+    Direct = 'direct',
+    /** The agent connects to the server */
+    Reverse = 'reverse',
+}
+
+export function HostConnectionModeAll() {
+    return [ 
+        HostConnectionMode.Direct,
+        HostConnectionMode.Reverse,
+    ];
+}
+
+export function HostConnectionModeConfig() {
+    return [
+        {
+            key: 'Direct',
+            value: 'direct',
+            description: 'The server connects to the agent',
+        },
+        {
+            key: 'Reverse',
+            value: 'reverse',
+            description: 'The agent connects to the server',
+        },
+    ];
+}
+
 export enum IPVersionOption { 
     /** IPv4 or IPv6 as chosen by the system automatically */
     Auto = 'auto',
@@ -99,6 +271,170 @@ export function IPVersionOptionConfig() {
     ];
 }
 
+export enum InventoryFormat { 
+    /** One host per row with columns for the name, address, port, groups, and environment variables */
+    CSV = 'csv',
+    /** An Ansible inventory in INI format */
+    AnsibleINI = 'ansible_ini',
+    /** An Ansible inventory in YAML format */
+    AnsibleYAML = 'ansible_yaml',
+}
+
+export function InventoryFormatAll() {
+    return [ 
+        InventoryFormat.CSV,
+        InventoryFormat.AnsibleINI,
+        InventoryFormat.AnsibleYAML,
+    ];
+}
+
+export function InventoryFormatConfig() {
+    return [
+        {
+            key: 'CSV',
+            value: 'csv',
+            description: 'One host per row with columns for the name, address, port, groups, and environment variables',
+        },
+        {
+            key: 'AnsibleINI',
+            value: 'ansible_ini',
+            description: 'An Ansible inventory in INI format',
+        },
+        {
+            key: 'AnsibleYAML',
+            value: 'ansible_yaml',
+            description: 'An Ansible inventory in YAML format',
+        },
+    ];
+}
+
+export enum JobStatus { 
+    /** The script has not started yet */
+    Pending = 'pending',
+    /** The script is running */
+    Running = 'running',
+    /** The script has finished running */
+    Finished = 'finished',
+    /** The script could not be run */
+    Failed = 'failed',
+    /** The script was canceled */
+    Canceled = 'canceled',
+    /** The script was not run because the rollout was stopped */
+    Skipped = 'skipped',
+}
+
+export function JobStatusAll() {
+    return [ 
+        JobStatus.Pending,
+        JobStatus.Running,
+        JobStatus.Finished,
+        JobStatus.Failed,
+        JobStatus.Canceled,
+        JobStatus.Skipped,
+    ];
+}
+
+export function JobStatusConfig() {
+    return [
+        {
+            key: 'Pending',
+            value: 'pending',
+            description: 'The script has not started yet',
+        },
+        {
+            key: 'Running',
+            value: 'running',
+            description: 'The script is running',
+        },
+        {
+            key: 'Finished',
+            value: 'finished',
+            description: 'The script has finished running',
+        },
+        {
+            key: 'Failed',
+            value: 'failed',
+            description: 'The script could not be run',
+        },
+        {
+            key: 'Canceled',
+            value: 'canceled',
+            description: 'The script was canceled',
+        },
+        {
+            key: 'Skipped',
+            value: 'skipped',
+            description: 'The script was not run because the rollout was stopped',
+        },
+    ];
+}
+
+export enum MaintenanceWindowMode { 
+    /** Scripts can not run on hosts while the window is active */
+    Blackout = 'blackout',
+    /** Scripts can only run on hosts while the window is active */
+    Maintenance = 'maintenance',
+}
+
+export function MaintenanceWindowModeAll() {
+    return [ 
+        MaintenanceWindowMode.Blackout,
+        MaintenanceWindowMode.Maintenance,
+    ];
+}
+
+export function MaintenanceWindowModeConfig() {
+    return [
+        {
+            key: 'Blackout',
+            value: 'blackout',
+            description: 'Scripts can not run on hosts while the window is active',
+        },
+        {
+            key: 'Maintenance',
+            value: 'maintenance',
+            description: 'Scripts can only run on hosts while the window is active',
+        },
+    ];
+}
+
+export enum OverlapPolicy { 
+    /** The run is skipped if a previous run is still in progress */
+    Skip = 'skip',
+    /** The run waits for the previous run to finish, if another run is already waiting the run is skipped */
+    Queue = 'queue',
+    /** The run starts even if a previous run is still in progress */
+    Allow = 'allow',
+}
+
+export function OverlapPolicyAll() {
+    return [ 
+        OverlapPolicy.Skip,
+        OverlapPolicy.Queue,
+        OverlapPolicy.Allow,
+    ];
+}
+
+export function OverlapPolicyConfig() {
+    return [
+        {
+            key: 'Skip',
+            value: 'skip',
+            description: 'The run is skipped if a previous run is still in progress',
+        },
+        {
+            key: 'Queue',
+            value: 'queue',
+            description: 'The run waits for the previous run to finish, if another run is already waiting the run is skipped',
+        },
+        {
+            key: 'Allow',
+            value: 'allow',
+            description: 'The run starts even if a previous run is still in progress',
+        },
+    ];
+}
+
 export enum RegisterRuleProperty { 
     /** Hostname */
     Hostname = 'hostname',
@@ -193,6 +529,80 @@ export function RequestResponseCodeConfig() {
     ];
 }
 
+export enum RetryFailure { 
+    /** The server could not connect to the host or the connection was lost */
+    Connection = 'connection',
+    /** The agent could not run the script */
+    Exec = 'exec',
+    /** The script ran but did not meet its success criteria, such as a non-zero exit code */
+    ExitCode = 'exit_code',
+}
+
+export function RetryFailureAll() {
+    return [ 
+        RetryFailure.Connection,
+        RetryFailure.Exec,
+        RetryFailure.ExitCode,
+    ];
+}
+
+export function RetryFailureConfig() {
+    return [
+        {
+            key: 'Connection',
+            value: 'connection',
+            description: 'The server could not connect to the host or the connection was lost',
+        },
+        {
+            key: 'Exec',
+            value: 'exec',
+            description: 'The agent could not run the script',
+        },
+        {
+            key: 'ExitCode',
+            value: 'exit_code',
+            description: 'The script ran but did not meet its success criteria, such as a non-zero exit code',
+        },
+    ];
+}
+
+export enum RolloutAbortPolicy { 
+    /** Continue the rollout regardless of failures */
+    Continue = 'continue',
+    /** Stop the rollout once the current batch has finished */
+    Stop = 'stop',
+    /** Stop the rollout and cancel the script on any hosts still running */
+    Cancel = 'cancel',
+}
+
+export function RolloutAbortPolicyAll() {
+    return [ 
+        RolloutAbortPolicy.Continue,
+        RolloutAbortPolicy.Stop,
+        RolloutAbortPolicy.Cancel,
+    ];
+}
+
+export function RolloutAbortPolicyConfig() {
+    return [
+        {
+            key: 'Continue',
+            value: 'continue',
+            description: 'Continue the rollout regardless of failures',
+        },
+        {
+            key: 'Stop',
+            value: 'stop',
+            description: 'Stop the rollout once the current batch has finished',
+        },
+        {
+            key: 'Cancel',
+            value: 'cancel',
+            description: 'Stop the rollout and cancel the script on any hosts still running',
+        },
+    ];
+}
+
 export enum ScheduleResult { 
     /** All hosts executed the script successfully */
     Success = 0,
@@ -200,6 +610,8 @@ export enum ScheduleResult {
     PartialSuccess = 1,
     /** No hosts executed the script successfully */
     Fail = 2,
+    /** No hosts executed the script because of maintenance windows or runs already in progress */
+    Skipped = 3,
 }
 
 export function ScheduleResultAll() {
@@ -207,6 +619,7 @@ export function ScheduleResultAll() {
         ScheduleResult.Success,
         ScheduleResult.PartialSuccess,
         ScheduleResult.Fail,
+        ScheduleResult.Skipped,
     ];
 }
 
@@ -227,6 +640,101 @@ export function ScheduleResultConfig() {
             value: 2,
             description: 'No hosts executed the script successfully',
         },
+        {
+            key: 'Skipped',
+            value: 3,
+            description: 'No hosts executed the script because of maintenance windows or runs already in progress',
+        },
+    ];
+}
+
+export enum ScheduleTrigger { 
+    /** The schedule runs whenever its cron pattern matches */
+    Pattern = 'pattern',
+    /** The schedule runs once at a set time and then disables itself */
+    Once = 'once',
+    /** The schedule runs whenever an event is recorded */
+    Event = 'event',
+}
+
+export function ScheduleTriggerAll() {
+    return [ 
+        ScheduleTrigger.Pattern,
+        ScheduleTrigger.Once,
+        ScheduleTrigger.Event,
+    ];
+}
+
+export function ScheduleTriggerConfig() {
+    return [
+        {
+            key: 'Pattern',
+            value: 'pattern',
+            description: 'The schedule runs whenever its cron pattern matches',
+        },
+        {
+            key: 'Once',
+            value: 'once',
+            description: 'The schedule runs once at a set time and then disables itself',
+        },
+        {
+            key: 'Event',
+            value: 'event',
+            description: 'The schedule runs whenever an event is recorded',
+        },
+    ];
+}
+
+export enum ScriptParameterType { 
+    /** Any text */
+    String = 'string',
+    /** A whole number */
+    Integer = 'integer',
+    /** Either true or false */
+    Boolean = 'boolean',
+    /** One of a list of options */
+    Enum = 'enum',
+    /** Any text that is never displayed or saved in the run history */
+    Secret = 'secret',
+}
+
+export function ScriptParameterTypeAll() {
+    return [ 
+        ScriptParameterType.String,
+        ScriptParameterType.Integer,
+        ScriptParameterType.Boolean,
+        ScriptParameterType.Enum,
+        ScriptParameterType.Secret,
+    ];
+}
+
+export function ScriptParameterTypeConfig() {
+    return [
+        {
+            key: 'String',
+            value: 'string',
+            description: 'Any text',
+        },
+        {
+            key: 'Integer',
+            value: 'integer',
+            description: 'A whole number',
+        },
+        {
+            key: 'Boolean',
+            value: 'boolean',
+            description: 'Either true or false',
+        },
+        {
+            key: 'Enum',
+            value: 'enum',
+            description: 'One of a list of options',
+        },
+        {
+            key: 'Secret',
+            value: 'secret',
+            description: 'Any text that is never displayed or saved in the run history',
+        },
     ];
 }
 
@@ -268,3 +776,77 @@ export function ScriptRunLevelConfig() {
     ];
 }
 
+export enum ScriptStatus { 
+    /** The script met its success criteria */
+    Success = 'success',
+    /** The script met its success criteria and reported that it made changes */
+    Changed = 'changed',
+    /** The script could not be run or did not meet its success criteria */
+    Failed = 'failed',
+    /** The script was not run because of a maintenance window */
+    Skipped = 'skipped',
+}
+
+export function ScriptStatusAll() {
+    return [ 
+        ScriptStatus.Success,
+        ScriptStatus.Changed,
+        ScriptStatus.Failed,
+        ScriptStatus.Skipped,
+    ];
+}
+
+export function ScriptStatusConfig() {
+    return [
+        {
+            key: 'Success',
+            value: 'success',
+            description: 'The script met its success criteria',
+        },
+        {
+            key: 'Changed',
+            value: 'changed',
+            description: 'The script met its success criteria and reported that it made changes',
+        },
+        {
+            key: 'Failed',
+            value: 'failed',
+            description: 'The script could not be run or did not meet its success criteria',
+        },
+        {
+            key: 'Skipped',
+            value: 'skipped',
+            description: 'The script was not run because of a maintenance window',
+        },
+    ];
+}
+
+export enum WorkflowOutput { 
+    /** Pass the output of the step to the next step */
+    Stdout = 'stdout',
+    /** Pass the declared outputs of the step to the next step */
+    Declared = 'declared',
+}
+
+export function WorkflowOutputAll() {
+    return [ 
+        WorkflowOutput.Stdout,
+        WorkflowOutput.Declared,
+    ];
+}
+
+export function WorkflowOutputConfig() {
+    return [
+        {
+            key: 'Stdout',
+            value: 'stdout',
+            description: 'Pass the output of the step to the next step',
+        },
+        {
+            key: 'Declared',
+            value: 'declared',
+            description: 'Pass the declared outputs of the step to the next step',
+        },
+    ];
+}
+
@@ -19,6 +19,10 @@ func handleTriggerAction(conn *otto.Connection, messageType otto.MessageType, me
 		conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{
 			Error: handleTriggerActionUploadFile(conn, message.(otto.MessageTriggerActionUploadFile)),
 		})
+	case otto.MessageTypeTriggerActionDownloadFile:
+		conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{
+			Error: handleTriggerActionDownloadFile(conn, message.(otto.MessageTriggerActionDownloadFile)),
+		})
 	case otto.MessageTypeTriggerActionExitAgent:
 		go handleTriggerActionExitAgent(conn)
 		conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{})
@@ -39,7 +43,8 @@ func handleTriggerActionReloadConfig(conn *otto.Connection) string {
 	restartServer = true
 	mustLoadIdentity()
 	conn.Close()
-	defer listener.Close()
+	// Only stop the listener so that actions running on other channels of this session can finish
+	defer listener.Stop()
 	return ""
 }
 
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+func handleTriggerActionDownloadFile(conn *otto.Connection, message otto.MessageTriggerActionDownloadFile) string {
+	err := downloadFile(message, func(fileInfo otto.FileInfo, f io.Reader) error {
+		if err := conn.WriteMessage(otto.MessageTypeDownloadFileInfo, otto.MessageDownloadFileInfo{FileInfo: fileInfo}); err != nil {
+			log.PError("Error replying to server", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return err
+		}
+
+		log.Debug("Waiting for server to be ready for file data")
+		if messageType, _, err := conn.ReadMessage(); err != nil || messageType != otto.MessageTypeReadyForData {
+			log.PError("Unexpected message from server when waiting for MessageTypeReadyForData", map[string]interface{}{
+				"message_type": messageType,
+			})
+			// The server has already been told about the file, so we can't reply with an error
+			conn.Close()
+			return fmt.Errorf("unexpected message from server %d", messageType)
+		}
+
+		wrote, err := conn.Copy(io.LimitReader(f, int64(fileInfo.Length)))
+		if err != nil || uint64(wrote) != fileInfo.Length {
+			log.PError("Error writing file data", map[string]interface{}{
+				"path":   fileInfo.Path,
+				"length": fileInfo.Length,
+				"wrote":  wrote,
+			})
+			// The server is expecting the full length of the file, close the connection so that it isn't left waiting
+			conn.Close()
+			return fmt.Errorf("error writing file data")
+		}
+		return nil
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func downloadFile(request otto.MessageTriggerActionDownloadFile, readFunc func(fileInfo otto.FileInfo, f io.Reader) error) error {
+	Stats.FilesDownloaded++
+
+	if request.Path == "" {
+		return fmt.Errorf("invalid file path")
+	}
+	if request.Path[0] != '/' {
+		return fmt.Errorf("file path must be absolute")
+	}
+
+	f, err := os.OpenFile(request.Path, os.O_RDONLY, os.ModePerm)
+	if err != nil {
+		log.PError("Error opening file", map[string]interface{}{
+			"path":  request.Path,
+			"error": err.Error(),
+		})
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.PError("Error performing stat on file", map[string]interface{}{
+			"path":  request.Path,
+			"error": err.Error(),
+		})
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("path is not a regular file")
+	}
+	if request.MaxSize > 0 && uint64(info.Size()) > request.MaxSize {
+		return fmt.Errorf("file is larger than maximum size")
+	}
+
+	// Calculate the checksum from the same file handle that the data will be read from, if the file changes before
+	// it is sent then the server will reject the data
+	h := sha256.New()
+	length, err := io.Copy(h, f)
+	if err != nil {
+		log.PError("Error calculating file checksum", map[string]interface{}{
+			"path":  request.Path,
+			"error": err.Error(),
+		})
+		return err
+	}
+	if request.MaxSize > 0 && uint64(length) > request.MaxSize {
+		return fmt.Errorf("file is larger than maximum size")
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	fileInfo := otto.FileInfo{
+		Path:     request.Path,
+		Owner:    otto.RunAs{Inherit: true},
+		Mode:     fileModeToInt(info.Mode()),
+		Checksum: fmt.Sprintf("%x", h.Sum(nil)),
+		Length:   uint64(length),
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		fileInfo.Owner = otto.RunAs{
+			UID: stat.Uid,
+			GID: stat.Gid,
+		}
+	}
+
+	if err := readFunc(fileInfo, f); err != nil {
+		return err
+	}
+
+	log.PDebug("Sent file", map[string]interface{}{
+		"path":     fileInfo.Path,
+		"length":   fileInfo.Length,
+		"checksum": fileInfo.Checksum,
+	})
+	return nil
+}
+
+// fileModeToInt is the inverse of intToFileMode, where the octal permission bits are represented as decimal digits
+func fileModeToInt(mode os.FileMode) uint32 {
+	n, err := strconv.ParseUint(fmt.Sprintf("%o", mode.Perm()), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(n)
+}
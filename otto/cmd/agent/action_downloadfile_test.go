@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+	"github.com/ecnepsnai/secutil"
+)
+
+func TestDownloadFile(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "example.test")
+	data := secutil.RandomBytes(32)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(data))
+	if err := os.WriteFile(filePath, data, 0640); err != nil {
+		t.Fatalf("Error writing file: %s", err.Error())
+	}
+
+	var fileInfo otto.FileInfo
+	downloaded := &bytes.Buffer{}
+	err := downloadFile(otto.MessageTriggerActionDownloadFile{Path: filePath, MaxSize: 1024}, func(info otto.FileInfo, f io.Reader) error {
+		fileInfo = info
+		_, e := io.Copy(downloaded, f)
+		return e
+	})
+	if err != nil {
+		t.Fatalf("Error downloading otto file: %s", err.Error())
+	}
+
+	if fileInfo.Checksum != checksum {
+		t.Fatalf("Unexpected file checksum: %s != %s", fileInfo.Checksum, checksum)
+	}
+	if fileInfo.Length != uint64(len(data)) {
+		t.Fatalf("Unexpected file length: %d != %d", fileInfo.Length, len(data))
+	}
+	if fileInfo.Mode != 640 {
+		t.Fatalf("Unexpected file mode: %d != %d", fileInfo.Mode, 640)
+	}
+	if !bytes.Equal(downloaded.Bytes(), data) {
+		t.Fatalf("Unexpected file data")
+	}
+}
+
+func TestDownloadFileInvalid(t *testing.T) {
+	fileDir := t.TempDir()
+	filePath := path.Join(fileDir, "example.test")
+	if err := os.WriteFile(filePath, secutil.RandomBytes(32), 0644); err != nil {
+		t.Fatalf("Error writing file: %s", err.Error())
+	}
+
+	readFunc := func(info otto.FileInfo, f io.Reader) error {
+		t.Fatalf("Read func should not be called")
+		return nil
+	}
+
+	if err := downloadFile(otto.MessageTriggerActionDownloadFile{Path: filePath, MaxSize: 16}, readFunc); err == nil {
+		t.Fatalf("No error seen when one expected for downloading file larger than max size")
+	}
+	if err := downloadFile(otto.MessageTriggerActionDownloadFile{Path: "example.test", MaxSize: 1024}, readFunc); err == nil {
+		t.Fatalf("No error seen when one expected for downloading file with relative path")
+	}
+	if err := downloadFile(otto.MessageTriggerActionDownloadFile{Path: fileDir, MaxSize: 1024}, readFunc); err == nil {
+		t.Fatalf("No error seen when one expected for downloading directory")
+	}
+	if err := downloadFile(otto.MessageTriggerActionDownloadFile{Path: path.Join(fileDir, "missing"), MaxSize: 1024}, readFunc); err == nil {
+		t.Fatalf("No error seen when one expected for downloading missing file")
+	}
+}
@@ -37,6 +37,24 @@ func (w *scriptOutputWriter) Write(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// scriptTimeout returns the number of seconds a script may run for. The timeout requested by the server replaces the
+// configured default, but can't be longer than the configured maximum. A negative value means there is no timeout.
+func scriptTimeout(requested int64) int64 {
+	timeoutSeconds := int64(30)
+	if config.ScriptTimeout != nil {
+		timeoutSeconds = *config.ScriptTimeout
+	}
+	if requested > 0 {
+		timeoutSeconds = requested
+	}
+	if config.MaxScriptTimeout != nil && *config.MaxScriptTimeout >= 0 {
+		if timeoutSeconds < 0 || timeoutSeconds > *config.MaxScriptTimeout {
+			timeoutSeconds = *config.MaxScriptTimeout
+		}
+	}
+	return timeoutSeconds
+}
+
 func handleTriggerActionRunScript(conn *otto.Connection, message otto.MessageTriggerActionRunScript) {
 	if pid, running := scriptLog.Load(message.Name); running {
 		log.Error("Cannot start script '%s' as it's already running on pid %d", message.Name, pid.(int))
@@ -241,18 +259,24 @@ func handleTriggerActionRunScript(conn *otto.Connection, message otto.MessageTri
 			result.ExecError = err.Error()
 		}
 	}
-	isRunning := true
 	proc := cmd.Process
 	log.Debug("Waiting for script on pid %d...", proc.Pid)
 	scriptLog.Store(message.Name, proc.Pid)
 
-	timeoutSeconds := 30
-	if config.ScriptTimeout != nil {
-		timeoutSeconds = int(*config.ScriptTimeout)
-	}
+	timeoutSeconds := scriptTimeout(message.TimeoutSeconds)
 
+	// The watchdog sends keepalives and kills the script if it exceeds its timeout. It reports if the script timed out
+	// once the script has exited, so that the result is only modified by this goroutine.
+	exited := make(chan struct{})
+	timedOut := make(chan bool, 1)
 	go func() {
-		for isRunning {
+		for {
+			select {
+			case <-exited:
+				timedOut <- false
+				return
+			default:
+			}
 			if time.Since(stdout.lastWrite) > 10*time.Second && time.Since(stderr.lastWrite) > 10*time.Second {
 				conn.WriteMessage(otto.MessageTypeKeepalive, nil)
 				stdout.lastWrite = time.Now()
@@ -260,16 +284,20 @@ func handleTriggerActionRunScript(conn *otto.Connection, message otto.MessageTri
 			}
 			if timeoutSeconds >= 0 && time.Since(start) > time.Duration(timeoutSeconds)*time.Second {
 				log.Error("Script execution exceeded timeout %dsec", timeoutSeconds)
-				killProcessAndDescendents(cmd.Process.Pid)
-				result.ExecError = "script timeout"
-				break
+				killProcessAndDescendents(proc.Pid)
+				timedOut <- true
+				return
 			}
 			time.Sleep(10 * time.Millisecond)
 		}
 	}()
 
 	cmd.Wait()
-	isRunning = false
+	close(exited)
+	if <-timedOut {
+		result.ExecError = fmt.Sprintf("script timed out after %d seconds", timeoutSeconds)
+		result.TimedOut = true
+	}
 	scriptLog.Delete(message.Name)
 
 	stdoutFile.Sync()
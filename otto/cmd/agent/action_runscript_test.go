@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+func TestScriptTimeout(t *testing.T) {
+	original := config
+	defer func() {
+		config = original
+	}()
+
+	i64 := func(v int64) *int64 {
+		return &v
+	}
+
+	type testCase struct {
+		Default   *int64
+		Maximum   *int64
+		Requested int64
+		Expected  int64
+	}
+	cases := []testCase{
+		{nil, nil, 0, 30},
+		{i64(60), nil, 0, 60},
+		{i64(60), nil, 3600, 3600},
+		{i64(-1), nil, 0, -1},
+		{i64(-1), i64(600), 0, 600},
+		{nil, i64(600), 3600, 600},
+		{nil, i64(600), 120, 120},
+		{i64(60), i64(-1), 7200, 7200},
+	}
+
+	for i, c := range cases {
+		config = &agentConfig{ScriptTimeout: c.Default, MaxScriptTimeout: c.Maximum}
+		if timeout := scriptTimeout(c.Requested); timeout != c.Expected {
+			t.Errorf("Unexpected timeout for case %d. Expected %d got %d", i, c.Expected, timeout)
+		}
+	}
+}
+
+func TestRunScriptTimedOut(t *testing.T) {
+	original := config
+	defer func() {
+		config = original
+	}()
+	config = &agentConfig{}
+
+	agentIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	serverIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := otto.SetupListener(&otto.ListenOptions{
+		Address:  "127.0.0.1:0",
+		Identity: agentIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{serverIdentity.PublicKeyString()}
+		},
+	}, func(conn *otto.Connection) {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Errorf("Error reading message: %s", err.Error())
+			return
+		}
+		handleTriggerActionRunScript(conn, message.(otto.MessageTriggerActionRunScript))
+	})
+	if err != nil {
+		panic(err)
+	}
+	go l.Accept()
+	defer l.Close()
+
+	conn, err := otto.Dial(otto.DialOptions{
+		Network:          "tcp",
+		Address:          fmt.Sprintf("127.0.0.1:%d", l.Port()),
+		Identity:         serverIdentity.Signer(),
+		TrustedPublicKey: agentIdentity.PublicKeyString(),
+		Timeout:          5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Error dialing: %s", err.Error())
+	}
+	defer conn.Close()
+
+	script := "sleep 10\n"
+	result, _, err := conn.TriggerActionRunScript(otto.ScriptInfo{
+		Name:           "timeout",
+		RunAs:          otto.RunAs{Inherit: true},
+		Executable:     "/bin/sh",
+		Length:         uint64(len(script)),
+		TimeoutSeconds: 1,
+	}, io.NopCloser(strings.NewReader(script)), func(stdout, stderr []byte) {})
+	if err != nil {
+		t.Fatalf("Error running script: %s", err.Error())
+	}
+	if !result.ScriptResult.TimedOut || result.ScriptResult.Success || result.ScriptResult.ExecError == "" {
+		t.Errorf("Script should be reported as timed out: %+v", result.ScriptResult)
+	}
+}
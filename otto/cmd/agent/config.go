@@ -14,18 +14,20 @@ import (
 )
 
 type agentConfig struct {
-	ListenAddr      string   `json:"listen_addr"`
-	IdentityPath    string   `json:"identity_path"`
-	ServerIdentity  string   `json:"server_identity"`
-	LogPath         string   `json:"log_path"`
-	DefaultUID      uint32   `json:"default_uid"`
-	DefaultGID      uint32   `json:"default_gid"`
-	Path            string   `json:"path"`
-	AllowFrom       []string `json:"allow_from"`
-	ScriptTimeout   *int64   `json:"script_timeout,omitempty"`
-	RebootCommand   *string  `json:"reboot_command,omitempty"`
-	ShutdownCommand *string  `json:"shutdown_command,omitempty"`
-	KillCommand     *string  `json:"kill_command,omitempty"`
+	ListenAddr       string   `json:"listen_addr"`
+	ReverseAddr      string   `json:"reverse_addr,omitempty"`
+	IdentityPath     string   `json:"identity_path"`
+	ServerIdentity   string   `json:"server_identity"`
+	LogPath          string   `json:"log_path"`
+	DefaultUID       uint32   `json:"default_uid"`
+	DefaultGID       uint32   `json:"default_gid"`
+	Path             string   `json:"path"`
+	AllowFrom        []string `json:"allow_from"`
+	ScriptTimeout    *int64   `json:"script_timeout,omitempty"`
+	MaxScriptTimeout *int64   `json:"max_script_timeout,omitempty"`
+	RebootCommand    *string  `json:"reboot_command,omitempty"`
+	ShutdownCommand  *string  `json:"shutdown_command,omitempty"`
+	KillCommand      *string  `json:"kill_command,omitempty"`
 }
 
 var config *agentConfig
@@ -69,7 +71,7 @@ func loadConfig() error {
 		return fmt.Errorf("empty server identity prohibited")
 	}
 
-	if config.ListenAddr == "" {
+	if config.ListenAddr == "" && config.ReverseAddr == "" {
 		return fmt.Errorf("empty listen address prohibited")
 	}
 
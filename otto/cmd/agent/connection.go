@@ -12,38 +12,67 @@ import (
 )
 
 var restartServer = false
-var listener *otto.Listener
+var listener agentListener
 var identityLock = &sync.RWMutex{}
 
+// reverseReconnectDelay how long to wait before reconnecting to the server after a reverse session was closed
+const reverseReconnectDelay = 10 * time.Second
+
+// agentListener describes either a regular listener or a reverse listener
+type agentListener interface {
+	Accept() error
+	Stop()
+	Close()
+}
+
 func listen() {
 	for {
 		var err error
-		listener, err = otto.SetupListener(&otto.ListenOptions{
-			Address:   config.ListenAddr,
-			AllowFrom: getAllowFroms(),
-			Identity:  agentIdentity,
-			GetTrustedPublicKeys: func() []string {
-				identityLock.RLock()
-				defer identityLock.RUnlock()
-				return []string{config.ServerIdentity}
-			},
-		}, handle)
+		getTrustedPublicKeys := func() []string {
+			identityLock.RLock()
+			defer identityLock.RUnlock()
+			return []string{config.ServerIdentity}
+		}
+		reverse := config.ReverseAddr != ""
+		if reverse {
+			listener, err = otto.SetupReverseListener(&otto.ReverseListenOptions{
+				ServerAddress:        config.ReverseAddr,
+				Identity:             agentIdentity,
+				GetTrustedPublicKeys: getTrustedPublicKeys,
+				Timeout:              reverseReconnectDelay,
+			}, handle)
+		} else {
+			listener, err = otto.SetupListener(&otto.ListenOptions{
+				Address:              config.ListenAddr,
+				AllowFrom:            getAllowFroms(),
+				Identity:             agentIdentity,
+				GetTrustedPublicKeys: getTrustedPublicKeys,
+			}, handle)
+		}
 		if err != nil {
 			panic("error listening: " + err.Error())
 		}
 		listener.Accept()
+		if reverse && !restartServer {
+			log.Warn("Reverse session with server closed, reconnecting in %s", reverseReconnectDelay.String())
+			time.Sleep(reverseReconnectDelay)
+			continue
+		}
 		log.Warn("Listener stopped")
 		if !restartServer {
 			break
 		}
 		log.Info("Listener restarting")
+		restartServer = false
 	}
 }
 
 func handle(conn *otto.Connection) {
 	log.PInfo("Connection established", map[string]interface{}{
-		"remote_addr": conn.RemoteAddr().String(),
-		"identity":    base64.StdEncoding.EncodeToString(conn.RemoteIdentity()),
+		"remote_addr":      conn.RemoteAddr().String(),
+		"identity":         base64.StdEncoding.EncodeToString(conn.RemoteIdentity()),
+		"action_id":        conn.ActionID(),
+		"protocol_version": conn.Version(),
 	})
 	defer conn.Close()
 
@@ -56,7 +85,11 @@ func handle(conn *otto.Connection) {
 			log.Error("Error reading message from server '%s': %s", conn.RemoteAddr().String(), err.Error())
 			break
 		}
-		log.Debug("Message from %s: %d", conn.RemoteAddr().String(), messageType)
+		log.PDebug("Message from server", map[string]interface{}{
+			"remote_addr":  conn.RemoteAddr().String(),
+			"action_id":    conn.ActionID(),
+			"message_type": messageType,
+		})
 
 		switch messageType {
 		case otto.MessageTypeKeepalive:
@@ -68,6 +101,7 @@ func handle(conn *otto.Connection) {
 		case otto.MessageTypeTriggerActionRunScript,
 			otto.MessageTypeTriggerActionReloadConfig,
 			otto.MessageTypeTriggerActionUploadFile,
+			otto.MessageTypeTriggerActionDownloadFile,
 			otto.MessageTypeTriggerActionExitAgent,
 			otto.MessageTypeTriggerActionReboot,
 			otto.MessageTypeTriggerActionShutdown:
@@ -147,7 +181,8 @@ func handleRotateIdentity(conn *otto.Connection, message otto.MessageRotateIdent
 	restartServer = true
 	mustLoadIdentity()
 	conn.Close()
-	defer listener.Close()
+	// Only stop the listener so that actions running on other channels of this session can finish
+	defer listener.Stop()
 }
 
 func getCurrentUIDandGID() (uint32, uint32) {
@@ -4,5 +4,6 @@ var Stats = struct {
 	LastHeartbeat      int64
 	ScriptsExecuted    uint
 	FilesUploaded      uint
+	FilesDownloaded    uint
 	LastScriptExecuted int64
 }{}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"time"
+)
+
+// ApprovalRequest describes a request to run a script that requires approval from a second user. Once approved the
+// script is run as a job.
+type ApprovalRequest struct {
+	ID       string `ds:"primary"`
+	ScriptID string `ds:"index"`
+	// ScriptRevision the revision of the script when the request was made. The request can't be approved if the script
+	// has been modified since.
+	ScriptRevision  int
+	HostIDs         []string
+	GroupIDs        []string
+	Selector        string
+	ExcludeHostIDs  []string
+	ExcludeGroupIDs []string
+	ExcludeSelector string
+	Parameters      map[string]string
+	Execution       ExecutionOptions
+	Rollout         RolloutStrategy
+	OverrideWindows bool
+	// Reason why the requesting user wants to run the script
+	Reason      string `max:"1000"`
+	Status      string `ds:"index"`
+	RequestedBy string
+	Requested   time.Time
+	Expires     time.Time
+	// ReviewedBy the username of the user who approved or rejected the request
+	ReviewedBy string
+	Reviewed   time.Time
+	Comment    string `max:"1000"`
+	// JobID the ID of the job that was started when the request was approved
+	JobID string
+}
+
+// scope returns the hosts that the script would run on
+func (r ApprovalRequest) scope() ScheduleScope {
+	return ScheduleScope{
+		HostIDs:         r.HostIDs,
+		GroupIDs:        r.GroupIDs,
+		Selector:        r.Selector,
+		ExcludeHostIDs:  r.ExcludeHostIDs,
+		ExcludeGroupIDs: r.ExcludeGroupIDs,
+		ExcludeSelector: r.ExcludeSelector,
+	}
+}
+
+// approvalExpiry returns how long approval requests are pending before they expire
+func approvalExpiry() time.Duration {
+	return time.Duration(Options.Security.ApprovalExpiryHours) * time.Hour
+}
+
+// expired returns true if the request is pending and has passed its expiry
+func (r ApprovalRequest) expired(t time.Time) bool {
+	return r.Status == ApprovalStatusPending && !t.Before(r.Expires)
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/limits"
+)
+
+// AllRequests returns all approval requests, newest first
+func (s *approvalStoreObject) AllRequests() (requests []ApprovalRequest) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		requests = s.allRequests(tx)
+		return nil
+	})
+	return
+}
+
+func (s *approvalStoreObject) allRequests(tx ds.IReadTransaction) []ApprovalRequest {
+	objects, err := tx.GetAll(nil)
+	if err != nil {
+		log.Error("Error listing all approval requests: error='%s'", err.Error())
+		return []ApprovalRequest{}
+	}
+	if len(objects) == 0 {
+		return []ApprovalRequest{}
+	}
+
+	requests := make([]ApprovalRequest, len(objects))
+	for i, obj := range objects {
+		request, k := obj.(ApprovalRequest)
+		if !k {
+			log.Fatal("Error listing all approval requests: error='%s'", "invalid type")
+		}
+		requests[i] = request
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Requested.After(requests[j].Requested)
+	})
+	return requests
+}
+
+func (s *approvalStoreObject) RequestWithID(id string) (request *ApprovalRequest) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		request = s.requestWithID(tx, id)
+		return nil
+	})
+	return
+}
+
+func (s *approvalStoreObject) requestWithID(tx ds.IReadTransaction, id string) *ApprovalRequest {
+	object, err := tx.Get(id)
+	if err != nil {
+		log.Error("Error getting approval request: id='%s' error='%s'", id, err.Error())
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	request, ok := object.(ApprovalRequest)
+	if !ok {
+		log.Fatal("Error getting approval request: id='%s' error='%s'", id, "invalid type")
+	}
+	return &request
+}
+
+type newApprovalRequestParameters struct {
+	ScriptID        string
+	HostIDs         []string
+	GroupIDs        []string
+	Selector        string
+	ExcludeHostIDs  []string
+	ExcludeGroupIDs []string
+	ExcludeSelector string
+	Parameters      map[string]string
+	Execution       ExecutionOptions
+	Rollout         RolloutStrategy
+	OverrideWindows bool
+	Reason          string
+}
+
+// NewRequest will add a new pending request to run a script that requires approval
+func (s *approvalStoreObject) NewRequest(params newApprovalRequestParameters, user *User) (request *ApprovalRequest, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		request, err = s.newRequest(tx, params, user)
+		return nil
+	})
+	return
+}
+
+func (s *approvalStoreObject) newRequest(tx ds.IReadWriteTransaction, params newApprovalRequestParameters, user *User) (*ApprovalRequest, *Error) {
+	script := ScriptCache.ByID(params.ScriptID)
+	if script == nil {
+		return nil, ErrorUser("No script with ID %s", params.ScriptID)
+	}
+	if !script.RequiresApproval {
+		return nil, ErrorUser("Script %s does not require approval", script.Name)
+	}
+	if user.Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(user.Username, fmt.Sprintf("Request approval to run script %s", script.ID))
+		return nil, ErrorUser("Permission denied")
+	}
+	if _, err := script.WithParameters(params.Parameters); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Execution.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Rollout.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	now := time.Now()
+	request := ApprovalRequest{
+		ID:              newID(),
+		ScriptID:        script.ID,
+		ScriptRevision:  script.Revision,
+		HostIDs:         params.HostIDs,
+		GroupIDs:        params.GroupIDs,
+		Selector:        params.Selector,
+		ExcludeHostIDs:  params.ExcludeHostIDs,
+		ExcludeGroupIDs: params.ExcludeGroupIDs,
+		ExcludeSelector: params.ExcludeSelector,
+		Parameters:      params.Parameters,
+		Execution:       params.Execution,
+		Rollout:         params.Rollout,
+		OverrideWindows: params.OverrideWindows,
+		Reason:          params.Reason,
+		Status:          ApprovalStatusPending,
+		RequestedBy:     user.Username,
+		Requested:       now,
+		Expires:         now.Add(approvalExpiry()),
+	}
+	if _, err := resolveHosts(request.scope(), ""); err != nil {
+		return nil, err
+	}
+	if err := limits.Check(request); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if err := tx.Add(request); err != nil {
+		log.Error("Error adding new approval request for script '%s': %s", script.ID, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.PInfo("Approval requested", map[string]interface{}{
+		"request_id":   request.ID,
+		"script_id":    script.ID,
+		"requested_by": user.Username,
+	})
+	EventStore.ApprovalRequested(request, script)
+	return &request, nil
+}
+
+// reviewable returns the script of the request, or an error if the request can't be reviewed by the user
+func (s *approvalStoreObject) reviewable(tx ds.IReadWriteTransaction, request *ApprovalRequest, user *User) (*Script, *Error) {
+	if request.expired(time.Now()) {
+		s.expireRequest(tx, *request)
+		return nil, ErrorUser("Request has expired")
+	}
+	if request.Status != ApprovalStatusPending {
+		return nil, ErrorUser("Request is not pending")
+	}
+
+	script := ScriptCache.ByID(request.ScriptID)
+	if script == nil {
+		return nil, ErrorUser("No script with ID %s", request.ScriptID)
+	}
+	if user.Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(user.Username, fmt.Sprintf("Review approval request %s", request.ID))
+		return nil, ErrorUser("Permission denied")
+	}
+	return script, nil
+}
+
+// Approve will approve the request and start running the script as a job. The user approving the request must be a
+// different user than the one who made it.
+func (s *approvalStoreObject) Approve(request *ApprovalRequest, user *User, comment string) (job *Job, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		job, err = s.approve(tx, request, user, comment)
+		return nil
+	})
+	return
+}
+
+func (s *approvalStoreObject) approve(tx ds.IReadWriteTransaction, request *ApprovalRequest, user *User, comment string) (*Job, *Error) {
+	script, err := s.reviewable(tx, request, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == request.RequestedBy {
+		EventStore.UserPermissionDenied(user.Username, fmt.Sprintf("Approve own request %s", request.ID))
+		return nil, ErrorUser("Requests must be approved by a different user")
+	}
+	if script.Revision != request.ScriptRevision {
+		return nil, ErrorUser("Script has been modified since the request was made")
+	}
+
+	script, perr := script.WithParameters(request.Parameters)
+	if perr != nil {
+		return nil, ErrorUser(perr.Error())
+	}
+	hosts, err := resolveHosts(request.scope(), "")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMaintenanceWindows(hosts, request.OverrideWindows, user, fmt.Sprintf("Run script %s", script.ID)); err != nil {
+		return nil, err
+	}
+
+	request.Status = ApprovalStatusApproved
+	request.ReviewedBy = user.Username
+	request.Reviewed = time.Now()
+	request.Comment = comment
+	if err := limits.Check(request); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	job := jobStore.StartJob(script, hosts, request.Execution, request.Rollout, request.RequestedBy)
+	request.JobID = job.ID
+	if err := tx.Update(*request); err != nil {
+		log.Error("Error updating approval request '%s': %s", request.ID, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.PInfo("Approval request approved", map[string]interface{}{
+		"request_id":  request.ID,
+		"script_id":   script.ID,
+		"approved_by": user.Username,
+		"job_id":      job.ID,
+	})
+	EventStore.ApprovalApproved(*request)
+	return &job, nil
+}
+
+// Reject will reject the request. The user who made the request may also reject it to withdraw it.
+func (s *approvalStoreObject) Reject(request *ApprovalRequest, user *User, comment string) (err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		err = s.reject(tx, request, user, comment)
+		return nil
+	})
+	return
+}
+
+func (s *approvalStoreObject) reject(tx ds.IReadWriteTransaction, request *ApprovalRequest, user *User, comment string) *Error {
+	if _, err := s.reviewable(tx, request, user); err != nil {
+		return err
+	}
+
+	request.Status = ApprovalStatusRejected
+	request.ReviewedBy = user.Username
+	request.Reviewed = time.Now()
+	request.Comment = comment
+	if err := limits.Check(request); err != nil {
+		return ErrorUser(err.Error())
+	}
+	if err := tx.Update(*request); err != nil {
+		log.Error("Error updating approval request '%s': %s", request.ID, err.Error())
+		return ErrorFrom(err)
+	}
+
+	log.PInfo("Approval request rejected", map[string]interface{}{
+		"request_id":  request.ID,
+		"rejected_by": user.Username,
+	})
+	EventStore.ApprovalRejected(*request)
+	return nil
+}
+
+func (s *approvalStoreObject) expireRequest(tx ds.IReadWriteTransaction, request ApprovalRequest) {
+	request.Status = ApprovalStatusExpired
+	if err := tx.Update(request); err != nil {
+		log.PError("Error expiring approval request", map[string]interface{}{
+			"request_id": request.ID,
+			"error":      err.Error(),
+		})
+		return
+	}
+	log.PInfo("Approval request expired", map[string]interface{}{
+		"request_id": request.ID,
+	})
+	EventStore.ApprovalExpired(request)
+}
+
+// ExpireRequests will expire all pending requests that have passed their expiry
+func (s *approvalStoreObject) ExpireRequests() {
+	now := time.Now()
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		for _, request := range s.allRequests(tx) {
+			if request.expired(now) {
+				s.expireRequest(tx, request)
+			}
+		}
+		return nil
+	})
+}
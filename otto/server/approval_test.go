@@ -0,0 +1,165 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/web"
+)
+
+func TestApprovalRequest(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:             randomString(6),
+		Executable:       "/bin/sh",
+		Script:           "echo hello",
+		RunLevel:         ScriptRunLevelReadWrite,
+		RequiresApproval: true,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	newUser := func(runLevel int) *User {
+		permissions := UserPermissionsMin()
+		permissions.ScriptRunLevel = runLevel
+		user, err := UserStore.NewUser(newUserParameters{
+			Username:    randomString(6),
+			Password:    randomString(6),
+			Permissions: permissions,
+		})
+		if err != nil {
+			t.Fatalf("Error making user: %s", err.Message)
+		}
+		return user
+	}
+	requester := newUser(ScriptRunLevelReadWrite)
+	approver := newUser(ScriptRunLevelReadWrite)
+	readOnly := newUser(ScriptRunLevelReadOnly)
+
+	h := handle{}
+	session := SessionStore.NewSessionForUser(requester)
+
+	// Running the script directly is rejected
+	_, _, werr := h.RequestNew(web.MockRequest(web.MockRequestParameters{UserData: &session, JSONBody: map[string]interface{}{
+		"HostID":   host.ID,
+		"Action":   AgentActionRunScript,
+		"ScriptID": script.ID,
+	}}))
+	if werr == nil {
+		t.Fatalf("No error seen running script that requires approval")
+	}
+
+	// Starting a job makes an approval request instead
+	data, _, werr := h.JobNew(web.MockRequest(web.MockRequestParameters{UserData: &session, JSONBody: map[string]interface{}{
+		"ScriptID": script.ID,
+		"HostIDs":  []string{host.ID},
+		"Reason":   "test",
+	}}))
+	if werr != nil {
+		t.Fatalf("Unexpected error starting job: %s", werr.Message)
+	}
+	request, ok := data.(*ApprovalRequest)
+	if !ok {
+		t.Fatalf("Job should return an approval request")
+	}
+	if request.Status != ApprovalStatusPending {
+		t.Fatalf("Unexpected request status: %s", request.Status)
+	}
+
+	if _, err := ApprovalStore.Approve(request, requester, ""); err == nil {
+		t.Errorf("No error seen approving own request")
+	}
+	if _, err := ApprovalStore.Approve(request, readOnly, ""); err == nil {
+		t.Errorf("No error seen approving request without permission")
+	}
+	job, err := ApprovalStore.Approve(request, approver, "ok")
+	if err != nil {
+		t.Fatalf("Unexpected error approving request: %s", err.Message)
+	}
+	if jobStore.Get(job.ID) == nil {
+		t.Errorf("Approving request should start a job")
+	}
+	request = ApprovalStore.RequestWithID(request.ID)
+	if request.Status != ApprovalStatusApproved || request.ReviewedBy != approver.Username || request.JobID != job.ID {
+		t.Errorf("Unexpected approved request: %+v", request)
+	}
+	if _, err := ApprovalStore.Approve(request, approver, ""); err == nil {
+		t.Errorf("No error seen approving request twice")
+	}
+
+	// Requests can be rejected
+	request, err = ApprovalStore.NewRequest(newApprovalRequestParameters{
+		ScriptID: script.ID,
+		HostIDs:  []string{host.ID},
+	}, requester)
+	if err != nil {
+		t.Fatalf("Unexpected error making request: %s", err.Message)
+	}
+	if err := ApprovalStore.Reject(request, approver, "no"); err != nil {
+		t.Fatalf("Unexpected error rejecting request: %s", err.Message)
+	}
+	if request := ApprovalStore.RequestWithID(request.ID); request.Status != ApprovalStatusRejected {
+		t.Errorf("Unexpected request status: %s", request.Status)
+	}
+	if _, err := ApprovalStore.Approve(request, approver, ""); err == nil {
+		t.Errorf("No error seen approving rejected request")
+	}
+}
+
+func TestApprovalRequestExpiry(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:             randomString(6),
+		Executable:       "/bin/sh",
+		Script:           "echo hello",
+		RunLevel:         ScriptRunLevelReadOnly,
+		RequiresApproval: true,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	requester := &User{Username: randomString(6), Permissions: UserPermissionsMax()}
+	approver := &User{Username: randomString(6), Permissions: UserPermissionsMax()}
+
+	request, err := ApprovalStore.NewRequest(newApprovalRequestParameters{
+		ScriptID: script.ID,
+		HostIDs:  []string{host.ID},
+	}, requester)
+	if err != nil {
+		t.Fatalf("Unexpected error making request: %s", err.Message)
+	}
+	if !request.Expires.After(time.Now()) {
+		t.Fatalf("Request should expire in the future")
+	}
+
+	request.Expires = time.Now().Add(-time.Minute)
+	ApprovalStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Update(*request)
+	})
+	ApprovalStore.ExpireRequests()
+
+	request = ApprovalStore.RequestWithID(request.ID)
+	if request.Status != ApprovalStatusExpired {
+		t.Fatalf("Unexpected request status: %s", request.Status)
+	}
+	if _, err := ApprovalStore.Approve(request, approver, ""); err == nil {
+		t.Errorf("No error seen approving expired request")
+	}
+}
@@ -35,6 +35,15 @@ func preBootstrapArgs() {
 			value := args[i+1]
 			bindAddress = value
 			i++
+		} else if arg == "-r" || arg == "--reverse-addr" {
+			if i == count-1 {
+				fmt.Fprintf(os.Stderr, "%s requires exactly 1 parameter\n", arg)
+				printHelpAndExit()
+			}
+
+			value := args[i+1]
+			reverseListenAddress = value
+			i++
 		} else if arg == "--no-scheduler" {
 			cronDisabled = true
 		} else if arg == "-h" || arg == "--help" {
@@ -206,6 +215,7 @@ func printHelpAndExit() {
 	fmt.Printf("Options:\n")
 	fmt.Printf("-d --data-dir <path>        Specify the absolute path to the data directory\n")
 	fmt.Printf("-b --bind-addr <socket>     Specify the listen address for the web server\n")
+	fmt.Printf("-r --reverse-addr <socket>  Specify the listen address for reverse connections from agents\n")
 	fmt.Printf("-v --verbose                Set the log level to debug\n")
 	fmt.Printf("--no-scheduler              Disable all automatic tasks\n")
 	os.Exit(1)
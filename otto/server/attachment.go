@@ -24,6 +24,8 @@ type Attachment struct {
 	Size        uint64
 	AfterScript bool
 	Checksum    string
+	// Template if the file is rendered as a template for each host before it is uploaded
+	Template bool
 }
 
 // GetChecksum get the real checksum for the file path
@@ -104,6 +104,7 @@ type newAttachmentParameters struct {
 	Mode        uint32
 	Size        uint64
 	AfterScript bool
+	Template    bool
 }
 
 func (s attachmentStoreObject) NewAttachment(params newAttachmentParameters) (attachment *Attachment, err *Error) {
@@ -130,6 +131,7 @@ func (s attachmentStoreObject) newAttachment(tx ds.IReadWriteTransaction, params
 		Modified:    time.Now(),
 		Size:        params.Size,
 		AfterScript: params.AfterScript,
+		Template:    params.Template,
 	}
 
 	f, err := os.OpenFile(attachment.FilePath(), os.O_CREATE|os.O_RDWR, 0644)
@@ -161,6 +163,13 @@ func (s attachmentStoreObject) newAttachment(tx ds.IReadWriteTransaction, params
 	}
 	attachment.Checksum = checksum
 
+	if attachment.Template {
+		if err := validateTemplateFile(attachment.Name, attachment.FilePath()); err != nil {
+			os.Remove(attachment.FilePath())
+			return nil, err
+		}
+	}
+
 	if err := tx.Add(attachment); err != nil {
 		log.PError("Error saving attachment", map[string]interface{}{
 			"attachment": attachment.ID,
@@ -181,6 +190,7 @@ type editAttachmentParams struct {
 	Mode        uint32
 	Size        uint64
 	AfterScript bool
+	Template    bool
 }
 
 func (s attachmentStoreObject) EditAttachment(id string, params editAttachmentParams) (attachment *Attachment, err *Error) {
@@ -202,6 +212,7 @@ func (s attachmentStoreObject) editAttachment(tx ds.IReadWriteTransaction, id st
 	attachment.Mode = params.Mode
 	attachment.Modified = time.Now()
 	attachment.AfterScript = params.AfterScript
+	attachment.Template = params.Template
 
 	if params.Data != nil {
 		f, err := os.OpenFile(attachment.AtomicFilePath(), os.O_CREATE|os.O_RDWR, 0644)
@@ -235,6 +246,13 @@ func (s attachmentStoreObject) editAttachment(tx ds.IReadWriteTransaction, id st
 			return nil, ErrorFrom(err)
 		}
 
+		if attachment.Template {
+			if err := validateTemplateFile(params.Name, attachment.AtomicFilePath()); err != nil {
+				os.Remove(attachment.AtomicFilePath())
+				return nil, err
+			}
+		}
+
 		if err := os.Rename(attachment.AtomicFilePath(), attachment.FilePath()); err != nil {
 			log.PError("Error writing updated attachment file", map[string]interface{}{
 				"attachment": attachment.ID,
@@ -248,6 +266,10 @@ func (s attachmentStoreObject) editAttachment(tx ds.IReadWriteTransaction, id st
 		attachment.Size = params.Size
 		attachment.Checksum = checksum
 		attachment.MimeType = params.MimeType
+	} else if attachment.Template {
+		if err := validateTemplateFile(attachment.Name, attachment.FilePath()); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Update(*attachment); err != nil {
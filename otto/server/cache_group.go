@@ -12,6 +12,10 @@ type cacheTypeGroup struct {
 	byName  map[string]int
 	byID    map[string]int
 	hostIDs map[string][]string
+	// selected the IDs of groups with a selector that matches each host, keyed by host ID
+	selected map[string][]string
+	// ancestors the IDs of the ancestors of each group starting with the root, keyed by group ID
+	ancestors map[string][]string
 }
 
 // GroupCache the group cache
@@ -19,6 +23,8 @@ var GroupCache = &cacheTypeGroup{lock: &sync.RWMutex{}}
 
 // Update populate the group cache, will panic if not able to populate
 func (c *cacheTypeGroup) Update(tx ds.IReadTransaction) {
+	properties := hostProperties()
+
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -26,6 +32,8 @@ func (c *cacheTypeGroup) Update(tx ds.IReadTransaction) {
 	c.byName = map[string]int{}
 	c.byID = map[string]int{}
 	c.hostIDs = map[string][]string{}
+	c.selected = map[string][]string{}
+	c.ancestors = map[string][]string{}
 	groups := GroupStore.allGroups(tx)
 
 	c.all = groups
@@ -34,9 +42,44 @@ func (c *cacheTypeGroup) Update(tx ds.IReadTransaction) {
 		c.byID[group.ID] = i
 		c.hostIDs[group.ID] = []string{}
 	}
-	for _, host := range HostCache.All() {
-		for _, groupID := range host.GroupIDs {
-			c.hostIDs[groupID] = append(c.hostIDs[groupID], host.ID)
+	for _, group := range groups {
+		c.ancestors[group.ID] = c.findAncestors(group)
+	}
+	hosts := HostCache.All()
+	for _, group := range groups {
+		if group.Selector == "" {
+			continue
+		}
+		selector, err := parseSelector(group.Selector)
+		if err != nil {
+			log.PError("Invalid group selector", map[string]interface{}{
+				"group_id": group.ID,
+				"selector": group.Selector,
+				"error":    err.Error(),
+			})
+			continue
+		}
+		for _, host := range hosts {
+			if sliceContains(group.ID, host.GroupIDs) {
+				continue
+			}
+			if selector.Matches(host.Labels, properties[host.Address]) {
+				c.selected[host.ID] = append(c.selected[host.ID], group.ID)
+			}
+		}
+	}
+
+	// Members of a group are also members of all of its ancestors
+	for _, host := range hosts {
+		added := map[string]bool{}
+		for _, groupID := range append(append([]string{}, host.GroupIDs...), c.selected[host.ID]...) {
+			for _, id := range append(append([]string{}, c.ancestors[groupID]...), groupID) {
+				if added[id] {
+					continue
+				}
+				added[id] = true
+				c.hostIDs[id] = append(c.hostIDs[id], host.ID)
+			}
 		}
 	}
 
@@ -44,6 +87,28 @@ func (c *cacheTypeGroup) Update(tx ds.IReadTransaction) {
 	Stats.Counters.NumberGroups.Set(uint64(len(c.all)))
 }
 
+// findAncestors returns the IDs of the ancestors of the group starting with the root. The store prevents cycles, but
+// should one exist the ancestors stop before the group repeats.
+func (c *cacheTypeGroup) findAncestors(group Group) []string {
+	ancestors := []string{}
+	seen := map[string]bool{group.ID: true}
+	parentID := group.ParentID
+	for parentID != "" && !seen[parentID] {
+		idx, k := c.byID[parentID]
+		if !k {
+			log.PError("Group refers to non-existant parent group", map[string]interface{}{
+				"group_id":  group.ID,
+				"parent_id": parentID,
+			})
+			break
+		}
+		seen[parentID] = true
+		ancestors = append([]string{parentID}, ancestors...)
+		parentID = c.all[idx].ParentID
+	}
+	return ancestors
+}
+
 // All get all groups
 func (c *cacheTypeGroup) All() []Group {
 	c.lock.RLock()
@@ -99,3 +164,33 @@ func (c *cacheTypeGroup) HostIDs(id string) []string {
 
 	return ids
 }
+
+// SelectedGroupIDs return the IDs of groups with a selector that matches the given host, not including groups that the
+// host is a member of
+func (c *cacheTypeGroup) SelectedGroupIDs(hostID string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.selected[hostID]
+}
+
+// Ancestors return the IDs of the ancestors of the given group starting with the root
+func (c *cacheTypeGroup) Ancestors(id string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ancestors[id]
+}
+
+// HasSelectors returns true if any group has a selector
+func (c *cacheTypeGroup) HasSelectors() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, group := range c.all {
+		if group.Selector != "" {
+			return true
+		}
+	}
+	return false
+}
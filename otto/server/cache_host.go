@@ -7,11 +7,12 @@ import (
 )
 
 type cacheTypeHost struct {
-	lock    *sync.RWMutex
-	all     []Host
-	enabled []Host
-	byName  map[string]int
-	byID    map[string]int
+	lock       *sync.RWMutex
+	all        []Host
+	enabled    []Host
+	byName     map[string]int
+	byID       map[string]int
+	byIdentity map[string]int
 }
 
 // HostCache the host cache
@@ -26,6 +27,7 @@ func (c *cacheTypeHost) Update(tx ds.IReadTransaction) {
 	c.enabled = []Host{}
 	c.byName = map[string]int{}
 	c.byID = map[string]int{}
+	c.byIdentity = map[string]int{}
 	hosts := HostStore.allHosts(tx)
 
 	nTrusted := uint64(0)
@@ -39,6 +41,7 @@ func (c *cacheTypeHost) Update(tx ds.IReadTransaction) {
 		c.byName[host.Name] = i
 		c.byID[host.ID] = i
 		if host.Trust.TrustedIdentity != "" {
+			c.byIdentity[host.Trust.TrustedIdentity] = i
 			nTrusted++
 		} else {
 			nUntrusted++
@@ -93,3 +96,15 @@ func (c *cacheTypeHost) ByID(id string) *Host {
 	}
 	return &c.all[idx]
 }
+
+// ByTrustedIdentity get a host by its trusted agent identity
+func (c *cacheTypeHost) ByTrustedIdentity(identity string) *Host {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	idx, k := c.byIdentity[identity]
+	if !k {
+		return nil
+	}
+	return &c.all[idx]
+}
@@ -9,6 +9,19 @@ import (
 	"github.com/ecnepsnai/ds"
 )
 
+type approvalStoreObject struct{ Table *ds.Table }
+
+// ApprovalStore the global approval store
+var ApprovalStore = approvalStoreObject{}
+
+func cbgenDataStoreRegisterApprovalStore() {
+	table, err := ds.Register(ApprovalRequest{}, path.Join(Directories.Data, "approval.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering approval store: %s", err.Error())
+	}
+	ApprovalStore.Table = table
+}
+
 type attachmentStoreObject struct{ Table *ds.Table }
 
 // AttachmentStore the global attachment store
@@ -61,6 +74,19 @@ func cbgenDataStoreRegisterHostStore() {
 	HostStore.Table = table
 }
 
+type maintenancewindowStoreObject struct{ Table *ds.Table }
+
+// MaintenanceWindowStore the global maintenancewindow store
+var MaintenanceWindowStore = maintenancewindowStoreObject{}
+
+func cbgenDataStoreRegisterMaintenanceWindowStore() {
+	table, err := ds.Register(MaintenanceWindow{}, path.Join(Directories.Data, "maintenancewindow.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering maintenancewindow store: %s", err.Error())
+	}
+	MaintenanceWindowStore.Table = table
+}
+
 type registerruleStoreObject struct{ Table *ds.Table }
 
 // RegisterRuleStore the global registerrule store
@@ -113,6 +139,32 @@ func cbgenDataStoreRegisterScriptStore() {
 	ScriptStore.Table = table
 }
 
+type scriptrevisionStoreObject struct{ Table *ds.Table }
+
+// ScriptRevisionStore the global scriptrevision store
+var ScriptRevisionStore = scriptrevisionStoreObject{}
+
+func cbgenDataStoreRegisterScriptRevisionStore() {
+	table, err := ds.Register(ScriptRevision{}, path.Join(Directories.Data, "scriptrevision.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering scriptrevision store: %s", err.Error())
+	}
+	ScriptRevisionStore.Table = table
+}
+
+type scriptrunStoreObject struct{ Table *ds.Table }
+
+// ScriptRunStore the global scriptrun store
+var ScriptRunStore = scriptrunStoreObject{}
+
+func cbgenDataStoreRegisterScriptRunStore() {
+	table, err := ds.Register(ScriptRun{}, path.Join(Directories.Data, "scriptrun.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering scriptrun store: %s", err.Error())
+	}
+	ScriptRunStore.Table = table
+}
+
 type userStoreObject struct{ Table *ds.Table }
 
 // UserStore the global user store
@@ -126,21 +178,56 @@ func cbgenDataStoreRegisterUserStore() {
 	UserStore.Table = table
 }
 
+type workflowStoreObject struct{ Table *ds.Table }
+
+// WorkflowStore the global workflow store
+var WorkflowStore = workflowStoreObject{}
+
+func cbgenDataStoreRegisterWorkflowStore() {
+	table, err := ds.Register(Workflow{}, path.Join(Directories.Data, "workflow.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering workflow store: %s", err.Error())
+	}
+	WorkflowStore.Table = table
+}
+
+type workflowreportStoreObject struct{ Table *ds.Table }
+
+// WorkflowReportStore the global workflowreport store
+var WorkflowReportStore = workflowreportStoreObject{}
+
+func cbgenDataStoreRegisterWorkflowReportStore() {
+	table, err := ds.Register(WorkflowReport{}, path.Join(Directories.Data, "workflowreport.db"), &ds.Options{})
+	if err != nil {
+		log.Fatal("Error registering workflowreport store: %s", err.Error())
+	}
+	WorkflowReportStore.Table = table
+}
+
 // dataStoreSetup set up the data store
 func dataStoreSetup() {
+	cbgenDataStoreRegisterApprovalStore()
 	cbgenDataStoreRegisterAttachmentStore()
 	cbgenDataStoreRegisterEventStore()
 	cbgenDataStoreRegisterGroupStore()
 	cbgenDataStoreRegisterHostStore()
+	cbgenDataStoreRegisterMaintenanceWindowStore()
 	cbgenDataStoreRegisterRegisterRuleStore()
 	cbgenDataStoreRegisterScheduleStore()
 	cbgenDataStoreRegisterScheduleReportStore()
 	cbgenDataStoreRegisterScriptStore()
+	cbgenDataStoreRegisterScriptRevisionStore()
+	cbgenDataStoreRegisterScriptRunStore()
 	cbgenDataStoreRegisterUserStore()
+	cbgenDataStoreRegisterWorkflowStore()
+	cbgenDataStoreRegisterWorkflowReportStore()
 }
 
 // dataStoreTeardown tear down the data store
 func dataStoreTeardown() {
+	if ApprovalStore.Table != nil {
+		ApprovalStore.Table.Close()
+	}
 	if AttachmentStore.Table != nil {
 		AttachmentStore.Table.Close()
 	}
@@ -153,6 +240,9 @@ func dataStoreTeardown() {
 	if HostStore.Table != nil {
 		HostStore.Table.Close()
 	}
+	if MaintenanceWindowStore.Table != nil {
+		MaintenanceWindowStore.Table.Close()
+	}
 	if RegisterRuleStore.Table != nil {
 		RegisterRuleStore.Table.Close()
 	}
@@ -165,7 +255,19 @@ func dataStoreTeardown() {
 	if ScriptStore.Table != nil {
 		ScriptStore.Table.Close()
 	}
+	if ScriptRevisionStore.Table != nil {
+		ScriptRevisionStore.Table.Close()
+	}
+	if ScriptRunStore.Table != nil {
+		ScriptRunStore.Table.Close()
+	}
 	if UserStore.Table != nil {
 		UserStore.Table.Close()
 	}
+	if WorkflowStore.Table != nil {
+		WorkflowStore.Table.Close()
+	}
+	if WorkflowReportStore.Table != nil {
+		WorkflowReportStore.Table.Close()
+	}
 }
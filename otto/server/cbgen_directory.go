@@ -27,6 +27,7 @@ type apiDirectories struct {
 	Agents      string
 	Data        string
 	Attachments string
+	Runs        string
 	Logs        string
 	Static      string
 }
@@ -44,6 +45,8 @@ func fsSetup() {
 
 		Attachments: path.Join(dataDirectory, "data", "attachments"),
 
+		Runs: path.Join(dataDirectory, "data", "runs"),
+
 		Logs: path.Join(dataDirectory, "logs"),
 
 		Static: path.Join(operatingDirectory, "static"),
@@ -55,6 +58,8 @@ func fsSetup() {
 
 	MakeDirectoryIfNotExist(Directories.Attachments)
 
+	MakeDirectoryIfNotExist(Directories.Runs)
+
 	MakeDirectoryIfNotExist(Directories.Logs)
 
 	MakeDirectoryIfNotExist(Directories.Static)
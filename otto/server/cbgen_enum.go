@@ -51,6 +51,130 @@ func ForEachAgentAction(m func(value string)) {
 	}
 }
 
+const (
+	// The request is waiting for approval
+	ApprovalStatusPending = "pending"
+	// The request was approved and the script was started
+	ApprovalStatusApproved = "approved"
+	// The request was rejected
+	ApprovalStatusRejected = "rejected"
+	// The request was not approved or rejected in time
+	ApprovalStatusExpired = "expired"
+)
+
+// AllApprovalStatus all ApprovalStatus values
+var AllApprovalStatus = []string{
+	ApprovalStatusPending,
+	ApprovalStatusApproved,
+	ApprovalStatusRejected,
+	ApprovalStatusExpired,
+}
+
+// ApprovalStatusMap map ApprovalStatus keys to values
+var ApprovalStatusMap = map[string]string{
+	ApprovalStatusPending:  "pending",
+	ApprovalStatusApproved: "approved",
+	ApprovalStatusRejected: "rejected",
+	ApprovalStatusExpired:  "expired",
+}
+
+// IsApprovalStatus is the provided value a valid ApprovalStatus
+func IsApprovalStatus(q string) bool {
+	_, k := ApprovalStatusMap[q]
+	return k
+}
+
+// ForEachApprovalStatus call m for each ApprovalStatus
+func ForEachApprovalStatus(m func(value string)) {
+	for _, v := range AllApprovalStatus {
+		m(v)
+	}
+}
+
+const (
+	// Runs missed while the server was not running are not run
+	CatchUpPolicySkip = "skip"
+	// If any runs were missed while the server was not running the schedule is run once
+	CatchUpPolicyOnce = "once"
+	// Every run missed while the server was not running is run, one after another
+	CatchUpPolicyAll = "all"
+)
+
+// AllCatchUpPolicy all CatchUpPolicy values
+var AllCatchUpPolicy = []string{
+	CatchUpPolicySkip,
+	CatchUpPolicyOnce,
+	CatchUpPolicyAll,
+}
+
+// CatchUpPolicyMap map CatchUpPolicy keys to values
+var CatchUpPolicyMap = map[string]string{
+	CatchUpPolicySkip: "skip",
+	CatchUpPolicyOnce: "once",
+	CatchUpPolicyAll:  "all",
+}
+
+// IsCatchUpPolicy is the provided value a valid CatchUpPolicy
+func IsCatchUpPolicy(q string) bool {
+	_, k := CatchUpPolicyMap[q]
+	return k
+}
+
+// ForEachCatchUpPolicy call m for each CatchUpPolicy
+func ForEachCatchUpPolicy(m func(value string)) {
+	for _, v := range AllCatchUpPolicy {
+		m(v)
+	}
+}
+
+const (
+	// Variables set by the otto server
+	EnvironmentSourceServer = "server"
+	// The global environment from the server options
+	EnvironmentSourceGlobal = "global"
+	// The environment of the script
+	EnvironmentSourceScript = "script"
+	// The environment of a group the host is a member of
+	EnvironmentSourceGroup = "group"
+	// The environment of the host
+	EnvironmentSourceHost = "host"
+	// A script parameter value
+	EnvironmentSourceParameter = "parameter"
+)
+
+// AllEnvironmentSource all EnvironmentSource values
+var AllEnvironmentSource = []string{
+	EnvironmentSourceServer,
+	EnvironmentSourceGlobal,
+	EnvironmentSourceScript,
+	EnvironmentSourceGroup,
+	EnvironmentSourceHost,
+	EnvironmentSourceParameter,
+}
+
+// EnvironmentSourceMap map EnvironmentSource keys to values
+var EnvironmentSourceMap = map[string]string{
+	EnvironmentSourceServer:    "server",
+	EnvironmentSourceGlobal:    "global",
+	EnvironmentSourceScript:    "script",
+	EnvironmentSourceGroup:     "group",
+	EnvironmentSourceHost:      "host",
+	EnvironmentSourceParameter: "parameter",
+}
+
+// IsEnvironmentSource is the provided value a valid EnvironmentSource
+func IsEnvironmentSource(q string) bool {
+	_, k := EnvironmentSourceMap[q]
+	return k
+}
+
+// ForEachEnvironmentSource call m for each EnvironmentSource
+func ForEachEnvironmentSource(m func(value string)) {
+	for _, v := range AllEnvironmentSource {
+		m(v)
+	}
+}
+
 const (
 	// UserLoggedIn event
 	EventTypeUserLoggedIn = "UserLoggedIn"
@@ -84,6 +208,10 @@ const (
 	EventTypeHostTrustModified = "HostTrustModified"
 	// HostIdentityRotated event
 	EventTypeHostIdentityRotated = "HostIdentityRotated"
+	// HostFileDownloaded event
+	EventTypeHostFileDownloaded = "HostFileDownloaded"
+	// HostFileDownloadFailed event
+	EventTypeHostFileDownloadFailed = "HostFileDownloadFailed"
 	// HostBecameReachable event
 	EventTypeHostBecameReachable = "HostBecameReachable"
 	// HostBecameUnreachable event
@@ -100,6 +228,14 @@ const (
 	EventTypeScheduleModified = "ScheduleModified"
 	// ScheduleDeleted event
 	EventTypeScheduleDeleted = "ScheduleDeleted"
+	// WorkflowAdded event
+	EventTypeWorkflowAdded = "WorkflowAdded"
+	// WorkflowModified event
+	EventTypeWorkflowModified = "WorkflowModified"
+	// WorkflowDeleted event
+	EventTypeWorkflowDeleted = "WorkflowDeleted"
+	// WorkflowRun event
+	EventTypeWorkflowRun = "WorkflowRun"
 	// AttachmentAdded event
 	EventTypeAttachmentAdded = "AttachmentAdded"
 	// AttachmentModified event
@@ -114,6 +250,24 @@ const (
 	EventTypeScriptDeleted = "ScriptDeleted"
 	// ScriptRun event
 	EventTypeScriptRun = "ScriptRun"
+	// CommandRun event
+	EventTypeCommandRun = "CommandRun"
+	// MaintenanceWindowAdded event
+	EventTypeMaintenanceWindowAdded = "MaintenanceWindowAdded"
+	// MaintenanceWindowModified event
+	EventTypeMaintenanceWindowModified = "MaintenanceWindowModified"
+	// MaintenanceWindowDeleted event
+	EventTypeMaintenanceWindowDeleted = "MaintenanceWindowDeleted"
+	// MaintenanceWindowOverridden event
+	EventTypeMaintenanceWindowOverridden = "MaintenanceWindowOverridden"
+	// ApprovalRequested event
+	EventTypeApprovalRequested = "ApprovalRequested"
+	// ApprovalApproved event
+	EventTypeApprovalApproved = "ApprovalApproved"
+	// ApprovalRejected event
+	EventTypeApprovalRejected = "ApprovalRejected"
+	// ApprovalExpired event
+	EventTypeApprovalExpired = "ApprovalExpired"
 	// ServerStarted event
 	EventTypeServerStarted = "ServerStarted"
 	// ServerOptionsModified event
@@ -144,6 +298,8 @@ var AllEventType = []string{
 	EventTypeHostRegisterIncorrectKey,
 	EventTypeHostTrustModified,
 	EventTypeHostIdentityRotated,
+	EventTypeHostFileDownloaded,
+	EventTypeHostFileDownloadFailed,
 	EventTypeHostBecameReachable,
 	EventTypeHostBecameUnreachable,
 	EventTypeGroupAdded,
@@ -152,6 +308,10 @@ var AllEventType = []string{
 	EventTypeScheduleAdded,
 	EventTypeScheduleModified,
 	EventTypeScheduleDeleted,
+	EventTypeWorkflowAdded,
+	EventTypeWorkflowModified,
+	EventTypeWorkflowDeleted,
+	EventTypeWorkflowRun,
 	EventTypeAttachmentAdded,
 	EventTypeAttachmentModified,
 	EventTypeAttachmentDeleted,
@@ -159,6 +319,15 @@ var AllEventType = []string{
 	EventTypeScriptModified,
 	EventTypeScriptDeleted,
 	EventTypeScriptRun,
+	EventTypeCommandRun,
+	EventTypeMaintenanceWindowAdded,
+	EventTypeMaintenanceWindowModified,
+	EventTypeMaintenanceWindowDeleted,
+	EventTypeMaintenanceWindowOverridden,
+	EventTypeApprovalRequested,
+	EventTypeApprovalApproved,
+	EventTypeApprovalRejected,
+	EventTypeApprovalExpired,
 	EventTypeServerStarted,
 	EventTypeServerOptionsModified,
 	EventTypeRegisterRuleAdded,
@@ -168,42 +337,57 @@ var AllEventType = []string{
 
 // EventTypeMap map EventType keys to values
 var EventTypeMap = map[string]string{
-	EventTypeUserLoggedIn:             "UserLoggedIn",
-	EventTypeUserIncorrectPassword:    "UserIncorrectPassword",
-	EventTypeUserLoggedOut:            "UserLoggedOut",
-	EventTypeUserAdded:                "UserAdded",
-	EventTypeUserModified:             "UserModified",
-	EventTypeUserResetPassword:        "UserResetPassword",
-	EventTypeUserResetAPIKey:          "UserResetAPIKey",
-	EventTypeUserDeleted:              "UserDeleted",
-	EventTypeUserPermissionDenied:     "UserPermissionDenied",
-	EventTypeHostAdded:                "HostAdded",
-	EventTypeHostModified:             "HostModified",
-	EventTypeHostDeleted:              "HostDeleted",
-	EventTypeHostRegisterSuccess:      "HostRegisterSuccess",
-	EventTypeHostRegisterIncorrectKey: "HostRegisterIncorrectKey",
-	EventTypeHostTrustModified:        "HostTrustModified",
-	EventTypeHostIdentityRotated:      "HostIdentityRotated",
-	EventTypeHostBecameReachable:      "HostBecameReachable",
-	EventTypeHostBecameUnreachable:    "HostBecameUnreachable",
-	EventTypeGroupAdded:               "GroupAdded",
-	EventTypeGroupModified:            "GroupModified",
-	EventTypeGroupDeleted:             "GroupDeleted",
-	EventTypeScheduleAdded:            "ScheduleAdded",
-	EventTypeScheduleModified:         "ScheduleModified",
-	EventTypeScheduleDeleted:          "ScheduleDeleted",
-	EventTypeAttachmentAdded:          "AttachmentAdded",
-	EventTypeAttachmentModified:       "AttachmentModified",
-	EventTypeAttachmentDeleted:        "AttachmentDeleted",
-	EventTypeScriptAdded:              "ScriptAdded",
-	EventTypeScriptModified:           "ScriptModified",
-	EventTypeScriptDeleted:            "ScriptDeleted",
-	EventTypeScriptRun:                "ScriptRun",
-	EventTypeServerStarted:            "ServerStarted",
-	EventTypeServerOptionsModified:    "ServerOptionsModified",
-	EventTypeRegisterRuleAdded:        "RegisterRuleAdded",
-	EventTypeRegisterRuleModified:     "RegisterRuleModified",
-	EventTypeRegisterRuleDeleted:      "RegisterRuleDeleted",
+	EventTypeUserLoggedIn:                "UserLoggedIn",
+	EventTypeUserIncorrectPassword:       "UserIncorrectPassword",
+	EventTypeUserLoggedOut:               "UserLoggedOut",
+	EventTypeUserAdded:                   "UserAdded",
+	EventTypeUserModified:                "UserModified",
+	EventTypeUserResetPassword:           "UserResetPassword",
+	EventTypeUserResetAPIKey:             "UserResetAPIKey",
+	EventTypeUserDeleted:                 "UserDeleted",
+	EventTypeUserPermissionDenied:        "UserPermissionDenied",
+	EventTypeHostAdded:                   "HostAdded",
+	EventTypeHostModified:                "HostModified",
+	EventTypeHostDeleted:                 "HostDeleted",
+	EventTypeHostRegisterSuccess:         "HostRegisterSuccess",
+	EventTypeHostRegisterIncorrectKey:    "HostRegisterIncorrectKey",
+	EventTypeHostTrustModified:           "HostTrustModified",
+	EventTypeHostIdentityRotated:         "HostIdentityRotated",
+	EventTypeHostFileDownloaded:          "HostFileDownloaded",
+	EventTypeHostFileDownloadFailed:      "HostFileDownloadFailed",
+	EventTypeHostBecameReachable:         "HostBecameReachable",
+	EventTypeHostBecameUnreachable:       "HostBecameUnreachable",
+	EventTypeGroupAdded:                  "GroupAdded",
+	EventTypeGroupModified:               "GroupModified",
+	EventTypeGroupDeleted:                "GroupDeleted",
+	EventTypeScheduleAdded:               "ScheduleAdded",
+	EventTypeScheduleModified:            "ScheduleModified",
+	EventTypeScheduleDeleted:             "ScheduleDeleted",
+	EventTypeWorkflowAdded:               "WorkflowAdded",
+	EventTypeWorkflowModified:            "WorkflowModified",
+	EventTypeWorkflowDeleted:             "WorkflowDeleted",
+	EventTypeWorkflowRun:                 "WorkflowRun",
+	EventTypeAttachmentAdded:             "AttachmentAdded",
+	EventTypeAttachmentModified:          "AttachmentModified",
+	EventTypeAttachmentDeleted:           "AttachmentDeleted",
+	EventTypeScriptAdded:                 "ScriptAdded",
+	EventTypeScriptModified:              "ScriptModified",
+	EventTypeScriptDeleted:               "ScriptDeleted",
+	EventTypeScriptRun:                   "ScriptRun",
+	EventTypeCommandRun:                  "CommandRun",
+	EventTypeMaintenanceWindowAdded:      "MaintenanceWindowAdded",
+	EventTypeMaintenanceWindowModified:   "MaintenanceWindowModified",
+	EventTypeMaintenanceWindowDeleted:    "MaintenanceWindowDeleted",
+	EventTypeMaintenanceWindowOverridden: "MaintenanceWindowOverridden",
+	EventTypeApprovalRequested:           "ApprovalRequested",
+	EventTypeApprovalApproved:            "ApprovalApproved",
+	EventTypeApprovalRejected:            "ApprovalRejected",
+	EventTypeApprovalExpired:             "ApprovalExpired",
+	EventTypeServerStarted:               "ServerStarted",
+	EventTypeServerOptionsModified:       "ServerOptionsModified",
+	EventTypeRegisterRuleAdded:           "RegisterRuleAdded",
+	EventTypeRegisterRuleModified:        "RegisterRuleModified",
+	EventTypeRegisterRuleDeleted:         "RegisterRuleDeleted",
 }
 
 // IsEventType is the provided value a valid EventType
@@ -219,6 +403,38 @@ func ForEachEventType(m func(value string)) {
 	}
 }
 
+const (
+	// The server connects to the agent
+	HostConnectionModeDirect = "direct"
+	// The agent connects to the server
+	HostConnectionModeReverse = "reverse"
+)
+
+// AllHostConnectionMode all HostConnectionMode values
+var AllHostConnectionMode = []string{
+	HostConnectionModeDirect,
+	HostConnectionModeReverse,
+}
+
+// HostConnectionModeMap map HostConnectionMode keys to values
+var HostConnectionModeMap = map[string]string{
+	HostConnectionModeDirect:  "direct",
+	HostConnectionModeReverse: "reverse",
+}
+
+// IsHostConnectionMode is the provided value a valid HostConnectionMode
+func IsHostConnectionMode(q string) bool {
+	_, k := HostConnectionModeMap[q]
+	return k
+}
+
+// ForEachHostConnectionMode call m for each HostConnectionMode
+func ForEachHostConnectionMode(m func(value string)) {
+	for _, v := range AllHostConnectionMode {
+		m(v)
+	}
+}
+
 const (
 	// IPv4 or IPv6 as chosen by the system automatically
 	IPVersionOptionAuto = "auto"
@@ -255,6 +471,158 @@ func ForEachIPVersionOption(m func(value string)) {
 	}
 }
 
+const (
+	// One host per row with columns for the name, address, port, groups, and environment variables
+	InventoryFormatCSV = "csv"
+	// An Ansible inventory in INI format
+	InventoryFormatAnsibleINI = "ansible_ini"
+	// An Ansible inventory in YAML format
+	InventoryFormatAnsibleYAML = "ansible_yaml"
+)
+
+// AllInventoryFormat all InventoryFormat values
+var AllInventoryFormat = []string{
+	InventoryFormatCSV,
+	InventoryFormatAnsibleINI,
+	InventoryFormatAnsibleYAML,
+}
+
+// InventoryFormatMap map InventoryFormat keys to values
+var InventoryFormatMap = map[string]string{
+	InventoryFormatCSV:         "csv",
+	InventoryFormatAnsibleINI:  "ansible_ini",
+	InventoryFormatAnsibleYAML: "ansible_yaml",
+}
+
+// IsInventoryFormat is the provided value a valid InventoryFormat
+func IsInventoryFormat(q string) bool {
+	_, k := InventoryFormatMap[q]
+	return k
+}
+
+// ForEachInventoryFormat call m for each InventoryFormat
+func ForEachInventoryFormat(m func(value string)) {
+	for _, v := range AllInventoryFormat {
+		m(v)
+	}
+}
+
+const (
+	// The script has not started yet
+	JobStatusPending = "pending"
+	// The script is running
+	JobStatusRunning = "running"
+	// The script has finished running
+	JobStatusFinished = "finished"
+	// The script could not be run
+	JobStatusFailed = "failed"
+	// The script was canceled
+	JobStatusCanceled = "canceled"
+	// The script was not run because the rollout was stopped
+	JobStatusSkipped = "skipped"
+)
+
+// AllJobStatus all JobStatus values
+var AllJobStatus = []string{
+	JobStatusPending,
+	JobStatusRunning,
+	JobStatusFinished,
+	JobStatusFailed,
+	JobStatusCanceled,
+	JobStatusSkipped,
+}
+
+// JobStatusMap map JobStatus keys to values
+var JobStatusMap = map[string]string{
+	JobStatusPending:  "pending",
+	JobStatusRunning:  "running",
+	JobStatusFinished: "finished",
+	JobStatusFailed:   "failed",
+	JobStatusCanceled: "canceled",
+	JobStatusSkipped:  "skipped",
+}
+
+// IsJobStatus is the provided value a valid JobStatus
+func IsJobStatus(q string) bool {
+	_, k := JobStatusMap[q]
+	return k
+}
+
+// ForEachJobStatus call m for each JobStatus
+func ForEachJobStatus(m func(value string)) {
+	for _, v := range AllJobStatus {
+		m(v)
+	}
+}
+
+const (
+	// Scripts can not run on hosts while the window is active
+	MaintenanceWindowModeBlackout = "blackout"
+	// Scripts can only run on hosts while the window is active
+	MaintenanceWindowModeMaintenance = "maintenance"
+)
+
+// AllMaintenanceWindowMode all MaintenanceWindowMode values
+var AllMaintenanceWindowMode = []string{
+	MaintenanceWindowModeBlackout,
+	MaintenanceWindowModeMaintenance,
+}
+
+// MaintenanceWindowModeMap map MaintenanceWindowMode keys to values
+var MaintenanceWindowModeMap = map[string]string{
+	MaintenanceWindowModeBlackout:    "blackout",
+	MaintenanceWindowModeMaintenance: "maintenance",
+}
+
+// IsMaintenanceWindowMode is the provided value a valid MaintenanceWindowMode
+func IsMaintenanceWindowMode(q string) bool {
+	_, k := MaintenanceWindowModeMap[q]
+	return k
+}
+
+// ForEachMaintenanceWindowMode call m for each MaintenanceWindowMode
+func ForEachMaintenanceWindowMode(m func(value string)) {
+	for _, v := range AllMaintenanceWindowMode {
+		m(v)
+	}
+}
+
+const (
+	// The run is skipped if a previous run is still in progress
+	OverlapPolicySkip = "skip"
+	// The run waits for the previous run to finish, if another run is already waiting the run is skipped
+	OverlapPolicyQueue = "queue"
+	// The run starts even if a previous run is still in progress
+	OverlapPolicyAllow = "allow"
+)
+
+// AllOverlapPolicy all OverlapPolicy values
+var AllOverlapPolicy = []string{
+	OverlapPolicySkip,
+	OverlapPolicyQueue,
+	OverlapPolicyAllow,
+}
+
+// OverlapPolicyMap map OverlapPolicy keys to values
+var OverlapPolicyMap = map[string]string{
+	OverlapPolicySkip:  "skip",
+	OverlapPolicyQueue: "queue",
+	OverlapPolicyAllow: "allow",
+}
+
+// IsOverlapPolicy is the provided value a valid OverlapPolicy
+func IsOverlapPolicy(q string) bool {
+	_, k := OverlapPolicyMap[q]
+	return k
+}
+
+// ForEachOverlapPolicy call m for each OverlapPolicy
+func ForEachOverlapPolicy(m func(value string)) {
+	for _, v := range AllOverlapPolicy {
+		m(v)
+	}
+}
+
 const (
 	// Hostname
 	RegisterRulePropertyHostname = "hostname"
@@ -335,6 +703,78 @@ func ForEachRequestResponseCode(m func(value int)) {
 	}
 }
 
+const (
+	// The server could not connect to the host or the connection was lost
+	RetryFailureConnection = "connection"
+	// The agent could not run the script
+	RetryFailureExec = "exec"
+	// The script ran but did not meet its success criteria, such as a non-zero exit code
+	RetryFailureExitCode = "exit_code"
+)
+
+// AllRetryFailure all RetryFailure values
+var AllRetryFailure = []string{
+	RetryFailureConnection,
+	RetryFailureExec,
+	RetryFailureExitCode,
+}
+
+// RetryFailureMap map RetryFailure keys to values
+var RetryFailureMap = map[string]string{
+	RetryFailureConnection: "connection",
+	RetryFailureExec:       "exec",
+	RetryFailureExitCode:   "exit_code",
+}
+
+// IsRetryFailure is the provided value a valid RetryFailure
+func IsRetryFailure(q string) bool {
+	_, k := RetryFailureMap[q]
+	return k
+}
+
+// ForEachRetryFailure call m for each RetryFailure
+func ForEachRetryFailure(m func(value string)) {
+	for _, v := range AllRetryFailure {
+		m(v)
+	}
+}
+
+const (
+	// Continue the rollout regardless of failures
+	RolloutAbortPolicyContinue = "continue"
+	// Stop the rollout once the current batch has finished
+	RolloutAbortPolicyStop = "stop"
+	// Stop the rollout and cancel the script on any hosts still running
+	RolloutAbortPolicyCancel = "cancel"
+)
+
+// AllRolloutAbortPolicy all RolloutAbortPolicy values
+var AllRolloutAbortPolicy = []string{
+	RolloutAbortPolicyContinue,
+	RolloutAbortPolicyStop,
+	RolloutAbortPolicyCancel,
+}
+
+// RolloutAbortPolicyMap map RolloutAbortPolicy keys to values
+var RolloutAbortPolicyMap = map[string]string{
+	RolloutAbortPolicyContinue: "continue",
+	RolloutAbortPolicyStop:     "stop",
+	RolloutAbortPolicyCancel:   "cancel",
+}
+
+// IsRolloutAbortPolicy is the provided value a valid RolloutAbortPolicy
+func IsRolloutAbortPolicy(q string) bool {
+	_, k := RolloutAbortPolicyMap[q]
+	return k
+}
+
+// ForEachRolloutAbortPolicy call m for each RolloutAbortPolicy
+func ForEachRolloutAbortPolicy(m func(value string)) {
+	for _, v := range AllRolloutAbortPolicy {
+		m(v)
+	}
+}
+
 const (
 	// All hosts executed the script successfully
 	ScheduleResultSuccess = 0
@@ -342,6 +782,8 @@ const (
 	ScheduleResultPartialSuccess = 1
 	// No hosts executed the script successfully
 	ScheduleResultFail = 2
+	// No hosts executed the script because of maintenance windows or runs already in progress
+	ScheduleResultSkipped = 3
 )
 
 // AllScheduleResult all ScheduleResult values
@@ -349,6 +791,7 @@ var AllScheduleResult = []int{
 	ScheduleResultSuccess,
 	ScheduleResultPartialSuccess,
 	ScheduleResultFail,
+	ScheduleResultSkipped,
 }
 
 // ScheduleResultMap map ScheduleResult keys to values
@@ -356,6 +799,7 @@ var ScheduleResultMap = map[int]int{
 	ScheduleResultSuccess:        0,
 	ScheduleResultPartialSuccess: 1,
 	ScheduleResultFail:           2,
+	ScheduleResultSkipped:        3,
 }
 
 // IsScheduleResult is the provided value a valid ScheduleResult
@@ -371,6 +815,86 @@ func ForEachScheduleResult(m func(value int)) {
 	}
 }
 
+const (
+	// The schedule runs whenever its cron pattern matches
+	ScheduleTriggerPattern = "pattern"
+	// The schedule runs once at a set time and then disables itself
+	ScheduleTriggerOnce = "once"
+	// The schedule runs whenever an event is recorded
+	ScheduleTriggerEvent = "event"
+)
+
+// AllScheduleTrigger all ScheduleTrigger values
+var AllScheduleTrigger = []string{
+	ScheduleTriggerPattern,
+	ScheduleTriggerOnce,
+	ScheduleTriggerEvent,
+}
+
+// ScheduleTriggerMap map ScheduleTrigger keys to values
+var ScheduleTriggerMap = map[string]string{
+	ScheduleTriggerPattern: "pattern",
+	ScheduleTriggerOnce:    "once",
+	ScheduleTriggerEvent:   "event",
+}
+
+// IsScheduleTrigger is the provided value a valid ScheduleTrigger
+func IsScheduleTrigger(q string) bool {
+	_, k := ScheduleTriggerMap[q]
+	return k
+}
+
+// ForEachScheduleTrigger call m for each ScheduleTrigger
+func ForEachScheduleTrigger(m func(value string)) {
+	for _, v := range AllScheduleTrigger {
+		m(v)
+	}
+}
+
+const (
+	// Any text
+	ScriptParameterTypeString = "string"
+	// A whole number
+	ScriptParameterTypeInteger = "integer"
+	// Either true or false
+	ScriptParameterTypeBoolean = "boolean"
+	// One of a list of options
+	ScriptParameterTypeEnum = "enum"
+	// Any text that is never displayed or saved in the run history
+	ScriptParameterTypeSecret = "secret"
+)
+
+// AllScriptParameterType all ScriptParameterType values
+var AllScriptParameterType = []string{
+	ScriptParameterTypeString,
+	ScriptParameterTypeInteger,
+	ScriptParameterTypeBoolean,
+	ScriptParameterTypeEnum,
+	ScriptParameterTypeSecret,
+}
+
+// ScriptParameterTypeMap map ScriptParameterType keys to values
+var ScriptParameterTypeMap = map[string]string{
+	ScriptParameterTypeString:  "string",
+	ScriptParameterTypeInteger: "integer",
+	ScriptParameterTypeBoolean: "boolean",
+	ScriptParameterTypeEnum:    "enum",
+	ScriptParameterTypeSecret:  "secret",
+}
+
+// IsScriptParameterType is the provided value a valid ScriptParameterType
+func IsScriptParameterType(q string) bool {
+	_, k := ScriptParameterTypeMap[q]
+	return k
+}
+
+// ForEachScriptParameterType call m for each ScriptParameterType
+func ForEachScriptParameterType(m func(value string)) {
+	for _, v := range AllScriptParameterType {
+		m(v)
+	}
+}
+
 // Permission level for users to run scripts
 const (
 	// No scripts can be executed
@@ -407,3 +931,75 @@ func ForEachScriptRunLevel(m func(value int)) {
 		m(v)
 	}
 }
+
+const (
+	// The script met its success criteria
+	ScriptStatusSuccess = "success"
+	// The script met its success criteria and reported that it made changes
+	ScriptStatusChanged = "changed"
+	// The script could not be run or did not meet its success criteria
+	ScriptStatusFailed = "failed"
+	// The script was not run because of a maintenance window
+	ScriptStatusSkipped = "skipped"
+)
+
+// AllScriptStatus all ScriptStatus values
+var AllScriptStatus = []string{
+	ScriptStatusSuccess,
+	ScriptStatusChanged,
+	ScriptStatusFailed,
+	ScriptStatusSkipped,
+}
+
+// ScriptStatusMap map ScriptStatus keys to values
+var ScriptStatusMap = map[string]string{
+	ScriptStatusSuccess: "success",
+	ScriptStatusChanged: "changed",
+	ScriptStatusFailed:  "failed",
+	ScriptStatusSkipped: "skipped",
+}
+
+// IsScriptStatus is the provided value a valid ScriptStatus
+func IsScriptStatus(q string) bool {
+	_, k := ScriptStatusMap[q]
+	return k
+}
+
+// ForEachScriptStatus call m for each ScriptStatus
+func ForEachScriptStatus(m func(value string)) {
+	for _, v := range AllScriptStatus {
+		m(v)
+	}
+}
+
+const (
+	// Pass the output of the step to the next step
+	WorkflowOutputStdout = "stdout"
+	// Pass the declared outputs of the step to the next step
+	WorkflowOutputDeclared = "declared"
+)
+
+// AllWorkflowOutput all WorkflowOutput values
+var AllWorkflowOutput = []string{
+	WorkflowOutputStdout,
+	WorkflowOutputDeclared,
+}
+
+// WorkflowOutputMap map WorkflowOutput keys to values
+var WorkflowOutputMap = map[string]string{
+	WorkflowOutputStdout:   "stdout",
+	WorkflowOutputDeclared: "declared",
+}
+
+// IsWorkflowOutput is the provided value a valid WorkflowOutput
+func IsWorkflowOutput(q string) bool {
+	_, k := WorkflowOutputMap[q]
+	return k
+}
+
+// ForEachWorkflowOutput call m for each WorkflowOutput
+func ForEachWorkflowOutput(m func(value string)) {
+	for _, v := range AllWorkflowOutput {
+		m(v)
+	}
+}
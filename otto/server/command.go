@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// Command describes an ad-hoc command that is run on hosts without creating a script
+type Command struct {
+	Executable       string
+	Script           string
+	Environment      []environ.Variable
+	RunAs            RunAs
+	WorkingDirectory string
+}
+
+// Validate returns an error if the command is not valid
+func (c Command) Validate() error {
+	if c.Executable == "" {
+		return fmt.Errorf("an executable is required")
+	}
+	if c.Script == "" {
+		return fmt.Errorf("a command is required")
+	}
+	return environ.Validate(c.Environment)
+}
+
+// script returns a script that can be used to run this command. Each command gets a unique name so that it can be
+// cancelled on the host without affecting other commands.
+func (c Command) script() *Script {
+	return &Script{
+		Name:             "command_" + newPlainID(),
+		Executable:       c.Executable,
+		Script:           c.Script,
+		Environment:      c.Environment,
+		RunAs:            c.RunAs,
+		WorkingDirectory: c.WorkingDirectory,
+	}
+}
+
+// run will run the command on the host and record it in the run history and event log. outputCb is optional.
+func (c Command) run(host *Host, triggeredBy string, outputCb func(stdout, stderr []byte)) (*ScriptResult, error) {
+	script := c.script()
+
+	log.PInfo("Running ad-hoc command", map[string]interface{}{
+		"host_id":      host.ID,
+		"executable":   c.Executable,
+		"triggered_by": triggeredBy,
+	})
+
+	start := time.Now()
+	result, err := host.RunScript(script, outputCb)
+	ScriptRunStore.NewRun(newScriptRunParameters{
+		Script:      script,
+		Command:     &c,
+		Host:        host,
+		TriggeredBy: triggeredBy,
+		Start:       start,
+		Result:      result,
+		Error:       err,
+	})
+	if err != nil {
+		EventStore.CommandRun(c, host, nil, triggeredBy)
+		return nil, err
+	}
+	EventStore.CommandRun(c, host, &result.Result, triggeredBy)
+	return result, nil
+}
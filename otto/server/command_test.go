@@ -0,0 +1,117 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ecnepsnai/web"
+)
+
+func TestCommandValidate(t *testing.T) {
+	if err := (Command{Executable: "/bin/sh", Script: "uptime"}).Validate(); err != nil {
+		t.Errorf("Unexpected error validating command: %s", err.Error())
+	}
+	if err := (Command{Script: "uptime"}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for missing executable")
+	}
+	if err := (Command{Executable: "/bin/sh"}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for missing command")
+	}
+}
+
+func TestResolveHostsQuery(t *testing.T) {
+	prefix := randomString(6)
+	for i := 0; i < 3; i++ {
+		if _, err := HostStore.NewHost(newHostParameters{
+			Name:    prefix + "-" + randomString(4),
+			Address: randLocalhostIP(),
+			Port:    1,
+		}); err != nil {
+			t.Fatalf("Error making host: %s", err.Message)
+		}
+	}
+
+	hosts, err := resolveHosts(ScheduleScope{}, prefix+"-*")
+	if err != nil {
+		t.Fatalf("Unexpected error resolving hosts: %s", err.Message)
+	}
+	if len(hosts) != 3 {
+		t.Errorf("Unexpected number of hosts: %d, expected 3", len(hosts))
+	}
+
+	if _, err := resolveHosts(ScheduleScope{}, randomString(12)); err == nil {
+		t.Errorf("No error seen when one expected for query matching no hosts")
+	}
+	if _, err := resolveHosts(ScheduleScope{}, "["); err == nil {
+		t.Errorf("No error seen when one expected for invalid query")
+	}
+}
+
+func TestCommandPermission(t *testing.T) {
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	user, err := UserStore.NewUser(newUserParameters{
+		Username: randomString(6),
+		Password: randomString(6),
+		Permissions: UserPermissions{
+			ScriptRunLevel: ScriptRunLevelReadWrite,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making user: %s", err.Message)
+	}
+
+	session := SessionStore.NewSessionForUser(user)
+	h := handle{}
+	params := commandParams{
+		Command: Command{
+			Executable: "/bin/sh",
+			Script:     "uptime",
+		},
+		ScheduleScope: ScheduleScope{HostIDs: []string{host.ID}},
+	}
+
+	// The script run level does not allow running commands
+	_, _, werr := h.CommandNew(web.MockRequest(web.MockRequestParameters{UserData: &session, JSONBody: params}))
+	if werr == nil {
+		t.Fatalf("No error seen when one expected")
+	}
+
+	if _, err := UserStore.EditUser(user, editUserParameters{
+		Permissions: UserPermissions{
+			CanRunCommands: true,
+		},
+	}); err != nil {
+		t.Fatalf("Error editing user: %s", err.Message)
+	}
+
+	data, _, werr := h.CommandNew(web.MockRequest(web.MockRequestParameters{UserData: &session, JSONBody: params}))
+	if werr != nil {
+		t.Fatalf("Unexpected error: %s", werr.Message)
+	}
+	results := data.([]commandHostResult)
+	if len(results) != 1 || results[0].HostID != host.ID {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	// The host is not reachable
+	if results[0].Error == "" {
+		t.Errorf("No error seen when one expected for unreachable host")
+	}
+
+	runs := ScriptRunStore.RunsForHost(host.ID)
+	if len(runs) != 1 {
+		t.Fatalf("Unexpected number of runs: %d, expected 1", len(runs))
+	}
+	if runs[0].Command == nil || runs[0].Command.Script != "uptime" {
+		t.Errorf("Command not saved with run")
+	}
+	if runs[0].TriggeredBy != user.Username {
+		t.Errorf("Unexpected triggered by: %s", runs[0].TriggeredBy)
+	}
+}
@@ -49,11 +49,33 @@ func CronSetup() {
 				AttachmentStore.Cleanup()
 			},
 		},
+		{
+			Pattern: "0 * * * *",
+			Name:    "CleanupScriptRuns",
+			Exec: func() {
+				ScriptRunStore.Cleanup()
+			},
+		},
+		{
+			Pattern: "0 * * * *",
+			Name:    "CleanupJobs",
+			Exec: func() {
+				jobStore.Cleanup()
+			},
+		},
+		{
+			Pattern: "* * * * *",
+			Name:    "ExpireApprovals",
+			Exec: func() {
+				ApprovalStore.ExpireRequests()
+			},
+		},
 	})
 	if err != nil {
 		log.Fatal("Error starting up scheduled tasks: %s", err.Error())
 	}
 	if !cronDisabled {
+		go ScheduleStore.CatchUpSchedules()
 		go schedule.Start()
 	}
 }
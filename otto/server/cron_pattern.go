@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronPattern describes a parsed cron pattern of minute, hour, day of month, month and day of week
+type cronPattern struct {
+	fields [5]map[int]bool
+}
+
+var cronPatternRanges = [5][2]int{
+	{0, 59}, // Minute
+	{0, 23}, // Hour
+	{1, 31}, // Day of month
+	{1, 12}, // Month
+	{0, 7},  // Day of week, 0 and 7 are both Sunday
+}
+
+// parseCronPattern parses a five field cron pattern. Each field may be '*', a number, a range 'a-b', a step '*/n' or
+// 'a-b/n', or a comma separated list of those.
+func parseCronPattern(pattern string) (*cronPattern, error) {
+	parts := strings.Fields(pattern)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("pattern must have 5 fields")
+	}
+
+	p := &cronPattern{}
+	for i, part := range parts {
+		values, err := parseCronField(part, cronPatternRanges[i][0], cronPatternRanges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid field '%s': %s", part, err.Error())
+		}
+		p.fields[i] = values
+	}
+	if p.fields[4][7] {
+		p.fields[4][0] = true
+	}
+	return p, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			s, err := strconv.Atoi(item[idx+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid step")
+			}
+			step = s
+			item = item[:idx]
+		}
+
+		low, high := min, max
+		if item != "*" {
+			if idx := strings.Index(item, "-"); idx >= 0 {
+				l, err := strconv.Atoi(item[:idx])
+				if err != nil {
+					return nil, fmt.Errorf("invalid range")
+				}
+				h, err := strconv.Atoi(item[idx+1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid range")
+				}
+				low, high = l, h
+			} else {
+				v, err := strconv.Atoi(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value")
+				}
+				low, high = v, v
+				if step > 1 {
+					high = max
+				}
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("value out of range %d-%d", min, max)
+		}
+
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// Matches returns true if the pattern matches the minute of the given time
+func (p *cronPattern) Matches(t time.Time) bool {
+	return p.fields[0][t.Minute()] &&
+		p.fields[1][t.Hour()] &&
+		p.fields[2][t.Day()] &&
+		p.fields[3][int(t.Month())] &&
+		p.fields[4][int(t.Weekday())]
+}
+
+// maxCronSearch how far after a time Next will look for a match
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first minute after t that matches the pattern, in the location of t. Returns the zero time if
+// nothing matches within five years, such as for a pattern of the 31st of February.
+func (p *cronPattern) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(maxCronSearch)
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	// skipTo moves to the start of the next month, day or hour. Times that don't exist because of a daylight saving
+	// change may be normalized to before the current time, in which case it moves one minute instead.
+	skipTo := func(t time.Time) time.Time {
+		if t.After(next) {
+			return t
+		}
+		return next.Add(time.Minute)
+	}
+	for next.Before(limit) {
+		// Skip ahead by the largest field that doesn't match
+		if !p.fields[3][int(next.Month())] {
+			next = skipTo(time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !p.fields[2][next.Day()] || !p.fields[4][int(next.Weekday())] {
+			next = skipTo(time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !p.fields[1][next.Hour()] {
+			next = skipTo(time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if !p.fields[0][next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
@@ -64,6 +64,7 @@ var ReservedKeys = []string{
 	"OTTO_HOST_ADDRESS",
 	"OTTO_HOST_PORT",
 	"OTTO_HOST_PSK",
+	"OTTO_WORKFLOW_STDOUT",
 }
 
 // Validate will return if any of the variables are invalid
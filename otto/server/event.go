@@ -39,6 +39,10 @@ func (e Event) Save() {
 		}
 		eventLog.PInfo(e.Event, details)
 	}
+
+	if err == nil && sliceContains(e.Event, scheduleTriggerEvents) {
+		ScheduleStore.RunEventSchedules(e)
+	}
 }
 
 func newEvent(eventType string, details map[string]string) Event {
@@ -222,6 +226,32 @@ func (s *eventStoreObject) HostIdentityRotated(host *Host, hostID, serverID, cur
 	event.Save()
 }
 
+func (s *eventStoreObject) HostFileDownloaded(host *Host, fileInfo *otto.FileInfo, attachmentID, currentUser string) {
+	event := newEvent(EventTypeHostFileDownloaded, map[string]string{
+		"host_id":       host.ID,
+		"name":          host.Name,
+		"file_path":     fileInfo.Path,
+		"checksum":      fileInfo.Checksum,
+		"size":          fmt.Sprintf("%d", fileInfo.Length),
+		"attachment_id": attachmentID,
+		"downloaded_by": currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) HostFileDownloadFailed(host *Host, filePath string, err error, currentUser string) {
+	event := newEvent(EventTypeHostFileDownloadFailed, map[string]string{
+		"host_id":       host.ID,
+		"name":          host.Name,
+		"file_path":     filePath,
+		"error":         err.Error(),
+		"downloaded_by": currentUser,
+	})
+
+	event.Save()
+}
+
 func (s *eventStoreObject) HostBecameReachable(host *Host) {
 	event := newEvent(EventTypeHostBecameReachable, map[string]string{
 		"host_id": host.ID,
@@ -276,6 +306,7 @@ func (s *eventStoreObject) ScheduleAdded(schedule *Schedule, currentUser string)
 		"schedule_id": schedule.ID,
 		"name":        schedule.Name,
 		"script_id":   schedule.ScriptID,
+		"workflow_id": schedule.WorkflowID,
 		"pattern":     schedule.Pattern,
 		"added_by":    currentUser,
 	})
@@ -288,6 +319,7 @@ func (s *eventStoreObject) ScheduleModified(schedule *Schedule, currentUser stri
 		"schedule_id": schedule.ID,
 		"name":        schedule.Name,
 		"script_id":   schedule.ScriptID,
+		"workflow_id": schedule.WorkflowID,
 		"pattern":     schedule.Pattern,
 		"modified_by": currentUser,
 	})
@@ -300,6 +332,7 @@ func (s *eventStoreObject) ScheduleDeleted(schedule *Schedule, currentUser strin
 		"schedule_id": schedule.ID,
 		"name":        schedule.Name,
 		"script_id":   schedule.ScriptID,
+		"workflow_id": schedule.WorkflowID,
 		"pattern":     schedule.Pattern,
 		"deleted_by":  currentUser,
 	})
@@ -307,6 +340,143 @@ func (s *eventStoreObject) ScheduleDeleted(schedule *Schedule, currentUser strin
 	event.Save()
 }
 
+func (s *eventStoreObject) WorkflowAdded(workflow *Workflow, currentUser string) {
+	event := newEvent(EventTypeWorkflowAdded, map[string]string{
+		"workflow_id": workflow.ID,
+		"name":        workflow.Name,
+		"steps":       fmt.Sprintf("%d", len(workflow.Steps)),
+		"added_by":    currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) WorkflowModified(workflow *Workflow, currentUser string) {
+	event := newEvent(EventTypeWorkflowModified, map[string]string{
+		"workflow_id": workflow.ID,
+		"name":        workflow.Name,
+		"steps":       fmt.Sprintf("%d", len(workflow.Steps)),
+		"modified_by": currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) WorkflowDeleted(workflow *Workflow, currentUser string) {
+	event := newEvent(EventTypeWorkflowDeleted, map[string]string{
+		"workflow_id": workflow.ID,
+		"name":        workflow.Name,
+		"deleted_by":  currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) WorkflowRun(workflow Workflow, report WorkflowReport, currentUser string) {
+	event := newEvent(EventTypeWorkflowRun, map[string]string{
+		"workflow_id": workflow.ID,
+		"report_id":   report.ID,
+		"result":      fmt.Sprintf("%d", report.Result),
+	})
+	if report.ScheduleID != "" {
+		event.Details["schedule_id"] = report.ScheduleID
+	} else {
+		event.Details["triggered_by"] = currentUser
+	}
+
+	event.Save()
+}
+
+func (s *eventStoreObject) MaintenanceWindowAdded(window *MaintenanceWindow, currentUser string) {
+	event := newEvent(EventTypeMaintenanceWindowAdded, map[string]string{
+		"window_id": window.ID,
+		"name":      window.Name,
+		"mode":      window.Mode,
+		"added_by":  currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) MaintenanceWindowModified(window *MaintenanceWindow, currentUser string) {
+	event := newEvent(EventTypeMaintenanceWindowModified, map[string]string{
+		"window_id":   window.ID,
+		"name":        window.Name,
+		"mode":        window.Mode,
+		"modified_by": currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) MaintenanceWindowDeleted(window *MaintenanceWindow, currentUser string) {
+	event := newEvent(EventTypeMaintenanceWindowDeleted, map[string]string{
+		"window_id":  window.ID,
+		"name":       window.Name,
+		"deleted_by": currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) MaintenanceWindowOverridden(window *MaintenanceWindow, host *Host, action string, currentUser string) {
+	event := newEvent(EventTypeMaintenanceWindowOverridden, map[string]string{
+		"window_id":     window.ID,
+		"name":          window.Name,
+		"host_id":       host.ID,
+		"action":        action,
+		"overridden_by": currentUser,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) ApprovalRequested(request ApprovalRequest, script *Script) {
+	event := newEvent(EventTypeApprovalRequested, map[string]string{
+		"request_id":   request.ID,
+		"script_id":    script.ID,
+		"script_name":  script.Name,
+		"reason":       request.Reason,
+		"requested_by": request.RequestedBy,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) ApprovalApproved(request ApprovalRequest) {
+	event := newEvent(EventTypeApprovalApproved, map[string]string{
+		"request_id":   request.ID,
+		"script_id":    request.ScriptID,
+		"job_id":       request.JobID,
+		"requested_by": request.RequestedBy,
+		"approved_by":  request.ReviewedBy,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) ApprovalRejected(request ApprovalRequest) {
+	event := newEvent(EventTypeApprovalRejected, map[string]string{
+		"request_id":   request.ID,
+		"script_id":    request.ScriptID,
+		"comment":      request.Comment,
+		"requested_by": request.RequestedBy,
+		"rejected_by":  request.ReviewedBy,
+	})
+
+	event.Save()
+}
+
+func (s *eventStoreObject) ApprovalExpired(request ApprovalRequest) {
+	event := newEvent(EventTypeApprovalExpired, map[string]string{
+		"request_id":   request.ID,
+		"script_id":    request.ScriptID,
+		"requested_by": request.RequestedBy,
+	})
+
+	event.Save()
+}
+
 func (s *eventStoreObject) AttachmentAdded(attachment *Attachment, currentUser string) {
 	event := newEvent(EventTypeAttachmentAdded, map[string]string{
 		"attachment_id": attachment.ID,
@@ -344,6 +514,7 @@ func (s *eventStoreObject) ScriptAdded(script *Script, currentUser string) {
 	event := newEvent(EventTypeScriptAdded, map[string]string{
 		"script_id": script.ID,
 		"name":      script.Name,
+		"revision":  fmt.Sprintf("%d", script.Revision),
 		"added_by":  currentUser,
 	})
 
@@ -354,6 +525,7 @@ func (s *eventStoreObject) ScriptModified(script *Script, currentUser string) {
 	event := newEvent(EventTypeScriptModified, map[string]string{
 		"script_id":   script.ID,
 		"name":        script.Name,
+		"revision":    fmt.Sprintf("%d", script.Revision),
 		"modified_by": currentUser,
 	})
 
@@ -370,12 +542,17 @@ func (s *eventStoreObject) ScriptDeleted(script *Script, currentUser string) {
 	event.Save()
 }
 
-func (s *eventStoreObject) ScriptRun(script *Script, host *Host, result *otto.ScriptResult, schedule *Schedule, currentUser string) {
+func (s *eventStoreObject) ScriptRun(script *Script, host *Host, result *ScriptResult, schedule *Schedule, currentUser string) {
 	event := newEvent(EventTypeScriptRun, map[string]string{
 		"script_id": script.ID,
+		"revision":  fmt.Sprintf("%d", script.Revision),
 		"host_id":   host.ID,
-		"exit_code": fmt.Sprintf("%d", result.Code),
+		"exit_code": fmt.Sprintf("%d", result.Result.Code),
+		"status":    result.Status,
 	})
+	if result.FailureReason != "" {
+		event.Details["failure_reason"] = result.FailureReason
+	}
 	if schedule != nil {
 		event.Details["schedule_id"] = schedule.ID
 	} else {
@@ -385,6 +562,20 @@ func (s *eventStoreObject) ScriptRun(script *Script, host *Host, result *otto.Sc
 	event.Save()
 }
 
+func (s *eventStoreObject) CommandRun(command Command, host *Host, result *otto.ScriptResult, currentUser string) {
+	event := newEvent(EventTypeCommandRun, map[string]string{
+		"host_id":      host.ID,
+		"executable":   command.Executable,
+		"command":      command.Script,
+		"triggered_by": currentUser,
+	})
+	if result != nil {
+		event.Details["exit_code"] = fmt.Sprintf("%d", result.Code)
+	}
+
+	event.Save()
+}
+
 func (s *eventStoreObject) ServerStarted(args []string) {
 	a := strings.Join(args, " ")
 	event := newEvent(EventTypeServerStarted, map[string]string{
@@ -23,6 +23,15 @@ type ScriptResult struct {
 	Result      otto.ScriptResult
 	Output      ScriptOutput
 	RunError    string
+	// Status the status of the result, according to the success criteria of the script
+	Status string
+	// FailureReason why the script did not meet its success criteria, if it did not
+	FailureReason string
+}
+
+// Succeeded returns true if the script was run and met its success criteria
+func (r ScriptResult) Succeeded() bool {
+	return r.RunError == "" && r.Status != ScriptStatusFailed
 }
 
 // ScriptOutput described script output
@@ -34,12 +43,34 @@ type ScriptOutput struct {
 type hostConnection struct {
 	Host     *Host
 	Address  string
-	Identity *otto.Identity
+	ActionID string
 	Conn     *otto.Connection
+	client   *otto.Client
 }
 
-// connect will open a connection to the Otto agent on the host
+// connect will open a connection for a new action to the Otto agent on the host. If the agent supports multiplexing an
+// existing session with the host is reused.
 func (host *Host) connect() (*hostConnection, error) {
+	actionID := newPlainID()
+
+	client, connection, err := hostClients.Open(host, actionID)
+	if err != nil {
+		heartbeatStore.UpdateHostReachability(host, false)
+		log.Error("Error connecting to host '%s': %s", host.ID, err.Error())
+		return nil, err
+	}
+
+	return &hostConnection{
+		Host:     host,
+		Address:  fmt.Sprintf("%s:%d", host.Address, host.Port),
+		ActionID: actionID,
+		Conn:     connection,
+		client:   client,
+	}, nil
+}
+
+// dial will establish a new session with the Otto agent on the host
+func (host *Host) dial() (*otto.Client, error) {
 	address := fmt.Sprintf("%s:%d", host.Address, host.Port)
 	log.Debug("Connecting to host %s", address)
 
@@ -64,7 +95,7 @@ func (host *Host) connect() (*hostConnection, error) {
 		return nil, fmt.Errorf("no identity")
 	}
 
-	connection, err := otto.Dial(otto.DialOptions{
+	client, err := otto.DialClient(otto.DialOptions{
 		Network:          network,
 		Address:          address,
 		Identity:         id.Signer(),
@@ -93,20 +124,29 @@ func (host *Host) connect() (*hostConnection, error) {
 		log.Error("Error connecting to host '%s': %s", address, err.Error())
 		return nil, err
 	}
-	return &hostConnection{
-		Host:     host,
-		Address:  fmt.Sprintf("%s:%d", host.Address, host.Port),
-		Identity: id,
-		Conn:     connection,
-	}, nil
+	return client, nil
 }
 
 // Close will close the connection to the Otto host
 func (hc *hostConnection) Close() {
 	hc.Conn.Close()
+	hostClients.Release(hc.Host.ID, hc.client)
 }
 
-func (conn *hostConnection) UploadFile(attachment Attachment) error {
+// UploadFile will upload the attachment to the host. If the attachment is a template then rendered must be the
+// rendered file, which is uploaded instead of the stored attachment.
+func (conn *hostConnection) UploadFile(attachment Attachment, rendered *renderedFile) error {
+	if rendered != nil {
+		if err := conn.Conn.TriggerActionUploadFile(rendered.Info, io.NopCloser(bytes.NewReader(rendered.Data))); err != nil {
+			log.PError("Error uploading file", map[string]interface{}{
+				"attachment_id": attachment.ID,
+				"error":         err.Error(),
+			})
+			return err
+		}
+		return nil
+	}
+
 	fileInfo, err := attachment.FileInfo()
 	if err != nil {
 		log.PError("Error uploading file", map[string]interface{}{
@@ -173,7 +213,7 @@ func (host *Host) Ping() error {
 		heartbeatStore.UpdateHostReachability(host, false)
 		return fmt.Errorf("invalid nonce")
 	}
-	heartbeatStore.RegisterHeartbeatReply(host, *reply)
+	heartbeatStore.RegisterHeartbeatReply(host, *reply, conn.Conn.Capabilities())
 
 	return nil
 }
@@ -187,16 +227,45 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 		"script_id": script.ID,
 	})
 
+	lock, err := lockManager.Acquire(hostLock(host, script), script.overlapPolicy)
+	if err != nil {
+		log.PWarn("Not running script on host", map[string]interface{}{
+			"host_id":   host.ID,
+			"script_id": script.ID,
+			"error":     err.Error(),
+		})
+		return nil, err
+	}
+	defer lockManager.Release(lock)
+
 	scriptRequest := script.ScriptInfo()
 
 	variables := host.environmentVariablesForScript(script)
 	scriptRequest.Environment = environ.Map(variables)
 	log.Debug("Environ: %s", scriptRequest.Environment)
 
-	attachments, aerr := script.Attachments()
-	if aerr != nil {
-		return nil, aerr.Error
+	rendered, rerr := host.renderScript(script, variables)
+	if rerr != nil {
+		if rerr.Server {
+			return nil, rerr.Error
+		}
+		log.PError("Error rendering script template", map[string]interface{}{
+			"host_id":   host.ID,
+			"script_id": script.ID,
+			"error":     rerr.Message,
+		})
+		return &ScriptResult{
+			ScriptID:    script.ID,
+			Duration:    time.Since(start),
+			Environment: variables,
+			Result: otto.ScriptResult{
+				Success: false,
+			},
+			RunError: rerr.Message,
+			Status:   ScriptStatusFailed,
+		}, nil
 	}
+	scriptRequest.Length = uint64(len(rendered.Script))
 
 	conn, err := host.connect()
 	if err != nil {
@@ -208,9 +277,10 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 		heartbeatStore.UpdateHostReachability(host, false)
 		return nil, err
 	}
+	defer conn.Close()
 
 	// Pre-execution files
-	for _, attachment := range attachments {
+	for _, attachment := range rendered.Attachments {
 		if attachment.AfterScript {
 			continue
 		}
@@ -220,7 +290,7 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 			"attachment_id": attachment.ID,
 			"host_id":       host.ID,
 		})
-		if err := conn.UploadFile(attachment); err != nil {
+		if err := conn.UploadFile(attachment, rendered.file(attachment.ID)); err != nil {
 			log.PError("Error running script on host", map[string]interface{}{
 				"script_id": script.ID,
 				"host_id":   host.ID,
@@ -235,7 +305,7 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 		"script_id": script.ID,
 		"host_id":   host.ID,
 	})
-	result, output, err := conn.RunScript(scriptRequest, []byte(script.Script), scriptOutput)
+	result, output, err := conn.RunScript(scriptRequest, rendered.Script, scriptOutput)
 	if result == nil && output == nil && err == nil {
 		err = fmt.Errorf("unexpected end of connection")
 	}
@@ -253,25 +323,53 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 				Success: false,
 			},
 			RunError: err.Error(),
+			Status:   ScriptStatusFailed,
 		}, nil
 	}
 
-	if !result.ScriptResult.Success {
+	if result.ScriptResult.ExecError != "" {
 		log.PError("Error running script on host", map[string]interface{}{
 			"host_id":   host.ID,
 			"script_id": script.ID,
 			"error":     result.ScriptResult.ExecError,
 		})
+		failureReason := result.ScriptResult.ExecError
+		if result.ScriptResult.TimedOut {
+			failureReason = fmt.Sprintf("timed out after %s", result.ScriptResult.Elapsed.Round(time.Second))
+		}
 		return &ScriptResult{
-			ScriptID:    script.ID,
-			Duration:    time.Since(start),
-			Environment: variables,
-			Result:      result.ScriptResult,
+			ScriptID:      script.ID,
+			Duration:      time.Since(start),
+			Environment:   variables,
+			Result:        result.ScriptResult,
+			Status:        ScriptStatusFailed,
+			FailureReason: failureReason,
 		}, nil
 	}
 
+	scriptResult := &ScriptResult{
+		ScriptID:    script.ID,
+		Duration:    time.Since(start),
+		Environment: variables,
+		Result:      result.ScriptResult,
+		Output: ScriptOutput{
+			Stdout: output.Stdout(),
+			Stderr: output.Stderr(),
+		},
+	}
+	scriptResult.Status, scriptResult.FailureReason = script.SuccessCriteria.Evaluate(result.ScriptResult.Code, scriptResult.Output, result.ScriptResult.Elapsed)
+	if scriptResult.Status == ScriptStatusFailed {
+		log.PError("Script did not meet success criteria", map[string]interface{}{
+			"host_id":   host.ID,
+			"script_id": script.ID,
+			"exit_code": result.ScriptResult.Code,
+			"reason":    scriptResult.FailureReason,
+		})
+		return scriptResult, nil
+	}
+
 	// Post-execution files
-	for _, attachment := range attachments {
+	for _, attachment := range rendered.Attachments {
 		if !attachment.AfterScript {
 			continue
 		}
@@ -281,7 +379,7 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 			"attachment_id": attachment.ID,
 			"host_id":       host.ID,
 		})
-		if err := conn.UploadFile(attachment); err != nil {
+		if err := conn.UploadFile(attachment, rendered.file(attachment.ID)); err != nil {
 			log.PError("Error running script on host", map[string]interface{}{
 				"script_id": script.ID,
 				"host_id":   host.ID,
@@ -319,6 +417,7 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 				Success: false,
 			},
 			RunError: fmt.Sprintf("unknown post-execution action %s", script.AfterExecution),
+			Status:   ScriptStatusFailed,
 		}, nil
 	}
 	if err != nil {
@@ -335,6 +434,7 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 				Success: false,
 			},
 			RunError: err.Error(),
+			Status:   ScriptStatusFailed,
 		}, nil
 	}
 
@@ -342,17 +442,10 @@ func (host *Host) RunScript(script *Script, scriptOutput func(stdout, stderr []b
 		"host_id":   host.ID,
 		"script_id": script.ID,
 		"elapsed":   time.Since(start).String(),
+		"status":    scriptResult.Status,
 	})
-	return &ScriptResult{
-		ScriptID:    script.ID,
-		Duration:    time.Since(start),
-		Environment: variables,
-		Result:      result.ScriptResult,
-		Output: ScriptOutput{
-			Stdout: output.Stdout(),
-			Stderr: output.Stderr(),
-		},
-	}, nil
+	scriptResult.Duration = time.Since(start)
+	return scriptResult, nil
 }
 
 func (host *Host) CancelScript(scriptName string) error {
@@ -360,6 +453,7 @@ func (host *Host) CancelScript(scriptName string) error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 	log.PWarn("Request to cancel running script on host", map[string]interface{}{
 		"host_id":     host.ID,
 		"script_name": scriptName,
@@ -370,17 +464,61 @@ func (host *Host) CancelScript(scriptName string) error {
 	return nil
 }
 
-func (host *Host) environmentVariablesForScript(script *Script) []environ.Variable {
-	variables := environ.Merge(staticEnvironment(), []environ.Variable{
-		environ.New("OTTO_HOST_ADDRESS", host.Address),
-		environ.New("OTTO_HOST_PORT", fmt.Sprintf("%d", host.Port)),
+// DownloadFile download the file at filePath from the host, writing its contents to w. Files larger than maxSize
+// are rejected by the host. The checksum of the file is verified once all data has been received, so callers should
+// not trust the data written to w unless no error was returned.
+func (host *Host) DownloadFile(filePath string, maxSize uint64, w io.Writer) (*otto.FileInfo, error) {
+	conn, err := host.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	log.PInfo("Downloading file from host", map[string]interface{}{
+		"host_id":  host.ID,
+		"path":     filePath,
+		"max_size": maxSize,
 	})
+	fileInfo, err := conn.Conn.TriggerActionDownloadFile(otto.MessageTriggerActionDownloadFile{
+		Path:    filePath,
+		MaxSize: maxSize,
+	}, w)
+	if err != nil {
+		log.PError("Error downloading file from host", map[string]interface{}{
+			"host_id": host.ID,
+			"path":    filePath,
+			"error":   err.Error(),
+		})
+		return nil, err
+	}
+
+	return fileInfo, nil
+}
 
-	// 1. Global environment variables
-	variables = environ.Merge(variables, Options.General.GlobalEnvironment)
+// environmentLayer describes a set of environment variables and where they came from
+type environmentLayer struct {
+	Source string
+	// Group the name of the group if the source is a group
+	Group     string
+	Variables []environ.Variable
+}
 
-	// 2. Script environment variables
-	variables = environ.Merge(variables, script.Environment)
+// environmentLayersForScript returns the layers of environment variables for the script on this host, in the order
+// that they are merged. Variables in later layers replace those with the same key in earlier layers.
+func (host *Host) environmentLayersForScript(script *Script) []environmentLayer {
+	layers := []environmentLayer{
+		{
+			Source: EnvironmentSourceServer,
+			Variables: environ.Merge(staticEnvironment(), []environ.Variable{
+				environ.New("OTTO_HOST_ADDRESS", host.Address),
+				environ.New("OTTO_HOST_PORT", fmt.Sprintf("%d", host.Port)),
+			}),
+		},
+		// 1. Global environment variables
+		{Source: EnvironmentSourceGlobal, Variables: Options.General.GlobalEnvironment},
+		// 2. Script environment variables
+		{Source: EnvironmentSourceScript, Variables: script.Environment},
+	}
 
 	// 3. Group environment variables
 	groups, err := host.Groups()
@@ -388,11 +526,23 @@ func (host *Host) environmentVariablesForScript(script *Script) []environ.Variab
 		groups = []Group{}
 	}
 	for _, group := range groups {
-		variables = environ.Merge(variables, group.Environment)
+		layers = append(layers, environmentLayer{Source: EnvironmentSourceGroup, Group: group.Name, Variables: group.Environment})
 	}
 
 	// 4. Host environment variables
-	variables = environ.Merge(variables, host.Environment)
+	layers = append(layers, environmentLayer{Source: EnvironmentSourceHost, Variables: host.Environment})
+
+	// 5. Script parameter values
+	layers = append(layers, environmentLayer{Source: EnvironmentSourceParameter, Variables: script.parameterValues})
+
+	return layers
+}
+
+func (host *Host) environmentVariablesForScript(script *Script) []environ.Variable {
+	variables := []environ.Variable{}
+	for _, layer := range host.environmentLayersForScript(script) {
+		variables = environ.Merge(variables, layer.Variables)
+	}
 
 	if logtic.Log.Level == logtic.LevelDebug {
 		varStr := make([]string, len(variables))
@@ -456,6 +606,7 @@ func (host *Host) RotateIdentity() (string, string, error) {
 	agentPublicKey := reply.PublicKey
 
 	IdentityStore.Set(host.ID, serverId)
+	hostClients.Close(host.ID)
 	trust := HostTrust{
 		TrustedIdentity: agentPublicKey,
 		LastTrustUpdate: time.Now(),
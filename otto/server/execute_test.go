@@ -1,6 +1,8 @@
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"net"
 	"testing"
@@ -100,6 +102,12 @@ func TestExecutePing(t *testing.T) {
 	if hb.Version != version {
 		t.Errorf("Unexpected version for host. Expected '%s' got '%s'", version, hb.Version)
 	}
+	if hb.Capabilities.ProtocolVersion != otto.ProtocolVersion {
+		t.Errorf("Unexpected protocol version for host. Expected %d got %d", otto.ProtocolVersion, hb.Capabilities.ProtocolVersion)
+	}
+	if !hb.Capabilities.Supports(otto.FeatureMultiplexing) {
+		t.Errorf("Host should support multiplexing")
+	}
 }
 
 func TestExecuteAction(t *testing.T) {
@@ -232,6 +240,9 @@ func TestExecuteAction(t *testing.T) {
 	if !result.Result.Success {
 		t.Errorf("Unexpected result status: %+v", result.Result)
 	}
+	if result.Status != ScriptStatusSuccess {
+		t.Errorf("Unexpected script status: %s", result.Status)
+	}
 
 	hb := heartbeatStore.LastHeartbeat(host)
 	if hb == nil {
@@ -239,6 +250,210 @@ func TestExecuteAction(t *testing.T) {
 	}
 }
 
+func TestExecuteDownloadFile(t *testing.T) {
+	t.Parallel()
+
+	ip := randLocalhostIP()
+	agentId, _ := otto.NewIdentity()
+	filePath := "/var/log/" + randomString(6)
+	fileData := secutil.RandomBytes(64)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(fileData))
+
+	group, err := GroupStore.NewGroup(newGroupParameters{
+		Name: randomString(6),
+	})
+	if err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:          randomString(6),
+		Address:       ip,
+		Port:          uint32(secutil.RandomNumber(0, 65535)),
+		AgentIdentity: agentId.PublicKeyString(),
+		GroupIDs:      []string{group.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	_, allowFrom, _ := net.ParseCIDR("0.0.0.0/0")
+	l, listenErr := otto.SetupListener(&otto.ListenOptions{
+		Address:   ip + ":0",
+		AllowFrom: []net.IPNet{*allowFrom},
+		Identity:  agentId.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			serverId, err := IdentityStore.Get(host.ID)
+			if err != nil {
+				panic(err)
+			}
+			if serverId == nil {
+				return []string{}
+			}
+			return []string{serverId.PublicKeyString()}
+		},
+	}, func(conn *otto.Connection) {
+		defer conn.Close()
+
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Errorf("Error reading message: " + err.Error())
+			return
+		}
+		if messageType != otto.MessageTypeTriggerActionDownloadFile {
+			t.Errorf("Incorrect message type")
+			return
+		}
+		request := message.(otto.MessageTriggerActionDownloadFile)
+		if request.Path != filePath {
+			conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{Error: "no such file"})
+			return
+		}
+		if err := conn.WriteMessage(otto.MessageTypeDownloadFileInfo, otto.MessageDownloadFileInfo{FileInfo: otto.FileInfo{
+			Path:     filePath,
+			Mode:     644,
+			Checksum: checksum,
+			Length:   uint64(len(fileData)),
+		}}); err != nil {
+			t.Errorf("Error writing message: " + err.Error())
+			return
+		}
+		if messageType, _, _ := conn.ReadMessage(); messageType != otto.MessageTypeReadyForData {
+			t.Errorf("Incorrect message type")
+			return
+		}
+		conn.WriteData(fileData)
+		conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{})
+	})
+	if listenErr != nil {
+		panic("error listening: " + listenErr.Error())
+	}
+
+	host.Port = uint32(l.Port())
+	HostStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Update(*host)
+	})
+
+	go func() {
+		l.Accept()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	downloaded := &bytes.Buffer{}
+	fileInfo, derr := host.DownloadFile(filePath, 1024, downloaded)
+	if derr != nil {
+		t.Fatalf("Error downloading file: %s", derr.Error())
+	}
+	if fileInfo.Checksum != checksum {
+		t.Errorf("Unexpected checksum. Expected '%s' got '%s'", checksum, fileInfo.Checksum)
+	}
+	if !bytes.Equal(downloaded.Bytes(), fileData) {
+		t.Errorf("Unexpected file data")
+	}
+
+	if _, derr := host.DownloadFile("/"+randomString(6), 1024, &bytes.Buffer{}); derr == nil {
+		t.Errorf("No error seen when one expected for downloading missing file")
+	}
+}
+
+func TestExecuteReverse(t *testing.T) {
+	t.Parallel()
+
+	version := randomString(4)
+	agentId, _ := otto.NewIdentity()
+
+	group, err := GroupStore.NewGroup(newGroupParameters{
+		Name: randomString(6),
+	})
+	if err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:           randomString(6),
+		Address:        randLocalhostIP(),
+		Port:           12444,
+		AgentIdentity:  agentId.PublicKeyString(),
+		ConnectionMode: HostConnectionModeReverse,
+		GroupIDs:       []string{group.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	if err := host.Ping(); err == nil {
+		t.Fatalf("No error seen when one expected for pinging reverse host without a session")
+	}
+
+	serverListener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		panic("error listening: " + listenErr.Error())
+	}
+	defer serverListener.Close()
+
+	l, listenErr := otto.SetupReverseListener(&otto.ReverseListenOptions{
+		ServerAddress: serverListener.Addr().String(),
+		Identity:      agentId.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			serverId, err := IdentityStore.Get(host.ID)
+			if err != nil {
+				panic(err)
+			}
+			return []string{serverId.PublicKeyString()}
+		},
+		Timeout: time.Second,
+	}, func(conn *otto.Connection) {
+		defer conn.Close()
+		messageType, m, err := conn.ReadMessage()
+		if err != nil {
+			t.Errorf("Error reading message: " + err.Error())
+			return
+		}
+		if messageType != otto.MessageTypeHeartbeatRequest {
+			t.Errorf("Incorrect message type")
+			return
+		}
+		message := m.(otto.MessageHeartbeatRequest)
+		conn.WriteMessage(otto.MessageTypeHeartbeatResponse, otto.MessageHeartbeatResponse{AgentVersion: version, Nonce: message.Nonce})
+	})
+	if listenErr != nil {
+		panic("error listening: " + listenErr.Error())
+	}
+	defer l.Close()
+	go l.Accept()
+
+	c, acceptErr := serverListener.Accept()
+	if acceptErr != nil {
+		t.Fatalf("Error accepting connection: %s", acceptErr.Error())
+	}
+	go acceptReverseConnection(c)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := host.Ping(); err != nil {
+		t.Fatalf("Error pinging host: %s", err.Error())
+	}
+
+	hb := heartbeatStore.LastHeartbeat(host)
+	if hb == nil {
+		t.Fatalf("No heartbeat found for host")
+	}
+	if !hb.IsReachable {
+		t.Errorf("Host should be reachable")
+	}
+	if hb.Version != version {
+		t.Errorf("Unexpected version for host. Expected '%s' got '%s'", version, hb.Version)
+	}
+
+	l.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	if hb := heartbeatStore.LastHeartbeat(host); hb.IsReachable {
+		t.Errorf("Host should not be reachable once the reverse session is closed")
+	}
+}
+
 func TestExecuteUntrustedKey(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+// ExecutionOptions describes how an action is performed when it targets many hosts
+type ExecutionOptions struct {
+	// MaxParallelism the maximum number of hosts the action is performed on at once, 0 uses the server default
+	MaxParallelism int
+	// BatchDelaySeconds how long to wait between each batch of hosts, 0 for no delay
+	BatchDelaySeconds int
+}
+
+// Validate returns an error if the execution options are not valid
+func (o ExecutionOptions) Validate() error {
+	if o.MaxParallelism < 0 {
+		return fmt.Errorf("max parallelism cannot be negative")
+	}
+	if o.BatchDelaySeconds < 0 {
+		return fmt.Errorf("batch delay cannot be negative")
+	}
+	return nil
+}
+
+func (o ExecutionOptions) parallelism() int {
+	if o.MaxParallelism > 0 {
+		return o.MaxParallelism
+	}
+	if Options.Network.MaxParallelism > 0 {
+		return Options.Network.MaxParallelism
+	}
+	return 1
+}
+
+// executeOnHosts will call fn for every host, with no more than the maximum parallelism running at once. The index of
+// the host is passed to fn so that callers can collect results in the same order as the hosts. When a batch delay is
+// set, hosts are run in batches of the maximum parallelism and the next batch does not start until the delay has passed
+// after the previous batch finished. Blocks until fn has returned for all hosts.
+func executeOnHosts(hosts []*Host, options ExecutionOptions, fn func(i int, host *Host)) {
+	parallelism := options.parallelism()
+	delay := time.Duration(options.BatchDelaySeconds) * time.Second
+
+	if delay > 0 {
+		for start := 0; start < len(hosts); start += parallelism {
+			if start > 0 {
+				time.Sleep(delay)
+			}
+			end := start + parallelism
+			if end > len(hosts) {
+				end = len(hosts)
+			}
+
+			wg := sync.WaitGroup{}
+			for i := start; i < end; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					fn(i, hosts[i])
+				}(i)
+			}
+			wg.Wait()
+		}
+		return
+	}
+
+	slots := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for i := range hosts {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			fn(i, hosts[i])
+		}(i)
+	}
+	wg.Wait()
+}
+
+// resolveHosts returns the hosts in the scope and the hosts with a name or address matching the query, less any hosts
+// excluded by the scope. The query is a shell pattern, such as "web-*". Unlike a schedule, the scope may have both
+// hosts and groups.
+func resolveHosts(scope ScheduleScope, query string) ([]*Host, *Error) {
+	for _, groupID := range append(append([]string{}, scope.GroupIDs...), scope.ExcludeGroupIDs...) {
+		if GroupCache.ByID(groupID) == nil {
+			return nil, ErrorUser("No group with ID %s", groupID)
+		}
+	}
+	for _, hostID := range append(append([]string{}, scope.HostIDs...), scope.ExcludeHostIDs...) {
+		if HostCache.ByID(hostID) == nil {
+			return nil, ErrorUser("No host with ID %s", hostID)
+		}
+	}
+	for _, selector := range []string{scope.Selector, scope.ExcludeSelector} {
+		if selector == "" {
+			continue
+		}
+		if _, err := parseSelector(selector); err != nil {
+			return nil, ErrorUser("Invalid selector: %s", err.Error())
+		}
+	}
+	if query != "" {
+		if _, err := path.Match(query, ""); err != nil {
+			return nil, ErrorUser("Invalid query: %s", err.Error())
+		}
+	}
+
+	m := scope.matcher()
+	properties := hostProperties()
+	hosts := []*Host{}
+	for _, host := range HostCache.All() {
+		if m.excludes(&host, properties[host.Address]) {
+			continue
+		}
+		included := m.includes(&host, properties[host.Address])
+		if !included && query != "" {
+			nameMatch, _ := path.Match(query, host.Name)
+			addressMatch, _ := path.Match(query, host.Address)
+			included = nameMatch || addressMatch
+		}
+		if included {
+			h := host
+			hosts = append(hosts, &h)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, ErrorUser("At least one host is required")
+	}
+	return hosts, nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExecuteOnHosts(t *testing.T) {
+	t.Parallel()
+
+	hosts := make([]*Host, 20)
+	for i := range hosts {
+		hosts[i] = &Host{ID: randomString(6)}
+	}
+
+	lock := sync.Mutex{}
+	running := 0
+	maxRunning := 0
+	results := make([]string, len(hosts))
+	executeOnHosts(hosts, ExecutionOptions{MaxParallelism: 4}, func(i int, host *Host) {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		results[i] = host.ID
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+	})
+
+	if maxRunning > 4 {
+		t.Errorf("Too many hosts running at once: %d", maxRunning)
+	}
+	for i, host := range hosts {
+		if results[i] != host.ID {
+			t.Errorf("Unexpected result for host %d", i)
+		}
+	}
+}
+
+func TestExecuteOnHostsBatchDelay(t *testing.T) {
+	t.Parallel()
+
+	hosts := make([]*Host, 5)
+	for i := range hosts {
+		hosts[i] = &Host{ID: randomString(6)}
+	}
+
+	lock := sync.Mutex{}
+	started := make([]time.Time, len(hosts))
+	executeOnHosts(hosts, ExecutionOptions{MaxParallelism: 2, BatchDelaySeconds: 1}, func(i int, host *Host) {
+		lock.Lock()
+		started[i] = time.Now()
+		lock.Unlock()
+	})
+
+	// Hosts 0 and 1 are in the first batch, 2 and 3 in the second, and 4 in the third
+	if started[2].Sub(started[1]) < time.Second {
+		t.Errorf("Second batch started without delay")
+	}
+	if started[4].Sub(started[3]) < time.Second {
+		t.Errorf("Third batch started without delay")
+	}
+}
+
+func TestExecutionOptionsValidate(t *testing.T) {
+	if err := (ExecutionOptions{}).Validate(); err != nil {
+		t.Errorf("Unexpected error validating default execution options: %s", err.Error())
+	}
+	if err := (ExecutionOptions{MaxParallelism: -1}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for negative max parallelism")
+	}
+	if err := (ExecutionOptions{BatchDelaySeconds: -1}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for negative batch delay")
+	}
+}
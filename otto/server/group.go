@@ -11,9 +11,14 @@ type Group struct {
 	Name        string `ds:"unique" min:"1" max:"140"`
 	ScriptIDs   []string
 	Environment []environ.Variable
+	// Selector if set, hosts that match the selector are also members of the group
+	Selector string
+	// ParentID if set, the group inherits the scripts and environment of the parent group and the members of the group
+	// are also members of the parent group
+	ParentID string
 }
 
-// HostIDs return the IDs for each host member of this group
+// HostIDs return the IDs for each host member of this group, including members of any child groups
 func (g *Group) HostIDs() []string {
 	return GroupCache.HostIDs(g.ID)
 }
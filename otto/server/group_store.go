@@ -90,6 +90,8 @@ type newGroupParameters struct {
 	Name        string
 	ScriptIDs   []string
 	Environment []environ.Variable
+	Selector    string
+	ParentID    string
 }
 
 func (s *groupStoreObject) NewGroup(params newGroupParameters) (group *Group, err *Error) {
@@ -109,6 +111,16 @@ func (s *groupStoreObject) newGroup(tx ds.IReadWriteTransaction, params newGroup
 	if err := environ.Validate(params.Environment); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
+	if params.Selector != "" {
+		if _, err := parseSelector(params.Selector); err != nil {
+			return nil, ErrorUser("Invalid selector: %s", err.Error())
+		}
+	}
+
+	id := newID()
+	if err := s.validateParent(tx, id, params.ParentID); err != nil {
+		return nil, err
+	}
 
 	var enabledScripts = make([]string, len(params.ScriptIDs))
 	for i, scriptID := range params.ScriptIDs {
@@ -121,10 +133,12 @@ func (s *groupStoreObject) newGroup(tx ds.IReadWriteTransaction, params newGroup
 	}
 
 	group := Group{
-		ID:          newID(),
+		ID:          id,
 		Name:        params.Name,
 		ScriptIDs:   enabledScripts,
 		Environment: params.Environment,
+		Selector:    params.Selector,
+		ParentID:    params.ParentID,
 	}
 	if err := limits.Check(group); err != nil {
 		return nil, ErrorUser(err.Error())
@@ -144,6 +158,8 @@ type editGroupParameters struct {
 	Name        string
 	ScriptIDs   []string
 	Environment []environ.Variable
+	Selector    string
+	ParentID    string
 }
 
 func (s *groupStoreObject) EditGroup(group *Group, params editGroupParameters) (newGroup *Group, err *Error) {
@@ -163,6 +179,14 @@ func (s *groupStoreObject) editGroup(tx ds.IReadWriteTransaction, group *Group,
 	if err := environ.Validate(params.Environment); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
+	if params.Selector != "" {
+		if _, err := parseSelector(params.Selector); err != nil {
+			return nil, ErrorUser("Invalid selector: %s", err.Error())
+		}
+	}
+	if err := s.validateParent(tx, group.ID, params.ParentID); err != nil {
+		return nil, err
+	}
 
 	var enabledScripts = make([]string, len(params.ScriptIDs))
 	for i, scriptID := range params.ScriptIDs {
@@ -177,6 +201,8 @@ func (s *groupStoreObject) editGroup(tx ds.IReadWriteTransaction, group *Group,
 	group.Name = params.Name
 	group.ScriptIDs = enabledScripts
 	group.Environment = params.Environment
+	group.Selector = params.Selector
+	group.ParentID = params.ParentID
 	if err := limits.Check(group); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
@@ -191,6 +217,32 @@ func (s *groupStoreObject) editGroup(tx ds.IReadWriteTransaction, group *Group,
 	return group, nil
 }
 
+// validateParent returns an error if the parent group does not exist or if making it the parent of the group would
+// create a cycle
+func (s *groupStoreObject) validateParent(tx ds.IReadTransaction, groupID, parentID string) *Error {
+	if parentID == "" {
+		return nil
+	}
+	if s.groupWithID(tx, parentID) == nil {
+		return ErrorUser("No group with ID %s", parentID)
+	}
+
+	seen := map[string]bool{}
+	for id := parentID; id != "" && !seen[id]; {
+		if id == groupID {
+			log.Warn("Group '%s' can't be a descendant of itself", groupID)
+			return ErrorUser("A group can't be a child of itself or of one of its children")
+		}
+		seen[id] = true
+		parent := s.groupWithID(tx, id)
+		if parent == nil {
+			break
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
+
 func (s *groupStoreObject) DeleteGroup(group *Group) (err *Error) {
 	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
 		err = s.deleteGroup(tx, group)
@@ -200,15 +252,25 @@ func (s *groupStoreObject) DeleteGroup(group *Group) (err *Error) {
 }
 
 func (s *groupStoreObject) deleteGroup(tx ds.IReadWriteTransaction, group *Group) *Error {
-	hosts, err := group.Hosts()
-	if err != nil {
-		return err
+	// Hosts that match the selector of the group or that are members of a child group don't refer to the group
+	hosts := []Host{}
+	for _, host := range HostCache.All() {
+		if sliceContains(group.ID, host.GroupIDs) {
+			hosts = append(hosts, host)
+		}
 	}
 	if len(hosts) > 0 {
 		log.Error("Can't delete group '%s' with hosts", group.Name)
 		return ErrorUser("Can't delete group with hosts")
 	}
 
+	for _, child := range s.allGroups(tx) {
+		if child.ParentID == group.ID {
+			log.Error("Can't delete group '%s' with child groups", group.Name)
+			return ErrorUser("Can't delete group with child groups")
+		}
+	}
+
 	if AutoRegisterOptions.DefaultGroupID == group.ID {
 		log.Error("Can't delete group '%s' that is the default group for host registration", group.Name)
 		return ErrorUser("Can't delete group that is the default group for host registration")
@@ -225,6 +287,16 @@ func (s *groupStoreObject) deleteGroup(tx ds.IReadWriteTransaction, group *Group
 		return ErrorUser("Can't delete group that is used in a schedule")
 	}
 
+	if workflows := WorkflowStore.AllWorkflowsForScope(group.ID); len(workflows) > 0 {
+		log.Error("Can't delete group '%s' that is used in a workflow", group.Name)
+		return ErrorUser("Can't delete group that is used in a workflow")
+	}
+
+	if windows := MaintenanceWindowStore.AllWindowsForScope(group.ID); len(windows) > 0 {
+		log.Error("Can't delete group '%s' that is used in a maintenance window", group.Name)
+		return ErrorUser("Can't delete group that is used in a maintenance window")
+	}
+
 	if groups := s.allGroups(tx); len(groups) <= 1 {
 		log.Error("At least one group must exist")
 		return ErrorUser("At least one group must exist")
@@ -2,6 +2,8 @@ package server
 
 import (
 	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
 )
 
 func TestAddGetGroup(t *testing.T) {
@@ -136,3 +138,87 @@ func TestRenameDuplicateGroup(t *testing.T) {
 		t.Fatalf("Should return error")
 	}
 }
+
+func TestNestedGroups(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	prod, err := GroupStore.NewGroup(newGroupParameters{
+		Name:      randomString(6),
+		ScriptIDs: []string{script.ID},
+		Environment: []environ.Variable{
+			environ.New("REGION", "global"),
+			environ.New("TIER", "prod"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making new group: %s", err.Message)
+	}
+	web, err := GroupStore.NewGroup(newGroupParameters{
+		Name:        randomString(6),
+		Environment: []environ.Variable{environ.New("TIER", "web")},
+		ParentID:    prod.ID,
+	})
+	if err != nil {
+		t.Fatalf("Error making new group: %s", err.Message)
+	}
+	eu, err := GroupStore.NewGroup(newGroupParameters{
+		Name:        randomString(6),
+		Environment: []environ.Variable{environ.New("REGION", "eu")},
+		ParentID:    web.ID,
+	})
+	if err != nil {
+		t.Fatalf("Error making new group: %s", err.Message)
+	}
+
+	// The host is also a member of the root group, the child group must still take precedence
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:     randomString(6),
+		Address:  randLocalhostIP(),
+		Port:     1,
+		GroupIDs: []string{eu.ID, prod.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	groupIDs := host.AllGroupIDs()
+	if len(groupIDs) != 3 || groupIDs[0] != prod.ID || groupIDs[1] != web.ID || groupIDs[2] != eu.ID {
+		t.Errorf("Unexpected groups for host: %v", groupIDs)
+	}
+	for _, group := range []*Group{prod, web, eu} {
+		if hostIDs := GroupCache.HostIDs(group.ID); len(hostIDs) != 1 || hostIDs[0] != host.ID {
+			t.Errorf("Unexpected members of group %s: %v", group.Name, hostIDs)
+		}
+	}
+
+	variables := environ.Map(host.environmentVariablesForScript(script))
+	if variables["REGION"] != "eu" || variables["TIER"] != "web" {
+		t.Errorf("Unexpected environment for host: %v", variables)
+	}
+	if scripts := host.Scripts(); len(scripts) != 1 || scripts[0].Script.ID != script.ID || scripts[0].GroupID != prod.ID {
+		t.Errorf("Host should inherit scripts from parent groups: %+v", scripts)
+	}
+	if !(ScheduleScope{GroupIDs: []string{prod.ID}}).Contains(host) {
+		t.Errorf("Host in a child group should be in the scope of the parent group")
+	}
+
+	if _, err := GroupStore.EditGroup(prod, editGroupParameters{Name: prod.Name, ParentID: eu.ID}); err == nil {
+		t.Errorf("No error seen making a group a child of its descendant")
+	}
+	if _, err := GroupStore.EditGroup(web, editGroupParameters{Name: web.Name, ParentID: web.ID}); err == nil {
+		t.Errorf("No error seen making a group a child of itself")
+	}
+	if _, err := GroupStore.NewGroup(newGroupParameters{Name: randomString(6), ParentID: randomString(6)}); err == nil {
+		t.Errorf("No error seen making a group with an unknown parent")
+	}
+	if err := GroupStore.DeleteGroup(web); err == nil {
+		t.Errorf("No error seen deleting a group with child groups")
+	}
+}
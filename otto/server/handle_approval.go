@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) ApprovalList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	return ApprovalStore.AllRequests(), nil, nil
+}
+
+func (h *handle) ApprovalGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+
+	approval := ApprovalStore.RequestWithID(id)
+	if approval == nil {
+		return nil, nil, web.ValidationError("No approval request with ID %s", id)
+	}
+
+	return approval, nil, nil
+}
+
+func (h *handle) ApprovalNew(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	params := newApprovalRequestParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	approval, err := ApprovalStore.NewRequest(params, session.User())
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return approval, nil, nil
+}
+
+type approvalReviewParams struct {
+	Comment string
+}
+
+func (h *handle) ApprovalApprove(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	approval := ApprovalStore.RequestWithID(id)
+	if approval == nil {
+		return nil, nil, web.ValidationError("No approval request with ID %s", id)
+	}
+
+	params := approvalReviewParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	job, err := ApprovalStore.Approve(approval, session.User(), params.Comment)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return job, nil, nil
+}
+
+func (h *handle) ApprovalReject(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	approval := ApprovalStore.RequestWithID(id)
+	if approval == nil {
+		return nil, nil, web.ValidationError("No approval request with ID %s", id)
+	}
+
+	params := approvalReviewParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ApprovalStore.Reject(approval, session.User(), params.Comment); err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return approval, nil, nil
+}
+
+// requiresApprovalError returns the error for an attempt to run a script that requires approval without an approval
+// request
+func requiresApprovalError(script *Script) string {
+	return fmt.Sprintf("Script %s requires approval, request approval to run it", script.Name)
+}
@@ -26,6 +26,7 @@ func (h *handle) AttachmentUpload(request web.Request) (interface{}, *web.APIRes
 	uidStr := request.HTTP.FormValue("UID")
 	gidStr := request.HTTP.FormValue("GID")
 	modeStr := request.HTTP.FormValue("Mode")
+	template := request.HTTP.FormValue("Template") == "true"
 
 	uid, err := strconv.ParseUint(uidStr, 10, 32)
 	if err != nil {
@@ -56,8 +57,9 @@ func (h *handle) AttachmentUpload(request web.Request) (interface{}, *web.APIRes
 			UID:     uint32(uid),
 			GID:     uint32(gid),
 		},
-		Mode: uint32(mode),
-		Size: uint64(info.Size),
+		Mode:     uint32(mode),
+		Size:     uint64(info.Size),
+		Template: template,
 	}
 
 	attachment, erro := AttachmentStore.NewAttachment(req)
@@ -116,6 +118,7 @@ func (h *handle) AttachmentEdit(request web.Request) (interface{}, *web.APIRespo
 	uidStr := request.HTTP.FormValue("UID")
 	gidStr := request.HTTP.FormValue("GID")
 	modeStr := request.HTTP.FormValue("Mode")
+	template := request.HTTP.FormValue("Template") == "true"
 
 	uid, err := strconv.ParseUint(uidStr, 10, 32)
 	if err != nil {
@@ -143,7 +146,8 @@ func (h *handle) AttachmentEdit(request web.Request) (interface{}, *web.APIRespo
 			UID:     uint32(uid),
 			GID:     uint32(gid),
 		},
-		Mode: uint32(mode),
+		Mode:     uint32(mode),
+		Template: template,
 	}
 
 	if info != nil {
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ecnepsnai/web"
+)
+
+type commandParams struct {
+	Command
+	ScheduleScope
+	Query     string
+	Execution ExecutionOptions
+	// OverrideWindows run the command even if hosts are blocked by a maintenance window
+	OverrideWindows bool
+}
+
+type commandHostResult struct {
+	HostID string
+	Result *ScriptResult `json:",omitempty"`
+	Error  string        `json:",omitempty"`
+}
+
+// validate will validate the command parameters and return the hosts the command should run on
+func (p commandParams) validate() ([]*Host, *Error) {
+	if err := p.Command.Validate(); err != nil {
+		return nil, ErrorUser("%s", err.Error())
+	}
+	if err := p.Execution.Validate(); err != nil {
+		return nil, ErrorUser("%s", err.Error())
+	}
+	return resolveHosts(p.ScheduleScope, p.Query)
+}
+
+func (h *handle) CommandNew(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanRunCommands {
+		EventStore.UserPermissionDenied(session.User().Username, "Run ad-hoc command")
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	r := commandParams{}
+	if err := request.DecodeJSON(&r); err != nil {
+		return nil, nil, err
+	}
+
+	hosts, err := r.validate()
+	if err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+	if err := checkMaintenanceWindows(hosts, r.OverrideWindows, session.User(), "Run ad-hoc command"); err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	results := make([]commandHostResult, len(hosts))
+	executeOnHosts(hosts, r.Execution, func(i int, host *Host) {
+		results[i].HostID = host.ID
+		result, err := r.Command.run(host, session.Username, nil)
+		if err != nil {
+			results[i].Error = err.Error()
+			return
+		}
+		results[i].Result = result
+	})
+
+	return results, nil, nil
+}
+
+func (h handle) CommandStream(request web.Request, conn *web.WSConn) {
+	session := request.UserData.(*Session)
+	defer conn.Close()
+
+	type commandResponse struct {
+		Code   int           `json:"Code,omitempty"`
+		HostID string        `json:"HostID,omitempty"`
+		Error  string        `json:"Error,omitempty"`
+		Stdout string        `json:"Stdout,omitempty"`
+		Stderr string        `json:"Stderr,omitempty"`
+		Result *ScriptResult `json:"Result,omitempty"`
+	}
+
+	// Output from many hosts is written to the same connection
+	writeLock := sync.Mutex{}
+	writeMessage := func(m commandResponse) {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		if err := conn.WriteJSON(m); err != nil {
+			log.PError("Error sending websocket message", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if !session.User().Permissions.CanRunCommands {
+		writeMessage(commandResponse{
+			Code:  RequestResponseCodeError,
+			Error: "Permission denied",
+		})
+		EventStore.UserPermissionDenied(session.User().Username, "Run ad-hoc command")
+		return
+	}
+
+	r := commandParams{}
+	if err := conn.ReadJSON(&r); err != nil {
+		writeMessage(commandResponse{
+			Code:  RequestResponseCodeError,
+			Error: "Invalid request",
+		})
+		return
+	}
+
+	hosts, err := r.validate()
+	if err != nil {
+		writeMessage(commandResponse{
+			Code:  RequestResponseCodeError,
+			Error: err.Message,
+		})
+		return
+	}
+	if err := checkMaintenanceWindows(hosts, r.OverrideWindows, session.User(), "Run ad-hoc command"); err != nil {
+		writeMessage(commandResponse{
+			Code:  RequestResponseCodeError,
+			Error: err.Message,
+		})
+		return
+	}
+
+	running := true
+	go func() {
+		lastKA := time.Now().AddDate(0, 0, -1)
+		for running {
+			if time.Since(lastKA) > 10*time.Second {
+				writeMessage(commandResponse{
+					Code: RequestResponseCodeKeepalive,
+				})
+				lastKA = time.Now()
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	executeOnHosts(hosts, r.Execution, func(i int, host *Host) {
+		result, err := r.Command.run(host, session.Username, func(stdout, stderr []byte) {
+			writeMessage(commandResponse{
+				Code:   RequestResponseCodeOutput,
+				HostID: host.ID,
+				Stdout: string(stdout),
+				Stderr: string(stderr),
+			})
+		})
+		if err != nil {
+			writeMessage(commandResponse{
+				Code:   RequestResponseCodeError,
+				HostID: host.ID,
+				Error:  err.Error(),
+			})
+			return
+		}
+		writeMessage(commandResponse{
+			Code:   RequestResponseCodeFinished,
+			HostID: host.ID,
+			Result: result,
+		})
+	})
+	running = false
+}
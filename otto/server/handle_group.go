@@ -130,6 +130,7 @@ func (h *handle) GroupSetHosts(request web.Request) (interface{}, *web.APIRespon
 			Enabled:     host.Enabled,
 			GroupIDs:    append(host.GroupIDs, id),
 			Environment: host.Environment,
+			Labels:      host.Labels,
 		}); err != nil {
 			return nil, nil, web.CommonErrors.ServerError
 		}
@@ -147,6 +148,7 @@ func (h *handle) GroupSetHosts(request web.Request) (interface{}, *web.APIRespon
 			Enabled:     host.Enabled,
 			GroupIDs:    filterSlice(id, host.GroupIDs),
 			Environment: host.Environment,
+			Labels:      host.Labels,
 		}); err != nil {
 			return nil, nil, web.CommonErrors.ServerError
 		}
@@ -192,6 +194,9 @@ func (h *handle) GroupGetSchedules(request web.Request) (interface{}, *web.APIRe
 	sort.Slice(schedules, func(i int, j int) bool {
 		return schedules[i].Name < schedules[j].Name
 	})
+	for i := range schedules {
+		schedules[i].hideSecrets()
+	}
 
 	return schedules, nil, nil
 }
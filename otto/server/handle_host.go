@@ -2,9 +2,16 @@ package server
 
 import (
 	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
 	"sort"
+	"strconv"
 	"time"
 
+	"github.com/ecnepsnai/otto/server/environ"
+	"github.com/ecnepsnai/otto/shared/otto"
 	"github.com/ecnepsnai/web"
 )
 
@@ -30,6 +37,44 @@ func (h *handle) HostList(request web.Request) (interface{}, *web.APIResponse, *
 	return hosts, nil, nil
 }
 
+func (h *handle) HostSelect(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	type selectParams struct {
+		Selector string
+	}
+
+	params := selectParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	selector, err := parseSelector(params.Selector)
+	if err != nil {
+		return nil, nil, web.ValidationError("Invalid selector: %s", err.Error())
+	}
+
+	hosts := selectHosts(selector)
+	for i, host := range hosts {
+		// Hide secret environment variables if the user cannot modify them
+		if !session.User().Permissions.CanModifyHosts {
+			environment := make([]environ.Variable, len(host.Environment))
+			for y, env := range host.Environment {
+				environment[y] = env
+				if env.Secret {
+					environment[y].Value = ""
+				}
+			}
+			hosts[i].Environment = environment
+		}
+	}
+	sort.Slice(hosts, func(i int, j int) bool {
+		return hosts[i].Name < hosts[j].Name
+	})
+
+	return hosts, nil, nil
+}
+
 func (h *handle) HostGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
 	id := request.Parameters["id"]
 	session := request.UserData.(*Session)
@@ -81,6 +126,9 @@ func (h *handle) HostGetSchedules(request web.Request) (interface{}, *web.APIRes
 	sort.Slice(schedules, func(i int, j int) bool {
 		return schedules[i].Name < schedules[j].Name
 	})
+	for i := range schedules {
+		schedules[i].hideSecrets()
+	}
 
 	return schedules, nil, nil
 }
@@ -295,3 +343,157 @@ func (h *handle) HostDelete(request web.Request) (interface{}, *web.APIResponse,
 
 	return true, nil, nil
 }
+
+// maxHostFileDownloadSize is the largest file that can be downloaded from a host
+const maxHostFileDownloadSize uint64 = 104857600
+
+// downloadHostFile downloads the file from the host into a temporary file. The caller is responsible for closing and
+// removing the returned file.
+func downloadHostFile(host *Host, filePath string, maxSize uint64) (*os.File, *otto.FileInfo, error) {
+	if maxSize == 0 || maxSize > maxHostFileDownloadSize {
+		maxSize = maxHostFileDownloadSize
+	}
+
+	f, err := os.CreateTemp(Directories.Data, "download_")
+	if err != nil {
+		log.PError("Error creating temporary file", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, nil, err
+	}
+
+	fileInfo, err := host.DownloadFile(filePath, maxSize, f)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, nil, err
+	}
+
+	return f, fileInfo, nil
+}
+
+// temporaryFileReader is a file that is removed once it has been closed
+type temporaryFileReader struct {
+	*os.File
+}
+
+func (r temporaryFileReader) Close() error {
+	err := r.File.Close()
+	os.Remove(r.File.Name())
+	return err
+}
+
+func (v *view) HostDownloadFile(request web.Request) (response web.HTTPResponse) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanDownloadFiles {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Download file from host %s", id))
+		response.Status = 403
+		return
+	}
+
+	host := HostCache.ByID(id)
+	if host == nil {
+		response.Status = 404
+		return
+	}
+
+	filePath := request.HTTP.URL.Query().Get("path")
+	if filePath == "" {
+		response.Status = 400
+		return
+	}
+	maxSize := uint64(0)
+	if maxSizeStr := request.HTTP.URL.Query().Get("max_size"); maxSizeStr != "" {
+		s, err := strconv.ParseUint(maxSizeStr, 10, 64)
+		if err != nil {
+			response.Status = 400
+			return
+		}
+		maxSize = s
+	}
+
+	f, fileInfo, err := downloadHostFile(host, filePath, maxSize)
+	if err != nil {
+		EventStore.HostFileDownloadFailed(host, filePath, err, session.Username)
+		response.Status = 500
+		return
+	}
+	EventStore.HostFileDownloaded(host, fileInfo, "", session.Username)
+
+	response.ContentType = "application/octet-stream"
+	response.Headers = map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", path.Base(fileInfo.Path)),
+		"Content-Length":      fmt.Sprintf("%d", fileInfo.Length),
+	}
+	response.Reader = temporaryFileReader{f}
+	return
+}
+
+func (h *handle) HostSaveFile(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanDownloadFiles || !session.User().Permissions.CanModifyHosts {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Save file from host %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	host := HostCache.ByID(id)
+	if host == nil {
+		return nil, nil, web.ValidationError("No host with ID %s", id)
+	}
+
+	type saveFileParams struct {
+		Path    string
+		MaxSize uint64
+	}
+	params := saveFileParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+	if params.Path == "" {
+		return nil, nil, web.ValidationError("Path is required")
+	}
+
+	f, fileInfo, err := downloadHostFile(host, params.Path, params.MaxSize)
+	if err != nil {
+		EventStore.HostFileDownloadFailed(host, params.Path, err, session.Username)
+		return nil, nil, web.ValidationError("%s", err.Error())
+	}
+	defer temporaryFileReader{f}.Close()
+
+	mimeType := mime.TypeByExtension(path.Ext(fileInfo.Path))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	attachment, erro := AttachmentStore.NewAttachment(newAttachmentParameters{
+		Data:     f,
+		Path:     fileInfo.Path,
+		Name:     path.Base(fileInfo.Path),
+		MimeType: mimeType,
+		Owner: RunAs{
+			UID: fileInfo.Owner.UID,
+			GID: fileInfo.Owner.GID,
+		},
+		Mode: fileInfo.Mode,
+		Size: fileInfo.Length,
+	})
+	if erro != nil {
+		if erro.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(erro.Message)
+	}
+	EventStore.HostFileDownloaded(host, fileInfo, attachment.ID, session.Username)
+	EventStore.AttachmentAdded(attachment, session.Username)
+
+	return attachment, nil, nil
+}
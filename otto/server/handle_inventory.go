@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) InventoryImport(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanModifyHosts || !session.User().Permissions.CanModifyGroups {
+		EventStore.UserPermissionDenied(session.User().Username, "Import inventory")
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	type importParameters struct {
+		Format       string
+		Data         string
+		ValidateOnly bool
+	}
+
+	params := importParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	inventory, err := ParseInventory(params.Format, params.Data)
+	if err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	result, err := ImportInventory(*inventory, params.ValidateOnly, session.Username)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return result, nil, nil
+}
+
+func (v *view) InventoryExport(request web.Request) (response web.HTTPResponse) {
+	session := request.UserData.(*Session)
+
+	format := request.HTTP.URL.Query().Get("format")
+	extension := map[string]string{
+		InventoryFormatCSV:         "csv",
+		InventoryFormatAnsibleINI:  "ini",
+		InventoryFormatAnsibleYAML: "yml",
+	}[format]
+	if extension == "" {
+		response.Status = 400
+		return
+	}
+
+	// Hide secret environment variables if the user cannot modify them
+	inventory := ExportInventory(!session.User().Permissions.CanModifyHosts)
+	data, err := inventory.Format(format)
+	if err != nil {
+		log.PError("Error formatting inventory", map[string]interface{}{
+			"format": format,
+			"error":  err.Message,
+		})
+		response.Status = 500
+		return
+	}
+
+	response.ContentType = "text/plain"
+	response.Headers = map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=\"otto_inventory.%s\"", extension),
+		"Content-Length":      fmt.Sprintf("%d", len(data)),
+	}
+	response.Reader = io.NopCloser(bytes.NewReader(data))
+	return
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) JobNew(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	type jobParams struct {
+		ScriptID string
+		ScheduleScope
+		Parameters map[string]string
+		Execution  ExecutionOptions
+		Rollout    RolloutStrategy
+		// OverrideWindows run the script even if hosts are blocked by a maintenance window
+		OverrideWindows bool
+		// Reason why the script is being run, used if the script requires approval
+		Reason string
+	}
+
+	r := jobParams{}
+	if err := request.DecodeJSON(&r); err != nil {
+		return nil, nil, err
+	}
+
+	script := ScriptCache.ByID(r.ScriptID)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", r.ScriptID)
+	}
+
+	if session.User().Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Run script %s", script.ID))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	// Scripts that require approval are not run, instead a request is made for another user to approve
+	if script.RequiresApproval {
+		approval, err := ApprovalStore.NewRequest(newApprovalRequestParameters{
+			ScriptID:        script.ID,
+			HostIDs:         r.HostIDs,
+			GroupIDs:        r.GroupIDs,
+			Selector:        r.Selector,
+			ExcludeHostIDs:  r.ExcludeHostIDs,
+			ExcludeGroupIDs: r.ExcludeGroupIDs,
+			ExcludeSelector: r.ExcludeSelector,
+			Parameters:      r.Parameters,
+			Execution:       r.Execution,
+			Rollout:         r.Rollout,
+			OverrideWindows: r.OverrideWindows,
+			Reason:          r.Reason,
+		}, session.User())
+		if err != nil {
+			if err.Server {
+				return nil, nil, web.CommonErrors.ServerError
+			}
+			return nil, nil, web.ValidationError(err.Message)
+		}
+		return approval, nil, nil
+	}
+
+	script, perr := script.WithParameters(r.Parameters)
+	if perr != nil {
+		return nil, nil, web.ValidationError(perr.Error())
+	}
+	if err := r.Execution.Validate(); err != nil {
+		return nil, nil, web.ValidationError(err.Error())
+	}
+	if err := r.Rollout.Validate(); err != nil {
+		return nil, nil, web.ValidationError(err.Error())
+	}
+
+	hosts, err := resolveHosts(r.ScheduleScope, "")
+	if err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+	if err := checkMaintenanceWindows(hosts, r.OverrideWindows, session.User(), fmt.Sprintf("Run script %s", script.ID)); err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return jobStore.StartJob(script, hosts, r.Execution, r.Rollout, session.Username), nil, nil
+}
+
+func (h *handle) JobGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	if session.User().Permissions.ScriptRunLevel < ScriptRunLevelReadOnly {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Get job %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	job := jobStore.Get(id)
+	if job == nil {
+		return nil, nil, web.ValidationError("No job with ID %s", id)
+	}
+
+	return job, nil, nil
+}
+
+func (h *handle) JobCancel(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	job := jobStore.Get(id)
+	if job == nil {
+		return nil, nil, web.ValidationError("No job with ID %s", id)
+	}
+
+	if session.User().Permissions.ScriptRunLevel < job.script.RunLevel {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Cancel job %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	if err := jobStore.Cancel(id); err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return true, nil, nil
+}
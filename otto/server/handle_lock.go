@@ -0,0 +1,9 @@
+package server
+
+import (
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) LockList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	return lockManager.All(), nil, nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) MaintenanceWindowList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	windows := MaintenanceWindowStore.AllWindows()
+	sort.Slice(windows, func(i int, j int) bool {
+		return windows[i].Name < windows[j].Name
+	})
+
+	return windows, nil, nil
+}
+
+func (h *handle) MaintenanceWindowGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+
+	window := MaintenanceWindowStore.WindowWithID(id)
+	if window == nil {
+		return nil, nil, web.ValidationError("No maintenance window with ID %s", id)
+	}
+
+	return window, nil, nil
+}
+
+func (h *handle) MaintenanceWindowNew(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanModifySchedules {
+		EventStore.UserPermissionDenied(session.User().Username, "Create new maintenance window")
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	params := newMaintenanceWindowParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	window, err := MaintenanceWindowStore.NewWindow(params)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.MaintenanceWindowAdded(window, session.Username)
+
+	return window, nil, nil
+}
+
+func (h *handle) MaintenanceWindowEdit(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if !session.User().Permissions.CanModifySchedules {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Modify maintenance window %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	window := MaintenanceWindowStore.WindowWithID(id)
+	if window == nil {
+		return nil, nil, web.ValidationError("No maintenance window with ID %s", id)
+	}
+
+	params := editMaintenanceWindowParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	window, err := MaintenanceWindowStore.EditWindow(window, params)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.MaintenanceWindowModified(window, session.Username)
+
+	return window, nil, nil
+}
+
+func (h *handle) MaintenanceWindowDelete(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if !session.User().Permissions.CanModifySchedules {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Delete maintenance window %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	window := MaintenanceWindowStore.WindowWithID(id)
+	if window == nil {
+		return nil, nil, web.ValidationError("No maintenance window with ID %s", id)
+	}
+
+	if err := MaintenanceWindowStore.DeleteWindow(window); err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.MaintenanceWindowDeleted(window, session.Username)
+
+	return true, nil, nil
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) ScriptPlan(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	type planParams struct {
+		ScheduleScope
+		Parameters map[string]string
+	}
+
+	params := planParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	if session.User().Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(session.Username, fmt.Sprintf("Plan script %s", script.Name))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	script, perr := script.WithParameters(params.Parameters)
+	if perr != nil {
+		return nil, nil, web.ValidationError(perr.Error())
+	}
+
+	hostPointers, err := resolveHosts(params.ScheduleScope, "")
+	if err != nil {
+		return nil, nil, web.ValidationError(err.Message)
+	}
+	hosts := make([]Host, len(hostPointers))
+	for i, host := range hostPointers {
+		hosts[i] = *host
+	}
+
+	// Secret values are only shown to users who can modify them
+	plan, err := PlanScript(script, hosts, !session.User().Permissions.CanModifyHosts)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return plan, nil, nil
+}
+
+func (h *handle) SchedulePlan(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	schedule := ScheduleCache.ByID(id)
+	if schedule == nil {
+		return nil, nil, web.ValidationError("No schedule with ID %s", id)
+	}
+
+	if script := ScriptCache.ByID(schedule.ScriptID); script != nil && session.User().Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(session.Username, fmt.Sprintf("Plan schedule %s", schedule.Name))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	// Secret values are only shown to users who can modify them
+	plan, err := schedule.Plan(!session.User().Permissions.CanModifyHosts)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return plan, nil, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 
 	"github.com/ecnepsnai/otto/shared/otto"
 	"github.com/ecnepsnai/secutil"
@@ -22,7 +23,7 @@ func (v *view) Register(request web.Request) web.HTTPResponse {
 		}
 	}
 
-	if requestProtocolVersion := request.HTTP.Header.Get("X-OTTO-PROTO-VERSION"); fmt.Sprintf("%d", otto.ProtocolVersion) != requestProtocolVersion {
+	if requestProtocolVersion := request.HTTP.Header.Get("X-OTTO-PROTO-VERSION"); !isSupportedProtocolVersion(requestProtocolVersion) {
 		log.PWarn("Rejected registration request", map[string]interface{}{
 			"remote_addr":            request.HTTP.RemoteAddr,
 			"reason":                 "unsupported otto protocol version",
@@ -117,9 +118,14 @@ func (v *view) Register(request web.Request) web.HTTPResponse {
 			continue
 		}
 
+		// The first matching rule is used unless a later rule is for a descendant of its group, so that a rule for a
+		// more specific group takes precedence
+		if matchedRule != nil && !sliceContains(matchedRule.GroupID, GroupCache.Ancestors(rule.GroupID)) {
+			continue
+		}
+
 		groupID = rule.GroupID
 		matchedRule = &rule
-		break
 	}
 
 	// Use the address that sent this request as the address for the new host, but first strip the port
@@ -185,3 +191,12 @@ func (v *view) Register(request web.Request) web.HTTPResponse {
 		},
 	}
 }
+
+// isSupportedProtocolVersion returns true if the given protocol version from an agent is supported by this server
+func isSupportedProtocolVersion(version string) bool {
+	v, err := strconv.ParseUint(version, 10, 32)
+	if err != nil {
+		return false
+	}
+	return uint32(v) >= otto.MinimumProtocolVersion && uint32(v) <= otto.ProtocolVersion
+}
@@ -11,9 +11,12 @@ func (h *handle) RequestNew(request web.Request) (interface{}, *web.APIResponse,
 	session := request.UserData.(*Session)
 
 	type requestParams struct {
-		HostID   string
-		Action   string
-		ScriptID string
+		HostID     string
+		Action     string
+		ScriptID   string
+		Parameters map[string]string
+		// OverrideWindows run the script even if the host is blocked by a maintenance window
+		OverrideWindows bool
 	}
 
 	r := requestParams{}
@@ -40,13 +43,32 @@ func (h *handle) RequestNew(request web.Request) (interface{}, *web.APIResponse,
 		if script == nil {
 			return nil, nil, web.ValidationError("No script with ID %s", r.ScriptID)
 		}
+		if script.RequiresApproval {
+			return nil, nil, web.ValidationError(requiresApprovalError(script))
+		}
+		script, perr := script.WithParameters(r.Parameters)
+		if perr != nil {
+			return nil, nil, web.ValidationError(perr.Error())
+		}
+		if err := checkMaintenanceWindows([]*Host{host}, r.OverrideWindows, session.User(), fmt.Sprintf("Run script %s", script.ID)); err != nil {
+			return nil, nil, web.ValidationError(err.Message)
+		}
 
+		start := time.Now()
 		result, err := host.RunScript(script, nil)
+		ScriptRunStore.NewRun(newScriptRunParameters{
+			Script:      script,
+			Host:        host,
+			TriggeredBy: session.Username,
+			Start:       start,
+			Result:      result,
+			Error:       err,
+		})
 		if err != nil {
 			return nil, nil, web.CommonErrors.ServerError
 		}
 
-		EventStore.ScriptRun(script, host, &result.Result, nil, session.Username)
+		EventStore.ScriptRun(script, host, result, nil, session.Username)
 
 		return result, nil, nil
 	}
@@ -98,9 +120,12 @@ func (h handle) RequestStream(request web.Request, conn *web.WSConn) {
 	defer conn.Close()
 
 	type requestParams struct {
-		HostID   string
-		Action   string
-		ScriptID string
+		HostID     string
+		Action     string
+		ScriptID   string
+		Parameters map[string]string
+		// OverrideWindows run the script even if the host is blocked by a maintenance window
+		OverrideWindows bool
 	}
 	type requestResponse struct {
 		Code   int           `json:"Code,omitempty"`
@@ -173,6 +198,30 @@ func (h handle) RequestStream(request web.Request, conn *web.WSConn) {
 			return
 		}
 
+		if script.RequiresApproval {
+			writeMessage(requestResponse{
+				Code:  RequestResponseCodeError,
+				Error: requiresApprovalError(script),
+			})
+			return
+		}
+
+		script, perr := script.WithParameters(r.Parameters)
+		if perr != nil {
+			writeMessage(requestResponse{
+				Code:  RequestResponseCodeError,
+				Error: perr.Error(),
+			})
+			return
+		}
+		if err := checkMaintenanceWindows([]*Host{host}, r.OverrideWindows, session.User(), fmt.Sprintf("Run script %s", script.ID)); err != nil {
+			writeMessage(requestResponse{
+				Code:  RequestResponseCodeError,
+				Error: err.Message,
+			})
+			return
+		}
+
 		running := true
 		go func() {
 			lastKA := time.Now().AddDate(0, 0, -1)
@@ -187,6 +236,7 @@ func (h handle) RequestStream(request web.Request, conn *web.WSConn) {
 			}
 		}()
 
+		start := time.Now()
 		result, err := host.RunScript(script, func(stdout, stderr []byte) {
 			writeMessage(requestResponse{
 				Code:   RequestResponseCodeOutput,
@@ -194,6 +244,14 @@ func (h handle) RequestStream(request web.Request, conn *web.WSConn) {
 				Stderr: string(stderr),
 			})
 		})
+		ScriptRunStore.NewRun(newScriptRunParameters{
+			Script:      script,
+			Host:        host,
+			TriggeredBy: session.Username,
+			Start:       start,
+			Result:      result,
+			Error:       err,
+		})
 		if err != nil {
 			writeMessage(requestResponse{
 				Code:  RequestResponseCodeError,
@@ -203,7 +261,7 @@ func (h handle) RequestStream(request web.Request, conn *web.WSConn) {
 			return
 		}
 
-		EventStore.ScriptRun(script, host, &result.Result, nil, session.Username)
+		EventStore.ScriptRun(script, host, result, nil, session.Username)
 		writeMessage(requestResponse{
 			Code:   RequestResponseCodeFinished,
 			Result: result,
@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/ecnepsnai/web"
 )
@@ -12,6 +13,9 @@ func (h *handle) ScheduleList(request web.Request) (interface{}, *web.APIRespons
 	sort.Slice(schedules, func(i int, j int) bool {
 		return schedules[i].Name < schedules[j].Name
 	})
+	for i := range schedules {
+		schedules[i].hideSecrets()
+	}
 
 	return schedules, nil, nil
 }
@@ -23,6 +27,7 @@ func (h *handle) ScheduleGet(request web.Request) (interface{}, *web.APIResponse
 	if schedule == nil {
 		return nil, nil, web.ValidationError("No schedule with ID %s", id)
 	}
+	schedule.hideSecrets()
 
 	return schedule, nil, nil
 }
@@ -85,6 +90,9 @@ func (h *handle) ScheduleGetScript(request web.Request) (interface{}, *web.APIRe
 	}
 
 	script := ScriptStore.ScriptWithID(schedule.ScriptID)
+	if script != nil {
+		script.hideSecrets()
+	}
 	return script, nil, nil
 }
 
@@ -110,6 +118,7 @@ func (h *handle) ScheduleNew(request web.Request) (interface{}, *web.APIResponse
 	}
 
 	EventStore.ScheduleAdded(schedule, session.Username)
+	schedule.hideSecrets()
 
 	return schedule, nil, nil
 }
@@ -142,6 +151,7 @@ func (h *handle) ScheduleEdit(request web.Request) (interface{}, *web.APIRespons
 	}
 
 	EventStore.ScheduleModified(schedule, session.Username)
+	schedule.hideSecrets()
 
 	return schedule, nil, nil
 }
@@ -171,3 +181,32 @@ func (h *handle) ScheduleDelete(request web.Request) (interface{}, *web.APIRespo
 
 	return true, nil, nil
 }
+
+func (h *handle) ScheduleNextRuns(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	type nextRunsParams struct {
+		Pattern  string
+		Timezone string
+		Count    int
+	}
+
+	params := nextRunsParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+	if params.Count <= 0 {
+		params.Count = 5
+	}
+	if params.Count > maxNextRuns {
+		return nil, nil, web.ValidationError("Count must be at most %d", maxNextRuns)
+	}
+
+	runs, err := nextRuns(params.Pattern, params.Timezone, time.Now(), params.Count)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return runs, nil, nil
+}
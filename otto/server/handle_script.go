@@ -17,12 +17,8 @@ func (h *handle) ScriptList(request web.Request) (interface{}, *web.APIResponse,
 
 	// Hide secret environment variables if the user cannot modify them
 	if !session.User().Permissions.CanModifyHosts {
-		for i, script := range scripts {
-			for y, env := range script.Environment {
-				if env.Secret {
-					scripts[i].Environment[y].Value = ""
-				}
-			}
+		for i := range scripts {
+			scripts[i].hideSecrets()
 		}
 	}
 
@@ -40,11 +36,7 @@ func (h *handle) ScriptGet(request web.Request) (interface{}, *web.APIResponse,
 
 	// Hide secret environment variables if the user cannot modify them
 	if !session.User().Permissions.CanModifyHosts {
-		for i, env := range script.Environment {
-			if env.Secret {
-				script.Environment[i].Value = ""
-			}
-		}
+		script.hideSecrets()
 	}
 
 	return script, nil, nil
@@ -87,6 +79,9 @@ func (h *handle) ScriptGetSchedules(request web.Request) (interface{}, *web.APIR
 	sort.Slice(schedules, func(i int, j int) bool {
 		return schedules[i].Name < schedules[j].Name
 	})
+	for i := range schedules {
+		schedules[i].hideSecrets()
+	}
 
 	return schedules, nil, nil
 }
@@ -158,6 +153,7 @@ func (h *handle) ScriptNew(request web.Request) (interface{}, *web.APIResponse,
 	if err := request.DecodeJSON(&params); err != nil {
 		return nil, nil, err
 	}
+	params.Author = session.Username
 
 	script, err := ScriptStore.NewScript(params)
 	if err != nil {
@@ -190,6 +186,7 @@ func (h *handle) ScriptEdit(request web.Request) (interface{}, *web.APIResponse,
 	if err := request.DecodeJSON(&params); err != nil {
 		return nil, nil, err
 	}
+	params.Author = session.Username
 
 	script, err := ScriptStore.EditScript(script, params)
 	if err != nil {
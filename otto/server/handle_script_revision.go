@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ecnepsnai/web"
+)
+
+// scriptRevisionFromRequest returns the revision of the script named by the given parameter of the request
+func scriptRevisionFromRequest(request web.Request, script *Script, value string) (*ScriptRevision, *web.Error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, web.ValidationError("Invalid revision %s", value)
+	}
+	revision := ScriptRevisionStore.RevisionForScript(script.ID, number)
+	if revision == nil {
+		return nil, web.ValidationError("No revision %d for script %s", number, script.ID)
+	}
+
+	// Hide secret environment variables if the user cannot modify them
+	session := request.UserData.(*Session)
+	if !session.User().Permissions.CanModifyHosts {
+		revision.Script.hideSecrets()
+	}
+
+	return revision, nil
+}
+
+func (h *handle) ScriptRevisionList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	revisions := ScriptRevisionStore.RevisionsForScript(script.ID)
+
+	// Hide secret environment variables if the user cannot modify them
+	if !session.User().Permissions.CanModifyHosts {
+		for i := range revisions {
+			revisions[i].Script.hideSecrets()
+		}
+	}
+
+	return revisions, nil, nil
+}
+
+func (h *handle) ScriptRevisionGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	revision, werr := scriptRevisionFromRequest(request, script, request.Parameters["revision"])
+	if werr != nil {
+		return nil, nil, werr
+	}
+
+	return revision, nil, nil
+}
+
+func (h *handle) ScriptRevisionDiff(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	query := request.HTTP.URL.Query()
+	from, werr := scriptRevisionFromRequest(request, script, query.Get("from"))
+	if werr != nil {
+		return nil, nil, werr
+	}
+	toStr := query.Get("to")
+	if toStr == "" {
+		toStr = fmt.Sprintf("%d", script.Revision)
+	}
+	to, werr := scriptRevisionFromRequest(request, script, toStr)
+	if werr != nil {
+		return nil, nil, werr
+	}
+
+	return DiffScriptRevisions(*from, *to), nil, nil
+}
+
+func (h *handle) ScriptRevisionRollback(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if !session.User().Permissions.CanModifyScripts {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Roll back script %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	number, err := strconv.Atoi(request.Parameters["revision"])
+	if err != nil {
+		return nil, nil, web.ValidationError("Invalid revision %s", request.Parameters["revision"])
+	}
+	// The revision is loaded directly so that secret values are never hidden from the rolled back script
+	revision := ScriptRevisionStore.RevisionForScript(script.ID, number)
+	if revision == nil {
+		return nil, nil, web.ValidationError("No revision %d for script %s", number, script.ID)
+	}
+
+	script, rerr := ScriptStore.RollbackScript(script, *revision, session.Username)
+	if rerr != nil {
+		if rerr.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(rerr.Message)
+	}
+
+	EventStore.ScriptModified(script, session.Username)
+
+	return script, nil, nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) RunList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	if session.User().Permissions.ScriptRunLevel < ScriptRunLevelReadOnly {
+		EventStore.UserPermissionDenied(session.User().Username, "List script runs")
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	query := request.HTTP.URL.Query()
+
+	var runs []ScriptRun
+	if scriptID := query.Get("script_id"); scriptID != "" {
+		runs = ScriptRunStore.RunsForScript(scriptID)
+	} else if hostID := query.Get("host_id"); hostID != "" {
+		runs = ScriptRunStore.RunsForHost(hostID)
+	} else if scheduleID := query.Get("schedule_id"); scheduleID != "" {
+		runs = ScriptRunStore.RunsForSchedule(scheduleID)
+	} else {
+		runs = ScriptRunStore.AllRuns()
+	}
+
+	if countStr := query.Get("c"); countStr != "" {
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 0 {
+			return nil, nil, web.ValidationError("Invalid count")
+		}
+		if count < len(runs) {
+			runs = runs[:count]
+		}
+	}
+
+	return runs, nil, nil
+}
+
+func (h *handle) RunGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	if session.User().Permissions.ScriptRunLevel < ScriptRunLevelReadOnly {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Get script run %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	run := ScriptRunStore.RunWithID(id)
+	if run == nil {
+		return nil, nil, web.ValidationError("No script run with ID %s", id)
+	}
+
+	output, err := run.Output()
+	if err != nil {
+		log.PError("Error reading script run output", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+		return nil, nil, web.CommonErrors.ServerError
+	}
+
+	type runDetails struct {
+		ScriptRun
+		Output ScriptOutput
+	}
+
+	return runDetails{
+		ScriptRun: *run,
+		Output:    *output,
+	}, nil, nil
+}
+
+func (v *view) RunDownload(request web.Request) (response web.HTTPResponse) {
+	id := request.Parameters["id"]
+	session := request.UserData.(*Session)
+
+	if session.User().Permissions.ScriptRunLevel < ScriptRunLevelReadOnly {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Download script run %s", id))
+		response.Status = 403
+		return
+	}
+
+	run := ScriptRunStore.RunWithID(id)
+	if run == nil {
+		response.Status = 404
+		return
+	}
+
+	filePath := run.StdoutPath()
+	stream := request.HTTP.URL.Query().Get("stream")
+	switch stream {
+	case "", "stdout":
+		stream = "stdout"
+	case "stderr":
+		filePath = run.StderrPath()
+	default:
+		response.Status = 400
+		return
+	}
+
+	f, err := os.OpenFile(filePath, os.O_RDONLY, 0644)
+	if err != nil {
+		log.PError("Error opening script run output", map[string]interface{}{
+			"run_id": run.ID,
+			"stream": stream,
+			"error":  err.Error(),
+		})
+		response.Status = 500
+		return
+	}
+	response.ContentType = "text/plain"
+	response.Headers = map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s_%s.txt\"", run.ID, stream),
+	}
+	response.Reader = f
+	return
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) ScriptPreview(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	type previewParams struct {
+		HostID     string
+		Parameters map[string]string
+	}
+
+	params := previewParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	script := ScriptStore.ScriptWithID(id)
+	if script == nil {
+		return nil, nil, web.ValidationError("No script with ID %s", id)
+	}
+
+	if session.User().Permissions.ScriptRunLevel < script.RunLevel {
+		EventStore.UserPermissionDenied(session.Username, fmt.Sprintf("Preview script %s", script.Name))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	host := HostCache.ByID(params.HostID)
+	if host == nil {
+		return nil, nil, web.ValidationError("No host with ID %s", params.HostID)
+	}
+
+	script, perr := script.WithParameters(params.Parameters)
+	if perr != nil {
+		return nil, nil, web.ValidationError(perr.Error())
+	}
+
+	// Secret values are only shown to users who can modify them
+	preview, err := host.PreviewScript(script, !session.User().Permissions.CanModifyHosts)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return preview, nil, nil
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ecnepsnai/web"
+)
+
+func (h *handle) WorkflowList(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	workflows := []Workflow{}
+	for _, workflow := range WorkflowStore.AllWorkflows() {
+		if !workflow.canView(session.User()) {
+			continue
+		}
+		workflow.hideSecrets()
+		workflows = append(workflows, workflow)
+	}
+	sort.Slice(workflows, func(i int, j int) bool {
+		return workflows[i].Name < workflows[j].Name
+	})
+
+	return workflows, nil, nil
+}
+
+// viewableWorkflow returns the workflow with the given ID if the user of the session can view it
+func viewableWorkflow(session *Session, id string) (*Workflow, *web.Error) {
+	workflow := WorkflowStore.WorkflowWithID(id)
+	if workflow == nil {
+		return nil, web.ValidationError("No workflow with ID %s", id)
+	}
+	if !workflow.canView(session.User()) {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("View workflow %s", id))
+		return nil, web.ValidationError("Permission denied")
+	}
+	return workflow, nil
+}
+
+func (h *handle) WorkflowGet(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	workflow, werr := viewableWorkflow(session, id)
+	if werr != nil {
+		return nil, nil, werr
+	}
+	workflow.hideSecrets()
+
+	return workflow, nil, nil
+}
+
+func (h *handle) WorkflowGetReports(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if _, werr := viewableWorkflow(session, id); werr != nil {
+		return nil, nil, werr
+	}
+
+	return WorkflowReportStore.GetReportsForWorkflow(id), nil, nil
+}
+
+func (h *handle) WorkflowGetReport(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+	reportID := request.Parameters["report_id"]
+
+	if _, werr := viewableWorkflow(session, id); werr != nil {
+		return nil, nil, werr
+	}
+
+	report := WorkflowReportStore.ReportWithID(reportID)
+	if report == nil || report.WorkflowID != id {
+		return nil, nil, web.ValidationError("No report with ID %s", reportID)
+	}
+
+	return report, nil, nil
+}
+
+func (h *handle) WorkflowNew(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+
+	if !session.User().Permissions.CanModifyScripts {
+		EventStore.UserPermissionDenied(session.User().Username, "Create new workflow")
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	params := newWorkflowParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	workflow, err := WorkflowStore.NewWorkflow(params)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.WorkflowAdded(workflow, session.Username)
+	workflow.hideSecrets()
+
+	return workflow, nil, nil
+}
+
+func (h *handle) WorkflowEdit(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if !session.User().Permissions.CanModifyScripts {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Modify workflow %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	workflow := WorkflowStore.WorkflowWithID(id)
+	if workflow == nil {
+		return nil, nil, web.ValidationError("No workflow with ID %s", id)
+	}
+
+	params := editWorkflowParameters{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	workflow, err := WorkflowStore.EditWorkflow(workflow, params)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.WorkflowModified(workflow, session.Username)
+	workflow.hideSecrets()
+
+	return workflow, nil, nil
+}
+
+func (h *handle) WorkflowDelete(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	if !session.User().Permissions.CanModifyScripts {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Delete workflow %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	workflow := WorkflowStore.WorkflowWithID(id)
+	if workflow == nil {
+		return nil, nil, web.ValidationError("No workflow with ID %s", id)
+	}
+
+	if err := WorkflowStore.DeleteWorkflow(workflow); err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	EventStore.WorkflowDeleted(workflow, session.Username)
+
+	return true, nil, nil
+}
+
+func (h *handle) WorkflowRun(request web.Request) (interface{}, *web.APIResponse, *web.Error) {
+	session := request.UserData.(*Session)
+	id := request.Parameters["id"]
+
+	type runParams struct {
+		Scope ScheduleScope
+	}
+
+	params := runParams{}
+	if err := request.DecodeJSON(&params); err != nil {
+		return nil, nil, err
+	}
+
+	workflow := WorkflowStore.WorkflowWithID(id)
+	if workflow == nil {
+		return nil, nil, web.ValidationError("No workflow with ID %s", id)
+	}
+
+	if session.User().Permissions.ScriptRunLevel < workflow.RunLevel() {
+		EventStore.UserPermissionDenied(session.User().Username, fmt.Sprintf("Run workflow %s", id))
+		return nil, nil, web.ValidationError("Permission denied")
+	}
+
+	for _, step := range workflow.Steps {
+		if script := ScriptCache.ByID(step.ScriptID); script != nil && script.RequiresApproval {
+			return nil, nil, web.ValidationError(requiresApprovalError(script))
+		}
+	}
+
+	if err := params.Scope.validate(); err != nil {
+		return nil, nil, web.ValidationError(err.Error())
+	}
+
+	report, err := WorkflowStore.RunWorkflow(*workflow, params.Scope, nil, session.Username)
+	if err != nil {
+		if err.Server {
+			return nil, nil, web.CommonErrors.ServerError
+		}
+		return nil, nil, web.ValidationError(err.Message)
+	}
+
+	return report, nil, nil
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"maps"
 	"sync"
 	"time"
 
@@ -10,12 +11,13 @@ import (
 
 // Heartbeat describes a heartbeat to a host
 type Heartbeat struct {
-	Address     string
-	IsReachable bool
-	LastReply   time.Time
-	LastAttempt time.Time
-	Version     string
-	Properties  map[string]string
+	Address      string
+	IsReachable  bool
+	LastReply    time.Time
+	LastAttempt  time.Time
+	Version      string
+	Capabilities otto.Capabilities
+	Properties   map[string]string
 }
 
 type heartbeatStoreType struct {
@@ -60,25 +62,38 @@ func StartHeartbeatMonitor() {
 }
 
 func (s *hostStoreObject) PingAll() error {
-	hosts := s.AllHosts()
-	for _, h := range hosts {
-		go func(host Host) {
-			host.Ping()
-			host.RotateIdentityIfNeeded()
-		}(h)
+	allHosts := s.AllHosts()
+	hosts := make([]*Host, len(allHosts))
+	for i := range allHosts {
+		hosts[i] = &allHosts[i]
 	}
+	executeOnHosts(hosts, ExecutionOptions{}, func(i int, host *Host) {
+		host.Ping()
+		host.RotateIdentityIfNeeded()
+	})
 	return nil
 }
 
-func (s *heartbeatStoreType) RegisterHeartbeatReply(host *Host, reply otto.MessageHeartbeatResponse) (*Heartbeat, *Error) {
+func (s *heartbeatStoreType) RegisterHeartbeatReply(host *Host, reply otto.MessageHeartbeatResponse, capabilities otto.Capabilities) (*Heartbeat, *Error) {
 	heartbeat := Heartbeat{
-		Address:     host.Address,
-		IsReachable: true,
-		LastReply:   time.Now(),
-		LastAttempt: time.Now(),
-		Version:     reply.AgentVersion,
-		Properties:  reply.Properties,
+		Address:      host.Address,
+		IsReachable:  true,
+		LastReply:    time.Now(),
+		LastAttempt:  time.Now(),
+		Version:      reply.AgentVersion,
+		Capabilities: capabilities,
+		Properties:   reply.Properties,
 	}
+	propertiesChanged := true
+	defer func() {
+		// Group membership from selectors may depend on the properties of the host
+		if propertiesChanged && GroupCache.HasSelectors() {
+			GroupStore.Table.StartRead(func(tx ds.IReadTransaction) error {
+				GroupCache.Update(tx)
+				return nil
+			})
+		}
+	}()
 	s.Lock.Lock()
 	defer s.Lock.Unlock()
 
@@ -87,6 +102,7 @@ func (s *heartbeatStoreType) RegisterHeartbeatReply(host *Host, reply otto.Messa
 		if hb.IsReachable {
 			wasUnreachable = false
 		}
+		propertiesChanged = !maps.Equal(hb.Properties, heartbeat.Properties)
 	}
 
 	s.Heartbeats[host.Address] = heartbeat
@@ -8,14 +8,22 @@ import (
 
 // Host describes a otto host
 type Host struct {
-	ID          string `ds:"primary"`
-	Name        string `ds:"unique" min:"1" max:"140"`
-	Address     string `ds:"unique" min:"1"`
-	Port        uint32
-	Enabled     bool `ds:"index"`
-	Trust       HostTrust
-	GroupIDs    []string
-	Environment []environ.Variable
+	ID             string `ds:"primary"`
+	Name           string `ds:"unique" min:"1" max:"140"`
+	Address        string `ds:"unique" min:"1"`
+	Port           uint32
+	ConnectionMode string
+	Enabled        bool `ds:"index"`
+	Trust          HostTrust
+	GroupIDs       []string
+	Environment    []environ.Variable
+	// Labels key/value pairs that can be matched by a selector
+	Labels map[string]string
+}
+
+// IsReverse returns true if the agent on this host connects to the server
+func (h Host) IsReverse() bool {
+	return h.ConnectionMode == HostConnectionModeReverse
 }
 
 type HostTrust struct {
@@ -24,10 +32,26 @@ type HostTrust struct {
 	LastTrustUpdate   time.Time
 }
 
+// AllGroupIDs return the IDs of all groups for this host, including groups with a selector that matches the host and
+// the ancestors of each group. The ancestors of a group always come before it, so values from child groups can
+// replace those from their parents.
+func (h Host) AllGroupIDs() []string {
+	groupIDs := []string{}
+	for _, groupID := range append(append([]string{}, h.GroupIDs...), GroupCache.SelectedGroupIDs(h.ID)...) {
+		for _, id := range append(append([]string{}, GroupCache.Ancestors(groupID)...), groupID) {
+			if !sliceContains(id, groupIDs) {
+				groupIDs = append(groupIDs, id)
+			}
+		}
+	}
+	return groupIDs
+}
+
 // Groups return all groups for this host
 func (h Host) Groups() ([]Group, *Error) {
-	groups := make([]Group, len(h.GroupIDs))
-	for i, groupID := range h.GroupIDs {
+	groupIDs := h.AllGroupIDs()
+	groups := make([]Group, len(groupIDs))
+	for i, groupID := range groupIDs {
 		group := GroupCache.ByID(groupID)
 		groups[i] = *group
 	}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+// hostClientIdleTimeout is how long an unused client is kept open before it is closed
+const hostClientIdleTimeout = 1 * time.Minute
+
+type hostClient struct {
+	client *otto.Client
+	open   int
+	idle   *time.Timer
+	// persistent clients are reverse sessions opened by the agent, they are never closed when idle
+	persistent bool
+}
+
+type hostClientCacheType struct {
+	lock    *sync.Mutex
+	clients map[string]*hostClient
+}
+
+// hostClients holds open clients for hosts that support multiplexing, so that concurrent actions on the same host can
+// share a single session
+var hostClients = &hostClientCacheType{lock: &sync.Mutex{}, clients: map[string]*hostClient{}}
+
+// Open will open a connection for the action on the host, reusing an existing session if the host supports
+// multiplexing. Hosts using reverse connections must have an open session. Callers must call Release with the returned
+// client once they are finished with the connection.
+func (c *hostClientCacheType) Open(host *Host, actionID string) (*otto.Client, *otto.Connection, error) {
+	if client := c.acquire(host.ID); client != nil {
+		conn, err := client.Open(actionID)
+		if err == nil {
+			return client, conn, nil
+		}
+		if host.IsReverse() {
+			c.Discard(host.ID, client)
+			return nil, nil, err
+		}
+		// The existing session may have been closed by the agent, try again with a new session
+		log.PWarn("Error opening connection on existing session, reconnecting", map[string]interface{}{
+			"host_id": host.ID,
+			"error":   err.Error(),
+		})
+		c.Discard(host.ID, client)
+	}
+
+	if host.IsReverse() {
+		return nil, nil, fmt.Errorf("no reverse session for host")
+	}
+
+	client, err := host.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := client.Open(actionID)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	if !client.Multiplexed() {
+		return client, conn, nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.clients[host.ID]; !ok {
+		c.clients[host.ID] = &hostClient{client: client, open: 1}
+	}
+	return client, conn, nil
+}
+
+func (c *hostClientCacheType) acquire(hostID string) *otto.Client {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	existing, ok := c.clients[hostID]
+	if !ok {
+		return nil
+	}
+	existing.open++
+	if existing.idle != nil {
+		existing.idle.Stop()
+		existing.idle = nil
+	}
+	log.PDebug("Reusing existing client for host", map[string]interface{}{
+		"host_id": hostID,
+	})
+	return existing.client
+}
+
+// Release will release the client. Clients that are not cached are closed immediately, cached clients are closed once
+// they have been idle for hostClientIdleTimeout.
+func (c *hostClientCacheType) Release(hostID string, client *otto.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	existing, ok := c.clients[hostID]
+	if !ok || existing.client != client {
+		client.Close()
+		return
+	}
+
+	existing.open--
+	if existing.open > 0 || existing.persistent {
+		return
+	}
+	existing.idle = time.AfterFunc(hostClientIdleTimeout, func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if current, ok := c.clients[hostID]; ok && current == existing && current.open <= 0 {
+			delete(c.clients, hostID)
+			current.client.Close()
+		}
+	})
+}
+
+// Discard will remove the client from the cache and close it, any other open connections on the client will fail.
+func (c *hostClientCacheType) Discard(hostID string, client *otto.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if existing, ok := c.clients[hostID]; ok && existing.client == client {
+		delete(c.clients, hostID)
+	}
+	client.Close()
+}
+
+// Close will close any cached client for the host. Should be called whenever the hosts address or trust changes.
+func (c *hostClientCacheType) Close(hostID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	existing, ok := c.clients[hostID]
+	if !ok {
+		return
+	}
+	if existing.idle != nil {
+		existing.idle.Stop()
+	}
+	delete(c.clients, hostID)
+	existing.client.Close()
+}
+
+// Attach will add a reverse session opened by the agent on the host to the cache, replacing any existing client.
+func (c *hostClientCacheType) Attach(hostID string, client *otto.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if existing, ok := c.clients[hostID]; ok {
+		if existing.idle != nil {
+			existing.idle.Stop()
+		}
+		existing.client.Close()
+	}
+	c.clients[hostID] = &hostClient{client: client, persistent: true}
+}
+
+// Detach will remove the reverse session from the cache once it has closed. Returns false if the session had already
+// been replaced by another client.
+func (c *hostClientCacheType) Detach(hostID string, client *otto.Client) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	existing, ok := c.clients[hostID]
+	if ok && existing.client != client {
+		return false
+	}
+	delete(c.clients, hostID)
+	return true
+}
@@ -129,12 +129,14 @@ func (s *hostStoreObject) allHosts(tx ds.IReadTransaction) []Host {
 }
 
 type newHostParameters struct {
-	Name          string
-	Address       string
-	Port          uint32
-	AgentIdentity string
-	GroupIDs      []string
-	Environment   []environ.Variable
+	Name           string
+	Address        string
+	Port           uint32
+	AgentIdentity  string
+	ConnectionMode string
+	GroupIDs       []string
+	Environment    []environ.Variable
+	Labels         map[string]string
 }
 
 func (s *hostStoreObject) NewHost(params newHostParameters) (host *Host, err *Error) {
@@ -154,6 +156,16 @@ func (s *hostStoreObject) newHost(tx ds.IReadWriteTransaction, params newHostPar
 	if err := environ.Validate(params.Environment); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
+	if err := validateHostLabels(params.Labels); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if params.ConnectionMode == "" {
+		params.ConnectionMode = HostConnectionModeDirect
+	}
+	if !IsHostConnectionMode(params.ConnectionMode) {
+		return nil, ErrorUser("Unknown connection mode %s", params.ConnectionMode)
+	}
 
 	var groupIDs = make([]string, len(params.GroupIDs))
 	for i, groupID := range params.GroupIDs {
@@ -166,14 +178,16 @@ func (s *hostStoreObject) newHost(tx ds.IReadWriteTransaction, params newHostPar
 	}
 
 	host := Host{
-		ID:          newID(),
-		Name:        params.Name,
-		Address:     params.Address,
-		Port:        params.Port,
-		Trust:       HostTrust{},
-		Enabled:     true,
-		GroupIDs:    groupIDs,
-		Environment: params.Environment,
+		ID:             newID(),
+		Name:           params.Name,
+		Address:        params.Address,
+		Port:           params.Port,
+		ConnectionMode: params.ConnectionMode,
+		Trust:          HostTrust{},
+		Enabled:        true,
+		GroupIDs:       groupIDs,
+		Environment:    params.Environment,
+		Labels:         params.Labels,
 	}
 	if err := limits.Check(host); err != nil {
 		return nil, ErrorUser(err.Error())
@@ -213,12 +227,14 @@ func (s *hostStoreObject) newHost(tx ds.IReadWriteTransaction, params newHostPar
 }
 
 type editHostParameters struct {
-	Name        string
-	Address     string
-	Port        uint32
-	Enabled     bool
-	GroupIDs    []string
-	Environment []environ.Variable
+	Name           string
+	Address        string
+	Port           uint32
+	ConnectionMode string
+	Enabled        bool
+	GroupIDs       []string
+	Environment    []environ.Variable
+	Labels         map[string]string
 }
 
 func (s *hostStoreObject) EditHost(host *Host, params editHostParameters) (newHost *Host, err *Error) {
@@ -239,6 +255,19 @@ func (s *hostStoreObject) editHost(tx ds.IReadWriteTransaction, host *Host, para
 	if err := environ.Validate(params.Environment); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
+	if err := validateHostLabels(params.Labels); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if params.ConnectionMode == "" {
+		params.ConnectionMode = host.ConnectionMode
+	}
+	if params.ConnectionMode == "" {
+		params.ConnectionMode = HostConnectionModeDirect
+	}
+	if !IsHostConnectionMode(params.ConnectionMode) {
+		return nil, ErrorUser("Unknown connection mode %s", params.ConnectionMode)
+	}
 
 	var groupIDs = make([]string, len(params.GroupIDs))
 	for i, groupID := range params.GroupIDs {
@@ -250,12 +279,18 @@ func (s *hostStoreObject) editHost(tx ds.IReadWriteTransaction, host *Host, para
 		groupIDs[i] = group.ID
 	}
 
+	if host.Address != params.Address || host.Port != params.Port || host.ConnectionMode != params.ConnectionMode {
+		hostClients.Close(host.ID)
+	}
+
 	host.Name = params.Name
 	host.Address = params.Address
 	host.Port = params.Port
+	host.ConnectionMode = params.ConnectionMode
 	host.Enabled = params.Enabled
 	host.GroupIDs = groupIDs
 	host.Environment = params.Environment
+	host.Labels = params.Labels
 	if err := limits.Check(host); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
@@ -290,6 +325,7 @@ func (s *hostStoreObject) UpdateHostTrust(hostID string, trust HostTrust) (rerr
 		}
 
 		log.Info("Updating host trust '%s'", host.Name)
+		hostClients.Close(host.ID)
 		HostCache.Update(tx)
 		GroupStore.Table.StartRead(func(groupTx ds.IReadTransaction) error {
 			GroupCache.Update(groupTx)
@@ -303,14 +339,19 @@ func (s *hostStoreObject) UpdateHostTrust(hostID string, trust HostTrust) (rerr
 func (s *hostStoreObject) DeleteHost(host *Host) (rerr *Error) {
 	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
 		for _, schedule := range ScheduleCache.All() {
-			if len(schedule.Scope.HostIDs) == 0 {
-				continue
-			}
-			if sliceContainsFold(host.ID, schedule.Scope.HostIDs) {
+			if schedule.Scope.references(host.ID) {
 				rerr = ErrorUser("Host belongs to schedule %s", schedule.Name)
 				return nil
 			}
 		}
+		if workflows := WorkflowStore.AllWorkflowsForScope(host.ID); len(workflows) > 0 {
+			rerr = ErrorUser("Host belongs to workflow %s", workflows[0].Name)
+			return nil
+		}
+		if windows := MaintenanceWindowStore.AllWindowsForScope(host.ID); len(windows) > 0 {
+			rerr = ErrorUser("Host belongs to maintenance window %s", windows[0].Name)
+			return nil
+		}
 
 		if err := tx.Delete(*host); err != nil {
 			log.Error("Error deleting host '%s': %s", host.Name, err.Error())
@@ -318,6 +359,7 @@ func (s *hostStoreObject) DeleteHost(host *Host) (rerr *Error) {
 			return nil
 		}
 
+		hostClients.Close(host.ID)
 		heartbeatStore.CleanupHeartbeats(tx)
 		HostCache.Update(tx)
 		GroupStore.Table.StartRead(func(groupTx ds.IReadTransaction) error {
@@ -2,6 +2,9 @@ package server
 
 import (
 	"testing"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/web"
 )
 
 func TestAddGetHost(t *testing.T) {
@@ -222,3 +225,68 @@ func TestHostRemoveWithSchedule(t *testing.T) {
 		t.Errorf("No error seen when trying to delete host associated with schedule")
 	}
 }
+
+func TestHostSaveFilePermission(t *testing.T) {
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	user, err := UserStore.NewUser(newUserParameters{
+		Username: randomString(6),
+		Password: randomString(6),
+		Permissions: UserPermissions{
+			ScriptRunLevel: ScriptRunLevelReadWrite,
+			CanModifyHosts: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making user: %s", err.Message)
+	}
+
+	session := SessionStore.NewSessionForUser(user)
+	h := handle{}
+	params := map[string]string{"Path": "/etc/shadow"}
+	request := func() web.MockRequestParameters {
+		return web.MockRequestParameters{UserData: &session, JSONBody: params, Parameters: map[string]string{"id": host.ID}}
+	}
+
+	// The script run level does not allow downloading files
+	_, _, werr := h.HostSaveFile(web.MockRequest(request()))
+	if werr == nil || werr.Message != "Permission denied" {
+		t.Fatalf("Permission denied error expected, got %v", werr)
+	}
+
+	if _, err := UserStore.EditUser(user, editUserParameters{
+		Permissions: UserPermissions{
+			CanModifyHosts:   true,
+			CanDownloadFiles: true,
+		},
+	}); err != nil {
+		t.Fatalf("Error editing user: %s", err.Message)
+	}
+
+	// The host is not reachable
+	_, _, werr = h.HostSaveFile(web.MockRequest(request()))
+	if werr == nil || werr.Message == "Permission denied" {
+		t.Fatalf("Error from unreachable host expected, got %v", werr)
+	}
+	recorded := false
+	EventStore.Table.StartRead(func(tx ds.IReadTransaction) error {
+		objects, _ := tx.GetIndex("Event", EventTypeHostFileDownloadFailed, nil)
+		for _, object := range objects {
+			event := object.(Event)
+			if event.Details["host_id"] == host.ID && event.Details["downloaded_by"] == user.Username {
+				recorded = true
+			}
+		}
+		return nil
+	})
+	if !recorded {
+		t.Errorf("Failed download should be recorded")
+	}
+}
@@ -0,0 +1,518 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// defaultAgentPort the port that the Otto agent listens on by default
+const defaultAgentPort = 12444
+
+// Inventory describes hosts and groups that are imported or exported in bulk
+type Inventory struct {
+	Groups []InventoryGroup
+	Hosts  []InventoryHost
+
+	// conflicts found while parsing the inventory
+	conflicts []string
+	// warnings about parts of the inventory that can't be imported
+	warnings []string
+}
+
+// InventoryGroup describes a group in an inventory
+type InventoryGroup struct {
+	Name string
+	// Parent the name of the parent group, if any
+	Parent      string
+	Environment []environ.Variable
+}
+
+// InventoryHost describes a host in an inventory
+type InventoryHost struct {
+	Name    string
+	Address string
+	Port    uint32
+	// Groups the names of the groups that the host is a member of
+	Groups      []string
+	Environment []environ.Variable
+}
+
+// InventoryImportResult describes the result of importing an inventory
+type InventoryImportResult struct {
+	// Groups the groups that were added, or would be added if only validating
+	Groups []InventoryGroup
+	// Hosts the hosts that were added, or would be added if only validating
+	Hosts []InventoryHost
+	// Conflicts problems that prevent the inventory from being imported
+	Conflicts []string
+	// Warnings parts of the inventory that are not imported
+	Warnings []string
+}
+
+// ParseInventory parses the data in the given format
+func ParseInventory(format string, data string) (*Inventory, *Error) {
+	var inventory *Inventory
+	var err error
+	switch format {
+	case InventoryFormatCSV:
+		inventory, err = parseInventoryCSV(data)
+	case InventoryFormatAnsibleINI:
+		inventory, err = parseInventoryINI(data)
+	case InventoryFormatAnsibleYAML:
+		inventory, err = parseInventoryYAML(data)
+	default:
+		return nil, ErrorUser("Unknown inventory format %s", format)
+	}
+	if err != nil {
+		return nil, ErrorUser("Invalid inventory: %s", err.Error())
+	}
+	return inventory, nil
+}
+
+// Format returns the inventory in the given format
+func (i Inventory) Format(format string) ([]byte, *Error) {
+	switch format {
+	case InventoryFormatCSV:
+		data, err := i.formatCSV()
+		if err != nil {
+			return nil, ErrorFrom(err)
+		}
+		return data, nil
+	case InventoryFormatAnsibleINI:
+		return i.formatINI(), nil
+	case InventoryFormatAnsibleYAML:
+		return i.formatYAML(), nil
+	}
+	return nil, ErrorUser("Unknown inventory format %s", format)
+}
+
+// ExportInventory returns the inventory of all hosts and groups. Hosts are only listed in the groups that they were
+// added to, not groups that they are members of through a selector or child group. If hideSecrets is true then the
+// values of secret environment variables are removed.
+func ExportInventory(hideSecrets bool) Inventory {
+	variables := func(vars []environ.Variable) []environ.Variable {
+		exported := make([]environ.Variable, len(vars))
+		for i, variable := range vars {
+			exported[i] = environ.New(variable.Key, variable.Value)
+			if hideSecrets && variable.Secret {
+				exported[i].Value = ""
+			}
+		}
+		return exported
+	}
+
+	inventory := Inventory{
+		Groups: []InventoryGroup{},
+		Hosts:  []InventoryHost{},
+	}
+	for _, group := range GroupCache.All() {
+		exported := InventoryGroup{
+			Name:        group.Name,
+			Environment: variables(group.Environment),
+		}
+		if parent := GroupCache.ByID(group.ParentID); parent != nil {
+			exported.Parent = parent.Name
+		}
+		inventory.Groups = append(inventory.Groups, exported)
+	}
+	for _, host := range HostCache.All() {
+		exported := InventoryHost{
+			Name:        host.Name,
+			Address:     host.Address,
+			Port:        host.Port,
+			Groups:      []string{},
+			Environment: variables(host.Environment),
+		}
+		for _, groupID := range host.GroupIDs {
+			if group := GroupCache.ByID(groupID); group != nil {
+				exported.Groups = append(exported.Groups, group.Name)
+			}
+		}
+		inventory.Hosts = append(inventory.Hosts, exported)
+	}
+
+	sort.Slice(inventory.Groups, func(i int, j int) bool {
+		return inventory.Groups[i].Name < inventory.Groups[j].Name
+	})
+	sort.Slice(inventory.Hosts, func(i int, j int) bool {
+		return inventory.Hosts[i].Name < inventory.Hosts[j].Name
+	})
+	return inventory
+}
+
+// parseInventoryPort parses a port number from an inventory
+func parseInventoryPort(value string) (uint32, error) {
+	port, err := strconv.ParseUint(strings.TrimSpace(value), 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	if port == 0 {
+		return 0, fmt.Errorf("invalid port 0")
+	}
+	return uint32(port), nil
+}
+
+// implicitGroupVariables marks that variables of an implicit Ansible group were ignored
+const implicitGroupVariables = ":implicit"
+
+// inventoryBuilder builds an inventory where groups and hosts may be listed more than once, such as an Ansible
+// inventory
+type inventoryBuilder struct {
+	inventory Inventory
+	groups    map[string]int
+	hosts     map[string]int
+	ignored   map[string]bool
+}
+
+func newInventoryBuilder() *inventoryBuilder {
+	return &inventoryBuilder{
+		inventory: Inventory{
+			Groups: []InventoryGroup{},
+			Hosts:  []InventoryHost{},
+		},
+		groups:  map[string]int{},
+		hosts:   map[string]int{},
+		ignored: map[string]bool{},
+	}
+}
+
+// group returns the group with the name, adding it if needed
+func (b *inventoryBuilder) group(name string) *InventoryGroup {
+	idx, ok := b.groups[name]
+	if !ok {
+		idx = len(b.inventory.Groups)
+		b.groups[name] = idx
+		b.inventory.Groups = append(b.inventory.Groups, InventoryGroup{Name: name, Environment: []environ.Variable{}})
+	}
+	return &b.inventory.Groups[idx]
+}
+
+// host returns the host with the name, adding it if needed
+func (b *inventoryBuilder) host(name string) *InventoryHost {
+	idx, ok := b.hosts[name]
+	if !ok {
+		idx = len(b.inventory.Hosts)
+		b.hosts[name] = idx
+		b.inventory.Hosts = append(b.inventory.Hosts, InventoryHost{
+			Name:        name,
+			Address:     name,
+			Port:        defaultAgentPort,
+			Groups:      []string{},
+			Environment: []environ.Variable{},
+		})
+	}
+	return &b.inventory.Hosts[idx]
+}
+
+// addHostToGroup adds the host to the group, the group "all" is implied and not added
+func (b *inventoryBuilder) addHostToGroup(hostName, groupName string) {
+	host := b.host(hostName)
+	if isAnsibleImplicitGroup(groupName) {
+		return
+	}
+	b.group(groupName)
+	if !sliceContains(groupName, host.Groups) {
+		host.Groups = append(host.Groups, groupName)
+	}
+}
+
+// setParent sets the parent of the group. Groups can only have one parent.
+func (b *inventoryBuilder) setParent(childName, parentName string) {
+	if isAnsibleImplicitGroup(childName) {
+		return
+	}
+	b.group(childName)
+	if isAnsibleImplicitGroup(parentName) {
+		return
+	}
+	b.group(parentName)
+	child := b.group(childName)
+	if child.Parent != "" && child.Parent != parentName {
+		b.inventory.conflicts = append(b.inventory.conflicts, fmt.Sprintf("Group %s has more than one parent group", childName))
+		return
+	}
+	child.Parent = parentName
+}
+
+// setHostVariable sets a variable from the inventory on the host. Ansible connection variables are ignored, except for
+// the address and port.
+func (b *inventoryBuilder) setHostVariable(hostName, key, value string) {
+	host := b.host(hostName)
+	switch key {
+	case "ansible_host":
+		host.Address = value
+		return
+	case "otto_port":
+		port, err := parseInventoryPort(value)
+		if err != nil {
+			b.inventory.conflicts = append(b.inventory.conflicts, fmt.Sprintf("Host %s has invalid port %s", hostName, value))
+			return
+		}
+		host.Port = port
+		return
+	}
+	if b.ignore(key) {
+		return
+	}
+	host.Environment = environ.Merge(host.Environment, []environ.Variable{environ.New(key, value)})
+}
+
+// setGroupVariable sets a variable from the inventory on the group. Variables of the "all" and "ungrouped" groups are
+// not imported.
+func (b *inventoryBuilder) setGroupVariable(groupName, key, value string) {
+	if isAnsibleImplicitGroup(groupName) {
+		b.ignored[implicitGroupVariables] = true
+		return
+	}
+	if b.ignore(key) {
+		return
+	}
+	group := b.group(groupName)
+	group.Environment = environ.Merge(group.Environment, []environ.Variable{environ.New(key, value)})
+}
+
+// ignore returns true if the variable is an Ansible or Otto variable that is not imported as an environment variable
+func (b *inventoryBuilder) ignore(key string) bool {
+	if strings.HasPrefix(key, "ansible_") || strings.HasPrefix(key, "otto_") {
+		b.ignored[key] = true
+		return true
+	}
+	return false
+}
+
+// finish returns the built inventory
+func (b *inventoryBuilder) finish() *Inventory {
+	if b.ignored[implicitGroupVariables] {
+		delete(b.ignored, implicitGroupVariables)
+		b.inventory.warnings = append(b.inventory.warnings, "Variables of the all and ungrouped groups are not imported, set them as global environment variables instead")
+	}
+	if len(b.ignored) > 0 {
+		keys := []string{}
+		for key := range b.ignored {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.inventory.warnings = append(b.inventory.warnings, fmt.Sprintf("Ignored variables: %s", strings.Join(keys, ", ")))
+	}
+	return &b.inventory
+}
+
+// validate checks the inventory against itself and the existing hosts and groups. Groups that already exist are
+// reused, everything else is added.
+func (i Inventory) validate() *InventoryImportResult {
+	result := &InventoryImportResult{
+		Groups:    []InventoryGroup{},
+		Hosts:     []InventoryHost{},
+		Conflicts: append([]string{}, i.conflicts...),
+		Warnings:  append([]string{}, i.warnings...),
+	}
+	conflict := func(format string, a ...interface{}) {
+		result.Conflicts = append(result.Conflicts, fmt.Sprintf(format, a...))
+	}
+
+	groups := map[string]InventoryGroup{}
+	for _, group := range i.Groups {
+		if _, duplicate := groups[group.Name]; duplicate {
+			conflict("Group %s is listed more than once", group.Name)
+			continue
+		}
+		groups[group.Name] = group
+	}
+
+	for _, group := range i.Groups {
+		if group.Name == "" {
+			conflict("Group with no name")
+			continue
+		}
+		if err := environ.Validate(group.Environment); err != nil {
+			conflict("Group %s has an invalid environment variable: %s", group.Name, err.Error())
+		}
+
+		if group.Parent != "" {
+			_, inInventory := groups[group.Parent]
+			if !inInventory && GroupCache.ByName(group.Parent) == nil {
+				conflict("Group %s has unknown parent group %s", group.Name, group.Parent)
+			}
+		}
+		if i.groupHasCycle(groups, group.Name) {
+			conflict("Group %s is a child of itself or of one of its children", group.Name)
+		}
+
+		existing := GroupCache.ByName(group.Name)
+		if existing == nil {
+			result.Groups = append(result.Groups, group)
+			continue
+		}
+		existingParent := ""
+		if parent := GroupCache.ByID(existing.ParentID); parent != nil {
+			existingParent = parent.Name
+		}
+		if (group.Parent != "" && group.Parent != existingParent) || !environmentContains(existing.Environment, group.Environment) {
+			conflict("Group %s already exists with a different parent group or environment variables", group.Name)
+		}
+	}
+
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	HostStore.Table.StartRead(func(tx ds.IReadTransaction) error {
+		for _, host := range i.Hosts {
+			if host.Name == "" || host.Address == "" {
+				conflict("Host %s must have a name and an address", host.Name)
+				continue
+			}
+			if names[host.Name] {
+				conflict("Host %s is listed more than once", host.Name)
+				continue
+			}
+			names[host.Name] = true
+			if addresses[host.Address] {
+				conflict("Host %s has the same address as another host, %s", host.Name, host.Address)
+			}
+			addresses[host.Address] = true
+
+			if dupID := HostStore.findDuplicate(tx, host.Name, host.Address); dupID != "" {
+				existingName := dupID
+				if existing := HostStore.hostWithID(tx, dupID); existing != nil {
+					existingName = existing.Name
+				}
+				conflict("Host %s has the same name or address as existing host %s", host.Name, existingName)
+			}
+			if err := environ.Validate(host.Environment); err != nil {
+				conflict("Host %s has an invalid environment variable: %s", host.Name, err.Error())
+			}
+			if host.Port == 0 {
+				conflict("Host %s must have a port", host.Name)
+			}
+			for _, groupName := range host.Groups {
+				if _, inInventory := groups[groupName]; !inInventory && GroupCache.ByName(groupName) == nil {
+					conflict("Host %s is a member of unknown group %s", host.Name, groupName)
+				}
+			}
+			result.Hosts = append(result.Hosts, host)
+		}
+		return nil
+	})
+
+	return result
+}
+
+// groupHasCycle returns true if the group is its own ancestor. Parents may be in the inventory or existing groups.
+func (i Inventory) groupHasCycle(groups map[string]InventoryGroup, name string) bool {
+	seen := map[string]bool{}
+	for current := name; current != ""; {
+		if seen[current] {
+			return current == name
+		}
+		seen[current] = true
+		if group, ok := groups[current]; ok {
+			current = group.Parent
+		} else if group := GroupCache.ByName(current); group != nil {
+			current = ""
+			if parent := GroupCache.ByID(group.ParentID); parent != nil {
+				current = parent.Name
+			}
+		} else {
+			current = ""
+		}
+	}
+	return false
+}
+
+// environmentContains returns true if every variable in subset has the same value in vars
+func environmentContains(vars []environ.Variable, subset []environ.Variable) bool {
+	values := environ.Map(vars)
+	for _, variable := range subset {
+		value, ok := values[variable.Key]
+		if !ok || value != variable.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportInventory adds the hosts and groups from the inventory. If validateOnly is true, or if there are any conflicts,
+// then nothing is added.
+func ImportInventory(inventory Inventory, validateOnly bool, username string) (*InventoryImportResult, *Error) {
+	result := inventory.validate()
+	if validateOnly {
+		return result, nil
+	}
+	if len(result.Conflicts) > 0 {
+		return nil, ErrorUser("Inventory has %d conflicts: %s", len(result.Conflicts), strings.Join(result.Conflicts, "; "))
+	}
+
+	// Parents must be added before their children
+	pending := result.Groups
+	for len(pending) > 0 {
+		remaining := []InventoryGroup{}
+		for _, inventoryGroup := range pending {
+			parentID := ""
+			if inventoryGroup.Parent != "" {
+				parent := GroupCache.ByName(inventoryGroup.Parent)
+				if parent == nil {
+					remaining = append(remaining, inventoryGroup)
+					continue
+				}
+				parentID = parent.ID
+			}
+
+			group, err := GroupStore.NewGroup(newGroupParameters{
+				Name:        inventoryGroup.Name,
+				ScriptIDs:   []string{},
+				Environment: inventoryGroup.Environment,
+				ParentID:    parentID,
+			})
+			if err != nil {
+				log.PError("Error adding group from inventory", map[string]interface{}{
+					"name":  inventoryGroup.Name,
+					"error": err.Message,
+				})
+				return nil, err
+			}
+			EventStore.GroupAdded(group, username)
+		}
+		if len(remaining) == len(pending) {
+			return nil, ErrorUser("Unable to add parent groups for %s", remaining[0].Name)
+		}
+		pending = remaining
+	}
+
+	for _, inventoryHost := range result.Hosts {
+		groupIDs := make([]string, len(inventoryHost.Groups))
+		for i, groupName := range inventoryHost.Groups {
+			group := GroupCache.ByName(groupName)
+			if group == nil {
+				return nil, ErrorUser("No group with name %s", groupName)
+			}
+			groupIDs[i] = group.ID
+		}
+
+		host, err := HostStore.NewHost(newHostParameters{
+			Name:        inventoryHost.Name,
+			Address:     inventoryHost.Address,
+			Port:        inventoryHost.Port,
+			GroupIDs:    groupIDs,
+			Environment: inventoryHost.Environment,
+		})
+		if err != nil {
+			log.PError("Error adding host from inventory", map[string]interface{}{
+				"name":  inventoryHost.Name,
+				"error": err.Message,
+			})
+			return nil, err
+		}
+		EventStore.HostAdded(host, username)
+	}
+
+	log.PInfo("Imported inventory", map[string]interface{}{
+		"groups":      len(result.Groups),
+		"hosts":       len(result.Hosts),
+		"imported_by": username,
+	})
+	return result, nil
+}
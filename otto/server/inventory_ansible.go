@@ -0,0 +1,364 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	ansibleGroupAll       = "all"
+	ansibleGroupUngrouped = "ungrouped"
+)
+
+// ansibleHostRangePattern matches host ranges such as `web[01:50]`, which are not supported
+var ansibleHostRangePattern = regexp.MustCompile(`\[[^\]]*:[^\]]*\]`)
+
+// isAnsibleImplicitGroup returns true if the group is one that every Ansible inventory has
+func isAnsibleImplicitGroup(name string) bool {
+	return name == ansibleGroupAll || name == ansibleGroupUngrouped
+}
+
+// parseInventoryINI parses an Ansible inventory in INI format
+func parseInventoryINI(data string) (*Inventory, error) {
+	b := newInventoryBuilder()
+
+	group := ansibleGroupUngrouped
+	section := "hosts"
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: invalid section '%s'", i+1, line)
+			}
+			group = strings.TrimSpace(line[1 : len(line)-1])
+			section = "hosts"
+			if idx := strings.LastIndex(group, ":"); idx >= 0 {
+				group, section = group[:idx], group[idx+1:]
+			}
+			if group == "" {
+				return nil, fmt.Errorf("line %d: section has no group name", i+1)
+			}
+			switch section {
+			case "hosts", "vars", "children":
+			default:
+				return nil, fmt.Errorf("line %d: unknown section type '%s'", i+1, section)
+			}
+			if !isAnsibleImplicitGroup(group) {
+				b.group(group)
+			}
+			continue
+		}
+
+		switch section {
+		case "hosts":
+			tokens, err := splitINITokens(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err.Error())
+			}
+			if len(tokens) == 0 {
+				continue
+			}
+			name := tokens[0]
+			if ansibleHostRangePattern.MatchString(name) {
+				return nil, fmt.Errorf("line %d: host ranges are not supported", i+1)
+			}
+			b.addHostToGroup(name, group)
+			for _, token := range tokens[1:] {
+				key, value, found := strings.Cut(token, "=")
+				if !found || key == "" {
+					return nil, fmt.Errorf("line %d: invalid host variable '%s'", i+1, token)
+				}
+				b.setHostVariable(name, key, value)
+			}
+		case "vars":
+			key, value, found := strings.Cut(line, "=")
+			key = strings.TrimSpace(key)
+			if !found || key == "" {
+				return nil, fmt.Errorf("line %d: invalid group variable '%s'", i+1, line)
+			}
+			b.setGroupVariable(group, key, unquoteINIValue(strings.TrimSpace(value)))
+		case "children":
+			tokens, err := splitINITokens(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err.Error())
+			}
+			if len(tokens) != 1 {
+				return nil, fmt.Errorf("line %d: invalid child group '%s'", i+1, line)
+			}
+			b.setParent(tokens[0], group)
+		}
+	}
+
+	return b.finish(), nil
+}
+
+// splitINITokens splits a line into tokens separated by whitespace, the same as a shell would. A token that starts
+// with `#` begins a comment.
+func splitINITokens(line string) ([]string, error) {
+	tokens := []string{}
+	token := &strings.Builder{}
+	inToken := false
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				token.WriteRune(runes[i])
+			} else {
+				token.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inToken = true
+		case c == ' ' || c == '\t':
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		case c == '#' && !inToken:
+			return tokens, nil
+		case c == '\\' && i+1 < len(runes):
+			i++
+			token.WriteRune(runes[i])
+			inToken = true
+		default:
+			token.WriteRune(c)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+// unquoteINIValue removes the quotes from a group variable value, if it is quoted
+func unquoteINIValue(value string) string {
+	if len(value) < 2 || (value[0] != '"' && value[0] != '\'') || value[len(value)-1] != value[0] {
+		return value
+	}
+	tokens, err := splitINITokens(value)
+	if err != nil || len(tokens) != 1 {
+		return value
+	}
+	return tokens[0]
+}
+
+// iniQuote returns the value quoted if needed
+func iniQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"'#;=\\") {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// ansibleVariables returns the variables for the host in an Ansible inventory
+func (host InventoryHost) ansibleVariables() [][2]string {
+	variables := [][2]string{
+		{"ansible_host", host.Address},
+		{"otto_port", fmt.Sprintf("%d", host.Port)},
+	}
+	for _, variable := range host.Environment {
+		variables = append(variables, [2]string{variable.Key, variable.Value})
+	}
+	return variables
+}
+
+// groupHosts returns the names of the hosts that were added to each group, keyed by group name
+func (i Inventory) groupHosts() map[string][]string {
+	hosts := map[string][]string{}
+	for _, host := range i.Hosts {
+		for _, group := range host.Groups {
+			hosts[group] = append(hosts[group], host.Name)
+		}
+	}
+	return hosts
+}
+
+// formatINI returns the inventory as an Ansible inventory in INI format. Every host is listed with its variables
+// first, then each group lists the names of its hosts.
+func (i Inventory) formatINI() []byte {
+	buf := &bytes.Buffer{}
+	for _, host := range i.Hosts {
+		buf.WriteString(iniQuote(host.Name))
+		for _, variable := range host.ansibleVariables() {
+			fmt.Fprintf(buf, " %s=%s", variable[0], iniQuote(variable[1]))
+		}
+		buf.WriteString("\n")
+	}
+
+	groupHosts := i.groupHosts()
+	for _, group := range i.Groups {
+		fmt.Fprintf(buf, "\n[%s]\n", group.Name)
+		for _, host := range groupHosts[group.Name] {
+			fmt.Fprintf(buf, "%s\n", iniQuote(host))
+		}
+		if len(group.Environment) > 0 {
+			fmt.Fprintf(buf, "\n[%s:vars]\n", group.Name)
+			for _, variable := range group.Environment {
+				fmt.Fprintf(buf, "%s=%s\n", variable.Key, iniQuote(variable.Value))
+			}
+		}
+	}
+	for _, parent := range i.Groups {
+		children := []string{}
+		for _, group := range i.Groups {
+			if group.Parent == parent.Name {
+				children = append(children, group.Name)
+			}
+		}
+		if len(children) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "\n[%s:children]\n%s\n", parent.Name, strings.Join(children, "\n"))
+	}
+	return buf.Bytes()
+}
+
+// parseInventoryYAML parses an Ansible inventory in YAML format
+func parseInventoryYAML(data string) (*Inventory, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Kind != yamlMapping {
+		return nil, fmt.Errorf("inventory must be a mapping of groups")
+	}
+
+	b := newInventoryBuilder()
+	for _, entry := range root.Mapping {
+		if err := b.parseYAMLGroup(entry.Key, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+	return b.finish(), nil
+}
+
+// parseYAMLGroup adds the group, its hosts, variables, and children from an Ansible YAML inventory
+func (b *inventoryBuilder) parseYAMLGroup(name string, node *yamlNode) error {
+	if !isAnsibleImplicitGroup(name) {
+		b.group(name)
+	}
+	if node.Kind == yamlNull {
+		return nil
+	}
+	if node.Kind != yamlMapping {
+		return fmt.Errorf("group %s must be a mapping", name)
+	}
+
+	for _, entry := range node.Mapping {
+		if !sliceContains(entry.Key, []string{"hosts", "vars", "children"}) {
+			return fmt.Errorf("unknown key %s in group %s", entry.Key, name)
+		}
+		if entry.Value.Kind == yamlNull {
+			continue
+		}
+		if entry.Value.Kind != yamlMapping {
+			return fmt.Errorf("%s of group %s must be a mapping", entry.Key, name)
+		}
+
+		switch entry.Key {
+		case "hosts":
+			for _, host := range entry.Value.Mapping {
+				b.addHostToGroup(host.Key, name)
+				if host.Value.Kind == yamlNull {
+					continue
+				}
+				if host.Value.Kind != yamlMapping {
+					return fmt.Errorf("variables of host %s must be a mapping", host.Key)
+				}
+				for _, variable := range host.Value.Mapping {
+					if variable.Value.Kind == yamlMapping {
+						return fmt.Errorf("variable %s of host %s must be a single value", variable.Key, host.Key)
+					}
+					b.setHostVariable(host.Key, variable.Key, variable.Value.Scalar)
+				}
+			}
+		case "vars":
+			for _, variable := range entry.Value.Mapping {
+				if variable.Value.Kind == yamlMapping {
+					return fmt.Errorf("variable %s of group %s must be a single value", variable.Key, name)
+				}
+				b.setGroupVariable(name, variable.Key, variable.Value.Scalar)
+			}
+		case "children":
+			for _, child := range entry.Value.Mapping {
+				b.setParent(child.Key, name)
+				if err := b.parseYAMLGroup(child.Key, child.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// formatYAML returns the inventory as an Ansible inventory in YAML format. Every host is listed with its variables in
+// the all group, then each group lists the names of its hosts.
+func (i Inventory) formatYAML() []byte {
+	w := yamlWriter{buf: &bytes.Buffer{}}
+	w.key(0, ansibleGroupAll)
+	if len(i.Hosts) > 0 {
+		w.key(1, "hosts")
+		for _, host := range i.Hosts {
+			w.key(2, host.Name)
+			for _, variable := range host.ansibleVariables() {
+				w.value(3, variable[0], variable[1])
+			}
+		}
+	}
+
+	groupHosts := i.groupHosts()
+	var writeGroups func(depth int, parent string)
+	writeGroups = func(depth int, parent string) {
+		for _, group := range i.Groups {
+			if group.Parent != parent {
+				continue
+			}
+			w.key(depth, group.Name)
+			if hosts := groupHosts[group.Name]; len(hosts) > 0 {
+				w.key(depth+1, "hosts")
+				for _, host := range hosts {
+					w.key(depth+2, host)
+				}
+			}
+			if len(group.Environment) > 0 {
+				w.key(depth+1, "vars")
+				for _, variable := range group.Environment {
+					w.value(depth+2, variable.Key, variable.Value)
+				}
+			}
+			for _, child := range i.Groups {
+				if child.Parent == group.Name {
+					w.key(depth+1, "children")
+					writeGroups(depth+2, group.Name)
+					break
+				}
+			}
+		}
+	}
+	for _, group := range i.Groups {
+		if group.Parent == "" {
+			w.key(1, "children")
+			writeGroups(2, "")
+			break
+		}
+	}
+	return w.buf.Bytes()
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+const (
+	inventoryColumnName    = "name"
+	inventoryColumnAddress = "address"
+	inventoryColumnPort    = "port"
+	inventoryColumnGroups  = "groups"
+)
+
+// inventoryGroupSeparator separates the names of groups in the groups column of a CSV inventory
+const inventoryGroupSeparator = ";"
+
+// parseInventoryCSV parses a CSV inventory. The first row is a header that names each column. The name column is
+// required, the address, port, and groups columns are optional, and any other column is an environment variable.
+// Empty environment variable cells are not set.
+func parseInventoryCSV(data string) (*Inventory, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		switch strings.ToLower(column) {
+		case inventoryColumnName, inventoryColumnAddress, inventoryColumnPort, inventoryColumnGroups:
+			column = strings.ToLower(column)
+		}
+		if column == "" {
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		}
+		if _, duplicate := columns[column]; duplicate {
+			return nil, fmt.Errorf("duplicate column %s", column)
+		}
+		columns[column] = i
+		header[i] = column
+	}
+	if _, ok := columns[inventoryColumnName]; !ok {
+		return nil, fmt.Errorf("missing %s column", inventoryColumnName)
+	}
+
+	inventory := &Inventory{
+		Groups: []InventoryGroup{},
+		Hosts:  []InventoryHost{},
+	}
+	groups := map[string]bool{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		host := InventoryHost{
+			Port:        defaultAgentPort,
+			Groups:      []string{},
+			Environment: []environ.Variable{},
+		}
+		for i, value := range record {
+			switch header[i] {
+			case inventoryColumnName:
+				host.Name = strings.TrimSpace(value)
+			case inventoryColumnAddress:
+				host.Address = strings.TrimSpace(value)
+			case inventoryColumnPort:
+				if strings.TrimSpace(value) == "" {
+					continue
+				}
+				port, err := parseInventoryPort(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid port '%s'", line, value)
+				}
+				host.Port = port
+			case inventoryColumnGroups:
+				for _, name := range strings.Split(value, inventoryGroupSeparator) {
+					name = strings.TrimSpace(name)
+					if name == "" || sliceContains(name, host.Groups) {
+						continue
+					}
+					host.Groups = append(host.Groups, name)
+					if !groups[name] {
+						groups[name] = true
+						inventory.Groups = append(inventory.Groups, InventoryGroup{Name: name, Environment: []environ.Variable{}})
+					}
+				}
+			default:
+				if value != "" {
+					host.Environment = append(host.Environment, environ.New(header[i], value))
+				}
+			}
+		}
+		if host.Address == "" {
+			host.Address = host.Name
+		}
+		inventory.Hosts = append(inventory.Hosts, host)
+	}
+
+	return inventory, nil
+}
+
+// formatCSV returns the inventory as CSV. Group environment variables and parent groups can't be included.
+func (i Inventory) formatCSV() ([]byte, error) {
+	keys := []string{}
+	for _, host := range i.Hosts {
+		for _, variable := range host.Environment {
+			if !sliceContains(variable.Key, keys) {
+				keys = append(keys, variable.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	header := append([]string{inventoryColumnName, inventoryColumnAddress, inventoryColumnPort, inventoryColumnGroups}, keys...)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, host := range i.Hosts {
+		values := environ.Map(host.Environment)
+		record := []string{host.Name, host.Address, fmt.Sprintf("%d", host.Port), strings.Join(host.Groups, inventoryGroupSeparator)}
+		for _, key := range keys {
+			record = append(record, values[key])
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
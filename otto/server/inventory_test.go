@@ -0,0 +1,280 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestInventoryCSV(t *testing.T) {
+	inventory, err := ParseInventory(InventoryFormatCSV, `Name,Address,Port,Groups,REGION
+web1,10.0.0.1,12445,web;prod,eu
+web2,,,web,
+`)
+	if err != nil {
+		t.Fatalf("Error parsing inventory: %s", err.Message)
+	}
+	if len(inventory.Hosts) != 2 || len(inventory.Groups) != 2 {
+		t.Fatalf("Unexpected inventory: %+v", inventory)
+	}
+	web1 := inventory.Hosts[0]
+	if web1.Name != "web1" || web1.Address != "10.0.0.1" || web1.Port != 12445 || strings.Join(web1.Groups, ",") != "web,prod" {
+		t.Errorf("Unexpected host: %+v", web1)
+	}
+	if environ.Map(web1.Environment)["REGION"] != "eu" {
+		t.Errorf("Unexpected host environment: %+v", web1.Environment)
+	}
+	web2 := inventory.Hosts[1]
+	if web2.Address != "web2" || web2.Port != defaultAgentPort || len(web2.Environment) != 0 {
+		t.Errorf("Unexpected host with defaults: %+v", web2)
+	}
+
+	data, err := inventory.Format(InventoryFormatCSV)
+	if err != nil {
+		t.Fatalf("Error formatting inventory: %s", err.Message)
+	}
+	parsed, err := ParseInventory(InventoryFormatCSV, string(data))
+	if err != nil {
+		t.Fatalf("Error parsing formatted inventory: %s", err.Message)
+	}
+	if len(parsed.Hosts) != 2 || parsed.Hosts[0].Port != 12445 || environ.Map(parsed.Hosts[0].Environment)["REGION"] != "eu" {
+		t.Errorf("Unexpected inventory after formatting: %+v", parsed)
+	}
+
+	for _, data := range []string{"", "address\n10.0.0.1\n", "name,port\nweb1,http\n", "name,address\nweb1\n"} {
+		if _, err := ParseInventory(InventoryFormatCSV, data); err == nil {
+			t.Errorf("No error seen for invalid inventory '%s'", data)
+		}
+	}
+}
+
+func TestInventoryAnsibleINI(t *testing.T) {
+	inventory, err := ParseInventory(InventoryFormatAnsibleINI, `# Comment
+bastion ansible_host=10.0.0.9 ansible_user=root
+
+[web]
+web1 ansible_host=10.0.0.1 otto_port=12445 GREETING="hello world"
+web2 ansible_host=10.0.0.2 # Comment
+
+[web:vars]
+TIER=web
+
+[prod:children]
+web
+
+[prod:vars]
+TIER = prod
+REGION = 'eu west'
+
+[all:vars]
+ansible_python_interpreter=/usr/bin/python3
+`)
+	if err != nil {
+		t.Fatalf("Error parsing inventory: %s", err.Message)
+	}
+	checkAnsibleInventory(t, inventory)
+	if len(inventory.warnings) != 2 {
+		t.Errorf("Unexpected warnings: %v", inventory.warnings)
+	}
+
+	data, err := inventory.Format(InventoryFormatAnsibleINI)
+	if err != nil {
+		t.Fatalf("Error formatting inventory: %s", err.Message)
+	}
+	parsed, err := ParseInventory(InventoryFormatAnsibleINI, string(data))
+	if err != nil {
+		t.Fatalf("Error parsing formatted inventory: %s\n%s", err.Message, data)
+	}
+	checkAnsibleInventory(t, parsed)
+
+	for _, data := range []string{"[web\nweb1\n", "[web:hosts]\nweb[01:10]\n", "web1 foo\n", "[web:other]\n", "web1 foo=\"bar\n"} {
+		if _, err := ParseInventory(InventoryFormatAnsibleINI, data); err == nil {
+			t.Errorf("No error seen for invalid inventory '%s'", data)
+		}
+	}
+}
+
+func TestInventoryAnsibleYAML(t *testing.T) {
+	inventory, err := ParseInventory(InventoryFormatAnsibleYAML, `---
+all:
+  hosts:
+    bastion:
+      ansible_host: 10.0.0.9
+      ansible_user: root
+  vars:
+    ansible_python_interpreter: /usr/bin/python3
+  children:
+    prod:
+      vars:
+        TIER: prod
+        REGION: "eu west" # Comment
+      children:
+        web:
+          hosts:
+            web1:
+              ansible_host: 10.0.0.1
+              otto_port: 12445
+              GREETING: 'hello world'
+            web2:
+              ansible_host: 10.0.0.2
+          vars:
+            TIER: web
+`)
+	if err != nil {
+		t.Fatalf("Error parsing inventory: %s", err.Message)
+	}
+	checkAnsibleInventory(t, inventory)
+
+	data, err := inventory.Format(InventoryFormatAnsibleYAML)
+	if err != nil {
+		t.Fatalf("Error formatting inventory: %s", err.Message)
+	}
+	parsed, err := ParseInventory(InventoryFormatAnsibleYAML, string(data))
+	if err != nil {
+		t.Fatalf("Error parsing formatted inventory: %s\n%s", err.Message, data)
+	}
+	checkAnsibleInventory(t, parsed)
+
+	for _, data := range []string{
+		"all:\n  hosts:\n    - web1\n",
+		"all:\n  hosts:\n   web1:\n  web2:\n",
+		"all:\n  other: {}\n",
+		"all:\n  vars:\n    FOO: [1, 2]\n",
+		"web:\n  hosts: {}\nweb:\n  hosts: {}\n",
+		"web:\n\thosts: {}\n",
+		"a:\n  children:\n    c: {}\nb:\n  children:\n    c: {}\n",
+	} {
+		inventory, err := ParseInventory(InventoryFormatAnsibleYAML, data)
+		if err == nil && len(inventory.conflicts) == 0 {
+			t.Errorf("No error seen for invalid inventory '%s'", data)
+		}
+	}
+}
+
+// checkAnsibleInventory checks the inventory used by the Ansible tests
+func checkAnsibleInventory(t *testing.T, inventory *Inventory) {
+	hosts := map[string]InventoryHost{}
+	for _, host := range inventory.Hosts {
+		hosts[host.Name] = host
+	}
+	groups := map[string]InventoryGroup{}
+	for _, group := range inventory.Groups {
+		groups[group.Name] = group
+	}
+	if len(hosts) != 3 || len(groups) != 2 {
+		t.Fatalf("Unexpected inventory: %+v", inventory)
+	}
+
+	if bastion := hosts["bastion"]; bastion.Address != "10.0.0.9" || len(bastion.Groups) != 0 || len(bastion.Environment) != 0 {
+		t.Errorf("Unexpected ungrouped host: %+v", bastion)
+	}
+	web1 := hosts["web1"]
+	if web1.Address != "10.0.0.1" || web1.Port != 12445 || strings.Join(web1.Groups, ",") != "web" {
+		t.Errorf("Unexpected host: %+v", web1)
+	}
+	if variables := environ.Map(web1.Environment); len(variables) != 1 || variables["GREETING"] != "hello world" {
+		t.Errorf("Unexpected host variables: %v", variables)
+	}
+	if web2 := hosts["web2"]; web2.Address != "10.0.0.2" || web2.Port != defaultAgentPort {
+		t.Errorf("Unexpected host: %+v", web2)
+	}
+
+	if web := groups["web"]; web.Parent != "prod" || environ.Map(web.Environment)["TIER"] != "web" {
+		t.Errorf("Unexpected group: %+v", web)
+	}
+	prod := groups["prod"]
+	if variables := environ.Map(prod.Environment); prod.Parent != "" || variables["TIER"] != "prod" || variables["REGION"] != "eu west" {
+		t.Errorf("Unexpected group: %+v", prod)
+	}
+}
+
+func TestInventoryImport(t *testing.T) {
+	existing, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	parent := randomString(6)
+	child := randomString(6)
+	host := randomString(6)
+	address := randLocalhostIP()
+	inventory := Inventory{
+		Groups: []InventoryGroup{
+			{Name: child, Parent: parent, Environment: []environ.Variable{environ.New("TIER", "web")}},
+			{Name: parent},
+		},
+		Hosts: []InventoryHost{
+			{Name: host, Address: address, Port: 12444, Groups: []string{child}, Environment: []environ.Variable{environ.New("REGION", "eu")}},
+			{Name: existing.Name, Address: randLocalhostIP(), Port: 12444},
+			{Name: randomString(6), Address: address, Port: 12444},
+			{Name: randomString(6), Address: randLocalhostIP(), Port: 12444, Groups: []string{randomString(6)}},
+		},
+	}
+
+	result, err := ImportInventory(inventory, true, "")
+	if err != nil {
+		t.Fatalf("Error validating inventory: %s", err.Message)
+	}
+	if len(result.Conflicts) != 3 {
+		t.Errorf("Unexpected conflicts: %v", result.Conflicts)
+	}
+	if len(result.Groups) != 2 {
+		t.Errorf("Unexpected groups: %+v", result.Groups)
+	}
+	if _, err := ImportInventory(inventory, false, ""); err == nil {
+		t.Errorf("No error seen importing inventory with conflicts")
+	}
+	if HostCache.ByName(host) != nil || GroupCache.ByName(child) != nil {
+		t.Fatalf("Nothing should be added from an inventory with conflicts")
+	}
+
+	inventory.Hosts = inventory.Hosts[:1]
+	result, err = ImportInventory(inventory, false, "")
+	if err != nil {
+		t.Fatalf("Error importing inventory: %s", err.Message)
+	}
+	if len(result.Conflicts) != 0 || len(result.Hosts) != 1 || len(result.Groups) != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	childGroup := GroupCache.ByName(child)
+	parentGroup := GroupCache.ByName(parent)
+	if childGroup == nil || parentGroup == nil || childGroup.ParentID != parentGroup.ID {
+		t.Fatalf("Groups should be added with their parent")
+	}
+	added := HostCache.ByName(host)
+	if added == nil || added.Address != address || len(added.GroupIDs) != 1 || added.GroupIDs[0] != childGroup.ID {
+		t.Fatalf("Unexpected imported host: %+v", added)
+	}
+	if environ.Map(added.Environment)["REGION"] != "eu" {
+		t.Errorf("Unexpected imported host environment: %+v", added.Environment)
+	}
+
+	// Importing the same groups again reuses them, but the hosts conflict
+	result, err = ImportInventory(inventory, true, "")
+	if err != nil {
+		t.Fatalf("Error validating inventory: %s", err.Message)
+	}
+	if len(result.Groups) != 0 || len(result.Conflicts) != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	exported := ExportInventory(false)
+	found := false
+	for _, exportedHost := range exported.Hosts {
+		if exportedHost.Name == host {
+			found = true
+			if strings.Join(exportedHost.Groups, ",") != child {
+				t.Errorf("Unexpected exported host: %+v", exportedHost)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Imported host should be exported")
+	}
+}
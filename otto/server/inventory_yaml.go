@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML used by Ansible inventories only needs block mappings of scalars, so only that subset of YAML is supported.
+// Lists, flow collections other than `{}`, block scalars, anchors, and tags are rejected.
+
+const (
+	yamlNull = iota
+	yamlScalar
+	yamlMapping
+)
+
+// yamlNode describes a YAML node
+type yamlNode struct {
+	Kind   int
+	Scalar string
+	// Mapping the entries of a mapping, in order
+	Mapping []yamlEntry
+}
+
+// yamlEntry describes a single entry of a YAML mapping
+type yamlEntry struct {
+	Key   string
+	Value *yamlNode
+}
+
+type yamlLine struct {
+	Number int
+	Indent int
+	Text   string
+}
+
+// yamlPlainPattern values that can be written without quotes
+var yamlPlainPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./\-]*$`)
+
+// yamlReservedPlain plain values that YAML would not read as strings
+var yamlReservedPlain = []string{"true", "false", "yes", "no", "on", "off", "null", "y", "n"}
+
+// parseYAML parses the document into a node
+func parseYAML(data string) (*yamlNode, error) {
+	lines := []yamlLine{}
+	for i, text := range strings.Split(data, "\n") {
+		text = strings.TrimRight(stripYAMLComment(strings.TrimRight(text, "\r")), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || text == "---" || text == "..." || strings.HasPrefix(text, "%") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't be used for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{Number: i + 1, Indent: len(text) - len(trimmed), Text: trimmed})
+	}
+	if len(lines) == 0 {
+		return &yamlNode{Kind: yamlMapping}, nil
+	}
+
+	node, next, err := parseYAMLMapping(lines, 0, lines[0].Indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].Number)
+	}
+	return node, nil
+}
+
+// stripYAMLComment removes any comment from the line
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		start := i == 0 || line[i-1] == ' ' || line[i-1] == '\t' || line[i-1] == ':'
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && start:
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseYAMLMapping parses the block mapping with the given indentation that starts at lines[idx]. Returns the node and
+// the index of the first line after the mapping.
+func parseYAMLMapping(lines []yamlLine, idx int, indent int) (*yamlNode, int, error) {
+	node := &yamlNode{Kind: yamlMapping}
+	keys := map[string]bool{}
+	for idx < len(lines) {
+		line := lines[idx]
+		if line.Indent < indent {
+			break
+		}
+		if line.Indent > indent {
+			return nil, 0, fmt.Errorf("line %d: unexpected indentation", line.Number)
+		}
+		if line.Text == "-" || strings.HasPrefix(line.Text, "- ") {
+			return nil, 0, fmt.Errorf("line %d: lists are not supported", line.Number)
+		}
+
+		key, rest, err := splitYAMLKey(line.Text)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %s", line.Number, err.Error())
+		}
+		if keys[key] {
+			return nil, 0, fmt.Errorf("line %d: duplicate key '%s'", line.Number, key)
+		}
+		keys[key] = true
+		idx++
+
+		value := &yamlNode{Kind: yamlNull}
+		if rest != "" {
+			value, err = parseYAMLScalar(rest)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %s", line.Number, err.Error())
+			}
+		} else if idx < len(lines) && lines[idx].Indent > indent {
+			value, idx, err = parseYAMLMapping(lines, idx, lines[idx].Indent)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		node.Mapping = append(node.Mapping, yamlEntry{Key: key, Value: value})
+	}
+	return node, idx, nil
+}
+
+// splitYAMLKey splits the text of a mapping entry into its key and the rest of the line after the colon
+func splitYAMLKey(text string) (string, string, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := -1
+		for i := 1; i < len(text); i++ {
+			if text[0] == '"' && text[i] == '\\' {
+				i++
+				continue
+			}
+			if text[i] != text[0] {
+				continue
+			}
+			if text[0] == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			end = i
+			break
+		}
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		rest := text[end+1:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		key, err := parseYAMLScalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		return key.Scalar, strings.TrimSpace(rest[1:]), nil
+	}
+
+	if strings.HasSuffix(text, ":") && !strings.Contains(text, ": ") {
+		return strings.TrimSpace(text[:len(text)-1]), "", nil
+	}
+	idx := strings.Index(text, ": ")
+	if idx <= 0 {
+		return "", "", fmt.Errorf("expected a key")
+	}
+	return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+2:]), nil
+}
+
+// parseYAMLScalar parses a value on the same line as its key
+func parseYAMLScalar(text string) (*yamlNode, error) {
+	switch {
+	case text == "{}":
+		return &yamlNode{Kind: yamlMapping}, nil
+	case text == "~" || text == "null" || text == "Null" || text == "NULL":
+		return &yamlNode{Kind: yamlNull}, nil
+	case text[0] == '"':
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted value %s", text)
+		}
+		return &yamlNode{Kind: yamlScalar, Scalar: value}, nil
+	case text[0] == '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("invalid quoted value %s", text)
+		}
+		return &yamlNode{Kind: yamlScalar, Scalar: strings.ReplaceAll(text[1:len(text)-1], "''", "'")}, nil
+	case strings.ContainsRune("[{", rune(text[0])):
+		return nil, fmt.Errorf("flow collections are not supported")
+	case strings.ContainsRune("|>", rune(text[0])):
+		return nil, fmt.Errorf("block scalars are not supported")
+	case strings.ContainsRune("&*!", rune(text[0])):
+		return nil, fmt.Errorf("anchors, aliases, and tags are not supported")
+	}
+	return &yamlNode{Kind: yamlScalar, Scalar: text}, nil
+}
+
+// yamlQuote returns the value quoted if needed
+func yamlQuote(value string) string {
+	if yamlPlainPattern.MatchString(value) && !sliceContains(strings.ToLower(value), yamlReservedPlain) {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// yamlWriter writes a YAML document
+type yamlWriter struct {
+	buf *bytes.Buffer
+}
+
+// key writes a key with no value, which is followed by a nested mapping or is null
+func (w yamlWriter) key(depth int, key string) {
+	fmt.Fprintf(w.buf, "%s%s:\n", strings.Repeat("  ", depth), yamlQuote(key))
+}
+
+// value writes a key with a scalar value
+func (w yamlWriter) value(depth int, key, value string) {
+	fmt.Fprintf(w.buf, "%s%s: %s\n", strings.Repeat("  ", depth), yamlQuote(key), yamlQuote(value))
+}
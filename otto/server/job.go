@@ -0,0 +1,294 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+// jobExpiry is how long a job is kept after it has finished
+const jobExpiry = 24 * time.Hour
+
+// Job describes a script running on one or more hosts in the background. Jobs are only kept in memory, but each host
+// run is saved to the run history.
+type Job struct {
+	ID       string
+	ScriptID string
+	// ScriptRevision the revision of the script that is run
+	ScriptRevision int
+	TriggeredBy    string
+	Status         string
+	Time           JobTime
+	Hosts          []JobHost
+	Execution      ExecutionOptions
+	Rollout        RolloutStrategy
+	// StopReason why the rollout was stopped before all hosts were run, if it was
+	StopReason string
+
+	script   *Script
+	canceled bool
+}
+
+// JobTime describes timing information for a job
+type JobTime struct {
+	Start    time.Time
+	Finished time.Time
+}
+
+// JobHost describes the status of a job on a single host
+type JobHost struct {
+	HostID string
+	Status string
+	// Batch the rollout batch that the host runs in, starting at 1
+	Batch int
+	// RunID the ID of the script run in the run history, only present once the script has finished
+	RunID  string
+	Result otto.ScriptResult
+	// ResultStatus the status of the result, according to the success criteria of the script
+	ResultStatus string
+	// FailureReason why the script did not meet its success criteria, if it did not
+	FailureReason string
+	Error         string
+	// Output the current output of the script, updated while the script is running
+	Output ScriptOutput
+}
+
+type jobStoreType struct {
+	Jobs map[string]*Job
+	Lock *sync.Mutex
+}
+
+var jobStore = &jobStoreType{Jobs: map[string]*Job{}, Lock: &sync.Mutex{}}
+
+// StartJob will start running the script on the given hosts in the background and return the job
+func (s *jobStoreType) StartJob(script *Script, hosts []*Host, options ExecutionOptions, rollout RolloutStrategy, triggeredBy string) Job {
+	job := &Job{
+		ID:             newID(),
+		ScriptID:       script.ID,
+		ScriptRevision: script.Revision,
+		TriggeredBy:    triggeredBy,
+		Status:         JobStatusRunning,
+		Time: JobTime{
+			Start: time.Now(),
+		},
+		Hosts:     make([]JobHost, len(hosts)),
+		Execution: options,
+		Rollout:   rollout,
+		script:    script,
+	}
+	for i, host := range hosts {
+		job.Hosts[i] = JobHost{
+			HostID: host.ID,
+			Status: JobStatusPending,
+		}
+	}
+	for b, batch := range rollout.batches(len(hosts)) {
+		for _, i := range batch {
+			job.Hosts[i].Batch = b + 1
+		}
+	}
+
+	s.Lock.Lock()
+	s.Jobs[job.ID] = job
+	s.Lock.Unlock()
+
+	log.PInfo("Starting job", map[string]interface{}{
+		"job_id":       job.ID,
+		"script_id":    script.ID,
+		"num_hosts":    len(hosts),
+		"triggered_by": triggeredBy,
+	})
+
+	go func() {
+		result := executeRollout(hosts, rollout, options, func(i int, host *Host) bool {
+			return s.runHost(job, i, host)
+		}, func(i int, host *Host) {
+			if err := host.CancelScript(script.Name); err != nil {
+				log.PError("Error cancelling script on host", map[string]interface{}{
+					"job_id":  job.ID,
+					"host_id": host.ID,
+					"error":   err.Error(),
+				})
+			}
+		})
+
+		s.Lock.Lock()
+		defer s.Lock.Unlock()
+		job.StopReason = result.StopReason
+		for i, jobHost := range job.Hosts {
+			if jobHost.Status == JobStatusPending {
+				job.Hosts[i].Status = JobStatusSkipped
+			}
+		}
+		s.finishHost(job)
+	}()
+
+	return s.copyJob(job)
+}
+
+// runHost will run the script on the host, returning false if it failed
+func (s *jobStoreType) runHost(job *Job, idx int, host *Host) bool {
+	s.Lock.Lock()
+	if job.canceled {
+		job.Hosts[idx].Status = JobStatusCanceled
+		s.finishHost(job)
+		s.Lock.Unlock()
+		return true
+	}
+	job.Hosts[idx].Status = JobStatusRunning
+	s.Lock.Unlock()
+
+	start := time.Now()
+	result, err := host.RunScript(job.script, func(stdout, stderr []byte) {
+		s.Lock.Lock()
+		job.Hosts[idx].Output = ScriptOutput{
+			Stdout: string(stdout),
+			Stderr: string(stderr),
+		}
+		s.Lock.Unlock()
+	})
+	run, _ := ScriptRunStore.NewRun(newScriptRunParameters{
+		Script:      job.script,
+		Host:        host,
+		TriggeredBy: job.TriggeredBy,
+		Start:       start,
+		Result:      result,
+		Error:       err,
+	})
+	if err == nil {
+		EventStore.ScriptRun(job.script, host, result, nil, job.TriggeredBy)
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	jobHost := &job.Hosts[idx]
+	if run != nil {
+		jobHost.RunID = run.ID
+	}
+	if err != nil {
+		jobHost.Status = JobStatusFailed
+		jobHost.ResultStatus = ScriptStatusFailed
+		jobHost.Error = err.Error()
+	} else {
+		jobHost.Result = result.Result
+		jobHost.ResultStatus = result.Status
+		jobHost.FailureReason = result.FailureReason
+		jobHost.Error = result.RunError
+		jobHost.Output = result.Output
+		if job.canceled {
+			jobHost.Status = JobStatusCanceled
+		} else if result.RunError != "" {
+			jobHost.Status = JobStatusFailed
+		} else {
+			jobHost.Status = JobStatusFinished
+		}
+	}
+	s.finishHost(job)
+	return err == nil && result.Succeeded()
+}
+
+// finishHost will mark the job as finished if no hosts are still running. The lock must be held by the caller.
+func (s *jobStoreType) finishHost(job *Job) {
+	if job.Status != JobStatusRunning {
+		return
+	}
+	for _, jobHost := range job.Hosts {
+		if jobHost.Status == JobStatusPending || jobHost.Status == JobStatusRunning {
+			return
+		}
+	}
+
+	job.Time.Finished = time.Now()
+	if job.canceled {
+		job.Status = JobStatusCanceled
+	} else {
+		job.Status = JobStatusFinished
+	}
+	log.PInfo("Finished job", map[string]interface{}{
+		"job_id":  job.ID,
+		"status":  job.Status,
+		"elapsed": job.Time.Finished.Sub(job.Time.Start).String(),
+	})
+}
+
+// Get returns a copy of the job with the given ID or nil
+func (s *jobStoreType) Get(id string) *Job {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	job, ok := s.Jobs[id]
+	if !ok {
+		return nil
+	}
+	c := s.copyJob(job)
+	return &c
+}
+
+func (s *jobStoreType) copyJob(job *Job) Job {
+	c := *job
+	c.Hosts = make([]JobHost, len(job.Hosts))
+	copy(c.Hosts, job.Hosts)
+	return c
+}
+
+// Cancel will cancel the job with the given ID. Hosts that have not started will not run the script, and hosts that
+// are running the script will be asked to cancel it.
+func (s *jobStoreType) Cancel(id string) *Error {
+	s.Lock.Lock()
+	job, ok := s.Jobs[id]
+	if !ok {
+		s.Lock.Unlock()
+		return ErrorUser("No job with ID %s", id)
+	}
+	if job.Status != JobStatusRunning {
+		s.Lock.Unlock()
+		return ErrorUser("Job is not running")
+	}
+	job.canceled = true
+	runningHostIDs := []string{}
+	for _, jobHost := range job.Hosts {
+		if jobHost.Status == JobStatusRunning {
+			runningHostIDs = append(runningHostIDs, jobHost.HostID)
+		}
+	}
+	s.Lock.Unlock()
+
+	log.PWarn("Canceling job", map[string]interface{}{
+		"job_id":    job.ID,
+		"num_hosts": len(runningHostIDs),
+	})
+
+	for _, hostID := range runningHostIDs {
+		host := HostCache.ByID(hostID)
+		if host == nil {
+			continue
+		}
+		if err := host.CancelScript(job.script.Name); err != nil {
+			log.PError("Error cancelling script on host", map[string]interface{}{
+				"job_id":  job.ID,
+				"host_id": host.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// Cleanup will remove jobs that finished longer ago than the job expiry
+func (s *jobStoreType) Cleanup() {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	for id, job := range s.Jobs {
+		if job.Time.Finished.IsZero() || time.Since(job.Time.Finished) < jobExpiry {
+			continue
+		}
+		delete(s.Jobs, id)
+		log.PDebug("Removed expired job", map[string]interface{}{
+			"job_id": id,
+		})
+	}
+}
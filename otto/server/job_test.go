@@ -0,0 +1,142 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/otto/shared/otto"
+	"github.com/ecnepsnai/secutil"
+)
+
+func TestJob(t *testing.T) {
+	t.Parallel()
+
+	ip := randLocalhostIP()
+	agentId, _ := otto.NewIdentity()
+
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo 'hello world'",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:          randomString(6),
+		Address:       ip,
+		Port:          uint32(secutil.RandomNumber(0, 65535)),
+		AgentIdentity: agentId.PublicKeyString(),
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+	unreachableHost, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	_, allowFrom, _ := net.ParseCIDR("0.0.0.0/0")
+	l, listenErr := otto.SetupListener(&otto.ListenOptions{
+		Address:   ip + ":0",
+		AllowFrom: []net.IPNet{*allowFrom},
+		Identity:  agentId.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			serverId, err := IdentityStore.Get(host.ID)
+			if err != nil || serverId == nil {
+				return []string{}
+			}
+			return []string{serverId.PublicKeyString()}
+		},
+	}, func(conn *otto.Connection) {
+		defer conn.Close()
+
+		messageType, message, err := conn.ReadMessage()
+		if err != nil || messageType != otto.MessageTypeTriggerActionRunScript {
+			t.Errorf("Unexpected message: %d", messageType)
+			return
+		}
+		scriptInfo := message.(otto.MessageTriggerActionRunScript)
+		if err := conn.WriteMessage(otto.MessageTypeReadyForData, nil); err != nil {
+			t.Errorf("Error sending message: " + err.Error())
+			return
+		}
+		var buf = make([]byte, scriptInfo.ScriptInfo.Length)
+		conn.ReadData(buf)
+		if err := conn.WriteMessage(otto.MessageTypeActionResult, otto.MessageActionResult{ScriptResult: otto.ScriptResult{Success: true}}); err != nil {
+			t.Errorf("Error writing message: " + err.Error())
+			return
+		}
+	})
+	if listenErr != nil {
+		t.Fatalf("Error listening: %s", listenErr.Error())
+	}
+	defer l.Close()
+
+	host.Port = uint32(l.Port())
+	HostStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Update(*host)
+	})
+
+	go func() {
+		l.Accept()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	started := jobStore.StartJob(script, []*Host{host, unreachableHost}, ExecutionOptions{}, RolloutStrategy{}, "example")
+	if started.Status != JobStatusRunning {
+		t.Fatalf("Unexpected job status: %s", started.Status)
+	}
+
+	var job *Job
+	for i := 0; i < 100; i++ {
+		job = jobStore.Get(started.ID)
+		if job == nil {
+			t.Fatalf("No job returned with ID")
+		}
+		if job.Status != JobStatusRunning {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if job.Status != JobStatusFinished {
+		t.Fatalf("Unexpected job status: %s", job.Status)
+	}
+	if job.Time.Finished.IsZero() {
+		t.Errorf("Job finished time not set")
+	}
+
+	if job.Hosts[0].Status != JobStatusFinished {
+		t.Errorf("Unexpected host status: %s %s", job.Hosts[0].Status, job.Hosts[0].Error)
+	}
+	if !job.Hosts[0].Result.Success {
+		t.Errorf("Unexpected host result: %+v", job.Hosts[0].Result)
+	}
+	if job.Hosts[1].Status != JobStatusFailed {
+		t.Errorf("Unexpected host status: %s", job.Hosts[1].Status)
+	}
+	if job.Hosts[1].Error == "" {
+		t.Errorf("No error for unreachable host")
+	}
+	for _, jobHost := range job.Hosts {
+		if ScriptRunStore.RunWithID(jobHost.RunID) == nil {
+			t.Errorf("No script run saved for host %s", jobHost.HostID)
+		}
+	}
+
+	if err := jobStore.Cancel(job.ID); err == nil {
+		t.Errorf("No error seen when one expected for cancelling finished job")
+	}
+	if err := jobStore.Cancel(newID()); err == nil {
+		t.Errorf("No error seen when one expected for cancelling unknown job")
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExecutionLock describes a run that is in progress, or waiting to start. Schedule locks have a ScheduleID and prevent
+// a schedule from overlapping with itself. Host locks have a HostID and prevent the same script from running more than
+// once at a time on a host.
+type ExecutionLock struct {
+	ID           string
+	ScheduleID   string
+	ScheduleName string
+	HostID       string
+	HostName     string
+	ScriptID     string
+	ScriptName   string
+	// Acquired when the lock was taken, or when the run started waiting for the lock
+	Acquired time.Time
+	// Waiting if the run is queued waiting for the lock
+	Waiting bool
+
+	key string
+}
+
+type lockManagerType struct {
+	Holders map[string][]ExecutionLock
+	Waiting map[string]ExecutionLock
+	Lock    *sync.Mutex
+	Cond    *sync.Cond
+}
+
+var lockManager = newLockManager()
+
+func newLockManager() *lockManagerType {
+	lock := &sync.Mutex{}
+	return &lockManagerType{
+		Holders: map[string][]ExecutionLock{},
+		Waiting: map[string]ExecutionLock{},
+		Lock:    lock,
+		Cond:    sync.NewCond(lock),
+	}
+}
+
+// lockedError is returned when a run can't start because of a lock held by another run
+type lockedError struct {
+	Held ExecutionLock
+}
+
+func (e lockedError) Error() string {
+	if e.Held.HostID != "" {
+		return fmt.Sprintf("Script %s is already running on host %s since %s", e.Held.ScriptName, e.Held.HostName, e.Held.Acquired.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("Schedule %s is already running since %s", e.Held.ScheduleName, e.Held.Acquired.Format(time.RFC3339))
+}
+
+// isLockedError returns true if the error is because a run could not take a lock
+func isLockedError(err error) bool {
+	return errors.As(err, &lockedError{})
+}
+
+func scheduleLock(schedule Schedule) ExecutionLock {
+	return ExecutionLock{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		ScriptID:     schedule.ScriptID,
+		key:          "schedule/" + schedule.ID,
+	}
+}
+
+func hostLock(host *Host, script *Script) ExecutionLock {
+	// The agent only allows one run of a script with the same name at a time
+	return ExecutionLock{
+		HostID:     host.ID,
+		HostName:   host.Name,
+		ScriptID:   script.ID,
+		ScriptName: script.Name,
+		key:        "host/" + host.ID + "/" + script.Name,
+	}
+}
+
+// Acquire takes the lock following the overlap policy. If the lock is held by another run and the policy is to queue
+// then Acquire blocks until the lock is released. An error is returned if the run can't start because of the lock.
+func (m *lockManagerType) Acquire(lock ExecutionLock, policy string) (*ExecutionLock, error) {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	lock.ID = newID()
+	lock.Acquired = time.Now()
+
+	holders := m.Holders[lock.key]
+	if len(holders) > 0 && policy != OverlapPolicyAllow {
+		held := holders[0]
+		if policy != OverlapPolicyQueue {
+			return nil, lockedError{held}
+		}
+		if _, waiting := m.Waiting[lock.key]; waiting {
+			return nil, lockedError{held}
+		}
+
+		lock.Waiting = true
+		m.Waiting[lock.key] = lock
+		log.PDebug("Waiting for execution lock", map[string]interface{}{
+			"lock": lock.key,
+		})
+		for len(m.Holders[lock.key]) > 0 {
+			m.Cond.Wait()
+		}
+		delete(m.Waiting, lock.key)
+		lock.Waiting = false
+		lock.Acquired = time.Now()
+	}
+
+	m.Holders[lock.key] = append(m.Holders[lock.key], lock)
+	return &lock, nil
+}
+
+// Release releases the lock
+func (m *lockManagerType) Release(lock *ExecutionLock) {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	holders := []ExecutionLock{}
+	for _, holder := range m.Holders[lock.key] {
+		if holder.ID != lock.ID {
+			holders = append(holders, holder)
+		}
+	}
+	if len(holders) == 0 {
+		delete(m.Holders, lock.key)
+	} else {
+		m.Holders[lock.key] = holders
+	}
+	m.Cond.Broadcast()
+}
+
+// All returns all held locks and runs waiting for a lock, oldest first
+func (m *lockManagerType) All() []ExecutionLock {
+	m.Lock.Lock()
+	defer m.Lock.Unlock()
+
+	locks := []ExecutionLock{}
+	for _, holders := range m.Holders {
+		locks = append(locks, holders...)
+	}
+	for _, waiting := range m.Waiting {
+		locks = append(locks, waiting)
+	}
+	sort.Slice(locks, func(i int, j int) bool {
+		return locks[i].Acquired.Before(locks[j].Acquired)
+	})
+	return locks
+}
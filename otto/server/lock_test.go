@@ -0,0 +1,131 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockManager(t *testing.T) {
+	m := newLockManager()
+	lock := ExecutionLock{ScheduleID: randomString(6)}
+	lock.key = "schedule/" + lock.ScheduleID
+
+	held, err := m.Acquire(lock, OverlapPolicySkip)
+	if err != nil {
+		t.Fatalf("Unexpected error taking lock: %s", err.Error())
+	}
+	if _, err := m.Acquire(lock, OverlapPolicySkip); !isLockedError(err) {
+		t.Fatalf("No locked error seen taking held lock")
+	}
+	allowed, err := m.Acquire(lock, OverlapPolicyAllow)
+	if err != nil {
+		t.Fatalf("Unexpected error taking lock that allows overlap: %s", err.Error())
+	}
+	if len(m.All()) != 2 {
+		t.Fatalf("Unexpected number of locks: %d", len(m.All()))
+	}
+	m.Release(allowed)
+
+	acquired := make(chan *ExecutionLock)
+	go func() {
+		queued, _ := m.Acquire(lock, OverlapPolicyQueue)
+		acquired <- queued
+	}()
+	for i := 0; i < 100 && len(m.All()) < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	locks := m.All()
+	if len(locks) != 2 || !locks[1].Waiting {
+		t.Fatalf("Queued run should be waiting for the lock: %+v", locks)
+	}
+	if _, err := m.Acquire(lock, OverlapPolicyQueue); !isLockedError(err) {
+		t.Errorf("No locked error seen queueing a second run")
+	}
+
+	m.Release(held)
+	queued := <-acquired
+	if queued == nil {
+		t.Fatalf("Queued run should take the lock once it is released")
+	}
+	m.Release(queued)
+	if len(m.All()) != 0 {
+		t.Errorf("Unexpected number of locks: %d", len(m.All()))
+	}
+}
+
+func TestScheduleOverlap(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID: script.ID,
+		Name:     randomString(6),
+		Scope:    ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:  "* * * * *",
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+	if schedule.OverlapPolicy != OverlapPolicySkip {
+		t.Errorf("Unexpected default overlap policy: %s", schedule.OverlapPolicy)
+	}
+
+	// The previous run of the schedule is still in progress
+	lock, lerr := lockManager.Acquire(scheduleLock(*schedule), OverlapPolicySkip)
+	if lerr != nil {
+		t.Fatalf("Unexpected error taking lock: %s", lerr.Error())
+	}
+	schedule.RunNow()
+	lockManager.Release(lock)
+
+	reports := ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of schedule reports: %d", len(reports))
+	}
+	if reports[0].Result != ScheduleResultSkipped || reports[0].SkipReason == "" {
+		t.Errorf("Unexpected skipped report: %+v", reports[0])
+	}
+
+	// The script is already running on the host
+	lock, lerr = lockManager.Acquire(hostLock(host, script), OverlapPolicySkip)
+	if lerr != nil {
+		t.Fatalf("Unexpected error taking lock: %s", lerr.Error())
+	}
+	schedule.RunNow()
+	lockManager.Release(lock)
+
+	reports = ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+	if len(reports) != 2 {
+		t.Fatalf("Unexpected number of schedule reports: %d", len(reports))
+	}
+	for _, report := range reports {
+		if report.SkipReason != "" {
+			continue
+		}
+		if report.Result != ScheduleResultSkipped {
+			t.Errorf("Unexpected schedule result: %d", report.Result)
+		}
+		if report.HostStatus[host.ID] != ScriptStatusSkipped || report.HostSkipReason[host.ID] == "" {
+			t.Errorf("Host should be skipped: %+v", report)
+		}
+	}
+	for _, lock := range lockManager.All() {
+		if lock.ScheduleID == schedule.ID || lock.HostID == host.ID {
+			t.Errorf("Locks should be released once the schedule has finished: %+v", lock)
+		}
+	}
+}
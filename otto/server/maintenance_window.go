@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxMaintenanceWindowMinutes the longest duration of a recurring maintenance window
+const maxMaintenanceWindowMinutes = 7 * 24 * 60
+
+// MaintenanceWindow describes a period of time that controls when scripts may run on hosts. During a blackout window
+// no scripts may run on the hosts of the window. If a host has any maintenance windows, scripts may only run on it
+// while one of those windows is active.
+type MaintenanceWindow struct {
+	ID   string `ds:"primary"`
+	Name string `ds:"unique" min:"1" max:"140"`
+	Mode string
+	// Pattern a cron pattern for the start of a recurring window, in UTC. If empty the window is a one-off range
+	// from Start to End.
+	Pattern string
+	// DurationMinutes how long a recurring window lasts from each time the pattern matches
+	DurationMinutes int
+	Start           time.Time
+	End             time.Time
+	// Global if the window applies to all hosts, otherwise it applies to the hosts and groups in the scope
+	Global  bool
+	Scope   ScheduleScope
+	Enabled bool
+}
+
+// Validate returns an error if the maintenance window is not valid
+func (w MaintenanceWindow) Validate() error {
+	if !IsMaintenanceWindowMode(w.Mode) {
+		return fmt.Errorf("invalid mode '%s'", w.Mode)
+	}
+
+	if w.Pattern != "" {
+		if _, err := parseCronPattern(w.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %s", err.Error())
+		}
+		if w.DurationMinutes < 1 || w.DurationMinutes > maxMaintenanceWindowMinutes {
+			return fmt.Errorf("duration must be between 1 and %d minutes", maxMaintenanceWindowMinutes)
+		}
+	} else {
+		if w.Start.IsZero() || w.End.IsZero() {
+			return fmt.Errorf("a start and end time or a pattern is required")
+		}
+		if !w.End.After(w.Start) {
+			return fmt.Errorf("end time must be after start time")
+		}
+	}
+
+	if w.Global {
+		if !w.Scope.isEmpty() {
+			return fmt.Errorf("cannot specify hosts or groups for a global window")
+		}
+		return nil
+	}
+	if w.Scope.isEmpty() {
+		return fmt.Errorf("window must be global or have at least one host or group")
+	}
+	// Unlike a schedule, a window may have both hosts and groups
+	return w.Scope.validateMembers()
+}
+
+// Active returns true if the window is active at the given time
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	if !w.Enabled {
+		return false
+	}
+
+	t = t.UTC()
+	if w.Pattern == "" {
+		return !t.Before(w.Start) && t.Before(w.End)
+	}
+
+	pattern, err := parseCronPattern(w.Pattern)
+	if err != nil {
+		return false
+	}
+	// The window is active if the pattern matched at any minute within the duration before now
+	minute := t.Truncate(time.Minute)
+	for i := 0; i < w.DurationMinutes; i++ {
+		if pattern.Matches(minute.Add(-time.Duration(i) * time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesTo returns true if the window applies to the given host
+func (w MaintenanceWindow) AppliesTo(host *Host) bool {
+	if w.Global {
+		return true
+	}
+	return w.Scope.Contains(host)
+}
+
+// String returns a description of the window for use in messages
+func (w MaintenanceWindow) String() string {
+	return fmt.Sprintf("%s window '%s'", w.Mode, w.Name)
+}
+
+// blockingWindow returns the window that prevents scripts from running on the host at the given time, or nil if scripts
+// may run. Blackout windows take priority over maintenance windows.
+func blockingWindow(windows []MaintenanceWindow, host *Host, t time.Time) *MaintenanceWindow {
+	var outside *MaintenanceWindow
+	inMaintenance := false
+	for i, window := range windows {
+		if !window.Enabled || !window.AppliesTo(host) {
+			continue
+		}
+		active := window.Active(t)
+		switch window.Mode {
+		case MaintenanceWindowModeBlackout:
+			if active {
+				return &windows[i]
+			}
+		case MaintenanceWindowModeMaintenance:
+			if active {
+				inMaintenance = true
+			} else if outside == nil {
+				outside = &windows[i]
+			}
+		}
+	}
+	if inMaintenance {
+		return nil
+	}
+	return outside
+}
+
+// skipBlockedHosts returns the hosts that are not blocked by a maintenance window at the given time, and the reason
+// that each blocked host was skipped
+func skipBlockedHosts(hosts []*Host, t time.Time) ([]*Host, map[string]string) {
+	windows := MaintenanceWindowStore.AllWindows()
+	allowed := []*Host{}
+	skipped := map[string]string{}
+	for _, host := range hosts {
+		window := blockingWindow(windows, host, t)
+		if window == nil {
+			allowed = append(allowed, host)
+			continue
+		}
+		log.PInfo("Skipping host blocked by maintenance window", map[string]interface{}{
+			"host_id":   host.ID,
+			"window_id": window.ID,
+		})
+		skipped[host.ID] = fmt.Sprintf("Blocked by %s", window.String())
+	}
+	return allowed, skipped
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/limits"
+)
+
+func (s *maintenancewindowStoreObject) AllWindows() (windows []MaintenanceWindow) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		windows = s.allWindows(tx)
+		return nil
+	})
+	return
+}
+
+func (s *maintenancewindowStoreObject) allWindows(tx ds.IReadTransaction) []MaintenanceWindow {
+	objects, err := tx.GetAll(&ds.GetOptions{Sorted: true, Ascending: true})
+	if err != nil {
+		log.Error("Error listing all maintenance windows: error='%s'", err.Error())
+		return []MaintenanceWindow{}
+	}
+	if len(objects) == 0 {
+		return []MaintenanceWindow{}
+	}
+
+	windows := make([]MaintenanceWindow, len(objects))
+	for i, obj := range objects {
+		window, k := obj.(MaintenanceWindow)
+		if !k {
+			log.Fatal("Error listing all maintenance windows: error='%s'", "invalid type")
+		}
+		windows[i] = window
+	}
+
+	return windows
+}
+
+func (s *maintenancewindowStoreObject) WindowWithID(id string) (window *MaintenanceWindow) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		window = s.windowWithID(tx, id)
+		return nil
+	})
+	return
+}
+
+func (s *maintenancewindowStoreObject) windowWithID(tx ds.IReadTransaction, id string) *MaintenanceWindow {
+	object, err := tx.Get(id)
+	if err != nil {
+		log.Error("Error getting maintenance window: id='%s' error='%s'", id, err.Error())
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	window, ok := object.(MaintenanceWindow)
+	if !ok {
+		log.Fatal("Error getting maintenance window: id='%s' error='%s'", id, "invalid type")
+	}
+	return &window
+}
+
+func (s *maintenancewindowStoreObject) windowWithName(tx ds.IReadTransaction, name string) *MaintenanceWindow {
+	object, err := tx.GetUnique("Name", name)
+	if err != nil {
+		log.Error("Error getting maintenance window: name='%s' error='%s'", name, err.Error())
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	window, ok := object.(MaintenanceWindow)
+	if !ok {
+		log.Fatal("Error getting maintenance window: name='%s' error='%s'", name, "invalid type")
+	}
+	return &window
+}
+
+// AllWindowsForScope returns all maintenance windows that are scoped to the given host or group
+func (s *maintenancewindowStoreObject) AllWindowsForScope(id string) []MaintenanceWindow {
+	matched := []MaintenanceWindow{}
+	for _, window := range s.AllWindows() {
+		if window.Scope.references(id) {
+			matched = append(matched, window)
+		}
+	}
+	return matched
+}
+
+// BlockingWindow returns the maintenance window that prevents scripts from running on the host at the given time, or
+// nil if scripts may run
+func (s *maintenancewindowStoreObject) BlockingWindow(host *Host, t time.Time) *MaintenanceWindow {
+	return blockingWindow(s.AllWindows(), host, t)
+}
+
+type newMaintenanceWindowParameters struct {
+	Name            string
+	Mode            string
+	Pattern         string
+	DurationMinutes int
+	Start           time.Time
+	End             time.Time
+	Global          bool
+	Scope           ScheduleScope
+}
+
+func (s *maintenancewindowStoreObject) NewWindow(params newMaintenanceWindowParameters) (window *MaintenanceWindow, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		window, err = s.newWindow(tx, params)
+		return nil
+	})
+	return
+}
+
+func (s *maintenancewindowStoreObject) newWindow(tx ds.IReadWriteTransaction, params newMaintenanceWindowParameters) (*MaintenanceWindow, *Error) {
+	if s.windowWithName(tx, params.Name) != nil {
+		return nil, ErrorUser("Maintenance window with name '%s' already exists", params.Name)
+	}
+
+	window := MaintenanceWindow{
+		ID:              newID(),
+		Name:            params.Name,
+		Mode:            params.Mode,
+		Pattern:         params.Pattern,
+		DurationMinutes: params.DurationMinutes,
+		Start:           params.Start.UTC(),
+		End:             params.End.UTC(),
+		Global:          params.Global,
+		Scope:           params.Scope,
+		Enabled:         true,
+	}
+	if err := limits.Check(window); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := window.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if err := tx.Add(window); err != nil {
+		log.Error("Error adding new maintenance window '%s': %s", params.Name, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.Info("Added new maintenance window '%s'", params.Name)
+	return &window, nil
+}
+
+type editMaintenanceWindowParameters struct {
+	Name            string
+	Mode            string
+	Pattern         string
+	DurationMinutes int
+	Start           time.Time
+	End             time.Time
+	Global          bool
+	Scope           ScheduleScope
+	Enabled         bool
+}
+
+func (s *maintenancewindowStoreObject) EditWindow(window *MaintenanceWindow, params editMaintenanceWindowParameters) (newWindow *MaintenanceWindow, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		newWindow, err = s.editWindow(tx, window, params)
+		return nil
+	})
+	return
+}
+
+func (s *maintenancewindowStoreObject) editWindow(tx ds.IReadWriteTransaction, window *MaintenanceWindow, params editMaintenanceWindowParameters) (*MaintenanceWindow, *Error) {
+	if existing := s.windowWithName(tx, params.Name); existing != nil && existing.ID != window.ID {
+		return nil, ErrorUser("Maintenance window with name '%s' already exists", params.Name)
+	}
+
+	window.Name = params.Name
+	window.Mode = params.Mode
+	window.Pattern = params.Pattern
+	window.DurationMinutes = params.DurationMinutes
+	window.Start = params.Start.UTC()
+	window.End = params.End.UTC()
+	window.Global = params.Global
+	window.Scope = params.Scope
+	window.Enabled = params.Enabled
+	if err := limits.Check(window); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := window.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if err := tx.Update(*window); err != nil {
+		log.Error("Error updating maintenance window '%s': %s", window.ID, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.Info("Updated maintenance window '%s'", window.ID)
+	return window, nil
+}
+
+func (s *maintenancewindowStoreObject) DeleteWindow(window *MaintenanceWindow) (err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		err = s.deleteWindow(tx, window)
+		return nil
+	})
+	return
+}
+
+func (s *maintenancewindowStoreObject) deleteWindow(tx ds.IReadWriteTransaction, window *MaintenanceWindow) *Error {
+	if err := tx.Delete(*window); err != nil {
+		log.Error("Error deleting maintenance window '%s': %s", window.ID, err.Error())
+		return ErrorFrom(err)
+	}
+
+	log.Info("Deleted maintenance window '%s'", window.ID)
+	return nil
+}
+
+// checkMaintenanceWindows returns an error if any of the hosts are blocked by a maintenance window. If override is
+// true and the user is permitted to override windows, the override is recorded for each blocked host and no error is
+// returned.
+func checkMaintenanceWindows(hosts []*Host, override bool, user *User, action string) *Error {
+	windows := MaintenanceWindowStore.AllWindows()
+	now := time.Now()
+	for _, host := range hosts {
+		window := blockingWindow(windows, host, now)
+		if window == nil {
+			continue
+		}
+
+		if !override {
+			return ErrorUser("Host %s is blocked by %s", host.Name, window.String())
+		}
+		if !user.Permissions.CanOverrideWindows {
+			EventStore.UserPermissionDenied(user.Username, "Override "+window.String())
+			return ErrorUser("Permission denied")
+		}
+		log.PWarn("Maintenance window overridden", map[string]interface{}{
+			"window_id": window.ID,
+			"host_id":   host.ID,
+			"action":    action,
+			"username":  user.Username,
+		})
+		EventStore.MaintenanceWindowOverridden(window, host, action, user.Username)
+	}
+	return nil
+}
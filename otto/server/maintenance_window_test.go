@@ -0,0 +1,251 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronPattern(t *testing.T) {
+	check := func(pattern string, t1 time.Time, expected bool) {
+		p, err := parseCronPattern(pattern)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing pattern '%s': %s", pattern, err.Error())
+		}
+		if p.Matches(t1) != expected {
+			t.Errorf("Unexpected match for pattern '%s' at %s, expected %v", pattern, t1, expected)
+		}
+	}
+
+	// Monday 2 March 2026
+	monday := time.Date(2026, 3, 2, 2, 30, 0, 0, time.UTC)
+	check("* * * * *", monday, true)
+	check("30 2 * * *", monday, true)
+	check("*/15 * * * *", monday, true)
+	check("*/20 * * * *", monday, false)
+	check("0-29 * * * *", monday, false)
+	check("15,30,45 1-3 * * *", monday, true)
+	check("30 2 * * 1-5", monday, true)
+	check("30 2 * * 0,6", monday, false)
+	check("30 2 2 3 *", monday, true)
+	check("30 2 * 4 *", monday, false)
+	check("0 0 * * 7", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), true)
+
+	for _, pattern := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := parseCronPattern(pattern); err == nil {
+			t.Errorf("No error seen for invalid pattern '%s'", pattern)
+		}
+	}
+}
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	oneOff := MaintenanceWindow{
+		Mode:    MaintenanceWindowModeBlackout,
+		Start:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		Global:  true,
+		Enabled: true,
+	}
+	if err := oneOff.Validate(); err != nil {
+		t.Fatalf("Unexpected error validating window: %s", err.Error())
+	}
+	if !oneOff.Active(time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("One-off window should be active during range")
+	}
+	if oneOff.Active(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("One-off window should not be active at end")
+	}
+
+	// Every day from 23:30 to 00:30
+	recurring := MaintenanceWindow{
+		Mode:            MaintenanceWindowModeMaintenance,
+		Pattern:         "30 23 * * *",
+		DurationMinutes: 60,
+		Global:          true,
+		Enabled:         true,
+	}
+	if err := recurring.Validate(); err != nil {
+		t.Fatalf("Unexpected error validating window: %s", err.Error())
+	}
+	if !recurring.Active(time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("Recurring window should be active at start")
+	}
+	if !recurring.Active(time.Date(2026, 3, 3, 0, 29, 59, 0, time.UTC)) {
+		t.Errorf("Recurring window should be active after midnight")
+	}
+	if recurring.Active(time.Date(2026, 3, 3, 0, 30, 0, 0, time.UTC)) {
+		t.Errorf("Recurring window should not be active at end")
+	}
+	if recurring.Active(time.Date(2026, 3, 2, 23, 29, 0, 0, time.UTC)) {
+		t.Errorf("Recurring window should not be active before start")
+	}
+
+	recurring.Enabled = false
+	if recurring.Active(time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("Disabled window should not be active")
+	}
+
+	invalid := []MaintenanceWindow{
+		{Mode: "invalid", Pattern: "* * * * *", DurationMinutes: 1, Global: true},
+		{Mode: MaintenanceWindowModeBlackout, Pattern: "* * * * *", Global: true},
+		{Mode: MaintenanceWindowModeBlackout, Pattern: "* * * * *", DurationMinutes: maxMaintenanceWindowMinutes + 1, Global: true},
+		{Mode: MaintenanceWindowModeBlackout, Global: true},
+		{Mode: MaintenanceWindowModeBlackout, Start: oneOff.End, End: oneOff.Start, Global: true},
+		{Mode: MaintenanceWindowModeBlackout, Pattern: "* * * * *", DurationMinutes: 1},
+		{Mode: MaintenanceWindowModeBlackout, Pattern: "* * * * *", DurationMinutes: 1, Scope: ScheduleScope{HostIDs: []string{newID()}}},
+	}
+	for i, window := range invalid {
+		if err := window.Validate(); err == nil {
+			t.Errorf("No error seen for invalid window %d", i)
+		}
+	}
+}
+
+func TestBlockingWindow(t *testing.T) {
+	host := &Host{ID: newID(), GroupIDs: []string{newID()}}
+	other := &Host{ID: newID()}
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	inactive := MaintenanceWindow{
+		Mode:    MaintenanceWindowModeMaintenance,
+		Start:   now.Add(time.Hour),
+		End:     now.Add(2 * time.Hour),
+		Scope:   ScheduleScope{GroupIDs: host.GroupIDs},
+		Enabled: true,
+	}
+	active := MaintenanceWindow{
+		Mode:    MaintenanceWindowModeMaintenance,
+		Start:   now.Add(-time.Hour),
+		End:     now.Add(time.Hour),
+		Scope:   ScheduleScope{HostIDs: []string{host.ID}},
+		Enabled: true,
+	}
+	blackout := MaintenanceWindow{
+		Mode:    MaintenanceWindowModeBlackout,
+		Start:   now.Add(-time.Hour),
+		End:     now.Add(time.Hour),
+		Scope:   ScheduleScope{HostIDs: []string{host.ID}},
+		Enabled: true,
+	}
+
+	if window := blockingWindow([]MaintenanceWindow{inactive}, host, now); window == nil {
+		t.Errorf("Host should be blocked outside of its maintenance window")
+	}
+	if window := blockingWindow([]MaintenanceWindow{inactive}, other, now); window != nil {
+		t.Errorf("Host without windows should not be blocked")
+	}
+	if window := blockingWindow([]MaintenanceWindow{inactive, active}, host, now); window != nil {
+		t.Errorf("Host should not be blocked during any of its maintenance windows")
+	}
+	if window := blockingWindow([]MaintenanceWindow{inactive, active, blackout}, host, now); window == nil || window.Mode != MaintenanceWindowModeBlackout {
+		t.Errorf("Blackout window should take priority over maintenance windows")
+	}
+	blackout.Enabled = false
+	if window := blockingWindow([]MaintenanceWindow{blackout}, host, now); window != nil {
+		t.Errorf("Disabled window should not block host")
+	}
+}
+
+func TestMaintenanceWindowSkipsSchedule(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+	window, err := MaintenanceWindowStore.NewWindow(newMaintenanceWindowParameters{
+		Name:            randomString(6),
+		Mode:            MaintenanceWindowModeBlackout,
+		Pattern:         "* * * * *",
+		DurationMinutes: 1,
+		Scope:           ScheduleScope{HostIDs: []string{host.ID}},
+	})
+	if err != nil {
+		t.Fatalf("Error making maintenance window: %s", err.Message)
+	}
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID: script.ID,
+		Name:     randomString(6),
+		Scope:    ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:  "* * * * *",
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+	schedule.RunNow()
+
+	reports := ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of schedule reports: %d", len(reports))
+	}
+	report := reports[0]
+	if report.Result != ScheduleResultSkipped {
+		t.Errorf("Unexpected schedule result: %d", report.Result)
+	}
+	if report.HostStatus[host.ID] != ScriptStatusSkipped {
+		t.Errorf("Unexpected host status: %s", report.HostStatus[host.ID])
+	}
+	if report.HostSkipReason[host.ID] == "" {
+		t.Errorf("Host skip reason should be recorded")
+	}
+	if len(report.HostAttempts[host.ID]) != 0 {
+		t.Errorf("Skipped host should not be run")
+	}
+
+	if err := HostStore.DeleteHost(host); err == nil {
+		t.Errorf("No error seen deleting host used by maintenance window")
+	}
+	if err := MaintenanceWindowStore.DeleteWindow(window); err != nil {
+		t.Fatalf("Error deleting maintenance window: %s", err.Message)
+	}
+}
+
+func TestMaintenanceWindowOverride(t *testing.T) {
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+	window, err := MaintenanceWindowStore.NewWindow(newMaintenanceWindowParameters{
+		Name:            randomString(6),
+		Mode:            MaintenanceWindowModeBlackout,
+		Pattern:         "* * * * *",
+		DurationMinutes: 1,
+		Scope:           ScheduleScope{HostIDs: []string{host.ID}},
+	})
+	if err != nil {
+		t.Fatalf("Error making maintenance window: %s", err.Message)
+	}
+	defer MaintenanceWindowStore.DeleteWindow(window)
+
+	user := &User{Username: randomString(6), Permissions: UserPermissionsMin()}
+	hosts := []*Host{host}
+
+	if err := checkMaintenanceWindows(hosts, false, user, "test"); err == nil {
+		t.Errorf("No error seen running on host in blackout window")
+	}
+	if err := checkMaintenanceWindows(hosts, true, user, "test"); err == nil {
+		t.Errorf("No error seen overriding window without permission")
+	}
+
+	user.Permissions.CanOverrideWindows = true
+	if err := checkMaintenanceWindows(hosts, false, user, "test"); err == nil {
+		t.Errorf("No error seen running on host in blackout window without override")
+	}
+	if err := checkMaintenanceWindows(hosts, true, user, "test"); err != nil {
+		t.Errorf("Unexpected error overriding window: %s", err.Message)
+	}
+}
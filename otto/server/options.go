@@ -24,6 +24,7 @@ type OttoOptions struct {
 type OptionsGeneral struct {
 	ServerURL         string
 	GlobalEnvironment []environ.Variable
+	RunRetentionDays  uint
 }
 
 // OptionsAuthentication describes the authentication options
@@ -35,6 +36,8 @@ type OptionsAuthentication struct {
 // OptionsSecurity describes security options
 type OptionsSecurity struct {
 	RotateID OptionsRotateID
+	// ApprovalExpiryHours how long requests to run scripts that require approval wait before they expire
+	ApprovalExpiryHours uint
 }
 
 // OptionsRotateID describes identity rotation options
@@ -48,6 +51,7 @@ type OptionsNetwork struct {
 	ForceIPVersion     string
 	Timeout            int64
 	HeartbeatFrequency int64
+	MaxParallelism     int
 }
 
 // Options the global options
@@ -60,6 +64,7 @@ func LoadOptions() {
 		General: OptionsGeneral{
 			ServerURL:         "http://" + bindAddress + "/",
 			GlobalEnvironment: []environ.Variable{},
+			RunRetentionDays:  30,
 		},
 		Authentication: OptionsAuthentication{
 			MaxAgeMinutes: 60,
@@ -69,12 +74,14 @@ func LoadOptions() {
 			ForceIPVersion:     IPVersionOptionAuto,
 			Timeout:            10,
 			HeartbeatFrequency: 5,
+			MaxParallelism:     25,
 		},
 		Security: OptionsSecurity{
 			RotateID: OptionsRotateID{
 				Enabled:       true,
 				FrequencyDays: 7,
 			},
+			ApprovalExpiryHours: 24,
 		},
 	}
 
@@ -153,10 +160,16 @@ func (o *OttoOptions) Validate() error {
 	if !IsIPVersionOption(o.Network.ForceIPVersion) {
 		return fmt.Errorf("invalid value for IP version")
 	}
+	if o.Network.MaxParallelism < 1 {
+		return fmt.Errorf("max parallelism must be greater than 0")
+	}
 	if o.Security.RotateID.Enabled {
 		if o.Security.RotateID.FrequencyDays == 0 {
 			return fmt.Errorf("id rotation frequency must be greater than 0")
 		}
 	}
+	if o.Security.ApprovalExpiryHours == 0 {
+		return fmt.Errorf("approval expiry must be greater than 0")
+	}
 	return nil
 }
@@ -55,6 +55,7 @@ func startup() {
 	CacheSetup()
 	CronSetup()
 	checkFirstRun()
+	startReverseListener()
 	go StartHeartbeatMonitor()
 }
 
@@ -27,12 +27,14 @@ func testSetup() {
 	Directories = apiDirectories{
 		Base:        operatingDirectory,
 		Attachments: path.Join(operatingDirectory, "attachments"),
+		Runs:        path.Join(operatingDirectory, "runs"),
 		Logs:        path.Join(operatingDirectory, "logs"),
 		Data:        path.Join(operatingDirectory, "data"),
 		Static:      path.Join(operatingDirectory, "static"),
 	}
 
 	os.Mkdir(Directories.Attachments, os.ModePerm)
+	os.Mkdir(Directories.Runs, os.ModePerm)
 	os.Mkdir(Directories.Logs, os.ModePerm)
 	os.Mkdir(Directories.Data, os.ModePerm)
 	os.Mkdir(Directories.Static, os.ModePerm)
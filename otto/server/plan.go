@@ -0,0 +1,191 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// ExecutionPlan describes what would happen if a script were run on a set of hosts. Plans are made without contacting
+// any agent.
+type ExecutionPlan struct {
+	ScriptID       string
+	ScriptName     string
+	ScriptRevision int
+	RunAs          RunAs
+	TimeoutSeconds int64
+	AfterExecution string
+	Attachments    []PlanAttachment
+	Hosts          []HostPlan
+}
+
+// PlanAttachment describes an attachment that would be uploaded to each host
+type PlanAttachment struct {
+	AttachmentID string
+	Path         string
+	Owner        RunAs
+	Mode         uint32
+	Length       uint64
+	Checksum     string
+	AfterScript  bool
+	// Template if the attachment is rendered for each host, see HostPlan.AttachmentChecksums
+	Template bool
+}
+
+// HostPlan describes what would happen on a single host
+type HostPlan struct {
+	HostID   string
+	HostName string
+	Address  string
+	// Reachable if the last heartbeat to the host was successful. Hosts that have not been contacted yet are not
+	// reachable.
+	Reachable bool
+	LastReply time.Time
+	// Blocked the reason that the host would be skipped because of a maintenance window, if any
+	Blocked string
+	// Error any error rendering the script for this host, the script would fail to run
+	Error       string
+	Environment []PlanVariable
+	// AttachmentChecksums the checksums of template attachments as rendered for this host, keyed by attachment ID
+	AttachmentChecksums map[string]string
+}
+
+// PlanVariable describes an environment variable and the layer that it came from
+type PlanVariable struct {
+	Key    string
+	Value  string
+	Secret bool
+	Source string
+	// Group the name of the group if the source is a group
+	Group string
+}
+
+// planEnvironment returns the given environment variables for the script on this host along with the layer each
+// variable came from. If hideSecrets is true then the values of secret variables are masked.
+func (host *Host) planEnvironment(script *Script, variables []environ.Variable, hideSecrets bool) []PlanVariable {
+	sources := map[string]environmentLayer{}
+	for _, layer := range host.environmentLayersForScript(script) {
+		for _, variable := range layer.Variables {
+			sources[variable.Key] = layer
+		}
+	}
+
+	plan := make([]PlanVariable, len(variables))
+	for i, variable := range variables {
+		layer := sources[variable.Key]
+		plan[i] = PlanVariable{
+			Key:    variable.Key,
+			Value:  variable.Value,
+			Secret: variable.Secret,
+			Source: layer.Source,
+			Group:  layer.Group,
+		}
+		if hideSecrets && variable.Secret {
+			plan[i].Value = "********"
+		}
+	}
+	return plan
+}
+
+// PlanScript returns the plan for running the script on the given hosts. If hideSecrets is true then the values of
+// secret environment variables are masked.
+func PlanScript(script *Script, hosts []Host, hideSecrets bool) (*ExecutionPlan, *Error) {
+	attachments, err := script.Attachments()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := ExecutionPlan{
+		ScriptID:       script.ID,
+		ScriptName:     script.Name,
+		ScriptRevision: script.Revision,
+		RunAs:          script.RunAs,
+		TimeoutSeconds: script.TimeoutSeconds,
+		AfterExecution: script.AfterExecution,
+		Attachments:    make([]PlanAttachment, len(attachments)),
+		Hosts:          make([]HostPlan, len(hosts)),
+	}
+	for i, attachment := range attachments {
+		plan.Attachments[i] = PlanAttachment{
+			AttachmentID: attachment.ID,
+			Path:         attachment.Path,
+			Owner:        attachment.Owner,
+			Mode:         attachment.Mode,
+			Length:       attachment.Size,
+			Checksum:     attachment.Checksum,
+			AfterScript:  attachment.AfterScript,
+			Template:     attachment.Template,
+		}
+	}
+
+	sort.Slice(hosts, func(i int, j int) bool {
+		return hosts[i].Name < hosts[j].Name
+	})
+
+	now := time.Now()
+	windows := MaintenanceWindowStore.AllWindows()
+	for i := range hosts {
+		host := &hosts[i]
+		variables := host.environmentVariablesForScript(script)
+		hostPlan := HostPlan{
+			HostID:              host.ID,
+			HostName:            host.Name,
+			Address:             host.Address,
+			Environment:         host.planEnvironment(script, variables, hideSecrets),
+			AttachmentChecksums: map[string]string{},
+		}
+		if heartbeat := heartbeatStore.LastHeartbeat(host); heartbeat != nil {
+			hostPlan.Reachable = heartbeat.IsReachable
+			hostPlan.LastReply = heartbeat.LastReply
+		}
+		if window := blockingWindow(windows, host, now); window != nil {
+			hostPlan.Blocked = fmt.Sprintf("Blocked by %s", window.String())
+		}
+
+		// Templates are rendered with the real values so that the checksums match what would be uploaded
+		rendered, err := host.renderScript(script, variables)
+		if err != nil {
+			if err.Server {
+				return nil, err
+			}
+			hostPlan.Error = err.Message
+		} else {
+			for _, attachment := range rendered.Attachments {
+				if file := rendered.file(attachment.ID); file != nil {
+					hostPlan.AttachmentChecksums[attachment.ID] = file.Info.Checksum
+				}
+			}
+		}
+
+		plan.Hosts[i] = hostPlan
+	}
+
+	return &plan, nil
+}
+
+// Plan returns the plan for the next run of this schedule. Schedules that run a workflow can't be planned.
+func (s Schedule) Plan(hideSecrets bool) (*ExecutionPlan, *Error) {
+	if s.WorkflowID != "" {
+		return nil, ErrorUser("Schedules that run a workflow can't be planned")
+	}
+
+	script := ScriptCache.ByID(s.ScriptID)
+	if script == nil {
+		return nil, ErrorUser("No script with ID %s", s.ScriptID)
+	}
+	script, perr := script.WithParameters(s.Parameters)
+	if perr != nil {
+		return nil, ErrorUser(perr.Error())
+	}
+	if s.TimeoutSeconds > 0 {
+		script.TimeoutSeconds = s.TimeoutSeconds
+	}
+
+	hosts, err := s.Scope.Hosts()
+	if err != nil {
+		return nil, err
+	}
+	return PlanScript(script, hosts, hideSecrets)
+}
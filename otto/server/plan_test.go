@@ -0,0 +1,109 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestPlanScript(t *testing.T) {
+	group, err := GroupStore.NewGroup(newGroupParameters{
+		Name: randomString(6),
+		Environment: []environ.Variable{
+			environ.New("GROUP_VAR", "group"),
+			environ.New("OVERRIDE", "group"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:     randomString(6),
+		Address:  randLocalhostIP(),
+		Port:     1,
+		GroupIDs: []string{group.ID},
+		Environment: []environ.Variable{
+			environ.New("OVERRIDE", "host"),
+			{Key: "PASSWORD", Value: "hunter2", Secret: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:           randomString(6),
+		Executable:     "/bin/sh",
+		Script:         "echo hello",
+		RunLevel:       ScriptRunLevelReadOnly,
+		AfterExecution: AgentActionReboot,
+		Environment: []environ.Variable{
+			environ.New("SCRIPT_VAR", "script"),
+			environ.New("OVERRIDE", "script"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID: script.ID,
+		Name:     randomString(6),
+		Scope: ScheduleScope{
+			GroupIDs: []string{group.ID},
+		},
+		Pattern: "0 * * * *",
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+
+	plan, err := schedule.Plan(true)
+	if err != nil {
+		t.Fatalf("Error planning schedule: %s", err.Message)
+	}
+	if plan.AfterExecution != AgentActionReboot {
+		t.Errorf("Unexpected after execution action: %s", plan.AfterExecution)
+	}
+	if len(plan.Hosts) != 1 || plan.Hosts[0].HostID != host.ID {
+		t.Fatalf("Unexpected hosts in plan: %+v", plan.Hosts)
+	}
+	hostPlan := plan.Hosts[0]
+	if hostPlan.Reachable {
+		t.Errorf("Host without a heartbeat should not be reachable")
+	}
+
+	variables := map[string]PlanVariable{}
+	for _, variable := range hostPlan.Environment {
+		variables[variable.Key] = variable
+	}
+	check := func(key, value, source string) {
+		variable, present := variables[key]
+		if !present {
+			t.Errorf("Missing variable %s", key)
+			return
+		}
+		if variable.Value != value || variable.Source != source {
+			t.Errorf("Unexpected variable %s. Expected '%s' from %s got '%s' from %s", key, value, source, variable.Value, variable.Source)
+		}
+	}
+	check("OTTO_HOST_ADDRESS", host.Address, EnvironmentSourceServer)
+	check("SCRIPT_VAR", "script", EnvironmentSourceScript)
+	check("GROUP_VAR", "group", EnvironmentSourceGroup)
+	check("OVERRIDE", "host", EnvironmentSourceHost)
+	check("PASSWORD", "********", EnvironmentSourceHost)
+	if variables["GROUP_VAR"].Group != group.Name {
+		t.Errorf("Unexpected group for variable: %s", variables["GROUP_VAR"].Group)
+	}
+
+	plan, err = PlanScript(script, []Host{*host}, false)
+	if err != nil {
+		t.Fatalf("Error planning script: %s", err.Message)
+	}
+	for _, variable := range plan.Hosts[0].Environment {
+		if variable.Key == "PASSWORD" && variable.Value != "hunter2" {
+			t.Errorf("Secret value should not be masked")
+		}
+	}
+}
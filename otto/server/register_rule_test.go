@@ -168,9 +168,17 @@ func TestRegisterRuleEndToEnd(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error making new group: %s", err.Message)
 	}
+	centosGroup, err := GroupStore.NewGroup(newGroupParameters{
+		Name:      randomString(6),
+		ScriptIDs: []string{},
+	})
+	if err != nil {
+		t.Fatalf("Error making new group: %s", err.Message)
+	}
 	centos7group, err := GroupStore.NewGroup(newGroupParameters{
 		Name:      randomString(6),
 		ScriptIDs: []string{},
+		ParentID:  centosGroup.ID,
 	})
 	if err != nil {
 		t.Fatalf("Error making new group: %s", err.Message)
@@ -178,6 +186,7 @@ func TestRegisterRuleEndToEnd(t *testing.T) {
 	centos8group, err := GroupStore.NewGroup(newGroupParameters{
 		Name:      randomString(6),
 		ScriptIDs: []string{},
+		ParentID:  centosGroup.ID,
 	})
 	if err != nil {
 		t.Fatalf("Error making new group: %s", err.Message)
@@ -186,6 +195,18 @@ func TestRegisterRuleEndToEnd(t *testing.T) {
 	RegisterRuleStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
 		return tx.DeleteAll()
 	})
+	if _, err := RegisterRuleStore.NewRule(newRegisterRuleParams{
+		Name: "CentOS Hosts",
+		Clauses: []RegisterRuleClause{
+			{
+				Property: RegisterRulePropertyDistributionName,
+				Pattern:  "CentOS Linux",
+			},
+		},
+		GroupID: centosGroup.ID,
+	}); err != nil {
+		t.Fatalf("Error making new rule: %s", err.Message)
+	}
 	if _, err := RegisterRuleStore.NewRule(newRegisterRuleParams{
 		Name: "CentOS 7 Hosts",
 		Clauses: []RegisterRuleClause{
@@ -309,6 +330,29 @@ func TestRegisterRuleEndToEnd(t *testing.T) {
 		t.Errorf("[centos 8] Incorrect group")
 	}
 
+	// Test that a host that only matches the rule for the parent group gets added to the parent group
+	centos9address := "127.0.0.5"
+	if webReply := v.Register(mockRequest(centos9address, Key, otto.RegisterRequest{
+		AgentIdentity: mustIdentity().PublicKeyString(),
+		Port:          12444,
+		Nonce:         secutil.RandomString(6),
+		Properties: otto.RegisterRequestProperties{
+			Hostname:            randomString(6),
+			KernelName:          randomString(6),
+			KernelVersion:       randomString(6),
+			DistributionName:    "CentOS Linux",
+			DistributionVersion: "9",
+		},
+	})); webReply.Status != 200 {
+		t.Fatalf("[centos 9] Unexpected error trying to register valid host: HTTP %d", webReply.Status)
+	}
+	if HostStore.HostWithAddress(centos9address) == nil {
+		t.Fatalf("[centos 9] Host was not registered")
+	}
+	if HostStore.HostWithAddress(centos9address).GroupIDs[0] != centosGroup.ID {
+		t.Errorf("[centos 9] Incorrect group")
+	}
+
 	// Test that an incorrect Key does not get registered
 	incorrectKeyAddress := "127.0.0.3"
 	if webReply := v.Register(mockRequest(incorrectKeyAddress, randomString(12), otto.RegisterRequest{
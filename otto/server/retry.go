@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRetryAttempts the highest number of attempts a retry policy may allow
+const maxRetryAttempts = 10
+
+// RetryPolicy describes when a failed script run is retried on a host. The zero value never retries.
+type RetryPolicy struct {
+	// MaxAttempts the maximum number of times the script is run on a host, including the first attempt. 0 or 1 never
+	// retries.
+	MaxAttempts int
+	// BackoffSeconds how long to wait before the first retry
+	BackoffSeconds int
+	// Exponential if the backoff is doubled for each retry after the first
+	Exponential bool
+	// RetryOn the classes of failures that are retried
+	RetryOn []string
+}
+
+// HostAttempt describes a single attempt at running a script on a host
+type HostAttempt struct {
+	Attempt int
+	// RunID the ID of the script run in the run history
+	RunID  string
+	Start  time.Time
+	Result int
+	Status string
+	// Failure the class of failure, if the attempt failed
+	Failure string
+	Error   string
+}
+
+// Validate returns an error if the retry policy is not valid
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 || p.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("max attempts must be between 0 and %d", maxRetryAttempts)
+	}
+	if p.BackoffSeconds < 0 {
+		return fmt.Errorf("backoff cannot be negative")
+	}
+	for _, failure := range p.RetryOn {
+		if !IsRetryFailure(failure) {
+			return fmt.Errorf("invalid retry failure '%s'", failure)
+		}
+	}
+	return nil
+}
+
+// backoff returns how long to wait before the given attempt, starting at 2 for the first retry
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := time.Duration(p.BackoffSeconds) * time.Second
+	if p.Exponential {
+		for i := 2; i < attempt; i++ {
+			backoff *= 2
+		}
+	}
+	return backoff
+}
+
+// retries returns true if the policy allows another attempt after a failure of the given class
+func (p RetryPolicy) retries(attempt int, failure string) bool {
+	return failure != "" && attempt < p.MaxAttempts && sliceContains(failure, p.RetryOn)
+}
+
+// scriptFailure returns the class of failure for a script run, or an empty string if the run succeeded
+func scriptFailure(result *ScriptResult, err error) string {
+	if err != nil || result == nil || result.RunError != "" {
+		return RetryFailureConnection
+	}
+	if result.Result.ExecError != "" {
+		return RetryFailureExec
+	}
+	if !result.Succeeded() {
+		return RetryFailureExitCode
+	}
+	return ""
+}
+
+// runScriptWithRetry runs the script on the host, retrying failed attempts as allowed by the policy. Every attempt is
+// saved to the run history. The result and error are from the last attempt.
+func (host *Host) runScriptWithRetry(script *Script, policy RetryPolicy, schedule *Schedule, triggeredBy string) (*ScriptResult, []HostAttempt, error) {
+	attempts := []HostAttempt{}
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		result, err := host.RunScript(script, nil)
+		if isLockedError(err) {
+			// The script never started, there is nothing to save or retry
+			return nil, attempts, err
+		}
+		run, _ := ScriptRunStore.NewRun(newScriptRunParameters{
+			Script:      script,
+			Host:        host,
+			Schedule:    schedule,
+			TriggeredBy: triggeredBy,
+			Start:       start,
+			Result:      result,
+			Error:       err,
+		})
+
+		hostAttempt := HostAttempt{
+			Attempt: attempt,
+			Start:   start,
+			Failure: scriptFailure(result, err),
+		}
+		if run != nil {
+			hostAttempt.RunID = run.ID
+		}
+		if err != nil {
+			hostAttempt.Result = 1
+			hostAttempt.Status = ScriptStatusFailed
+			hostAttempt.Error = err.Error()
+		} else {
+			EventStore.ScriptRun(script, host, result, schedule, triggeredBy)
+			hostAttempt.Result = result.Result.Code
+			hostAttempt.Status = result.Status
+			hostAttempt.Error = result.RunError
+			if hostAttempt.Error == "" {
+				hostAttempt.Error = result.FailureReason
+			}
+		}
+		attempts = append(attempts, hostAttempt)
+
+		if !policy.retries(attempt, hostAttempt.Failure) {
+			return result, attempts, err
+		}
+
+		backoff := policy.backoff(attempt + 1)
+		log.PWarn("Retrying failed script run", map[string]interface{}{
+			"host_id":   host.ID,
+			"script_id": script.ID,
+			"attempt":   attempt,
+			"failure":   hostAttempt.Failure,
+			"backoff":   backoff.String(),
+		})
+		time.Sleep(backoff)
+	}
+}
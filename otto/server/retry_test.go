@@ -0,0 +1,111 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	valid := []RetryPolicy{
+		{},
+		{MaxAttempts: 3, BackoffSeconds: 5, Exponential: true, RetryOn: []string{RetryFailureConnection, RetryFailureExitCode}},
+	}
+	for _, policy := range valid {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Unexpected error validating retry policy %+v: %s", policy, err.Error())
+		}
+	}
+
+	invalid := []RetryPolicy{
+		{MaxAttempts: -1},
+		{MaxAttempts: maxRetryAttempts + 1},
+		{MaxAttempts: 2, BackoffSeconds: -1},
+		{MaxAttempts: 2, RetryOn: []string{"everything"}},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("No error seen when one expected for retry policy %+v", policy)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	fixed := RetryPolicy{BackoffSeconds: 2}
+	exponential := RetryPolicy{BackoffSeconds: 2, Exponential: true}
+
+	expected := map[int][]time.Duration{
+		2: {2 * time.Second, 2 * time.Second},
+		3: {2 * time.Second, 4 * time.Second},
+		4: {2 * time.Second, 8 * time.Second},
+	}
+	for attempt, backoff := range expected {
+		if fixed.backoff(attempt) != backoff[0] {
+			t.Errorf("Unexpected fixed backoff for attempt %d: %s", attempt, fixed.backoff(attempt))
+		}
+		if exponential.backoff(attempt) != backoff[1] {
+			t.Errorf("Unexpected exponential backoff for attempt %d: %s", attempt, exponential.backoff(attempt))
+		}
+	}
+}
+
+func TestRetryUnreachableHost(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	// Connection failures are not retried unless the policy includes them
+	_, attempts, _ := host.runScriptWithRetry(script, RetryPolicy{MaxAttempts: 3, RetryOn: []string{RetryFailureExitCode}}, nil, "test")
+	if len(attempts) != 1 {
+		t.Errorf("Unexpected number of attempts: %d", len(attempts))
+	}
+
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID: script.ID,
+		Name:     randomString(6),
+		Scope:    ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:  "* * * * *",
+		Retry:    RetryPolicy{MaxAttempts: 3, RetryOn: []string{RetryFailureConnection}},
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+	schedule.RunNow()
+
+	reports := ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of schedule reports: %d", len(reports))
+	}
+	report := reports[0]
+	if report.Result != ScheduleResultFail {
+		t.Errorf("Unexpected schedule result: %d", report.Result)
+	}
+	attempts = report.HostAttempts[host.ID]
+	if len(attempts) != 3 {
+		t.Fatalf("Unexpected number of attempts: %d", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Attempt != i+1 {
+			t.Errorf("Unexpected attempt number %d for attempt %d", attempt.Attempt, i+1)
+		}
+		if attempt.Failure != RetryFailureConnection {
+			t.Errorf("Unexpected failure for attempt %d: %s", i+1, attempt.Failure)
+		}
+		if attempt.RunID == "" || ScriptRunStore.RunWithID(attempt.RunID) == nil {
+			t.Errorf("Attempt %d should be saved to the run history", i+1)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+// reverseListenAddress the address to listen for reverse connections from agents on. Disabled if empty.
+var reverseListenAddress = ""
+
+// startReverseListener will start listening for reverse connections from agents, if enabled
+func startReverseListener() {
+	if reverseListenAddress == "" {
+		return
+	}
+
+	l, err := net.Listen("tcp", reverseListenAddress)
+	if err != nil {
+		log.Fatal("Error listening for reverse connections on %s: %s", reverseListenAddress, err.Error())
+	}
+	log.Info("Listening for reverse connections on %s", reverseListenAddress)
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				log.PError("Error accepting reverse connection", map[string]interface{}{
+					"error": err.Error(),
+				})
+				return
+			}
+			go acceptReverseConnection(c)
+		}
+	}()
+}
+
+// acceptReverseConnection will perform the handshake with an agent that connected to the server and keep the session
+// available for actions until it is closed. Blocking.
+func acceptReverseConnection(c net.Conn) {
+	var host *Host
+	client, err := otto.AcceptClient(c, otto.AcceptClientOptions{
+		GetIdentity: func(agentPublicKey string) (*otto.Identity, error) {
+			h := HostCache.ByTrustedIdentity(agentPublicKey)
+			if h == nil {
+				return nil, fmt.Errorf("no host with identity")
+			}
+			if !h.IsReverse() {
+				return nil, fmt.Errorf("host does not use reverse connections")
+			}
+			host = h
+			return IdentityStore.Get(h.ID)
+		},
+		Timeout: time.Duration(Options.Network.Timeout) * time.Second,
+	})
+	if err != nil {
+		log.PWarn("Rejected reverse connection", map[string]interface{}{
+			"remote_addr": c.RemoteAddr().String(),
+			"error":       err.Error(),
+		})
+		c.Close()
+		return
+	}
+
+	log.PInfo("Reverse session opened", map[string]interface{}{
+		"host_id":     host.ID,
+		"remote_addr": c.RemoteAddr().String(),
+	})
+	hostClients.Attach(host.ID, client)
+	heartbeatStore.UpdateHostReachability(host, true)
+	go host.Ping()
+
+	client.Wait()
+
+	log.PInfo("Reverse session closed", map[string]interface{}{
+		"host_id":     host.ID,
+		"remote_addr": c.RemoteAddr().String(),
+	})
+	if hostClients.Detach(host.ID, client) {
+		heartbeatStore.UpdateHostReachability(host, false)
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RolloutStrategy describes how a script is rolled out to many hosts. The zero value runs all hosts in a single batch
+// and never stops.
+type RolloutStrategy struct {
+	// CanaryHosts the number of hosts to run on before any others
+	CanaryHosts int
+	// CanaryPercent the percentage of hosts to run on before any others, cannot be used with CanaryHosts
+	CanaryPercent int
+	// BatchSize the number of hosts in each batch after the canary, 0 runs all remaining hosts in one batch
+	BatchSize int
+	// MaxFailurePercent the percentage of failed hosts above which the rollout is stopped
+	MaxFailurePercent int
+	// AbortPolicy what to do once the maximum failure percentage has been exceeded
+	AbortPolicy string
+}
+
+// Validate returns an error if the rollout strategy is not valid
+func (r RolloutStrategy) Validate() error {
+	if r.CanaryHosts < 0 {
+		return fmt.Errorf("canary hosts cannot be negative")
+	}
+	if r.CanaryPercent < 0 || r.CanaryPercent > 100 {
+		return fmt.Errorf("canary percent must be between 0 and 100")
+	}
+	if r.CanaryHosts > 0 && r.CanaryPercent > 0 {
+		return fmt.Errorf("cannot specify both canary hosts and canary percent")
+	}
+	if r.BatchSize < 0 {
+		return fmt.Errorf("batch size cannot be negative")
+	}
+	if r.MaxFailurePercent < 0 || r.MaxFailurePercent > 100 {
+		return fmt.Errorf("max failure percent must be between 0 and 100")
+	}
+	if r.AbortPolicy != "" && !IsRolloutAbortPolicy(r.AbortPolicy) {
+		return fmt.Errorf("invalid abort policy")
+	}
+	return nil
+}
+
+// batches returns the indexes of the hosts in each batch of the rollout
+func (r RolloutStrategy) batches(numHosts int) [][]int {
+	batches := [][]int{}
+	next := 0
+	addBatch := func(size int) {
+		if size <= 0 || next+size > numHosts {
+			size = numHosts - next
+		}
+		batch := make([]int, size)
+		for i := range batch {
+			batch[i] = next + i
+		}
+		batches = append(batches, batch)
+		next += size
+	}
+
+	canary := r.CanaryHosts
+	if r.CanaryPercent > 0 {
+		canary = (numHosts*r.CanaryPercent + 99) / 100
+	}
+	if canary > 0 && numHosts > 0 {
+		addBatch(canary)
+	}
+	for next < numHosts {
+		addBatch(r.BatchSize)
+	}
+	return batches
+}
+
+// exceeded returns true if the number of failed hosts out of the hosts in all started batches is over the maximum
+// failure percentage and the abort policy would stop the rollout
+func (r RolloutStrategy) exceeded(failed, attempted int) bool {
+	if r.AbortPolicy == "" || r.AbortPolicy == RolloutAbortPolicyContinue || attempted == 0 {
+		return false
+	}
+	return failed*100 > r.MaxFailurePercent*attempted
+}
+
+type rolloutResult struct {
+	// HostBatch the batch number, starting at 1, that each host ran in or 0 if the host did not run
+	HostBatch  []int
+	StopReason string
+}
+
+// executeRollout will call run for every host following the rollout strategy, using the execution options within each
+// batch. run must return false if the host failed. If the rollout is stopped then any remaining hosts are not run, and
+// if the abort policy is to cancel then cancel is called for any hosts that are still running. Blocks until all hosts
+// have finished.
+func executeRollout(hosts []*Host, rollout RolloutStrategy, options ExecutionOptions, run func(i int, host *Host) bool, cancel func(i int, host *Host)) rolloutResult {
+	result := rolloutResult{HostBatch: make([]int, len(hosts))}
+	lock := sync.Mutex{}
+	attempted := 0
+	failed := 0
+
+	for b, batch := range rollout.batches(len(hosts)) {
+		batchHosts := make([]*Host, len(batch))
+		for j, i := range batch {
+			batchHosts[j] = hosts[i]
+		}
+		attempted += len(batch)
+		aborted := false
+		running := map[int]bool{}
+
+		executeOnHosts(batchHosts, options, func(j int, host *Host) {
+			i := batch[j]
+			lock.Lock()
+			if aborted {
+				lock.Unlock()
+				return
+			}
+			result.HostBatch[i] = b + 1
+			running[i] = true
+			lock.Unlock()
+
+			ok := run(i, host)
+
+			lock.Lock()
+			delete(running, i)
+			if ok {
+				lock.Unlock()
+				return
+			}
+			failed++
+			if rollout.AbortPolicy != RolloutAbortPolicyCancel || aborted || !rollout.exceeded(failed, attempted) {
+				lock.Unlock()
+				return
+			}
+			aborted = true
+			cancelHosts := []int{}
+			for k := range running {
+				cancelHosts = append(cancelHosts, k)
+			}
+			lock.Unlock()
+
+			for _, k := range cancelHosts {
+				cancel(k, hosts[k])
+			}
+		})
+
+		if rollout.exceeded(failed, attempted) {
+			result.StopReason = fmt.Sprintf("%d of %d hosts failed by batch %d, exceeding the maximum failure rate of %d%%", failed, attempted, b+1, rollout.MaxFailurePercent)
+			log.PWarn("Stopping rollout", map[string]interface{}{
+				"batch":               b + 1,
+				"num_failed":          failed,
+				"num_attempted":       attempted,
+				"max_failure_percent": rollout.MaxFailurePercent,
+				"abort_policy":        rollout.AbortPolicy,
+			})
+			break
+		}
+	}
+
+	return result
+}
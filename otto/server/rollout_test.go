@@ -0,0 +1,148 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRolloutBatches(t *testing.T) {
+	check := func(rollout RolloutStrategy, numHosts int, expected []int) {
+		batches := rollout.batches(numHosts)
+		if len(batches) != len(expected) {
+			t.Errorf("Unexpected number of batches for %+v: %d, expected %d", rollout, len(batches), len(expected))
+			return
+		}
+		next := 0
+		for i, batch := range batches {
+			if len(batch) != expected[i] {
+				t.Errorf("Unexpected size of batch %d for %+v: %d, expected %d", i, rollout, len(batch), expected[i])
+			}
+			for _, idx := range batch {
+				if idx != next {
+					t.Errorf("Unexpected host index in batch %d for %+v: %d, expected %d", i, rollout, idx, next)
+				}
+				next++
+			}
+		}
+	}
+
+	check(RolloutStrategy{}, 10, []int{10})
+	check(RolloutStrategy{}, 0, []int{})
+	check(RolloutStrategy{CanaryHosts: 1}, 10, []int{1, 9})
+	check(RolloutStrategy{CanaryHosts: 1, BatchSize: 4}, 10, []int{1, 4, 4, 1})
+	check(RolloutStrategy{CanaryPercent: 10, BatchSize: 5}, 20, []int{2, 5, 5, 5, 3})
+	check(RolloutStrategy{CanaryPercent: 1}, 20, []int{1, 19})
+	check(RolloutStrategy{CanaryHosts: 50}, 10, []int{10})
+	check(RolloutStrategy{BatchSize: 3}, 7, []int{3, 3, 1})
+}
+
+func TestRolloutValidate(t *testing.T) {
+	if err := (RolloutStrategy{}).Validate(); err != nil {
+		t.Errorf("Unexpected error validating default rollout: %s", err.Error())
+	}
+	if err := (RolloutStrategy{CanaryHosts: 1, CanaryPercent: 10}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for both canary hosts and percent")
+	}
+	if err := (RolloutStrategy{MaxFailurePercent: 101}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for invalid max failure percent")
+	}
+	if err := (RolloutStrategy{AbortPolicy: "foo"}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for invalid abort policy")
+	}
+}
+
+func TestExecuteRolloutStop(t *testing.T) {
+	t.Parallel()
+
+	hosts := make([]*Host, 10)
+	for i := range hosts {
+		hosts[i] = &Host{ID: randomString(6)}
+	}
+
+	rollout := RolloutStrategy{
+		CanaryHosts:       2,
+		BatchSize:         4,
+		MaxFailurePercent: 10,
+		AbortPolicy:       RolloutAbortPolicyStop,
+	}
+	// The canary succeeds and one host fails in the second batch, which is 1 of 6 hosts and more than 10%
+	result := executeRollout(hosts, rollout, ExecutionOptions{}, func(i int, host *Host) bool {
+		return i != 3
+	}, func(i int, host *Host) {
+		t.Errorf("Cancel should not be called")
+	})
+
+	if result.StopReason == "" {
+		t.Errorf("Rollout was not stopped")
+	}
+	expected := []int{1, 1, 2, 2, 2, 2, 0, 0, 0, 0}
+	for i, batch := range result.HostBatch {
+		if batch != expected[i] {
+			t.Errorf("Unexpected batch for host %d: %d, expected %d", i, batch, expected[i])
+		}
+	}
+}
+
+func TestExecuteRolloutContinue(t *testing.T) {
+	t.Parallel()
+
+	hosts := make([]*Host, 6)
+	for i := range hosts {
+		hosts[i] = &Host{ID: randomString(6)}
+	}
+
+	result := executeRollout(hosts, RolloutStrategy{CanaryHosts: 1, AbortPolicy: RolloutAbortPolicyContinue}, ExecutionOptions{}, func(i int, host *Host) bool {
+		return false
+	}, func(i int, host *Host) {
+		t.Errorf("Cancel should not be called")
+	})
+
+	if result.StopReason != "" {
+		t.Errorf("Unexpected stop reason: %s", result.StopReason)
+	}
+	for i, batch := range result.HostBatch {
+		if batch == 0 {
+			t.Errorf("Host %d was not run", i)
+		}
+	}
+}
+
+func TestExecuteRolloutCancel(t *testing.T) {
+	t.Parallel()
+
+	hosts := make([]*Host, 4)
+	for i := range hosts {
+		hosts[i] = &Host{ID: randomString(6)}
+	}
+
+	lock := sync.Mutex{}
+	canceled := map[int]bool{}
+	cancelChan := make(chan bool)
+	result := executeRollout(hosts, RolloutStrategy{AbortPolicy: RolloutAbortPolicyCancel}, ExecutionOptions{MaxParallelism: 4}, func(i int, host *Host) bool {
+		if i == 0 {
+			time.Sleep(10 * time.Millisecond)
+			return false
+		}
+		// The other hosts run until they are canceled
+		select {
+		case <-cancelChan:
+		case <-time.After(5 * time.Second):
+		}
+		return true
+	}, func(i int, host *Host) {
+		lock.Lock()
+		canceled[i] = true
+		lock.Unlock()
+		cancelChan <- true
+	})
+
+	if result.StopReason == "" {
+		t.Errorf("Rollout was not stopped")
+	}
+	for i := 1; i < 4; i++ {
+		if !canceled[i] {
+			t.Errorf("Host %d was not canceled", i)
+		}
+	}
+}
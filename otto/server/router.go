@@ -60,17 +60,24 @@ func RouterSetup() {
 	// Hosts
 	server.API.GET("/api/hosts", h.HostList, authenticatedOptions(false))
 	server.API.PUT("/api/hosts/host", h.HostNew, authenticatedOptions(false))
+	server.API.POST("/api/hosts/select", h.HostSelect, authenticatedOptions(false))
 	server.API.GET("/api/hosts/host/:id", h.HostGet, authenticatedOptions(false))
 	server.API.GET("/api/hosts/host/:id/scripts", h.HostGetScripts, authenticatedOptions(false))
 	server.API.GET("/api/hosts/host/:id/groups", h.HostGetGroups, authenticatedOptions(false))
 	server.API.GET("/api/hosts/host/:id/schedules", h.HostGetSchedules, authenticatedOptions(false))
 	server.API.GET("/api/hosts/host/:id/id", h.HostGetServerID, authenticatedOptions(false))
 	server.API.POST("/api/hosts/host/:id/heartbeat", h.HostTriggerHeartbeat, authenticatedOptions(false))
+	server.HTTPEasy.GET("/api/hosts/host/:id/files", v.HostDownloadFile, authenticatedOptions(false))
+	server.API.POST("/api/hosts/host/:id/files", h.HostSaveFile, authenticatedOptions(false))
 	server.API.POST("/api/hosts/host/:id/id/trust", h.HostUpdateTrust, authenticatedOptions(false))
 	server.API.POST("/api/hosts/host/:id/id/rotate", h.HostRotateID, authenticatedOptions(false))
 	server.API.POST("/api/hosts/host/:id", h.HostEdit, authenticatedOptions(false))
 	server.API.DELETE("/api/hosts/host/:id", h.HostDelete, authenticatedOptions(false))
 
+	// Inventory
+	server.API.POST("/api/inventory/import", h.InventoryImport, authenticatedOptions(false))
+	server.HTTPEasy.GET("/api/inventory/export", v.InventoryExport, authenticatedOptions(false))
+
 	// Register
 	server.HTTPEasy.PUT("/api/register", v.Register, unauthenticatedOptions)
 	// Register Rules
@@ -97,14 +104,43 @@ func RouterSetup() {
 	// Schedules
 	server.API.GET("/api/schedules", h.ScheduleList, authenticatedOptions(false))
 	server.API.PUT("/api/schedules/schedule", h.ScheduleNew, authenticatedOptions(false))
+	server.API.POST("/api/schedules/next_runs", h.ScheduleNextRuns, authenticatedOptions(false))
 	server.API.GET("/api/schedules/schedule/:id", h.ScheduleGet, authenticatedOptions(false))
 	server.API.GET("/api/schedules/schedule/:id/reports", h.ScheduleGetReports, authenticatedOptions(false))
 	server.API.GET("/api/schedules/schedule/:id/hosts", h.ScheduleGetHosts, authenticatedOptions(false))
 	server.API.GET("/api/schedules/schedule/:id/groups", h.ScheduleGetGroups, authenticatedOptions(false))
 	server.API.GET("/api/schedules/schedule/:id/script", h.ScheduleGetScript, authenticatedOptions(false))
+	server.API.GET("/api/schedules/schedule/:id/plan", h.SchedulePlan, authenticatedOptions(false))
 	server.API.POST("/api/schedules/schedule/:id", h.ScheduleEdit, authenticatedOptions(false))
 	server.API.DELETE("/api/schedules/schedule/:id", h.ScheduleDelete, authenticatedOptions(false))
 
+	// Execution Locks
+	server.API.GET("/api/locks", h.LockList, authenticatedOptions(false))
+
+	// Workflows
+	server.API.GET("/api/workflows", h.WorkflowList, authenticatedOptions(false))
+	server.API.PUT("/api/workflows/workflow", h.WorkflowNew, authenticatedOptions(false))
+	server.API.GET("/api/workflows/workflow/:id", h.WorkflowGet, authenticatedOptions(false))
+	server.API.GET("/api/workflows/workflow/:id/reports", h.WorkflowGetReports, authenticatedOptions(false))
+	server.API.GET("/api/workflows/workflow/:id/reports/:report_id", h.WorkflowGetReport, authenticatedOptions(false))
+	server.API.POST("/api/workflows/workflow/:id/run", h.WorkflowRun, authenticatedOptions(false))
+	server.API.POST("/api/workflows/workflow/:id", h.WorkflowEdit, authenticatedOptions(false))
+	server.API.DELETE("/api/workflows/workflow/:id", h.WorkflowDelete, authenticatedOptions(false))
+
+	// Approvals
+	server.API.GET("/api/approvals", h.ApprovalList, authenticatedOptions(false))
+	server.API.PUT("/api/approvals/approval", h.ApprovalNew, authenticatedOptions(false))
+	server.API.GET("/api/approvals/approval/:id", h.ApprovalGet, authenticatedOptions(false))
+	server.API.POST("/api/approvals/approval/:id/approve", h.ApprovalApprove, authenticatedOptions(false))
+	server.API.POST("/api/approvals/approval/:id/reject", h.ApprovalReject, authenticatedOptions(false))
+
+	// Maintenance Windows
+	server.API.GET("/api/maintenance_windows", h.MaintenanceWindowList, authenticatedOptions(false))
+	server.API.PUT("/api/maintenance_windows/window", h.MaintenanceWindowNew, authenticatedOptions(false))
+	server.API.GET("/api/maintenance_windows/window/:id", h.MaintenanceWindowGet, authenticatedOptions(false))
+	server.API.POST("/api/maintenance_windows/window/:id", h.MaintenanceWindowEdit, authenticatedOptions(false))
+	server.API.DELETE("/api/maintenance_windows/window/:id", h.MaintenanceWindowDelete, authenticatedOptions(false))
+
 	// Heartbeats
 	server.API.GET("/api/heartbeat", h.HeartbeatLast, authenticatedOptions(false))
 
@@ -117,6 +153,12 @@ func RouterSetup() {
 	server.API.GET("/api/scripts/script/:id/schedules", h.ScriptGetSchedules, authenticatedOptions(false))
 	server.API.GET("/api/scripts/script/:id/attachments", h.ScriptGetAttachments, authenticatedOptions(false))
 	server.API.POST("/api/scripts/script/:id/groups", h.ScriptSetGroups, authenticatedOptions(false))
+	server.API.GET("/api/scripts/script/:id/revisions", h.ScriptRevisionList, authenticatedOptions(false))
+	server.API.GET("/api/scripts/script/:id/revisions/:revision", h.ScriptRevisionGet, authenticatedOptions(false))
+	server.API.POST("/api/scripts/script/:id/revisions/:revision/rollback", h.ScriptRevisionRollback, authenticatedOptions(false))
+	server.API.GET("/api/scripts/script/:id/diff", h.ScriptRevisionDiff, authenticatedOptions(false))
+	server.API.POST("/api/scripts/script/:id/preview", h.ScriptPreview, authenticatedOptions(false))
+	server.API.POST("/api/scripts/script/:id/plan", h.ScriptPlan, authenticatedOptions(false))
 	server.API.POST("/api/scripts/script/:id", h.ScriptEdit, authenticatedOptions(false))
 	server.API.DELETE("/api/scripts/script/:id", h.ScriptDelete, authenticatedOptions(false))
 
@@ -133,6 +175,20 @@ func RouterSetup() {
 	server.API.POST("/api/action/cancel", h.RequestCancel, authenticatedOptions(false))
 	server.Socket("/api/action/async", h.RequestStream, authenticatedOptions(false))
 
+	// Ad-hoc Commands
+	server.API.PUT("/api/command/sync", h.CommandNew, authenticatedOptions(false))
+	server.Socket("/api/command/async", h.CommandStream, authenticatedOptions(false))
+
+	// Jobs
+	server.API.PUT("/api/jobs", h.JobNew, authenticatedOptions(false))
+	server.API.GET("/api/jobs/:id", h.JobGet, authenticatedOptions(false))
+	server.API.POST("/api/jobs/:id/cancel", h.JobCancel, authenticatedOptions(false))
+
+	// Runs
+	server.API.GET("/api/runs", h.RunList, authenticatedOptions(false))
+	server.API.GET("/api/runs/run/:id", h.RunGet, authenticatedOptions(false))
+	server.HTTPEasy.GET("/api/runs/run/:id/download", v.RunDownload, authenticatedOptions(false))
+
 	// State
 	server.API.GET("/api/state", h.State, authenticatedOptions(false))
 	server.API.GET("/api/stats", h.Stats, authenticatedOptions(false))
@@ -183,6 +239,7 @@ func RouterSetup() {
 	ngRoutes := []string{
 		"/hosts",
 		"/hosts/host",
+		"/hosts/import",
 		"/hosts/host/:id",
 		"/hosts/host/:id/edit",
 		"/groups",
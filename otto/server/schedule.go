@@ -1,6 +1,7 @@
 package server
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/ecnepsnai/ds"
@@ -9,19 +10,103 @@ import (
 
 // Schedule describes a recurring task
 type Schedule struct {
-	ID          string `ds:"primary"`
-	Name        string `ds:"unique" min:"1" max:"140"`
-	ScriptID    string `ds:"index"`
-	Scope       ScheduleScope
-	Pattern     string
-	Enabled     bool
-	LastRunTime time.Time
+	ID       string `ds:"primary"`
+	Name     string `ds:"unique" min:"1" max:"140"`
+	ScriptID string `ds:"index"`
+	// WorkflowID the ID of the workflow that is run instead of a script, if set
+	WorkflowID string `ds:"index"`
+	Scope      ScheduleScope
+	// Trigger what causes the schedule to run, the pattern if not set
+	Trigger string
+	Pattern string
+	// RunAt when a schedule with the once trigger runs
+	RunAt time.Time
+	// TriggerEvent the type of event that runs a schedule with the event trigger
+	TriggerEvent string
+	// EventHost if the schedule runs only on the host that caused the event. If the schedule has a scope then the
+	// host must also be in the scope.
+	EventHost bool
+	// Timezone the IANA time zone that the pattern is evaluated in, UTC if not set
+	Timezone string
+	// CatchUp what happens to runs that were missed while the server was not running
+	CatchUp string
+	// JitterSeconds the maximum random delay before each run starts
+	JitterSeconds int
+	Enabled       bool
+	LastRunTime   time.Time
+	Execution     ExecutionOptions
+	Rollout       RolloutStrategy
+	Retry         RetryPolicy
+	// TimeoutSeconds if set, replaces the timeout of the script when it is run by this schedule
+	TimeoutSeconds int64
+	// Parameters the values for the parameters of the script
+	Parameters map[string]string
+	// OverlapPolicy what happens if the previous run of the schedule, or the script on a host, is still in progress
+	OverlapPolicy string
+
+	// eventID the ID of the event that triggered this run of the schedule, if any
+	eventID string
+}
+
+// scheduleTriggerEvents the types of events that can trigger a schedule. Every event has the ID of a host.
+var scheduleTriggerEvents = []string{
+	EventTypeHostAdded,
+	EventTypeHostModified,
+	EventTypeHostRegisterSuccess,
+	EventTypeHostTrustModified,
+	EventTypeHostIdentityRotated,
+	EventTypeHostBecameReachable,
+}
+
+// trigger returns the trigger of the schedule. Schedules saved before triggers were added run on their pattern.
+func (s Schedule) trigger() string {
+	if s.Trigger == "" {
+		return ScheduleTriggerPattern
+	}
+	return s.Trigger
 }
 
-// ScheduleScope describes the scope for a schedule
+// hideSecrets masks the values of secret parameters
+func (s *Schedule) hideSecrets() {
+	if len(s.Parameters) == 0 {
+		return
+	}
+	s.Parameters = ScriptStore.ScriptWithID(s.ScriptID).hideParameterSecrets(s.Parameters)
+}
+
+// runForEvent runs the schedule because the event was recorded
+func (s Schedule) runForEvent(event Event) {
+	s.eventID = event.ID
+	if s.EventHost {
+		host := HostCache.ByID(event.Details["host_id"])
+		if host == nil {
+			return
+		}
+		if !s.Scope.isEmpty() && !s.Scope.Contains(host) {
+			return
+		}
+		s.Scope = ScheduleScope{HostIDs: []string{host.ID}}
+	}
+
+	log.PInfo("Running schedule triggered by event", map[string]interface{}{
+		"schedule_id": s.ID,
+		"event_id":    event.ID,
+		"event":       event.Event,
+	})
+	s.runWithJitter()
+}
+
+// ScheduleScope describes the scope for a schedule. Hosts in the scope are the hosts, the members of the groups, and
+// the hosts that match the selector, less any excluded hosts.
 type ScheduleScope struct {
 	HostIDs  []string
 	GroupIDs []string
+	// Selector a label selector, such as `env=prod,os in (debian,ubuntu),!canary`
+	Selector string
+	// ExcludeHostIDs, ExcludeGroupIDs, and ExcludeSelector hosts that are never in the scope
+	ExcludeHostIDs  []string
+	ExcludeGroupIDs []string
+	ExcludeSelector string
 }
 
 // Groups get the groups for this schedule
@@ -39,63 +124,237 @@ func (s ScheduleScope) Groups() ([]Group, *Error) {
 	return groups, nil
 }
 
-// Hosts get the hosts for this schedule
-func (s ScheduleScope) Hosts() ([]Host, *Error) {
-	hostIDs := set.NewString()
-	if len(s.GroupIDs) > 0 {
-		for _, id := range s.GroupIDs {
-			for _, hostID := range GroupCache.HostIDs(id) {
-				hostIDs.Add(hostID)
-			}
-		}
-	} else if len(s.HostIDs) > 0 {
-		for _, hostID := range s.HostIDs {
-			hostIDs.Add(hostID)
+// scopeMatcher matches hosts against a scope with its selectors already parsed
+type scopeMatcher struct {
+	scope    ScheduleScope
+	include  hostSelector
+	exclude  hostSelector
+	excluded bool
+}
+
+// matcher returns a matcher for the scope. Invalid selectors don't match any host.
+func (s ScheduleScope) matcher() scopeMatcher {
+	m := scopeMatcher{scope: s}
+	if s.Selector != "" {
+		selector, err := parseSelector(s.Selector)
+		if err != nil {
+			log.PWarn("Invalid selector in scope", map[string]interface{}{
+				"selector": s.Selector,
+				"error":    err.Error(),
+			})
+		}
+		m.include = selector
+	}
+	if s.ExcludeSelector != "" {
+		selector, err := parseSelector(s.ExcludeSelector)
+		if err != nil {
+			log.PWarn("Invalid selector in scope", map[string]interface{}{
+				"selector": s.ExcludeSelector,
+				"error":    err.Error(),
+			})
+			// Don't run on hosts that were meant to be excluded
+			m.excluded = true
+		}
+		m.exclude = selector
+	}
+	return m
+}
+
+// inGroup returns true if the host is a member of any of the groups
+func inGroup(host *Host, groupIDs []string) bool {
+	for _, groupID := range host.AllGroupIDs() {
+		if sliceContains(groupID, groupIDs) {
+			return true
 		}
 	}
+	return false
+}
+
+// excludes returns true if the host is excluded from the scope. properties are from the last heartbeat of the host.
+func (m scopeMatcher) excludes(host *Host, properties map[string]string) bool {
+	if m.excluded || sliceContains(host.ID, m.scope.ExcludeHostIDs) || inGroup(host, m.scope.ExcludeGroupIDs) {
+		return true
+	}
+	return m.exclude != nil && m.exclude.Matches(host.Labels, properties)
+}
+
+// includes returns true if the host is included by the scope, ignoring exclusions
+func (m scopeMatcher) includes(host *Host, properties map[string]string) bool {
+	if sliceContains(host.ID, m.scope.HostIDs) || inGroup(host, m.scope.GroupIDs) {
+		return true
+	}
+	return m.include != nil && m.include.Matches(host.Labels, properties)
+}
 
-	if hostIDs.Length() == 0 {
+// contains returns true if the host is in the scope
+func (m scopeMatcher) contains(host *Host, properties map[string]string) bool {
+	return !m.excludes(host, properties) && m.includes(host, properties)
+}
+
+// Contains returns true if the host is in the scope
+func (s ScheduleScope) Contains(host *Host) bool {
+	var properties map[string]string
+	if heartbeat := heartbeatStore.LastHeartbeat(host); heartbeat != nil {
+		properties = heartbeat.Properties
+	}
+	return s.matcher().contains(host, properties)
+}
+
+// references returns true if the host or group ID is included or excluded by the scope
+func (s ScheduleScope) references(id string) bool {
+	return sliceContains(id, s.HostIDs) || sliceContains(id, s.GroupIDs) || sliceContains(id, s.ExcludeHostIDs) || sliceContains(id, s.ExcludeGroupIDs)
+}
+
+// Hosts get the hosts for this schedule
+func (s ScheduleScope) Hosts() ([]Host, *Error) {
+	if s.isEmpty() {
 		log.Warn("Schedule with no hosts or groups")
 		return []Host{}, nil
 	}
 
+	m := s.matcher()
+	properties := hostProperties()
 	hosts := []Host{}
-	for _, hostID := range hostIDs.Values() {
-		host := HostCache.ByID(hostID)
-		if host == nil {
-			log.Warn("Schedule contains unknown host %s", hostID)
-			continue
+	for _, host := range HostCache.All() {
+		if m.contains(&host, properties[host.Address]) {
+			hosts = append(hosts, host)
 		}
-		hosts = append(hosts, *host)
 	}
 
 	return hosts, nil
 }
 
+// maxScheduleJitterSeconds the maximum jitter of a schedule
+const maxScheduleJitterSeconds = 3600
+
+// maxNextRuns the maximum number of next run times that can be requested at once
+const maxNextRuns = 100
+
+// maxCatchUpRuns the maximum number of missed runs of a schedule that are run after the server starts
+const maxCatchUpRuns = 100
+
+// location returns the location that the pattern of the schedule is evaluated in
+func (s Schedule) location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		log.PError("Invalid schedule timezone", map[string]interface{}{
+			"schedule_id": s.ID,
+			"timezone":    s.Timezone,
+			"error":       err.Error(),
+		})
+		return time.UTC
+	}
+	return loc
+}
+
+// nextRuns returns the next count times after t that the pattern will run in the given timezone
+func nextRuns(pattern string, timezone string, t time.Time, count int) ([]time.Time, *Error) {
+	p, err := parseCronPattern(pattern)
+	if err != nil {
+		return nil, ErrorUser("Invalid pattern: %s", err.Error())
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrorUser("Invalid timezone %s", timezone)
+	}
+
+	runs := []time.Time{}
+	next := t.In(loc)
+	for len(runs) < count {
+		next = p.Next(next)
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
+
+// missedRuns returns the times that the schedule should have started between the last time it finished and the start
+// of the minute of t, at most maxCatchUpRuns
+func (s Schedule) missedRuns(t time.Time) []time.Time {
+	end := t.Truncate(time.Minute)
+	switch s.trigger() {
+	case ScheduleTriggerOnce:
+		if s.RunAt.Before(end) && s.LastRunTime.Before(s.RunAt) {
+			return []time.Time{s.RunAt}
+		}
+		return []time.Time{}
+	case ScheduleTriggerEvent:
+		return []time.Time{}
+	}
+
+	if s.LastRunTime.IsZero() {
+		return []time.Time{}
+	}
+	p, err := parseCronPattern(s.Pattern)
+	if err != nil {
+		return []time.Time{}
+	}
+
+	missed := []time.Time{}
+	next := s.LastRunTime.In(s.location())
+	for len(missed) < maxCatchUpRuns {
+		next = p.Next(next)
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+		missed = append(missed, next)
+	}
+	return missed
+}
+
+// runWithJitter waits for a random delay up to the jitter of the schedule and then runs it
+func (s Schedule) runWithJitter() {
+	if s.JitterSeconds > 0 {
+		delay := time.Duration(rand.Int63n(int64(s.JitterSeconds) * int64(time.Second)))
+		log.PDebug("Delaying schedule run", map[string]interface{}{
+			"schedule_id": s.ID,
+			"delay":       delay.String(),
+		})
+		time.Sleep(delay)
+	}
+	s.RunNow()
+}
+
+// overlapPolicy returns the overlap policy of the schedule. Schedules saved before overlap policies were added skip
+// runs that overlap.
+func (s Schedule) overlapPolicy() string {
+	if s.OverlapPolicy == "" {
+		return OverlapPolicySkip
+	}
+	return s.OverlapPolicy
+}
+
 // RunNow run the schedule now
 func (s Schedule) RunNow() {
 	if !s.Enabled {
 		return
 	}
 
+	lock, lerr := lockManager.Acquire(scheduleLock(s), s.overlapPolicy())
+	if lerr != nil {
+		s.skipRun(lerr.Error())
+		return
+	}
+	defer lockManager.Release(lock)
+
+	if s.WorkflowID != "" {
+		s.runWorkflow()
+		return
+	}
+
 	report := ScheduleReport{
 		ID:         newID(),
 		ScheduleID: s.ID,
+		EventID:    s.eventID,
 	}
 	start := time.Now()
 
-	hosts := set.NewString()
-	if len(s.Scope.GroupIDs) > 0 {
-		for _, id := range s.Scope.GroupIDs {
-			for _, hostID := range GroupCache.HostIDs(id) {
-				hosts.Add(hostID)
-			}
-		}
-	} else if len(s.Scope.HostIDs) > 0 {
-		for _, hostID := range s.Scope.HostIDs {
-			hosts.Add(hostID)
-		}
-	}
+	hosts, _ := s.Scope.Hosts()
 
 	script := ScriptCache.ByID(s.ScriptID)
 	if script == nil {
@@ -105,44 +364,108 @@ func (s Schedule) RunNow() {
 		})
 		return
 	}
+	script, err := script.WithParameters(s.Parameters)
+	if err != nil {
+		// The script parameters may have changed since the schedule was saved
+		log.PError("Schedule parameters are not valid for script", map[string]interface{}{
+			"schedule_id": s.ID,
+			"script_id":   s.ScriptID,
+			"error":       err.Error(),
+		})
+		return
+	}
+	if s.TimeoutSeconds > 0 {
+		script.TimeoutSeconds = s.TimeoutSeconds
+	}
+	script.overlapPolicy = s.overlapPolicy()
 
-	report.HostIDs = hosts.Values()
+	report.ScriptRevision = script.Revision
+	report.HostIDs = make([]string, len(hosts))
 	report.HostResult = map[string]int{}
 	success := 0
 	fail := 0
 
-	for _, hostID := range hosts.Values() {
-		host := HostCache.ByID(hostID)
-		if host == nil {
-			log.PError("Schedule targets non-existant host", map[string]interface{}{
-				"schedule_id": s.ID,
-				"host_id":     hostID,
-			})
-			continue
-		}
+	targets := make([]*Host, len(hosts))
+	for i := range hosts {
+		report.HostIDs[i] = hosts[i].ID
+		targets[i] = &hosts[i]
+	}
+	targets, report.HostSkipReason = skipBlockedHosts(targets, start)
 
-		result, err := host.RunScript(script, nil)
+	// Each host only writes to its own index, results are collected in order once all hosts have finished
+	hostResults := make([]int, len(targets))
+	hostStatus := make([]string, len(targets))
+	hostFailed := make([]bool, len(targets))
+	hostAttempts := make([][]HostAttempt, len(targets))
+	hostLocked := make([]string, len(targets))
+	rollout := executeRollout(targets, s.Rollout, s.Execution, func(i int, host *Host) bool {
+		result, attempts, err := host.runScriptWithRetry(script, s.Retry, &s, "")
+		hostAttempts[i] = attempts
+		if isLockedError(err) {
+			hostLocked[i] = err.Error()
+			return true
+		}
 		if err != nil {
-			fail++
 			log.PError("Error running scheduled script", map[string]interface{}{
 				"schedule_id": s.ID,
 				"script_id":   s.ScriptID,
 				"host_id":     host.ID,
 				"error":       err.Error(),
 			})
-			report.HostResult[host.ID] = 1
-			continue
-		} else {
-			success++
-			report.HostResult[host.ID] = result.Result.Code
+			hostFailed[i] = true
+			hostResults[i] = 1
+			hostStatus[i] = ScriptStatusFailed
+			return false
 		}
+		hostResults[i] = result.Result.Code
+		hostStatus[i] = result.Status
+		hostFailed[i] = !result.Succeeded()
 
-		EventStore.ScriptRun(script, host, &result.Result, &s, "")
 		log.PInfo("Finished running scheduled script", map[string]interface{}{
 			"schedule_id": s.ID,
 			"script_id":   s.ScriptID,
 			"host_id":     host.ID,
+			"status":      result.Status,
+			"attempts":    len(attempts),
 		})
+		return !hostFailed[i]
+	}, func(i int, host *Host) {
+		if err := host.CancelScript(script.Name); err != nil {
+			log.PError("Error cancelling scheduled script", map[string]interface{}{
+				"schedule_id": s.ID,
+				"host_id":     host.ID,
+				"error":       err.Error(),
+			})
+		}
+	})
+
+	report.HostBatch = map[string]int{}
+	report.HostStatus = map[string]string{}
+	report.HostAttempts = map[string][]HostAttempt{}
+	report.StopReason = rollout.StopReason
+	for hostID := range report.HostSkipReason {
+		report.HostStatus[hostID] = ScriptStatusSkipped
+	}
+	for i, host := range targets {
+		if rollout.HostBatch[i] == 0 {
+			// Host was not run because the rollout was stopped
+			fail++
+			continue
+		}
+		if hostLocked[i] != "" {
+			report.HostSkipReason[host.ID] = hostLocked[i]
+			report.HostStatus[host.ID] = ScriptStatusSkipped
+			continue
+		}
+		report.HostResult[host.ID] = hostResults[i]
+		report.HostStatus[host.ID] = hostStatus[i]
+		report.HostAttempts[host.ID] = hostAttempts[i]
+		report.HostBatch[host.ID] = rollout.HostBatch[i]
+		if hostFailed[i] {
+			fail++
+		} else {
+			success++
+		}
 	}
 
 	ScheduleStore.updateLastRun(s)
@@ -153,7 +476,9 @@ func (s Schedule) RunNow() {
 		Finished:       finished,
 		ElapsedSeconds: time.Since(start).Seconds(),
 	}
-	if fail == 0 {
+	if success == 0 && fail == 0 && len(report.HostSkipReason) > 0 {
+		report.Result = ScheduleResultSkipped
+	} else if fail == 0 {
 		report.Result = ScheduleResultSuccess
 	} else if success > 0 {
 		report.Result = ScheduleResultPartialSuccess
@@ -171,5 +496,75 @@ func (s Schedule) RunNow() {
 		"elapsed":       time.Since(start).String(),
 		"num_success":   success,
 		"num_fail":      fail,
+		"num_skipped":   len(report.HostSkipReason),
+	})
+}
+
+// skipRun saves a report for a run of this schedule that was skipped
+func (s Schedule) skipRun(reason string) {
+	log.PWarn("Skipping schedule run", map[string]interface{}{
+		"schedule_id": s.ID,
+		"reason":      reason,
+	})
+
+	now := time.Now()
+	report := ScheduleReport{
+		ID:         newID(),
+		ScheduleID: s.ID,
+		EventID:    s.eventID,
+		HostIDs:    []string{},
+		Time: ScheduleReportTime{
+			Start:    now,
+			Finished: now,
+		},
+		Result:     ScheduleResultSkipped,
+		HostResult: map[string]int{},
+		HostStatus: map[string]string{},
+		SkipReason: reason,
+	}
+	ScheduleReportStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Add(report)
+	})
+}
+
+// runWorkflow runs the workflow of this schedule on the hosts of the schedule
+func (s Schedule) runWorkflow() {
+	workflow := WorkflowStore.WorkflowWithID(s.WorkflowID)
+	if workflow == nil {
+		log.PError("Schedule targets non-existant workflow", map[string]interface{}{
+			"schedule_id": s.ID,
+			"workflow_id": s.WorkflowID,
+		})
+		return
+	}
+
+	run := newWorkflowRun(*workflow, s.Scope, &s, "")
+	if err := WorkflowReportStore.SaveReport(*run.Report); err != nil {
+		return
+	}
+	run.Run()
+
+	ScheduleStore.updateLastRun(s)
+
+	hostIDs := set.NewString()
+	for _, step := range run.Report.Steps {
+		for _, hostID := range step.HostIDs {
+			hostIDs.Add(hostID)
+		}
+	}
+	report := ScheduleReport{
+		ID:               newID(),
+		ScheduleID:       s.ID,
+		WorkflowReportID: run.Report.ID,
+		EventID:          s.eventID,
+		HostIDs:          hostIDs.Values(),
+		Time:             run.Report.Time,
+		Result:           run.Report.Result,
+		HostResult:       map[string]int{},
+		HostBatch:        map[string]int{},
+		HostStatus:       map[string]string{},
+	}
+	ScheduleReportStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Add(report)
 	})
 }
@@ -11,10 +11,29 @@ import (
 type ScheduleReport struct {
 	ID         string `ds:"primary"`
 	ScheduleID string `ds:"index"`
-	HostIDs    []string
-	Time       ScheduleReportTime
-	Result     int
-	HostResult map[string]int
+	// ScriptRevision the revision of the script that was run
+	ScriptRevision int
+	HostIDs        []string
+	Time           ScheduleReportTime
+	Result         int
+	HostResult     map[string]int
+	// HostStatus the status of the result for each host that was run
+	HostStatus map[string]string
+	// HostSkipReason why each host that was skipped because of a maintenance window or a run already in progress was
+	// not run
+	HostSkipReason map[string]string
+	// HostAttempts every attempt at running the script on each host that was run, including retries
+	HostAttempts map[string][]HostAttempt
+	// HostBatch the rollout batch that each host ran in, starting at 1
+	HostBatch map[string]int
+	// StopReason why the rollout was stopped before all hosts were run, if it was
+	StopReason string
+	// WorkflowReportID the ID of the workflow report, if the schedule ran a workflow
+	WorkflowReportID string
+	// SkipReason why the run was skipped, if the previous run of the schedule was still in progress
+	SkipReason string
+	// EventID the ID of the event that triggered the run, if the schedule has the event trigger
+	EventID string
 }
 
 // ScheduleReportTime describes timing information from a schedule run
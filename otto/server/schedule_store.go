@@ -3,7 +3,6 @@ package server
 import (
 	"time"
 
-	"github.com/ecnepsnai/cron"
 	"github.com/ecnepsnai/ds"
 	"github.com/ecnepsnai/limits"
 )
@@ -80,7 +79,7 @@ func (s *scheduleStoreObject) allSchedulesForGroup(tx ds.IReadTransaction, group
 	matchedSchedules := []Schedule{}
 	schedules := s.allSchedules(tx)
 	for _, schedule := range schedules {
-		if sliceContains(groupID, schedule.Scope.GroupIDs) {
+		if schedule.Scope.references(groupID) {
 			matchedSchedules = append(matchedSchedules, schedule)
 		}
 	}
@@ -99,20 +98,13 @@ func (s *scheduleStoreObject) AllSchedulesForHost(hostID string) (schedules []Sc
 func (s *scheduleStoreObject) allSchedulesForHost(tx ds.IReadTransaction, hostID string) []Schedule {
 	matchedSchedules := []Schedule{}
 	schedules := s.allSchedules(tx)
+	host := HostCache.ByID(hostID)
+	if host == nil {
+		return matchedSchedules
+	}
 	for _, schedule := range schedules {
-		if len(schedule.Scope.GroupIDs) > 0 {
-			for _, groupID := range schedule.Scope.GroupIDs {
-				for _, h := range GroupCache.HostIDs(groupID) {
-					if hostID == h {
-						matchedSchedules = append(matchedSchedules, schedule)
-						break
-					}
-				}
-			}
-		} else if len(schedule.Scope.HostIDs) > 0 {
-			if sliceContains(hostID, schedule.Scope.HostIDs) {
-				matchedSchedules = append(matchedSchedules, schedule)
-			}
+		if schedule.Scope.Contains(host) {
+			matchedSchedules = append(matchedSchedules, schedule)
 		}
 	}
 
@@ -170,6 +162,7 @@ func (s *scheduleStoreObject) scheduleWithName(tx ds.IReadTransaction, name stri
 }
 
 func (s *scheduleStoreObject) RunSchedules() {
+	now := time.Now()
 	schedules := s.AllSchedules()
 	for _, schedule := range schedules {
 		if !schedule.Enabled {
@@ -177,18 +170,152 @@ func (s *scheduleStoreObject) RunSchedules() {
 			continue
 		}
 
-		j := cron.Job{Pattern: schedule.Pattern}
-		if j.WouldRunNowInTZ(time.UTC) {
-			schedule.RunNow()
+		switch schedule.trigger() {
+		case ScheduleTriggerEvent:
+			continue
+		case ScheduleTriggerOnce:
+			if schedule.RunAt.Truncate(time.Minute).Equal(now.Truncate(time.Minute)) {
+				// One-shot schedules are disabled before they start so that they can't run again
+				s.disableSchedule(schedule.ID)
+				go schedule.runWithJitter()
+			}
+			continue
+		}
+
+		pattern, err := parseCronPattern(schedule.Pattern)
+		if err != nil {
+			log.PError("Invalid schedule pattern", map[string]interface{}{
+				"schedule_id": schedule.ID,
+				"pattern":     schedule.Pattern,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		if pattern.Matches(now.In(schedule.location())) {
+			// Schedules are run concurrently so that a schedule waiting for its previous run doesn't hold up others
+			go schedule.runWithJitter()
+		}
+	}
+}
+
+// CatchUpSchedules runs schedules that missed runs while the server was not running, following the catch-up policy of
+// each schedule. One-shot schedules that missed their run are disabled.
+func (s *scheduleStoreObject) CatchUpSchedules() {
+	now := time.Now()
+	for _, schedule := range s.AllSchedules() {
+		if !schedule.Enabled {
+			continue
+		}
+
+		missed := schedule.missedRuns(now)
+		if len(missed) == 0 {
+			continue
+		}
+		if schedule.trigger() == ScheduleTriggerOnce {
+			s.disableSchedule(schedule.ID)
+		}
+		if schedule.CatchUp == "" || schedule.CatchUp == CatchUpPolicySkip {
+			log.PWarn("Skipping missed schedule runs", map[string]interface{}{
+				"schedule_id": schedule.ID,
+				"num_missed":  len(missed),
+				"last_run":    schedule.LastRunTime,
+			})
+			continue
+		}
+
+		runs := 1
+		if schedule.CatchUp == CatchUpPolicyAll {
+			runs = len(missed)
 		}
+		log.PWarn("Catching up missed schedule runs", map[string]interface{}{
+			"schedule_id": schedule.ID,
+			"num_missed":  len(missed),
+			"num_runs":    runs,
+			"last_run":    schedule.LastRunTime,
+		})
+
+		go func(schedule Schedule, runs int) {
+			for i := 0; i < runs; i++ {
+				schedule.RunNow()
+			}
+		}(schedule, runs)
 	}
 }
 
+// RunEventSchedules runs all enabled schedules that are triggered by the event
+func (s *scheduleStoreObject) RunEventSchedules(event Event) {
+	for _, schedule := range ScheduleCache.All() {
+		if !schedule.Enabled || schedule.trigger() != ScheduleTriggerEvent || schedule.TriggerEvent != event.Event {
+			continue
+		}
+		go schedule.runForEvent(event)
+	}
+}
+
+// validateTrigger returns an error if the trigger, pattern, timezone, catch-up policy, or jitter of the schedule are
+// not valid
+func (s Schedule) validateTrigger() *Error {
+	switch s.Trigger {
+	case ScheduleTriggerPattern:
+		if _, err := parseCronPattern(s.Pattern); err != nil {
+			return ErrorUser("Invalid pattern: %s", err.Error())
+		}
+	case ScheduleTriggerOnce:
+		if s.RunAt.IsZero() {
+			return ErrorUser("A time to run is required")
+		}
+		if s.Enabled && s.RunAt.Before(time.Now()) {
+			return ErrorUser("Time to run must be in the future")
+		}
+	case ScheduleTriggerEvent:
+		if !sliceContains(s.TriggerEvent, scheduleTriggerEvents) {
+			return ErrorUser("Schedules can't be triggered by %s events", s.TriggerEvent)
+		}
+	default:
+		return ErrorUser("Invalid trigger %s", s.Trigger)
+	}
+	if s.Trigger != ScheduleTriggerEvent && s.EventHost {
+		return ErrorUser("Only schedules with the event trigger can run on the host from the event")
+	}
+	if s.Scope.isEmpty() && !s.EventHost {
+		return ErrorUser("Must specify at least one group or host")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return ErrorUser("Invalid timezone %s", s.Timezone)
+	}
+	if !IsCatchUpPolicy(s.CatchUp) {
+		return ErrorUser("Invalid catch-up policy %s", s.CatchUp)
+	}
+	if s.JitterSeconds < 0 || s.JitterSeconds > maxScheduleJitterSeconds {
+		return ErrorUser("Jitter must be between 0 and %d seconds", maxScheduleJitterSeconds)
+	}
+	return nil
+}
+
 type newScheduleParameters struct {
-	ScriptID string
-	Name     string
-	Scope    ScheduleScope
-	Pattern  string
+	ScriptID   string
+	WorkflowID string
+	Name       string
+	Scope      ScheduleScope
+	// Trigger what causes the schedule to run, the pattern if not set
+	Trigger      string
+	Pattern      string
+	RunAt        time.Time
+	TriggerEvent string
+	EventHost    bool
+	Execution    ExecutionOptions
+	Rollout      RolloutStrategy
+	Retry        RetryPolicy
+	// TimeoutSeconds if set, replaces the timeout of the script
+	TimeoutSeconds int64
+	Parameters     map[string]string
+	// OverlapPolicy what happens if the previous run of the schedule is still in progress, skip if not set
+	OverlapPolicy string
+	// Timezone the IANA time zone that the pattern is evaluated in, UTC if not set
+	Timezone string
+	// CatchUp what happens to runs missed while the server was not running, skip if not set
+	CatchUp       string
+	JitterSeconds int
 }
 
 func (s *scheduleStoreObject) NewSchedule(params newScheduleParameters) (schedule *Schedule, err *Error) {
@@ -203,14 +330,31 @@ func (s *scheduleStoreObject) newSchedule(tx ds.IReadWriteTransaction, params ne
 	if len(params.Scope.GroupIDs) > 0 && len(params.Scope.HostIDs) > 0 {
 		return nil, ErrorUser("Cannot specify both group IDs and host IDs")
 	}
-	if len(params.Scope.GroupIDs) <= 0 && len(params.Scope.HostIDs) <= 0 {
-		return nil, ErrorUser("Must specify at least one group or host")
-	}
 	if schedule, _ := tx.GetUnique("Name", params.Name); schedule != nil {
 		return nil, ErrorUser("Duplicate script name")
 	}
-	if script := ScriptStore.ScriptWithID(params.ScriptID); script == nil {
-		return nil, ErrorUser("Unknown script ID '%s'", params.ScriptID)
+	if params.WorkflowID != "" {
+		if params.ScriptID != "" {
+			return nil, ErrorUser("Cannot specify both a script and a workflow")
+		}
+		if WorkflowStore.WorkflowWithID(params.WorkflowID) == nil {
+			return nil, ErrorUser("Unknown workflow ID '%s'", params.WorkflowID)
+		}
+		if len(params.Parameters) > 0 {
+			return nil, ErrorUser("Parameters are set on each step of a workflow")
+		}
+		if params.Retry.MaxAttempts > 1 {
+			return nil, ErrorUser("Retry policies are set on each step of a workflow")
+		}
+	} else {
+		script := ScriptStore.ScriptWithID(params.ScriptID)
+		if script == nil {
+			return nil, ErrorUser("Unknown script ID '%s'", params.ScriptID)
+		}
+		params.Parameters = script.restoreParameterSecrets(params.Parameters, nil)
+		if _, err := script.parameterVariables(params.Parameters); err != nil {
+			return nil, ErrorUser(err.Error())
+		}
 	}
 
 	for _, groupID := range params.Scope.GroupIDs {
@@ -224,17 +368,58 @@ func (s *scheduleStoreObject) newSchedule(tx ds.IReadWriteTransaction, params ne
 			return nil, ErrorUser("Unknown host ID '%s'", hostID)
 		}
 	}
+	if err := params.Scope.validateMembers(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Execution.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Rollout.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Retry.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if params.TimeoutSeconds < 0 {
+		return nil, ErrorUser("Timeout cannot be negative")
+	}
+	if params.OverlapPolicy == "" {
+		params.OverlapPolicy = OverlapPolicySkip
+	}
+	if !IsOverlapPolicy(params.OverlapPolicy) {
+		return nil, ErrorUser("Invalid overlap policy %s", params.OverlapPolicy)
+	}
+	if params.CatchUp == "" {
+		params.CatchUp = CatchUpPolicySkip
+	}
+	if params.Trigger == "" {
+		params.Trigger = ScheduleTriggerPattern
+	}
 
 	schedule := Schedule{
-		ID:       newID(),
-		Name:     params.Name,
-		ScriptID: params.ScriptID,
-		Scope: ScheduleScope{
-			HostIDs:  params.Scope.HostIDs,
-			GroupIDs: params.Scope.GroupIDs,
-		},
-		Pattern: params.Pattern,
-		Enabled: true,
+		ID:             newID(),
+		Name:           params.Name,
+		ScriptID:       params.ScriptID,
+		WorkflowID:     params.WorkflowID,
+		Scope:          params.Scope,
+		Trigger:        params.Trigger,
+		Pattern:        params.Pattern,
+		RunAt:          params.RunAt,
+		TriggerEvent:   params.TriggerEvent,
+		EventHost:      params.EventHost,
+		Enabled:        true,
+		Execution:      params.Execution,
+		Rollout:        params.Rollout,
+		Retry:          params.Retry,
+		TimeoutSeconds: params.TimeoutSeconds,
+		Parameters:     params.Parameters,
+		OverlapPolicy:  params.OverlapPolicy,
+		Timezone:       params.Timezone,
+		CatchUp:        params.CatchUp,
+		JitterSeconds:  params.JitterSeconds,
+	}
+	if err := schedule.validateTrigger(); err != nil {
+		return nil, err
 	}
 	if err := limits.Check(schedule); err != nil {
 		return nil, ErrorUser(err.Error())
@@ -252,10 +437,28 @@ func (s *scheduleStoreObject) newSchedule(tx ds.IReadWriteTransaction, params ne
 }
 
 type editScheduleParameters struct {
-	Name    string
-	Scope   ScheduleScope
-	Pattern string
-	Enabled bool
+	Name  string
+	Scope ScheduleScope
+	// Trigger what causes the schedule to run, the pattern if not set
+	Trigger      string
+	Pattern      string
+	RunAt        time.Time
+	TriggerEvent string
+	EventHost    bool
+	Enabled      bool
+	Execution    ExecutionOptions
+	Rollout      RolloutStrategy
+	Retry        RetryPolicy
+	// TimeoutSeconds if set, replaces the timeout of the script
+	TimeoutSeconds int64
+	Parameters     map[string]string
+	// OverlapPolicy what happens if the previous run of the schedule is still in progress, skip if not set
+	OverlapPolicy string
+	// Timezone the IANA time zone that the pattern is evaluated in, UTC if not set
+	Timezone string
+	// CatchUp what happens to runs missed while the server was not running, skip if not set
+	CatchUp       string
+	JitterSeconds int
 }
 
 func (s *scheduleStoreObject) EditSchedule(schedule *Schedule, params editScheduleParameters) (newSchedule *Schedule, err *Error) {
@@ -270,9 +473,6 @@ func (s *scheduleStoreObject) editSchedule(tx ds.IReadWriteTransaction, schedule
 	if len(params.Scope.GroupIDs) > 0 && len(params.Scope.HostIDs) > 0 {
 		return nil, ErrorUser("Cannot specify both group IDs and host IDs")
 	}
-	if len(params.Scope.GroupIDs) <= 0 && len(params.Scope.HostIDs) <= 0 {
-		return nil, ErrorUser("Must specify at least one group or host")
-	}
 	if existing := s.scheduleWithName(tx, params.Name); existing != nil && existing.ID != schedule.ID {
 		log.PWarn("Schedule rename collission", map[string]interface{}{
 			"schedule_id":   schedule.ID,
@@ -294,12 +494,66 @@ func (s *scheduleStoreObject) editSchedule(tx ds.IReadWriteTransaction, schedule
 			return nil, ErrorUser("Unknown host ID '%s'", hostID)
 		}
 	}
+	if err := params.Scope.validateMembers(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Execution.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Rollout.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := params.Retry.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if params.TimeoutSeconds < 0 {
+		return nil, ErrorUser("Timeout cannot be negative")
+	}
+	if params.OverlapPolicy == "" {
+		params.OverlapPolicy = OverlapPolicySkip
+	}
+	if !IsOverlapPolicy(params.OverlapPolicy) {
+		return nil, ErrorUser("Invalid overlap policy %s", params.OverlapPolicy)
+	}
+	if params.CatchUp == "" {
+		params.CatchUp = CatchUpPolicySkip
+	}
+	if params.Trigger == "" {
+		params.Trigger = ScheduleTriggerPattern
+	}
+	if schedule.WorkflowID != "" && len(params.Parameters) > 0 {
+		return nil, ErrorUser("Parameters are set on each step of a workflow")
+	}
+	if schedule.WorkflowID != "" && params.Retry.MaxAttempts > 1 {
+		return nil, ErrorUser("Retry policies are set on each step of a workflow")
+	}
+	if script := ScriptStore.ScriptWithID(schedule.ScriptID); script != nil {
+		params.Parameters = script.restoreParameterSecrets(params.Parameters, schedule.Parameters)
+		if _, err := script.parameterVariables(params.Parameters); err != nil {
+			return nil, ErrorUser(err.Error())
+		}
+	}
 
 	schedule.Name = params.Name
-	schedule.Scope.HostIDs = params.Scope.HostIDs
-	schedule.Scope.GroupIDs = params.Scope.GroupIDs
+	schedule.Scope = params.Scope
+	schedule.Trigger = params.Trigger
 	schedule.Pattern = params.Pattern
+	schedule.RunAt = params.RunAt
+	schedule.TriggerEvent = params.TriggerEvent
+	schedule.EventHost = params.EventHost
 	schedule.Enabled = params.Enabled
+	schedule.Execution = params.Execution
+	schedule.Rollout = params.Rollout
+	schedule.Retry = params.Retry
+	schedule.TimeoutSeconds = params.TimeoutSeconds
+	schedule.Parameters = params.Parameters
+	schedule.OverlapPolicy = params.OverlapPolicy
+	schedule.Timezone = params.Timezone
+	schedule.CatchUp = params.CatchUp
+	schedule.JitterSeconds = params.JitterSeconds
+	if err := schedule.validateTrigger(); err != nil {
+		return nil, err
+	}
 	if err := limits.Check(schedule); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
@@ -335,10 +589,36 @@ func (s *scheduleStoreObject) deleteSchedule(tx ds.IReadWriteTransaction, schedu
 	return nil
 }
 
+// disableSchedule disables the schedule, used once a schedule with the once trigger has run
+func (s *scheduleStoreObject) disableSchedule(id string) (rerr *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		schedule := s.scheduleWithID(tx, id)
+		if schedule == nil {
+			return nil
+		}
+		schedule.Enabled = false
+		if err := tx.Update(*schedule); err != nil {
+			log.Error("Error disabling schedule '%s': %s", schedule.ID, err.Error())
+			rerr = ErrorFrom(err)
+			return nil
+		}
+		log.Info("Disabled schedule '%s'", schedule.ID)
+		ScheduleCache.Update(tx)
+		return nil
+	})
+	return
+}
+
 func (s *scheduleStoreObject) updateLastRun(schedule Schedule) (rerr *Error) {
 	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
-		schedule.LastRunTime = time.Now()
-		if err := tx.Update(schedule); err != nil {
+		// The schedule may have been modified since the run started, and the scope of a run triggered by an event only
+		// applies to that run
+		current := s.scheduleWithID(tx, schedule.ID)
+		if current == nil {
+			return nil
+		}
+		current.LastRunTime = time.Now()
+		if err := tx.Update(*current); err != nil {
 			log.Error("Error updating last run for schedule '%s': %s", schedule.ID, err.Error())
 			rerr = ErrorFrom(err)
 			return nil
@@ -2,7 +2,9 @@ package server
 
 import (
 	"testing"
+	"time"
 
+	"github.com/ecnepsnai/ds"
 	"github.com/ecnepsnai/otto/server/environ"
 )
 
@@ -457,3 +459,247 @@ func TestRenameDuplicateSchedule(t *testing.T) {
 		t.Fatalf("Should return an error")
 	}
 }
+
+func TestScheduleNextRuns(t *testing.T) {
+	// Daylight saving time starts in New York at 2AM on 8 March 2026, so there is no 2:30AM that day
+	start := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	runs, err := nextRuns("30 2 * * *", "America/New_York", start, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error getting next runs: %s", err.Message)
+	}
+	expected := []time.Time{
+		time.Date(2026, 3, 7, 7, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC),
+	}
+	if len(runs) != len(expected) {
+		t.Fatalf("Unexpected number of runs: %d", len(runs))
+	}
+	for i, run := range runs {
+		if !run.Equal(expected[i]) {
+			t.Errorf("Unexpected run %d. Expected %s got %s", i, expected[i], run.UTC())
+		}
+	}
+
+	runs, err = nextRuns("0 0 31 2 *", "", start, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error getting next runs: %s", err.Message)
+	}
+	if len(runs) != 0 {
+		t.Errorf("Pattern that never matches should have no runs")
+	}
+
+	if _, err := nextRuns("* * * *", "", start, 1); err == nil {
+		t.Errorf("No error seen for invalid pattern")
+	}
+	if _, err := nextRuns("* * * * *", "Mars/Olympus_Mons", start, 1); err == nil {
+		t.Errorf("No error seen for invalid timezone")
+	}
+}
+
+func TestScheduleMissedRuns(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 30, 15, 0, time.UTC)
+	schedule := Schedule{
+		Pattern:     "0 * * * *",
+		LastRunTime: time.Date(2026, 3, 2, 8, 0, 30, 0, time.UTC),
+	}
+	if missed := schedule.missedRuns(now); len(missed) != 4 {
+		t.Errorf("Unexpected number of missed runs: %d", len(missed))
+	}
+
+	schedule.Timezone = "Asia/Kolkata"
+	if missed := schedule.missedRuns(now); len(missed) != 4 || missed[0].Minute() != 0 || missed[0].UTC().Minute() != 30 {
+		t.Errorf("Unexpected missed runs: %v", missed)
+	}
+
+	schedule.Pattern = "* * * * *"
+	schedule.LastRunTime = now.Add(-30 * 24 * time.Hour)
+	if missed := schedule.missedRuns(now); len(missed) != maxCatchUpRuns {
+		t.Errorf("Unexpected number of missed runs: %d", len(missed))
+	}
+
+	schedule.LastRunTime = time.Time{}
+	if missed := schedule.missedRuns(now); len(missed) != 0 {
+		t.Errorf("Schedule that has never run should not have missed runs")
+	}
+}
+
+func TestScheduleTimingValidation(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	params := func() newScheduleParameters {
+		return newScheduleParameters{
+			ScriptID: script.ID,
+			Name:     randomString(6),
+			Scope:    ScheduleScope{HostIDs: []string{host.ID}},
+			Pattern:  "0 2 * * *",
+			Timezone: "Europe/Berlin",
+			CatchUp:  CatchUpPolicyOnce,
+		}
+	}
+
+	schedule, err := ScheduleStore.NewSchedule(params())
+	if err != nil {
+		t.Fatalf("Unexpected error making schedule: %s", err.Message)
+	}
+	if schedule.Timezone != "Europe/Berlin" || schedule.CatchUp != CatchUpPolicyOnce {
+		t.Errorf("Unexpected schedule: %+v", schedule)
+	}
+
+	invalid := params()
+	invalid.Pattern = "every day"
+	if _, err := ScheduleStore.NewSchedule(invalid); err == nil {
+		t.Errorf("No error seen for invalid pattern")
+	}
+	invalid = params()
+	invalid.Timezone = "Europe/Atlantis"
+	if _, err := ScheduleStore.NewSchedule(invalid); err == nil {
+		t.Errorf("No error seen for invalid timezone")
+	}
+	invalid = params()
+	invalid.CatchUp = "sometimes"
+	if _, err := ScheduleStore.NewSchedule(invalid); err == nil {
+		t.Errorf("No error seen for invalid catch-up policy")
+	}
+	invalid = params()
+	invalid.JitterSeconds = maxScheduleJitterSeconds + 1
+	if _, err := ScheduleStore.NewSchedule(invalid); err == nil {
+		t.Errorf("No error seen for invalid jitter")
+	}
+}
+
+func TestScheduleOnce(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	params := newScheduleParameters{
+		ScriptID: script.ID,
+		Name:     randomString(6),
+		Scope:    ScheduleScope{HostIDs: []string{host.ID}},
+		Trigger:  ScheduleTriggerOnce,
+		RunAt:    time.Now().Add(-time.Hour),
+	}
+	if _, err := ScheduleStore.NewSchedule(params); err == nil {
+		t.Errorf("No error seen for one-shot schedule in the past")
+	}
+	params.RunAt = time.Now().Add(time.Hour)
+	schedule, err := ScheduleStore.NewSchedule(params)
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+	if missed := schedule.missedRuns(time.Now()); len(missed) != 0 {
+		t.Errorf("Unexpected missed runs: %v", missed)
+	}
+
+	// The server was not running when the schedule should have run
+	schedule.RunAt = time.Now().Add(-time.Hour)
+	ScheduleStore.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		return tx.Update(*schedule)
+	})
+	if missed := schedule.missedRuns(time.Now()); len(missed) != 1 {
+		t.Errorf("Unexpected number of missed runs: %d", len(missed))
+	}
+	ScheduleStore.CatchUpSchedules()
+	schedule = ScheduleStore.ScheduleWithID(schedule.ID)
+	if schedule.Enabled {
+		t.Errorf("One-shot schedule that missed its run should be disabled")
+	}
+	if reports := ScheduleReportStore.GetReportsForSchedule(schedule.ID); len(reports) != 0 {
+		t.Errorf("One-shot schedule should not run when missed runs are skipped")
+	}
+}
+
+func TestScheduleEventTrigger(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	params := newScheduleParameters{
+		ScriptID:     script.ID,
+		Name:         randomString(6),
+		Trigger:      ScheduleTriggerEvent,
+		TriggerEvent: EventTypeUserLoggedIn,
+		EventHost:    true,
+	}
+	if _, err := ScheduleStore.NewSchedule(params); err == nil {
+		t.Errorf("No error seen for event that can't trigger a schedule")
+	}
+	params.TriggerEvent = EventTypeHostTrustModified
+	params.EventHost = false
+	if _, err := ScheduleStore.NewSchedule(params); err == nil {
+		t.Errorf("No error seen for schedule without a scope")
+	}
+	params.EventHost = true
+	schedule, err := ScheduleStore.NewSchedule(params)
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+
+	EventStore.HostTrustModified(host, "test")
+
+	var reports []ScheduleReport
+	for i := 0; i < 100; i++ {
+		reports = ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+		if len(reports) > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of schedule reports: %d", len(reports))
+	}
+	if reports[0].EventID == "" {
+		t.Errorf("Report should have the ID of the event that triggered the schedule")
+	}
+	if len(reports[0].HostIDs) != 1 || reports[0].HostIDs[0] != host.ID {
+		t.Errorf("Schedule should only run on the host from the event: %+v", reports[0].HostIDs)
+	}
+	if saved := ScheduleStore.ScheduleWithID(schedule.ID); !saved.Scope.isEmpty() {
+		t.Errorf("Scope of the event should not be saved to the schedule: %+v", saved.Scope)
+	}
+}
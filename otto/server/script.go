@@ -18,6 +18,22 @@ type Script struct {
 	AfterExecution   string
 	AttachmentIDs    []string
 	RunLevel         int
+	Parameters       []ScriptParameter
+	// Template if the script body is rendered as a template for each host before it is run
+	Template bool
+	// SuccessCriteria the rules used to decide if a run of the script was successful
+	SuccessCriteria SuccessCriteria
+	// TimeoutSeconds the number of seconds the script may run for before the agent aborts it, 0 uses the agent default
+	TimeoutSeconds int64
+	// RequiresApproval if running the script on demand must be approved by a second user
+	RequiresApproval bool
+	// Revision the current revision of the script, 0 if the script has not been modified since revisions were added
+	Revision int
+
+	// parameterValues the environment variables for the parameter values this script is being run with
+	parameterValues []environ.Variable
+	// overlapPolicy what happens if this script is already running on the host, runs are skipped if not set
+	overlapPolicy string
 }
 
 // RunAs describes the properties of which user runs a script
@@ -27,6 +43,20 @@ type RunAs struct {
 	GID     uint32
 }
 
+// hideSecrets removes the values of secret environment variables and the defaults of secret parameters
+func (s *Script) hideSecrets() {
+	for i, env := range s.Environment {
+		if env.Secret {
+			s.Environment[i].Value = ""
+		}
+	}
+	for i, parameter := range s.Parameters {
+		if parameter.Type == ScriptParameterTypeSecret {
+			s.Parameters[i].Default = ""
+		}
+	}
+}
+
 // Groups all groups with this script enabled
 func (s *Script) Groups() []Group {
 	enabledGroups := []Group{}
@@ -168,5 +198,6 @@ func (s Script) ScriptInfo() otto.ScriptInfo {
 		Length:           uint64(len(s.Script)),
 		WorkingDirectory: s.WorkingDirectory,
 		Environment:      map[string]string{}, // Populated during execution
+		TimeoutSeconds:   s.TimeoutSeconds,
 	}
 }
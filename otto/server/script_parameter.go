@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// ScriptParameter describes a value that is provided when a script is run. The value is passed to the script as an
+// environment variable with the same name as the parameter.
+type ScriptParameter struct {
+	Name        string
+	Description string
+	Type        string
+	Default     string
+	Required    bool
+	// Pattern an optional regular expression that the value must match
+	Pattern string
+	// Options the possible values for an enum parameter
+	Options []string
+}
+
+var scriptParameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate returns an error if the parameter definition is not valid
+func (p ScriptParameter) Validate() error {
+	if !scriptParameterNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid parameter name '%s'", p.Name)
+	}
+	for _, key := range environ.ReservedKeys {
+		if p.Name == key {
+			return fmt.Errorf("parameter name '%s' is reserved by the Otto system", p.Name)
+		}
+	}
+	if !IsScriptParameterType(p.Type) {
+		return fmt.Errorf("invalid type for parameter '%s'", p.Name)
+	}
+	if p.Type == ScriptParameterTypeEnum && len(p.Options) == 0 {
+		return fmt.Errorf("enum parameter '%s' must have at least one option", p.Name)
+	}
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern for parameter '%s': %s", p.Name, err.Error())
+		}
+	}
+	if p.Default != "" {
+		if _, err := p.parseValue(p.Default); err != nil {
+			return fmt.Errorf("invalid default for parameter '%s': %s", p.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// parseValue validates the value for this parameter and returns it in its normalized form
+func (p ScriptParameter) parseValue(value string) (string, error) {
+	switch p.Type {
+	case ScriptParameterTypeInteger:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("must be a whole number")
+		}
+		value = strconv.FormatInt(i, 10)
+	case ScriptParameterTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("must be true or false")
+		}
+		value = strconv.FormatBool(b)
+	case ScriptParameterTypeEnum:
+		found := false
+		for _, option := range p.Options {
+			if option == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("must be one of the parameter options")
+		}
+	}
+
+	if p.Pattern != "" {
+		pattern, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return "", err
+		}
+		if !pattern.MatchString(value) {
+			return "", fmt.Errorf("does not match the required pattern")
+		}
+	}
+
+	return value, nil
+}
+
+// validateScriptParameters returns an error if any of the parameters are not valid or if any names are repeated
+func validateScriptParameters(parameters []ScriptParameter) error {
+	names := map[string]bool{}
+	for _, parameter := range parameters {
+		if err := parameter.Validate(); err != nil {
+			return err
+		}
+		if names[parameter.Name] {
+			return fmt.Errorf("duplicate parameter '%s'", parameter.Name)
+		}
+		names[parameter.Name] = true
+	}
+	return nil
+}
+
+// parameterVariables validates the given values against the parameters of this script and returns the environment
+// variables for them. Defaults are used for any parameters without a value and optional parameters without a value or
+// default are not included.
+func (s Script) parameterVariables(values map[string]string) ([]environ.Variable, error) {
+	known := map[string]bool{}
+	variables := []environ.Variable{}
+	for _, parameter := range s.Parameters {
+		known[parameter.Name] = true
+
+		value := values[parameter.Name]
+		if value == "" {
+			value = parameter.Default
+		}
+		if value == "" {
+			if parameter.Required {
+				return nil, fmt.Errorf("parameter '%s' is required", parameter.Name)
+			}
+			continue
+		}
+
+		value, err := parameter.parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter '%s' %s", parameter.Name, err.Error())
+		}
+		variables = append(variables, environ.Variable{
+			Key:    parameter.Name,
+			Value:  value,
+			Secret: parameter.Type == ScriptParameterTypeSecret,
+		})
+	}
+
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter '%s'", name)
+		}
+	}
+
+	return variables, nil
+}
+
+// secretParameterMask is returned in place of the values of secret parameters
+const secretParameterMask = "********"
+
+// hideParameterSecrets returns a copy of the values where the values of secret parameters are masked. If the script is
+// nil then every value is masked.
+func (s *Script) hideParameterSecrets(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	secret := map[string]bool{}
+	if s != nil {
+		for _, parameter := range s.Parameters {
+			secret[parameter.Name] = parameter.Type == ScriptParameterTypeSecret
+		}
+	}
+
+	hidden := map[string]string{}
+	for name, value := range values {
+		if value != "" && (s == nil || secret[name]) {
+			value = secretParameterMask
+		}
+		hidden[name] = value
+	}
+	return hidden
+}
+
+// restoreParameterSecrets returns a copy of the values where any masked value of a secret parameter is replaced with
+// the stored value, so that sending back a masked value keeps the current value
+func (s Script) restoreParameterSecrets(values map[string]string, stored map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	secret := map[string]bool{}
+	for _, parameter := range s.Parameters {
+		secret[parameter.Name] = parameter.Type == ScriptParameterTypeSecret
+	}
+
+	restored := map[string]string{}
+	for name, value := range values {
+		if secret[name] && value == secretParameterMask {
+			value = stored[name]
+		}
+		if value != "" {
+			restored[name] = value
+		}
+	}
+	return restored
+}
+
+// WithParameters returns a copy of the script that will be run with the given parameter values, or an error if the
+// values are not valid for the script
+func (s Script) WithParameters(values map[string]string) (*Script, error) {
+	variables, err := s.parameterVariables(values)
+	if err != nil {
+		return nil, err
+	}
+	s.parameterValues = variables
+	return &s, nil
+}
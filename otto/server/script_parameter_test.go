@@ -0,0 +1,197 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestScriptParameterValidate(t *testing.T) {
+	valid := []ScriptParameter{
+		{Name: "SERVICE", Type: ScriptParameterTypeString, Pattern: "^[a-z]+$", Default: "nginx"},
+		{Name: "COUNT", Type: ScriptParameterTypeInteger, Default: "3"},
+		{Name: "FORCE", Type: ScriptParameterTypeBoolean},
+		{Name: "LEVEL", Type: ScriptParameterTypeEnum, Options: []string{"low", "high"}},
+		{Name: "TOKEN", Type: ScriptParameterTypeSecret, Required: true},
+	}
+	if err := validateScriptParameters(valid); err != nil {
+		t.Errorf("Unexpected error validating parameters: %s", err.Error())
+	}
+
+	invalid := [][]ScriptParameter{
+		{{Name: "1FOO", Type: ScriptParameterTypeString}},
+		{{Name: "OTTO_HOST_ADDRESS", Type: ScriptParameterTypeString}},
+		{{Name: "FOO", Type: "float"}},
+		{{Name: "FOO", Type: ScriptParameterTypeEnum}},
+		{{Name: "FOO", Type: ScriptParameterTypeString, Pattern: "["}},
+		{{Name: "FOO", Type: ScriptParameterTypeInteger, Default: "one"}},
+		{{Name: "FOO", Type: ScriptParameterTypeString}, {Name: "FOO", Type: ScriptParameterTypeString}},
+	}
+	for _, parameters := range invalid {
+		if err := validateScriptParameters(parameters); err == nil {
+			t.Errorf("No error seen when one expected for parameters %+v", parameters)
+		}
+	}
+}
+
+func TestScriptParameterValues(t *testing.T) {
+	script := Script{
+		Parameters: []ScriptParameter{
+			{Name: "SERVICE", Type: ScriptParameterTypeString, Pattern: "^[a-z]+$", Default: "nginx"},
+			{Name: "COUNT", Type: ScriptParameterTypeInteger},
+			{Name: "FORCE", Type: ScriptParameterTypeBoolean},
+			{Name: "LEVEL", Type: ScriptParameterTypeEnum, Options: []string{"low", "high"}},
+			{Name: "TOKEN", Type: ScriptParameterTypeSecret, Required: true},
+		},
+	}
+
+	variables, err := script.parameterVariables(map[string]string{
+		"COUNT": "007",
+		"FORCE": "1",
+		"TOKEN": "hunter2",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error getting parameter values: %s", err.Error())
+	}
+	values := environ.Map(variables)
+	expected := map[string]string{
+		"SERVICE": "nginx",
+		"COUNT":   "7",
+		"FORCE":   "true",
+		"TOKEN":   "hunter2",
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("Unexpected value for %s: '%s', expected '%s'", key, values[key], value)
+		}
+	}
+	if _, present := values["LEVEL"]; present {
+		t.Errorf("Optional parameter without a value should not be included")
+	}
+	for _, variable := range variables {
+		if variable.Key == "TOKEN" && !variable.Secret {
+			t.Errorf("Secret parameter should be a secret variable")
+		}
+	}
+
+	invalid := []map[string]string{
+		{},
+		{"TOKEN": "x", "SERVICE": "NGINX"},
+		{"TOKEN": "x", "COUNT": "1.5"},
+		{"TOKEN": "x", "FORCE": "maybe"},
+		{"TOKEN": "x", "LEVEL": "medium"},
+		{"TOKEN": "x", "UNKNOWN": "x"},
+	}
+	for _, values := range invalid {
+		if _, err := script.parameterVariables(values); err == nil {
+			t.Errorf("No error seen when one expected for values %+v", values)
+		}
+	}
+}
+
+func TestScriptParameterEnvironment(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "systemctl restart $SERVICE",
+		Environment: []environ.Variable{
+			environ.New("SERVICE", "from-script"),
+		},
+		RunLevel: ScriptRunLevelReadOnly,
+		Parameters: []ScriptParameter{
+			{Name: "SERVICE", Type: ScriptParameterTypeString, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+
+	host := &Host{ID: randomString(6), Address: randLocalhostIP()}
+	withParameters, perr := script.WithParameters(map[string]string{"SERVICE": "nginx"})
+	if perr != nil {
+		t.Fatalf("Unexpected error: %s", perr.Error())
+	}
+	values := environ.Map(host.environmentVariablesForScript(withParameters))
+	if values["SERVICE"] != "nginx" {
+		t.Errorf("Parameter value did not override script environment: '%s'", values["SERVICE"])
+	}
+
+	if _, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "true",
+		RunLevel:   ScriptRunLevelReadOnly,
+		Parameters: []ScriptParameter{{Name: "FOO", Type: "float"}},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for invalid parameter")
+	}
+}
+
+func TestScheduleParameterSecrets(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "deploy $SERVICE",
+		RunLevel:   ScriptRunLevelReadOnly,
+		Parameters: []ScriptParameter{
+			{Name: "SERVICE", Type: ScriptParameterTypeString},
+			{Name: "TOKEN", Type: ScriptParameterTypeSecret, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    12444,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID:   script.ID,
+		Name:       randomString(6),
+		Scope:      ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:    "0 * * * *",
+		Parameters: map[string]string{"SERVICE": "nginx", "TOKEN": "hunter2"},
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+
+	hidden := *schedule
+	hidden.hideSecrets()
+	if hidden.Parameters["TOKEN"] != secretParameterMask || hidden.Parameters["SERVICE"] != "nginx" {
+		t.Errorf("Unexpected parameters: %+v", hidden.Parameters)
+	}
+	if schedule.Parameters["TOKEN"] != "hunter2" {
+		t.Errorf("Hiding secrets should not modify the original schedule")
+	}
+
+	// Sending the mask back keeps the stored value
+	edited, err := ScheduleStore.EditSchedule(schedule, editScheduleParameters{
+		Name:       schedule.Name,
+		Scope:      schedule.Scope,
+		Pattern:    schedule.Pattern,
+		Parameters: hidden.Parameters,
+	})
+	if err != nil {
+		t.Fatalf("Error editing schedule: %s", err.Message)
+	}
+	if edited.Parameters["TOKEN"] != "hunter2" {
+		t.Errorf("Secret parameter should be kept when the mask is sent back: %+v", edited.Parameters)
+	}
+
+	// The mask can't be used as the value of a new schedule
+	if _, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		ScriptID:   script.ID,
+		Name:       randomString(6),
+		Scope:      ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:    "0 * * * *",
+		Parameters: hidden.Parameters,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for masked required parameter")
+	}
+}
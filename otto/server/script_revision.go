@@ -0,0 +1,329 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// ScriptRevision describes an immutable copy of a script as it was after it was created or modified
+type ScriptRevision struct {
+	ID       string `ds:"primary"`
+	ScriptID string `ds:"index"`
+	// Revision the revision number, starting at 1
+	Revision int
+	// Author the username of the user who made this revision, empty if unknown
+	Author string
+	Time   time.Time
+	// RollbackOf the revision that was restored to make this revision, if it was a rollback
+	RollbackOf int
+	Script     Script
+}
+
+// NewRevision will save a copy of the script as its current revision
+func (s *scriptrevisionStoreObject) NewRevision(script Script, author string, rollbackOf int) (revision *ScriptRevision, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		revision, err = s.newRevision(tx, script, author, rollbackOf)
+		return nil
+	})
+	return
+}
+
+func (s *scriptrevisionStoreObject) newRevision(tx ds.IReadWriteTransaction, script Script, author string, rollbackOf int) (*ScriptRevision, *Error) {
+	revision := ScriptRevision{
+		ID:         newID(),
+		ScriptID:   script.ID,
+		Revision:   script.Revision,
+		Author:     author,
+		Time:       time.Now(),
+		RollbackOf: rollbackOf,
+		Script:     script,
+	}
+
+	if err := tx.Add(revision); err != nil {
+		log.PError("Error adding script revision", map[string]interface{}{
+			"script_id": script.ID,
+			"revision":  script.Revision,
+			"error":     err.Error(),
+		})
+		return nil, ErrorFrom(err)
+	}
+
+	log.PDebug("Saved script revision", map[string]interface{}{
+		"script_id": script.ID,
+		"revision":  script.Revision,
+		"author":    author,
+	})
+	return &revision, nil
+}
+
+// RevisionsForScript returns all revisions of the given script, most recent first
+func (s *scriptrevisionStoreObject) RevisionsForScript(scriptID string) (revisions []ScriptRevision) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		revisions = s.revisionsForScript(tx, scriptID)
+		return nil
+	})
+	return
+}
+
+func (s *scriptrevisionStoreObject) revisionsForScript(tx ds.IReadTransaction, scriptID string) []ScriptRevision {
+	objs, err := tx.GetIndex("ScriptID", scriptID, nil)
+	if err != nil {
+		log.Error("Error getting script revisions: script_id='%s' error='%s'", scriptID, err.Error())
+		return []ScriptRevision{}
+	}
+
+	revisions := make([]ScriptRevision, 0, len(objs))
+	for _, obj := range objs {
+		revision, k := obj.(ScriptRevision)
+		if !k {
+			log.Error("Object is not of type 'ScriptRevision'")
+			return []ScriptRevision{}
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions
+}
+
+// RevisionForScript returns the given revision of the script or nil
+func (s *scriptrevisionStoreObject) RevisionForScript(scriptID string, revision int) *ScriptRevision {
+	for _, r := range s.RevisionsForScript(scriptID) {
+		if r.Revision == revision {
+			return &r
+		}
+	}
+	return nil
+}
+
+// DeleteRevisionsForScript will delete all revisions of the given script
+func (s *scriptrevisionStoreObject) DeleteRevisionsForScript(scriptID string) (err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		if e := tx.DeleteAllIndex("ScriptID", scriptID); e != nil {
+			log.Error("Error deleting script revisions: script_id='%s' error='%s'", scriptID, e.Error())
+			err = ErrorFrom(e)
+		}
+		return nil
+	})
+	return
+}
+
+// ScriptRevisionDiff describes the differences between two revisions of a script
+type ScriptRevisionDiff struct {
+	ScriptID string
+	From     int
+	To       int
+	// Fields the properties of the script, other than its body, that differ between the revisions
+	Fields []ScriptRevisionFieldChange
+	// Lines the lines of the script body, in order, with the change made to each line
+	Lines []ScriptRevisionDiffLine
+}
+
+// ScriptRevisionFieldChange describes a change to a single property of a script
+type ScriptRevisionFieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// ScriptRevisionDiffLine describes a single line from a diff of a script body. Op is "=" for a line present in both
+// revisions, "-" for a line that was removed and "+" for a line that was added.
+type ScriptRevisionDiffLine struct {
+	Op   string
+	Line string
+}
+
+// DiffScriptRevisions returns the differences between two revisions of a script. The values of secret environment
+// variables are never included, only that they were changed.
+func DiffScriptRevisions(from, to ScriptRevision) ScriptRevisionDiff {
+	diff := ScriptRevisionDiff{
+		ScriptID: to.ScriptID,
+		From:     from.Revision,
+		To:       to.Revision,
+		Fields:   []ScriptRevisionFieldChange{},
+	}
+
+	addField := func(field, a, b string) {
+		if a != b {
+			diff.Fields = append(diff.Fields, ScriptRevisionFieldChange{Field: field, From: a, To: b})
+		}
+	}
+
+	a := from.Script
+	b := to.Script
+	addField("Name", a.Name, b.Name)
+	addField("Executable", a.Executable, b.Executable)
+	addField("RunAs", formatRunAs(a.RunAs), formatRunAs(b.RunAs))
+	addField("WorkingDirectory", a.WorkingDirectory, b.WorkingDirectory)
+	addField("AfterExecution", a.AfterExecution, b.AfterExecution)
+	addField("AttachmentIDs", strings.Join(a.AttachmentIDs, ", "), strings.Join(b.AttachmentIDs, ", "))
+	addField("RunLevel", fmt.Sprintf("%d", a.RunLevel), fmt.Sprintf("%d", b.RunLevel))
+	addField("Parameters", formatParameters(a.Parameters), formatParameters(b.Parameters))
+	addField("Template", fmt.Sprintf("%t", a.Template), fmt.Sprintf("%t", b.Template))
+	addField("SuccessCriteria", a.SuccessCriteria.String(), b.SuccessCriteria.String())
+	addField("TimeoutSeconds", fmt.Sprintf("%d", a.TimeoutSeconds), fmt.Sprintf("%d", b.TimeoutSeconds))
+	addField("RequiresApproval", fmt.Sprintf("%t", a.RequiresApproval), fmt.Sprintf("%t", b.RequiresApproval))
+	diff.Fields = append(diff.Fields, diffEnvironment(a.Environment, b.Environment)...)
+
+	diff.Lines = diffLines(strings.Split(a.Script, "\n"), strings.Split(b.Script, "\n"))
+	return diff
+}
+
+func formatRunAs(runAs RunAs) string {
+	if runAs.Inherit {
+		return "inherit"
+	}
+	return fmt.Sprintf("%d:%d", runAs.UID, runAs.GID)
+}
+
+func formatParameters(parameters []ScriptParameter) string {
+	lines := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		line := fmt.Sprintf("%s (%s)", parameter.Name, parameter.Type)
+		if parameter.Required {
+			line += " required"
+		}
+		if parameter.Default != "" && parameter.Type != ScriptParameterTypeSecret {
+			line += " default=" + parameter.Default
+		}
+		if parameter.Pattern != "" {
+			line += " pattern=" + parameter.Pattern
+		}
+		if len(parameter.Options) > 0 {
+			line += " options=" + strings.Join(parameter.Options, "|")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diffEnvironment(from, to []environ.Variable) []ScriptRevisionFieldChange {
+	const secretValue = "********"
+	format := func(variable *environ.Variable) string {
+		if variable == nil {
+			return ""
+		}
+		if variable.Secret {
+			return secretValue
+		}
+		return variable.Value
+	}
+
+	fromMap := map[string]environ.Variable{}
+	for _, variable := range from {
+		fromMap[variable.Key] = variable
+	}
+	toMap := map[string]environ.Variable{}
+	for _, variable := range to {
+		toMap[variable.Key] = variable
+	}
+
+	keys := []string{}
+	for key := range fromMap {
+		keys = append(keys, key)
+	}
+	for key := range toMap {
+		if _, present := fromMap[key]; !present {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []ScriptRevisionFieldChange{}
+	for _, key := range keys {
+		var a, b *environ.Variable
+		if variable, present := fromMap[key]; present {
+			a = &variable
+		}
+		if variable, present := toMap[key]; present {
+			b = &variable
+		}
+		if a != nil && b != nil && a.Value == b.Value && a.Secret == b.Secret {
+			continue
+		}
+		change := ScriptRevisionFieldChange{
+			Field: "Environment." + key,
+			From:  format(a),
+			To:    format(b),
+		}
+		if change.From == change.To {
+			// Both values are secret, so only show that the value changed
+			change.To = secretValue + " (changed)"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffMaxCells the largest table of lines that diffLines will compare. Scripts have no maximum size, so changes that
+// would need a larger table are shown as every changed line being removed and then added.
+const diffMaxCells = 1 << 20
+
+// diffLines returns the lines of a and b with the operations needed to change a into b, using the longest common
+// subsequence of lines. Lines common to the start and end of both are not compared.
+func diffLines(a, b []string) []ScriptRevisionDiffLine {
+	lines := []ScriptRevisionDiffLine{}
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		lines = append(lines, ScriptRevisionDiffLine{Op: "=", Line: a[0]})
+		a, b = a[1:], b[1:]
+	}
+	suffix := []ScriptRevisionDiffLine{}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]ScriptRevisionDiffLine{{Op: "=", Line: a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if (len(a)+1)*(len(b)+1) > diffMaxCells {
+		for _, line := range a {
+			lines = append(lines, ScriptRevisionDiffLine{Op: "-", Line: line})
+		}
+		for _, line := range b {
+			lines = append(lines, ScriptRevisionDiffLine{Op: "+", Line: line})
+		}
+		return append(lines, suffix...)
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			lines = append(lines, ScriptRevisionDiffLine{Op: "=", Line: a[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			lines = append(lines, ScriptRevisionDiffLine{Op: "-", Line: a[i]})
+			i++
+		} else {
+			lines = append(lines, ScriptRevisionDiffLine{Op: "+", Line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, ScriptRevisionDiffLine{Op: "-", Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, ScriptRevisionDiffLine{Op: "+", Line: b[j]})
+	}
+	return append(lines, suffix...)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestScriptRevisions(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo one\necho two\n",
+		Environment: []environ.Variable{
+			{
+				Key:    "PASSWORD",
+				Value:  "hunter2",
+				Secret: true,
+			},
+		},
+		RunLevel: ScriptRunLevelReadOnly,
+		Author:   "alice",
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	if script.Revision != 1 {
+		t.Fatalf("Unexpected revision for new script: %d", script.Revision)
+	}
+
+	script, err = ScriptStore.EditScript(script, editScriptParameters{
+		Name:       script.Name,
+		Executable: "/bin/sh",
+		Script:     "echo one\necho three\n",
+		Environment: []environ.Variable{
+			{
+				Key:    "PASSWORD",
+				Value:  "correct horse",
+				Secret: true,
+			},
+		},
+		RunLevel: ScriptRunLevelReadWrite,
+		Author:   "bob",
+	})
+	if err != nil {
+		t.Fatalf("Error editing script: %s", err.Message)
+	}
+	if script.Revision != 2 {
+		t.Fatalf("Unexpected revision for edited script: %d", script.Revision)
+	}
+
+	revisions := ScriptRevisionStore.RevisionsForScript(script.ID)
+	if len(revisions) != 2 {
+		t.Fatalf("Unexpected number of revisions: %d, expected 2", len(revisions))
+	}
+	if revisions[0].Revision != 2 || revisions[0].Author != "bob" {
+		t.Errorf("Unexpected latest revision: %d by %s", revisions[0].Revision, revisions[0].Author)
+	}
+	if revisions[1].Revision != 1 || revisions[1].Author != "alice" || revisions[1].Script.Script != "echo one\necho two\n" {
+		t.Errorf("Unexpected first revision: %+v", revisions[1])
+	}
+
+	diff := DiffScriptRevisions(revisions[1], revisions[0])
+	fields := map[string]ScriptRevisionFieldChange{}
+	for _, field := range diff.Fields {
+		fields[field.Field] = field
+	}
+	if _, present := fields["RunLevel"]; !present {
+		t.Errorf("Run level change not included in diff")
+	}
+	secret, present := fields["Environment.PASSWORD"]
+	if !present {
+		t.Errorf("Environment change not included in diff")
+	} else if secret.From == "hunter2" || secret.To == "correct horse" {
+		t.Errorf("Secret value included in diff")
+	}
+	removed := 0
+	added := 0
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case "-":
+			removed++
+			if line.Line != "echo two" {
+				t.Errorf("Unexpected removed line: %s", line.Line)
+			}
+		case "+":
+			added++
+			if line.Line != "echo three" {
+				t.Errorf("Unexpected added line: %s", line.Line)
+			}
+		}
+	}
+	if removed != 1 || added != 1 {
+		t.Errorf("Unexpected diff: %+v", diff.Lines)
+	}
+
+	script, err = ScriptStore.RollbackScript(script, revisions[1], "carol")
+	if err != nil {
+		t.Fatalf("Error rolling back script: %s", err.Message)
+	}
+	if script.Revision != 3 {
+		t.Errorf("Unexpected revision for rolled back script: %d", script.Revision)
+	}
+	if script.Script != "echo one\necho two\n" || script.RunLevel != ScriptRunLevelReadOnly || script.Environment[0].Value != "hunter2" {
+		t.Errorf("Script not rolled back: %+v", script)
+	}
+	latest := ScriptRevisionStore.RevisionForScript(script.ID, 3)
+	if latest == nil || latest.RollbackOf != 1 || latest.Author != "carol" {
+		t.Errorf("Unexpected rollback revision: %+v", latest)
+	}
+
+	if err := ScriptStore.DeleteScript(script); err != nil {
+		t.Fatalf("Error deleting script: %s", err.Message)
+	}
+	if revisions := ScriptRevisionStore.RevisionsForScript(script.ID); len(revisions) != 0 {
+		t.Errorf("Revisions not deleted with script")
+	}
+}
+
+func TestScriptRevisionLegacyScript(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo one",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+
+	// Simulate a script from before revisions were added
+	ScriptRevisionStore.DeleteRevisionsForScript(script.ID)
+	script.Revision = 0
+
+	script, err = ScriptStore.EditScript(script, editScriptParameters{
+		Name:       script.Name,
+		Executable: "/bin/sh",
+		Script:     "echo two",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error editing script: %s", err.Message)
+	}
+
+	revisions := ScriptRevisionStore.RevisionsForScript(script.ID)
+	if len(revisions) != 2 {
+		t.Fatalf("Unexpected number of revisions: %d, expected 2", len(revisions))
+	}
+	if revisions[1].Script.Script != "echo one" {
+		t.Errorf("Original script not saved as first revision")
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	format := func(lines []ScriptRevisionDiffLine) string {
+		result := []string{}
+		for _, line := range lines {
+			result = append(result, line.Op+line.Line)
+		}
+		return strings.Join(result, ",")
+	}
+
+	diff := format(diffLines([]string{"a", "b", "c", "d", "e"}, []string{"a", "c", "x", "d", "e"}))
+	if diff != "=a,-b,=c,+x,=d,=e" {
+		t.Errorf("Unexpected diff: %s", diff)
+	}
+
+	// Large changes are not compared line by line
+	a := make([]string, 2000)
+	b := make([]string, 2000)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	a = append([]string{"first"}, append(a, "last")...)
+	b = append([]string{"first"}, append(b, "last")...)
+	lines := diffLines(a, b)
+	if len(lines) != 4002 || format(lines[:2]) != "=first,-a0" || format(lines[2001:2003]) != "+b0,+b1" || format(lines[4001:]) != "=last" {
+		t.Errorf("Unexpected diff of large change")
+	}
+}
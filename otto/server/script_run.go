@@ -0,0 +1,305 @@
+package server
+
+import (
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/otto/server/environ"
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+// ScriptRun describes a single execution of a script on a host
+type ScriptRun struct {
+	ID       string `ds:"primary"`
+	ScriptID string `ds:"index"`
+	// ScriptRevision the revision of the script that was run
+	ScriptRevision int
+	// Command the ad-hoc command that was run, only present if the run was not for a script
+	Command     *Command
+	HostID      string `ds:"index"`
+	ScheduleID  string `ds:"index"`
+	TriggeredBy string
+	Environment []environ.Variable
+	Time        ScriptRunTime
+	Result      otto.ScriptResult
+	RunError    string
+	OutputSize  ScriptRunOutputSize
+	// Status the status of the run, according to the success criteria of the script
+	Status string
+	// FailureReason why the script did not meet its success criteria, if it did not
+	FailureReason string
+}
+
+// ScriptRunTime describes timing information from a script run
+type ScriptRunTime struct {
+	Start          time.Time
+	Finished       time.Time
+	ElapsedSeconds float64
+}
+
+// ScriptRunOutputSize describes the length of the output saved for a script run
+type ScriptRunOutputSize struct {
+	Stdout uint64
+	Stderr uint64
+}
+
+// StdoutPath returns the path of the file containing the stdout of the script run
+func (run ScriptRun) StdoutPath() string {
+	return path.Join(Directories.Runs, run.ID+".stdout")
+}
+
+// StderrPath returns the path of the file containing the stderr of the script run
+func (run ScriptRun) StderrPath() string {
+	return path.Join(Directories.Runs, run.ID+".stderr")
+}
+
+// Output returns the output of the script run
+func (run ScriptRun) Output() (*ScriptOutput, error) {
+	stdout, err := os.ReadFile(run.StdoutPath())
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := os.ReadFile(run.StderrPath())
+	if err != nil {
+		return nil, err
+	}
+	return &ScriptOutput{
+		Stdout: string(stdout),
+		Stderr: string(stderr),
+	}, nil
+}
+
+type newScriptRunParameters struct {
+	Script      *Script
+	Command     *Command
+	Host        *Host
+	Schedule    *Schedule
+	TriggeredBy string
+	Start       time.Time
+	Result      *ScriptResult
+	Error       error
+}
+
+// NewRun will save a record of a script being run on a host, including its output
+func (s *scriptrunStoreObject) NewRun(params newScriptRunParameters) (run *ScriptRun, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		run, err = s.newRun(tx, params)
+		return nil
+	})
+	return
+}
+
+func (s *scriptrunStoreObject) newRun(tx ds.IReadWriteTransaction, params newScriptRunParameters) (*ScriptRun, *Error) {
+	finished := time.Now()
+	run := ScriptRun{
+		ID:             newID(),
+		ScriptID:       params.Script.ID,
+		ScriptRevision: params.Script.Revision,
+		HostID:         params.Host.ID,
+		TriggeredBy:    params.TriggeredBy,
+		Environment:    []environ.Variable{},
+		Time: ScriptRunTime{
+			Start:          params.Start,
+			Finished:       finished,
+			ElapsedSeconds: finished.Sub(params.Start).Seconds(),
+		},
+	}
+	if params.Schedule != nil {
+		run.ScheduleID = params.Schedule.ID
+	}
+	if params.Command != nil {
+		// The resolved environment is saved with the run, so the command environment is not needed
+		run.Command = &Command{
+			Executable:       params.Command.Executable,
+			Script:           params.Command.Script,
+			RunAs:            params.Command.RunAs,
+			WorkingDirectory: params.Command.WorkingDirectory,
+		}
+	}
+
+	output := ScriptOutput{}
+	if params.Error != nil {
+		run.RunError = params.Error.Error()
+		run.Status = ScriptStatusFailed
+	} else if params.Result != nil {
+		run.Result = params.Result.Result
+		run.RunError = params.Result.RunError
+		run.Status = params.Result.Status
+		run.FailureReason = params.Result.FailureReason
+		output = params.Result.Output
+
+		// Secret values are never saved to the run history
+		for _, variable := range params.Result.Environment {
+			if variable.Secret {
+				variable.Value = ""
+			}
+			run.Environment = append(run.Environment, variable)
+		}
+	}
+	run.OutputSize = ScriptRunOutputSize{
+		Stdout: uint64(len(output.Stdout)),
+		Stderr: uint64(len(output.Stderr)),
+	}
+
+	if err := os.WriteFile(run.StdoutPath(), []byte(output.Stdout), 0644); err != nil {
+		log.PError("Error writing script run output", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+		return nil, ErrorFrom(err)
+	}
+	if err := os.WriteFile(run.StderrPath(), []byte(output.Stderr), 0644); err != nil {
+		log.PError("Error writing script run output", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+		os.Remove(run.StdoutPath())
+		return nil, ErrorFrom(err)
+	}
+
+	if err := tx.Add(run); err != nil {
+		log.PError("Error adding script run", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+		os.Remove(run.StdoutPath())
+		os.Remove(run.StderrPath())
+		return nil, ErrorFrom(err)
+	}
+
+	log.PDebug("Saved script run", map[string]interface{}{
+		"run_id":    run.ID,
+		"script_id": run.ScriptID,
+		"host_id":   run.HostID,
+	})
+	return &run, nil
+}
+
+// RunWithID returns the script run with the given ID or nil
+func (s *scriptrunStoreObject) RunWithID(id string) (run *ScriptRun) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		run = s.runWithID(tx, id)
+		return nil
+	})
+	return
+}
+
+func (s *scriptrunStoreObject) runWithID(tx ds.IReadTransaction, id string) *ScriptRun {
+	obj, err := tx.Get(id)
+	if err != nil {
+		log.Error("Error getting script run: id='%s' error='%s'", id, err.Error())
+		return nil
+	}
+	if obj == nil {
+		return nil
+	}
+	run, k := obj.(ScriptRun)
+	if !k {
+		log.Error("Object is not of type 'ScriptRun'")
+		return nil
+	}
+	return &run
+}
+
+// AllRuns returns all script runs, most recent first
+func (s *scriptrunStoreObject) AllRuns() (runs []ScriptRun) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		runs = s.allRuns(tx)
+		return nil
+	})
+	return
+}
+
+func (s *scriptrunStoreObject) allRuns(tx ds.IReadTransaction) []ScriptRun {
+	objs, err := tx.GetAll(nil)
+	if err != nil {
+		log.Error("Error getting all script runs: %s", err.Error())
+		return []ScriptRun{}
+	}
+	return sortedScriptRuns(objs)
+}
+
+// RunsForScript returns all runs of the given script, most recent first
+func (s *scriptrunStoreObject) RunsForScript(scriptID string) (runs []ScriptRun) {
+	return s.runsWithIndex("ScriptID", scriptID)
+}
+
+// RunsForHost returns all runs on the given host, most recent first
+func (s *scriptrunStoreObject) RunsForHost(hostID string) (runs []ScriptRun) {
+	return s.runsWithIndex("HostID", hostID)
+}
+
+// RunsForSchedule returns all runs started by the given schedule, most recent first
+func (s *scriptrunStoreObject) RunsForSchedule(scheduleID string) (runs []ScriptRun) {
+	return s.runsWithIndex("ScheduleID", scheduleID)
+}
+
+func (s *scriptrunStoreObject) runsWithIndex(index, value string) (runs []ScriptRun) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		objs, err := tx.GetIndex(index, value, nil)
+		if err != nil {
+			log.Error("Error getting script runs: index='%s' error='%s'", index, err.Error())
+			runs = []ScriptRun{}
+			return nil
+		}
+		runs = sortedScriptRuns(objs)
+		return nil
+	})
+	return
+}
+
+func sortedScriptRuns(objs []interface{}) []ScriptRun {
+	runs := make([]ScriptRun, 0, len(objs))
+	for _, obj := range objs {
+		run, k := obj.(ScriptRun)
+		if !k {
+			log.Error("Object is not of type 'ScriptRun'")
+			return []ScriptRun{}
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Time.Start.UnixNano() > runs[j].Time.Start.UnixNano()
+	})
+
+	return runs
+}
+
+// Cleanup will delete all script runs, and their output, that are older than the configured retention period
+func (s *scriptrunStoreObject) Cleanup() {
+	retentionDays := Options.General.RunRetentionDays
+	if retentionDays == 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -int(retentionDays))
+
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		deleted := 0
+		for _, run := range s.allRuns(tx) {
+			if run.Time.Start.After(cutoff) {
+				continue
+			}
+			if err := tx.Delete(run); err != nil {
+				log.PError("Error deleting script run", map[string]interface{}{
+					"run_id": run.ID,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			os.Remove(run.StdoutPath())
+			os.Remove(run.StderrPath())
+			deleted++
+		}
+		if deleted > 0 {
+			log.PInfo("Removed expired script runs", map[string]interface{}{
+				"deleted":        deleted,
+				"retention_days": retentionDays,
+			})
+		}
+		return nil
+	})
+}
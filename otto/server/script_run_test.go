@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ecnepsnai/otto/server/environ"
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+func TestScriptRunHistory(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/bash",
+		Script:     "#!/bin/bash\necho hello\n",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    12444,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	stdout := randomString(32)
+	stderr := randomString(32)
+	run, err := ScriptRunStore.NewRun(newScriptRunParameters{
+		Script:      script,
+		Host:        host,
+		TriggeredBy: "example",
+		Start:       time.Now().Add(-1 * time.Second),
+		Result: &ScriptResult{
+			ScriptID: script.ID,
+			Environment: []environ.Variable{
+				{Key: "FOO", Value: "BAR"},
+				{Key: "PASSWORD", Value: "hunter2", Secret: true},
+			},
+			Result: otto.ScriptResult{
+				Success: true,
+				Code:    2,
+			},
+			Output: ScriptOutput{
+				Stdout: stdout,
+				Stderr: stderr,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error saving script run: %s", err.Message)
+	}
+
+	run = ScriptRunStore.RunWithID(run.ID)
+	if run == nil {
+		t.Fatalf("No script run returned with ID")
+	}
+	if run.TriggeredBy != "example" {
+		t.Errorf("Unexpected triggered by: %s", run.TriggeredBy)
+	}
+	if run.Result.Code != 2 {
+		t.Errorf("Unexpected exit code: %d", run.Result.Code)
+	}
+	if run.Time.ElapsedSeconds < 1 {
+		t.Errorf("Unexpected elapsed time: %f", run.Time.ElapsedSeconds)
+	}
+	if len(run.Environment) != 2 {
+		t.Fatalf("Unexpected number of environment variables: %d", len(run.Environment))
+	}
+	if run.Environment[0].Value != "BAR" {
+		t.Errorf("Non-secret environment variable was modified")
+	}
+	if run.Environment[1].Value != "" {
+		t.Errorf("Secret environment variable was not masked")
+	}
+
+	output, oerr := run.Output()
+	if oerr != nil {
+		t.Fatalf("Error reading script run output: %s", oerr.Error())
+	}
+	if output.Stdout != stdout || output.Stderr != stderr {
+		t.Errorf("Unexpected script run output")
+	}
+
+	if runs := ScriptRunStore.RunsForHost(host.ID); len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("Script run not returned for host")
+	}
+	if runs := ScriptRunStore.RunsForScript(script.ID); len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("Script run not returned for script")
+	}
+}
+
+func TestScriptRunFailed(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/bash",
+		Script:     "#!/bin/bash\necho hello\n",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    12444,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	run, err := ScriptRunStore.NewRun(newScriptRunParameters{
+		Script: script,
+		Host:   host,
+		Start:  time.Now(),
+		Error:  fmt.Errorf("connection refused"),
+	})
+	if err != nil {
+		t.Fatalf("Error saving script run: %s", err.Message)
+	}
+	if run.RunError != "connection refused" {
+		t.Errorf("Unexpected run error: %s", run.RunError)
+	}
+	if run.OutputSize.Stdout != 0 || run.OutputSize.Stderr != 0 {
+		t.Errorf("Unexpected output for failed run")
+	}
+}
+
+func TestScriptRunCleanup(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/bash",
+		Script:     "#!/bin/bash\necho hello\n",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    12444,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	retentionDays := Options.General.RunRetentionDays
+	oldRun, err := ScriptRunStore.NewRun(newScriptRunParameters{
+		Script: script,
+		Host:   host,
+		Start:  time.Now().AddDate(0, 0, -int(retentionDays)-1),
+		Result: &ScriptResult{},
+	})
+	if err != nil {
+		t.Fatalf("Error saving script run: %s", err.Message)
+	}
+	newRun, err := ScriptRunStore.NewRun(newScriptRunParameters{
+		Script: script,
+		Host:   host,
+		Start:  time.Now(),
+		Result: &ScriptResult{},
+	})
+	if err != nil {
+		t.Fatalf("Error saving script run: %s", err.Message)
+	}
+
+	ScriptRunStore.Cleanup()
+
+	if ScriptRunStore.RunWithID(oldRun.ID) != nil {
+		t.Errorf("Expired script run was not removed")
+	}
+	if FileExists(oldRun.StdoutPath()) {
+		t.Errorf("Expired script run output was not removed")
+	}
+	if ScriptRunStore.RunWithID(newRun.ID) == nil {
+		t.Errorf("Current script run was removed")
+	}
+}
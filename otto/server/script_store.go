@@ -96,6 +96,13 @@ type newScriptParameters struct {
 	AfterExecution   string
 	AttachmentIDs    []string
 	RunLevel         int
+	Parameters       []ScriptParameter
+	Template         bool
+	SuccessCriteria  SuccessCriteria
+	TimeoutSeconds   int64
+	RequiresApproval bool
+	// Author the username of the user making the change, recorded in the script revision
+	Author string `json:"-"`
 }
 
 func (s *scriptStoreObject) NewScript(params newScriptParameters) (script *Script, err *Error) {
@@ -123,6 +130,24 @@ func (s *scriptStoreObject) newScript(tx ds.IReadWriteTransaction, params newScr
 		return nil, ErrorUser("Invalid run level %d", params.RunLevel)
 	}
 
+	if err := validateScriptParameters(params.Parameters); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if params.Template {
+		if err := validateTemplate(params.Name, params.Script); err != nil {
+			return nil, ErrorUser("Invalid script template: %s", err.Error())
+		}
+	}
+
+	if err := params.SuccessCriteria.Validate(); err != nil {
+		return nil, ErrorUser("Invalid success criteria: %s", err.Error())
+	}
+
+	if params.TimeoutSeconds < 0 {
+		return nil, ErrorUser("Timeout cannot be negative")
+	}
+
 	script := Script{
 		ID:               newID(),
 		Name:             params.Name,
@@ -134,6 +159,12 @@ func (s *scriptStoreObject) newScript(tx ds.IReadWriteTransaction, params newScr
 		AfterExecution:   params.AfterExecution,
 		AttachmentIDs:    params.AttachmentIDs,
 		RunLevel:         params.RunLevel,
+		Parameters:       params.Parameters,
+		Template:         params.Template,
+		SuccessCriteria:  params.SuccessCriteria,
+		TimeoutSeconds:   params.TimeoutSeconds,
+		RequiresApproval: params.RequiresApproval,
+		Revision:         1,
 	}
 	if err := limits.Check(script); err != nil {
 		return nil, ErrorUser(err.Error())
@@ -144,6 +175,10 @@ func (s *scriptStoreObject) newScript(tx ds.IReadWriteTransaction, params newScr
 		return nil, ErrorFrom(err)
 	}
 
+	if _, err := ScriptRevisionStore.NewRevision(script, params.Author, 0); err != nil {
+		return nil, err
+	}
+
 	log.Info("Added new script '%s'", params.Name)
 	ScriptCache.Update(tx)
 	return &script, nil
@@ -159,17 +194,62 @@ type editScriptParameters struct {
 	AfterExecution   string
 	AttachmentIDs    []string
 	RunLevel         int
+	Parameters       []ScriptParameter
+	Template         bool
+	SuccessCriteria  SuccessCriteria
+	TimeoutSeconds   int64
+	RequiresApproval bool
+	// Author the username of the user making the change, recorded in the script revision
+	Author string `json:"-"`
 }
 
 func (s *scriptStoreObject) EditScript(script *Script, params editScriptParameters) (newScript *Script, err *Error) {
 	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
-		newScript, err = s.editScript(tx, script, params)
+		newScript, err = s.editScript(tx, script, params, 0)
+		return nil
+	})
+	return
+}
+
+// RollbackScript will modify the script to match the given revision. The rollback is saved as a new revision.
+func (s *scriptStoreObject) RollbackScript(script *Script, revision ScriptRevision, author string) (newScript *Script, err *Error) {
+	if revision.ScriptID != script.ID {
+		return nil, ErrorUser("Revision is not for script %s", script.ID)
+	}
+
+	// Attachments may have been deleted since the revision was made
+	attachmentIDs := []string{}
+	for _, id := range revision.Script.AttachmentIDs {
+		if AttachmentStore.AttachmentWithID(id) != nil {
+			attachmentIDs = append(attachmentIDs, id)
+		}
+	}
+
+	params := editScriptParameters{
+		Name:             revision.Script.Name,
+		Executable:       revision.Script.Executable,
+		Script:           revision.Script.Script,
+		Environment:      revision.Script.Environment,
+		RunAs:            revision.Script.RunAs,
+		WorkingDirectory: revision.Script.WorkingDirectory,
+		AfterExecution:   revision.Script.AfterExecution,
+		AttachmentIDs:    attachmentIDs,
+		RunLevel:         revision.Script.RunLevel,
+		Parameters:       revision.Script.Parameters,
+		Template:         revision.Script.Template,
+		SuccessCriteria:  revision.Script.SuccessCriteria,
+		TimeoutSeconds:   revision.Script.TimeoutSeconds,
+		RequiresApproval: revision.Script.RequiresApproval,
+		Author:           author,
+	}
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		newScript, err = s.editScript(tx, script, params, revision.Revision)
 		return nil
 	})
 	return
 }
 
-func (s *scriptStoreObject) editScript(tx ds.IReadWriteTransaction, script *Script, params editScriptParameters) (*Script, *Error) {
+func (s *scriptStoreObject) editScript(tx ds.IReadWriteTransaction, script *Script, params editScriptParameters, rollbackOf int) (*Script, *Error) {
 	if existingScript := s.scriptWithName(tx, params.Name); existingScript != nil && existingScript.ID != script.ID {
 		log.Warn("Script with name '%s' already exists", params.Name)
 		return nil, ErrorUser("Script with name '%s' already exists", params.Name)
@@ -186,6 +266,32 @@ func (s *scriptStoreObject) editScript(tx ds.IReadWriteTransaction, script *Scri
 		return nil, ErrorUser("Invalid run level %d", params.RunLevel)
 	}
 
+	if err := validateScriptParameters(params.Parameters); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if params.Template {
+		if err := validateTemplate(params.Name, params.Script); err != nil {
+			return nil, ErrorUser("Invalid script template: %s", err.Error())
+		}
+	}
+
+	if err := params.SuccessCriteria.Validate(); err != nil {
+		return nil, ErrorUser("Invalid success criteria: %s", err.Error())
+	}
+
+	if params.TimeoutSeconds < 0 {
+		return nil, ErrorUser("Timeout cannot be negative")
+	}
+
+	if script.Revision == 0 {
+		// Scripts from before revisions were added have their current state saved as the first revision
+		script.Revision = 1
+		if _, err := ScriptRevisionStore.NewRevision(*script, "", 0); err != nil {
+			return nil, err
+		}
+	}
+
 	script.Name = params.Name
 	script.Executable = params.Executable
 	script.Script = params.Script
@@ -195,6 +301,12 @@ func (s *scriptStoreObject) editScript(tx ds.IReadWriteTransaction, script *Scri
 	script.AfterExecution = params.AfterExecution
 	script.AttachmentIDs = params.AttachmentIDs
 	script.RunLevel = params.RunLevel
+	script.Parameters = params.Parameters
+	script.Template = params.Template
+	script.SuccessCriteria = params.SuccessCriteria
+	script.TimeoutSeconds = params.TimeoutSeconds
+	script.RequiresApproval = params.RequiresApproval
+	script.Revision++
 	if err := limits.Check(script); err != nil {
 		return nil, ErrorUser(err.Error())
 	}
@@ -204,6 +316,10 @@ func (s *scriptStoreObject) editScript(tx ds.IReadWriteTransaction, script *Scri
 		return nil, ErrorFrom(err)
 	}
 
+	if _, err := ScriptRevisionStore.NewRevision(*script, params.Author, rollbackOf); err != nil {
+		return nil, err
+	}
+
 	log.Info("Updating script '%s'", params.Name)
 	ScriptCache.Update(tx)
 	return script, nil
@@ -223,6 +339,9 @@ func (s *scriptStoreObject) deleteScript(tx ds.IReadWriteTransaction, script *Sc
 			return ErrorUser("Script is used by schedule %s", schedule.Name)
 		}
 	}
+	if workflows := WorkflowStore.AllWorkflowsForScript(script.ID); len(workflows) > 0 {
+		return ErrorUser("Script is used by workflow %s", workflows[0].Name)
+	}
 
 	if err := tx.Delete(*script); err != nil {
 		log.Error("Error deleting script '%s': %s", script.Name, err.Error())
@@ -235,6 +354,10 @@ func (s *scriptStoreObject) deleteScript(tx ds.IReadWriteTransaction, script *Sc
 		}
 	}
 
+	if err := ScriptRevisionStore.DeleteRevisionsForScript(script.ID); err != nil {
+		log.Error("Error deleting revisions for script '%s': %s", script.Name, err.Message)
+	}
+
 	GroupStore.CleanupDeadScripts(tx)
 	log.Info("Deleting script '%s'", script.Name)
 	ScriptCache.Update(tx)
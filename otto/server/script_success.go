@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SuccessCriteria describes the rules used to decide if a script run was successful. The zero value only allows an
+// exit code of 0.
+type SuccessCriteria struct {
+	// ExitCodes the exit codes that indicate the script succeeded. If empty only 0 is allowed.
+	ExitCodes []int
+	// ChangedExitCodes the exit codes that indicate the script succeeded and made changes. These codes are always
+	// allowed, and do not need to be included in ExitCodes.
+	ChangedExitCodes []int
+	// StdoutMatch a regular expression that must match stdout
+	StdoutMatch string
+	// StdoutNotMatch a regular expression that must not match stdout
+	StdoutNotMatch string
+	// StderrMatch a regular expression that must match stderr
+	StderrMatch string
+	// StderrNotMatch a regular expression that must not match stderr
+	StderrNotMatch string
+	// MaxDurationSeconds the maximum number of seconds the script may run for, 0 for no limit
+	MaxDurationSeconds int
+}
+
+// Validate returns an error if the success criteria is not valid
+func (c SuccessCriteria) Validate() error {
+	for _, pattern := range []string{c.StdoutMatch, c.StdoutNotMatch, c.StderrMatch, c.StderrNotMatch} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %s", pattern, err.Error())
+		}
+	}
+	if c.MaxDurationSeconds < 0 {
+		return fmt.Errorf("maximum duration must not be negative")
+	}
+	return nil
+}
+
+// Evaluate returns the status of a script run with the given exit code, output and duration. If the status is
+// ScriptStatusFailed the reason describes which rule was not met.
+func (c SuccessCriteria) Evaluate(code int, output ScriptOutput, duration time.Duration) (status string, reason string) {
+	status = ScriptStatusSuccess
+	if sliceContains(code, c.ChangedExitCodes) {
+		status = ScriptStatusChanged
+	} else if len(c.ExitCodes) == 0 && code != 0 {
+		return ScriptStatusFailed, fmt.Sprintf("exit code %d", code)
+	} else if len(c.ExitCodes) > 0 && !sliceContains(code, c.ExitCodes) {
+		return ScriptStatusFailed, fmt.Sprintf("exit code %d is not allowed", code)
+	}
+
+	rules := []struct {
+		Name    string
+		Pattern string
+		Output  string
+		Match   bool
+	}{
+		{"stdout", c.StdoutMatch, output.Stdout, true},
+		{"stdout", c.StdoutNotMatch, output.Stdout, false},
+		{"stderr", c.StderrMatch, output.Stderr, true},
+		{"stderr", c.StderrNotMatch, output.Stderr, false},
+	}
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return ScriptStatusFailed, fmt.Sprintf("invalid pattern '%s'", rule.Pattern)
+		}
+		matched := pattern.MatchString(rule.Output)
+		if rule.Match && !matched {
+			return ScriptStatusFailed, fmt.Sprintf("%s did not match '%s'", rule.Name, rule.Pattern)
+		}
+		if !rule.Match && matched {
+			return ScriptStatusFailed, fmt.Sprintf("%s matched '%s'", rule.Name, rule.Pattern)
+		}
+	}
+
+	if c.MaxDurationSeconds > 0 && duration > time.Duration(c.MaxDurationSeconds)*time.Second {
+		return ScriptStatusFailed, fmt.Sprintf("took longer than %d seconds", c.MaxDurationSeconds)
+	}
+
+	return status, ""
+}
+
+// String returns a description of the success criteria, used when comparing script revisions
+func (c SuccessCriteria) String() string {
+	formatCodes := func(codes []int) string {
+		values := make([]string, len(codes))
+		for i, code := range codes {
+			values[i] = fmt.Sprintf("%d", code)
+		}
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprintf("exit_codes=%s changed_exit_codes=%s stdout_match='%s' stdout_not_match='%s' stderr_match='%s' stderr_not_match='%s' max_duration=%d",
+		formatCodes(c.ExitCodes), formatCodes(c.ChangedExitCodes), c.StdoutMatch, c.StdoutNotMatch, c.StderrMatch, c.StderrNotMatch, c.MaxDurationSeconds)
+}
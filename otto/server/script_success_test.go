@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSuccessCriteriaEvaluate(t *testing.T) {
+	type testCase struct {
+		Criteria SuccessCriteria
+		Code     int
+		Output   ScriptOutput
+		Duration time.Duration
+		Status   string
+	}
+
+	cases := []testCase{
+		{SuccessCriteria{}, 0, ScriptOutput{}, time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{}, 1, ScriptOutput{}, time.Second, ScriptStatusFailed},
+		{SuccessCriteria{ExitCodes: []int{0, 3}}, 3, ScriptOutput{}, time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{ExitCodes: []int{3}}, 0, ScriptOutput{}, time.Second, ScriptStatusFailed},
+		{SuccessCriteria{ChangedExitCodes: []int{2}}, 2, ScriptOutput{}, time.Second, ScriptStatusChanged},
+		{SuccessCriteria{ChangedExitCodes: []int{2}}, 0, ScriptOutput{}, time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{StdoutMatch: "^ok$"}, 0, ScriptOutput{Stdout: "ok"}, time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{StdoutMatch: "^ok$"}, 0, ScriptOutput{Stdout: "not ok"}, time.Second, ScriptStatusFailed},
+		{SuccessCriteria{StdoutNotMatch: "(?i)error"}, 0, ScriptOutput{Stdout: "An ERROR happened"}, time.Second, ScriptStatusFailed},
+		{SuccessCriteria{StderrMatch: "done"}, 0, ScriptOutput{Stderr: "done"}, time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{StderrNotMatch: "warning"}, 0, ScriptOutput{Stderr: "warning: disk"}, time.Second, ScriptStatusFailed},
+		{SuccessCriteria{MaxDurationSeconds: 5}, 0, ScriptOutput{}, 4 * time.Second, ScriptStatusSuccess},
+		{SuccessCriteria{MaxDurationSeconds: 5}, 0, ScriptOutput{}, 6 * time.Second, ScriptStatusFailed},
+		{SuccessCriteria{ChangedExitCodes: []int{2}, StdoutNotMatch: "error"}, 2, ScriptOutput{Stdout: "error"}, time.Second, ScriptStatusFailed},
+	}
+
+	for i, c := range cases {
+		status, reason := c.Criteria.Evaluate(c.Code, c.Output, c.Duration)
+		if status != c.Status {
+			t.Errorf("Unexpected status for case %d. Expected '%s' got '%s'", i, c.Status, status)
+		}
+		if status == ScriptStatusFailed && reason == "" {
+			t.Errorf("No reason given for failed case %d", i)
+		}
+	}
+}
+
+func TestSuccessCriteriaValidate(t *testing.T) {
+	if err := (SuccessCriteria{StdoutMatch: "^[a-z]+$", MaxDurationSeconds: 10}).Validate(); err != nil {
+		t.Errorf("Unexpected error validating success criteria: %s", err.Error())
+	}
+	if err := (SuccessCriteria{StderrNotMatch: "(unclosed"}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for invalid pattern")
+	}
+	if err := (SuccessCriteria{MaxDurationSeconds: -1}).Validate(); err == nil {
+		t.Errorf("No error seen when one expected for negative duration")
+	}
+
+	if _, err := ScriptStore.NewScript(newScriptParameters{
+		Name:            randomString(6),
+		Executable:      "/bin/sh",
+		Script:          "true",
+		RunLevel:        ScriptRunLevelReadOnly,
+		SuccessCriteria: SuccessCriteria{StdoutMatch: "["},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for script with invalid success criteria")
+	}
+}
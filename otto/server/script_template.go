@@ -0,0 +1,265 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/ecnepsnai/otto/server/environ"
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+// TemplateContext describes the data available to script and attachment templates
+type TemplateContext struct {
+	Host TemplateHost
+	// Groups the names of the groups the host is a member of
+	Groups []string
+	// Properties the properties reported by the host in its last heartbeat
+	Properties map[string]string
+	// Environment the merged environment variables for the script on this host
+	Environment map[string]string
+}
+
+// TemplateHost describes the host in a template context
+type TemplateHost struct {
+	ID      string
+	Name    string
+	Address string
+	Port    uint32
+}
+
+// templateContext returns the template context for this host with the given environment variables
+func (host *Host) templateContext(variables []environ.Variable) TemplateContext {
+	context := TemplateContext{
+		Host: TemplateHost{
+			ID:      host.ID,
+			Name:    host.Name,
+			Address: host.Address,
+			Port:    host.Port,
+		},
+		Groups:      []string{},
+		Properties:  map[string]string{},
+		Environment: environ.Map(variables),
+	}
+
+	groups, err := host.Groups()
+	if err == nil {
+		for _, group := range groups {
+			context.Groups = append(context.Groups, group.Name)
+		}
+	}
+
+	if heartbeat := heartbeatStore.LastHeartbeat(host); heartbeat != nil {
+		for key, value := range heartbeat.Properties {
+			context.Properties[key] = value
+		}
+	}
+
+	return context
+}
+
+// validateTemplate returns an error if the template content cannot be parsed
+func validateTemplate(name, content string) error {
+	_, err := template.New(name).Parse(content)
+	return err
+}
+
+// renderTemplate renders the given template content with the context. Referencing a key that is not present in the
+// context is an error, so that typos are never silently rendered as empty values.
+func renderTemplate(name, content string, context TemplateContext) ([]byte, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, context); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isText returns true if the data looks like text that can be used as a template
+func isText(data []byte) bool {
+	return utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+}
+
+// validateTemplateFile returns an error if the file at filePath is not text or is not a valid template
+func validateTemplateFile(name, filePath string) *Error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		log.PError("Error reading attachment template", map[string]interface{}{
+			"file_path": filePath,
+			"error":     err.Error(),
+		})
+		return ErrorFrom(err)
+	}
+	if !isText(data) {
+		return ErrorUser("Only text files can be templates")
+	}
+	if err := validateTemplate(name, string(data)); err != nil {
+		return ErrorUser("Invalid template: %s", err.Error())
+	}
+	return nil
+}
+
+// renderedFile describes a template attachment after it was rendered for a host
+type renderedFile struct {
+	Info otto.FileInfo
+	Data []byte
+}
+
+// render renders this template attachment with the context. The returned file info contains the length and checksum
+// of the rendered data, not of the stored template.
+func (attachment Attachment) render(context TemplateContext) (*renderedFile, *Error) {
+	fileInfo, err := attachment.FileInfo()
+	if err != nil {
+		return nil, ErrorFrom(err)
+	}
+	reader, err := attachment.Reader()
+	if err != nil {
+		return nil, ErrorFrom(err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ErrorFrom(err)
+	}
+
+	data, err := renderTemplate(attachment.Name, string(content), context)
+	if err != nil {
+		return nil, ErrorUser("attachment %s: %s", attachment.Name, err.Error())
+	}
+
+	fileInfo.Length = uint64(len(data))
+	fileInfo.Checksum = fmt.Sprintf("%x", sha256.Sum256(data))
+	return &renderedFile{
+		Info: *fileInfo,
+		Data: data,
+	}, nil
+}
+
+// renderedScript describes the script body and attachments as they will be sent to a host
+type renderedScript struct {
+	Script      []byte
+	Attachments []Attachment
+	// Files the rendered template attachments, keyed by attachment ID
+	Files map[string]renderedFile
+}
+
+// file returns the rendered file for the attachment or nil if the attachment is not a template
+func (r renderedScript) file(attachmentID string) *renderedFile {
+	file, present := r.Files[attachmentID]
+	if !present {
+		return nil
+	}
+	return &file
+}
+
+// renderScript renders the script body and template attachments of the script for this host. Errors in a template are
+// returned as user errors, anything else is a server error.
+func (host *Host) renderScript(script *Script, variables []environ.Variable) (*renderedScript, *Error) {
+	attachments, aerr := script.Attachments()
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	rendered := renderedScript{
+		Script:      []byte(script.Script),
+		Attachments: attachments,
+		Files:       map[string]renderedFile{},
+	}
+
+	hasTemplate := script.Template
+	for _, attachment := range attachments {
+		hasTemplate = hasTemplate || attachment.Template
+	}
+	if !hasTemplate {
+		return &rendered, nil
+	}
+
+	context := host.templateContext(variables)
+	if script.Template {
+		data, err := renderTemplate(script.Name, script.Script, context)
+		if err != nil {
+			return nil, ErrorUser("script: %s", err.Error())
+		}
+		rendered.Script = data
+	}
+
+	for _, attachment := range attachments {
+		if !attachment.Template {
+			continue
+		}
+		file, err := attachment.render(context)
+		if err != nil {
+			return nil, err
+		}
+		rendered.Files[attachment.ID] = *file
+	}
+
+	return &rendered, nil
+}
+
+// ScriptPreview describes the rendered script body and template attachments of a script for a host
+type ScriptPreview struct {
+	ScriptID    string
+	HostID      string
+	Script      string
+	Attachments []AttachmentPreview
+}
+
+// AttachmentPreview describes a rendered template attachment
+type AttachmentPreview struct {
+	AttachmentID string
+	Path         string
+	Length       uint64
+	Checksum     string
+	Content      string
+}
+
+// PreviewScript renders the script for this host without running it. If hideSecrets is true then the values of secret
+// environment variables are masked in the template context.
+func (host *Host) PreviewScript(script *Script, hideSecrets bool) (*ScriptPreview, *Error) {
+	variables := host.environmentVariablesForScript(script)
+	if hideSecrets {
+		masked := make([]environ.Variable, len(variables))
+		for i, variable := range variables {
+			masked[i] = variable
+			if variable.Secret {
+				masked[i].Value = "********"
+			}
+		}
+		variables = masked
+	}
+
+	rendered, err := host.renderScript(script, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := ScriptPreview{
+		ScriptID:    script.ID,
+		HostID:      host.ID,
+		Script:      string(rendered.Script),
+		Attachments: []AttachmentPreview{},
+	}
+	for _, attachment := range rendered.Attachments {
+		file := rendered.file(attachment.ID)
+		if file == nil {
+			continue
+		}
+		preview.Attachments = append(preview.Attachments, AttachmentPreview{
+			AttachmentID: attachment.ID,
+			Path:         file.Info.Path,
+			Length:       file.Info.Length,
+			Checksum:     file.Info.Checksum,
+			Content:      string(file.Data),
+		})
+	}
+	return &preview, nil
+}
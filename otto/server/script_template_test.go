@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestScriptTemplatePreview(t *testing.T) {
+	group, err := GroupStore.NewGroup(newGroupParameters{
+		Name: randomString(6),
+	})
+	if err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:     randomString(6),
+		Address:  randLocalhostIP(),
+		Port:     12444,
+		GroupIDs: []string{group.ID},
+		Environment: []environ.Variable{
+			environ.New("FOO", "bar"),
+			{Key: "PASSWORD", Value: "hunter2", Secret: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	attachmentData := "listen {{ .Host.Address }}:{{ .Host.Port }}\npassword {{ .Environment.PASSWORD }}\n"
+	attachment, err := AttachmentStore.NewAttachment(newAttachmentParameters{
+		Data:     bytes.NewReader([]byte(attachmentData)),
+		Path:     "/etc/" + randomString(6),
+		Name:     randomString(6),
+		MimeType: "text/plain",
+		Owner: RunAs{
+			UID: uint32(os.Getuid()),
+			GID: uint32(os.Getgid()),
+		},
+		Mode:     0644,
+		Size:     uint64(len(attachmentData)),
+		Template: true,
+	})
+	if err != nil {
+		t.Fatalf("Error making new attachment: %s", err.Message)
+	}
+
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:          randomString(6),
+		Executable:    "/bin/bash",
+		Script:        "echo {{ .Host.Name }} {{ index .Groups 0 }} {{ .Environment.FOO }}",
+		AttachmentIDs: []string{attachment.ID},
+		RunLevel:      ScriptRunLevelReadOnly,
+		Template:      true,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+
+	preview, err := host.PreviewScript(script, false)
+	if err != nil {
+		t.Fatalf("Error previewing script: %s", err.Message)
+	}
+	expected := fmt.Sprintf("echo %s %s bar", host.Name, group.Name)
+	if preview.Script != expected {
+		t.Errorf("Unexpected rendered script. Expected '%s' got '%s'", expected, preview.Script)
+	}
+	if len(preview.Attachments) != 1 {
+		t.Fatalf("Unexpected number of rendered attachments: %d", len(preview.Attachments))
+	}
+	rendered := preview.Attachments[0]
+	expected = fmt.Sprintf("listen %s:12444\npassword hunter2\n", host.Address)
+	if rendered.Content != expected {
+		t.Errorf("Unexpected rendered attachment. Expected '%s' got '%s'", expected, rendered.Content)
+	}
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte(expected)))
+	if rendered.Checksum != checksum {
+		t.Errorf("Checksum should be of the rendered attachment. Expected '%s' got '%s'", checksum, rendered.Checksum)
+	}
+	if rendered.Length != uint64(len(expected)) {
+		t.Errorf("Length should be of the rendered attachment. Expected %d got %d", len(expected), rendered.Length)
+	}
+
+	preview, err = host.PreviewScript(script, true)
+	if err != nil {
+		t.Fatalf("Error previewing script: %s", err.Message)
+	}
+	if !bytes.Contains([]byte(preview.Attachments[0].Content), []byte("password ********")) {
+		t.Errorf("Secret values should be hidden from the preview: '%s'", preview.Attachments[0].Content)
+	}
+}
+
+func TestScriptTemplateErrors(t *testing.T) {
+	if _, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/bash",
+		Script:     "echo {{ .Host.Name",
+		RunLevel:   ScriptRunLevelReadOnly,
+		Template:   true,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for invalid script template")
+	}
+
+	if _, err := AttachmentStore.NewAttachment(newAttachmentParameters{
+		Data:     bytes.NewReader([]byte{0x00, 0xff, 0xfe}),
+		Path:     "/" + randomString(6),
+		Name:     randomString(6),
+		MimeType: "application/octet-stream",
+		Mode:     0644,
+		Size:     3,
+		Template: true,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for binary template attachment")
+	}
+
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    12444,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/bash",
+		Script:     "echo {{ .Environment.DOES_NOT_EXIST }}",
+		RunLevel:   ScriptRunLevelReadOnly,
+		Template:   true,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	if _, err := host.PreviewScript(script, false); err == nil || err.Server {
+		t.Errorf("Missing template keys should be a user error")
+	}
+}
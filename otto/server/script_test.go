@@ -247,3 +247,29 @@ func TestRenameDuplicateScript(t *testing.T) {
 		t.Fatalf("Should return error")
 	}
 }
+
+func TestScriptTimeout(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:           randomString(6),
+		Executable:     "/bin/bash",
+		Script:         "#!/bin/bash\nsleep 120\n",
+		RunLevel:       ScriptRunLevelReadOnly,
+		TimeoutSeconds: 3600,
+	})
+	if err != nil {
+		t.Fatalf("Error making new script: %s", err.Message)
+	}
+	if info := script.ScriptInfo(); info.TimeoutSeconds != 3600 {
+		t.Errorf("Timeout not included in script info. Expected 3600 got %d", info.TimeoutSeconds)
+	}
+
+	if _, err := ScriptStore.EditScript(script, editScriptParameters{
+		Name:           script.Name,
+		Executable:     script.Executable,
+		Script:         script.Script,
+		RunLevel:       script.RunLevel,
+		TimeoutSeconds: -1,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for negative timeout")
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostLabelKeyPattern the pattern that label keys must match
+var hostLabelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-/]*$`)
+
+// hostLabelValuePattern the pattern that label values must match
+var hostLabelValuePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-/]*$`)
+
+var (
+	selectorSetPattern     = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.\-/]*)\s+(in|notin)\s*\(([^()]*)\)$`)
+	selectorComparePattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.\-/]*)\s*(==|=|!=)\s*([A-Za-z0-9_.\-/]*)$`)
+	selectorExistsPattern  = regexp.MustCompile(`^(!?)\s*([A-Za-z0-9][A-Za-z0-9_.\-/]*)$`)
+)
+
+const (
+	selectorOperatorExists    = "exists"
+	selectorOperatorNotExists = "!"
+	selectorOperatorEquals    = "="
+	selectorOperatorNotEquals = "!="
+	selectorOperatorIn        = "in"
+	selectorOperatorNotIn     = "notin"
+)
+
+// selectorRequirement describes a single requirement of a selector
+type selectorRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// hostSelector describes a parsed selector. A host matches the selector if it matches every requirement.
+type hostSelector []selectorRequirement
+
+// validateHostLabels returns an error if any of the labels are not valid
+func validateHostLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !hostLabelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key '%s'", key)
+		}
+		if !hostLabelValuePattern.MatchString(value) {
+			return fmt.Errorf("invalid value '%s' for label '%s'", value, key)
+		}
+	}
+	return nil
+}
+
+// parseSelector parses a comma separated list of requirements, such as `env=prod,os in (debian,ubuntu),!canary`
+func parseSelector(selector string) (hostSelector, error) {
+	terms := []string{}
+	depth := 0
+	start := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("unexpected '(' at %d", i)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected ')' at %d", i)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("missing ')'")
+	}
+	terms = append(terms, selector[start:])
+
+	parsed := make(hostSelector, len(terms))
+	for i, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return nil, fmt.Errorf("empty requirement")
+		}
+
+		if match := selectorSetPattern.FindStringSubmatch(term); match != nil {
+			values := []string{}
+			for _, value := range strings.Split(match[3], ",") {
+				value = strings.TrimSpace(value)
+				if !hostLabelValuePattern.MatchString(value) {
+					return nil, fmt.Errorf("invalid value '%s' in requirement '%s'", value, term)
+				}
+				values = append(values, value)
+			}
+			parsed[i] = selectorRequirement{Key: match[1], Operator: match[2], Values: values}
+			continue
+		}
+		if match := selectorComparePattern.FindStringSubmatch(term); match != nil {
+			operator := match[2]
+			if operator == "==" {
+				operator = selectorOperatorEquals
+			}
+			parsed[i] = selectorRequirement{Key: match[1], Operator: operator, Values: []string{match[3]}}
+			continue
+		}
+		if match := selectorExistsPattern.FindStringSubmatch(term); match != nil {
+			operator := selectorOperatorExists
+			if match[1] != "" {
+				operator = selectorOperatorNotExists
+			}
+			parsed[i] = selectorRequirement{Key: match[2], Operator: operator}
+			continue
+		}
+		return nil, fmt.Errorf("invalid requirement '%s'", term)
+	}
+
+	return parsed, nil
+}
+
+// Matches returns true if the labels or properties match every requirement of the selector. Labels take precedence
+// over properties with the same key.
+func (s hostSelector) Matches(labels map[string]string, properties map[string]string) bool {
+	for _, requirement := range s {
+		value, present := labels[requirement.Key]
+		if !present {
+			value, present = properties[requirement.Key]
+		}
+
+		var matched bool
+		switch requirement.Operator {
+		case selectorOperatorExists:
+			matched = present
+		case selectorOperatorNotExists:
+			matched = !present
+		case selectorOperatorEquals:
+			matched = present && value == requirement.Values[0]
+		case selectorOperatorNotEquals:
+			matched = !present || value != requirement.Values[0]
+		case selectorOperatorIn:
+			matched = present && sliceContains(value, requirement.Values)
+		case selectorOperatorNotIn:
+			matched = !present || !sliceContains(value, requirement.Values)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// hostProperties returns the properties from the last heartbeat of each host, keyed by host address
+func hostProperties() map[string]map[string]string {
+	properties := map[string]map[string]string{}
+	for _, heartbeat := range heartbeatStore.AllHeartbeats() {
+		properties[heartbeat.Address] = heartbeat.Properties
+	}
+	return properties
+}
+
+// selectHosts returns all hosts that match the selector
+func selectHosts(selector hostSelector) []Host {
+	properties := hostProperties()
+	hosts := []Host{}
+	for _, host := range HostCache.All() {
+		if selector.Matches(host.Labels, properties[host.Address]) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
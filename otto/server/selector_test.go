@@ -0,0 +1,146 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ecnepsnai/otto/shared/otto"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{
+		"env": "prod",
+		"os":  "debian",
+	}
+	properties := map[string]string{
+		"distribution_name": "debian",
+		"os":                "linux",
+	}
+
+	check := func(selector string, expected bool) {
+		parsed, err := parseSelector(selector)
+		if err != nil {
+			t.Errorf("Unexpected error parsing selector '%s': %s", selector, err.Error())
+			return
+		}
+		if parsed.Matches(labels, properties) != expected {
+			t.Errorf("Unexpected result for selector '%s'. Expected %v", selector, expected)
+		}
+	}
+
+	check("env=prod", true)
+	check("env==prod", true)
+	check("env=dev", false)
+	check("env!=dev", true)
+	check("missing!=dev", true)
+	check("env=prod,os in (debian,ubuntu),!canary", true)
+	check("env=prod, os in (centos, rhel)", false)
+	check("os notin (centos,rhel)", true)
+	check("env", true)
+	check("canary", false)
+	check("!env", false)
+	check("distribution_name=debian", true)
+	check("os=linux", false)
+
+	for _, selector := range []string{"", "env=prod,", "env in (prod", "env in prod)", "env=(prod)", "=prod", "env in ((prod))", "env ~ prod"} {
+		if _, err := parseSelector(selector); err == nil {
+			t.Errorf("No error seen for invalid selector '%s'", selector)
+		}
+	}
+}
+
+func TestSelectorScope(t *testing.T) {
+	label := randomString(6)
+	newHost := func(labels map[string]string) *Host {
+		host, err := HostStore.NewHost(newHostParameters{
+			Name:    randomString(6),
+			Address: randLocalhostIP(),
+			Port:    1,
+			Labels:  labels,
+		})
+		if err != nil {
+			t.Fatalf("Error making host: %s", err.Message)
+		}
+		return host
+	}
+	prod := newHost(map[string]string{label: "prod"})
+	canary := newHost(map[string]string{label: "prod", "canary": "true"})
+	dev := newHost(nil)
+
+	if _, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+		Labels:  map[string]string{"bad label": "value"},
+	}); err == nil {
+		t.Errorf("No error seen for invalid label")
+	}
+
+	// At least one group must always exist
+	if _, err := GroupStore.NewGroup(newGroupParameters{Name: randomString(6)}); err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+	group, err := GroupStore.NewGroup(newGroupParameters{
+		Name:     randomString(6),
+		Selector: label + "=prod",
+	})
+	if err != nil {
+		t.Fatalf("Error making group: %s", err.Message)
+	}
+	members := GroupCache.HostIDs(group.ID)
+	if len(members) != 2 || !sliceContains(prod.ID, members) || !sliceContains(canary.ID, members) {
+		t.Errorf("Unexpected group members: %v", members)
+	}
+	if !sliceContains(group.ID, prod.AllGroupIDs()) {
+		t.Errorf("Host that matches the group selector should be in the group")
+	}
+
+	// Group membership follows the properties from heartbeats
+	heartbeatStore.RegisterHeartbeatReply(dev, otto.MessageHeartbeatResponse{
+		Properties: map[string]string{label: "prod"},
+	}, otto.Capabilities{})
+	if !sliceContains(dev.ID, GroupCache.HostIDs(group.ID)) {
+		t.Errorf("Host with matching property should be in the group")
+	}
+	heartbeatStore.RegisterHeartbeatReply(dev, otto.MessageHeartbeatResponse{
+		Properties: map[string]string{},
+	}, otto.Capabilities{})
+	if sliceContains(dev.ID, GroupCache.HostIDs(group.ID)) {
+		t.Errorf("Host should leave the group when its properties no longer match")
+	}
+
+	scope := ScheduleScope{
+		GroupIDs:        []string{group.ID},
+		HostIDs:         []string{dev.ID},
+		ExcludeSelector: "canary",
+	}
+	hosts, rerr := resolveHosts(scope, "")
+	if rerr != nil {
+		t.Fatalf("Error resolving hosts: %s", rerr.Message)
+	}
+	hostIDs := []string{}
+	for _, host := range hosts {
+		hostIDs = append(hostIDs, host.ID)
+	}
+	if len(hostIDs) != 2 || !sliceContains(prod.ID, hostIDs) || !sliceContains(dev.ID, hostIDs) {
+		t.Errorf("Unexpected hosts in scope: %v", hostIDs)
+	}
+
+	scope = ScheduleScope{
+		Selector:       label + " in (prod,dev)",
+		ExcludeHostIDs: []string{dev.ID},
+	}
+	if !scope.Contains(prod) || !scope.Contains(canary) || scope.Contains(dev) {
+		t.Errorf("Unexpected result for scope with selector")
+	}
+	if err := scope.validate(); err != nil {
+		t.Errorf("Unexpected error validating scope: %s", err.Error())
+	}
+	scope.Selector = "env in (prod"
+	if err := scope.validate(); err == nil {
+		t.Errorf("No error seen for scope with invalid selector")
+	}
+
+	if err := GroupStore.DeleteGroup(group); err != nil {
+		t.Errorf("Group with only selected members should be able to be deleted: %s", err.Message)
+	}
+}
@@ -17,10 +17,13 @@ type UserPermissions struct {
 	CanModifyGroups       bool
 	CanModifyScripts      bool
 	CanModifySchedules    bool
+	CanRunCommands        bool
+	CanDownloadFiles      bool
 	CanAccessAuditLog     bool
 	CanModifyUsers        bool
 	CanModifyAutoregister bool
 	CanModifySystem       bool
+	CanOverrideWindows    bool
 }
 
 func (p UserPermissions) EqualTo(o UserPermissions) bool {
@@ -34,10 +37,13 @@ func UserPermissionsMax() UserPermissions {
 		CanModifyGroups:       true,
 		CanModifyScripts:      true,
 		CanModifySchedules:    true,
+		CanRunCommands:        true,
+		CanDownloadFiles:      true,
 		CanAccessAuditLog:     true,
 		CanModifyUsers:        true,
 		CanModifyAutoregister: true,
 		CanModifySystem:       true,
+		CanOverrideWindows:    true,
 	}
 }
 
@@ -48,9 +54,12 @@ func UserPermissionsMin() UserPermissions {
 		CanModifyGroups:       false,
 		CanModifyScripts:      false,
 		CanModifySchedules:    false,
+		CanRunCommands:        false,
+		CanDownloadFiles:      false,
 		CanAccessAuditLog:     false,
 		CanModifyUsers:        false,
 		CanModifyAutoregister: false,
 		CanModifySystem:       false,
+		CanOverrideWindows:    false,
 	}
 }
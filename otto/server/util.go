@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
 	"github.com/ecnepsnai/secutil"
 	nanoid "github.com/matoous/go-nanoid"
@@ -17,7 +16,7 @@ func generateSessionSecret() string {
 }
 
 // sliceContains does slice h contain n?
-func sliceContains[T string](n T, h []T) bool {
+func sliceContains[T comparable](n T, h []T) bool {
 	for _, s := range h {
 		if s == n {
 			return true
@@ -26,16 +25,6 @@ func sliceContains[T string](n T, h []T) bool {
 	return false
 }
 
-// sliceContainsFold does this slice of strings contain n? (cast insensitive)
-func sliceContainsFold(n string, h []string) bool {
-	for _, s := range h {
-		if strings.EqualFold(s, n) {
-			return true
-		}
-	}
-	return false
-}
-
 // filterSlice remove any occurrence of `r` from `s`, returning a new slice
 func filterSlice[T string](r T, s []T) []T {
 	sl := []T{}
@@ -0,0 +1,514 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+// WorkflowEnd the name used in a step branch to end the workflow
+const WorkflowEnd = "end"
+
+// Workflow describes an ordered list of steps that each run a script
+type Workflow struct {
+	ID   string `ds:"primary"`
+	Name string `ds:"unique" min:"1" max:"140"`
+	// Scope the default hosts or groups for steps that do not specify their own scope. Schedules that run the workflow
+	// replace this with the scope of the schedule.
+	Scope ScheduleScope
+	Steps []WorkflowStep
+}
+
+// WorkflowStep describes a single step of a workflow
+type WorkflowStep struct {
+	Name     string
+	ScriptID string
+	// Scope the hosts or groups the step runs on, if empty the scope of the workflow is used
+	Scope      ScheduleScope
+	Parameters map[string]string
+	Execution  ExecutionOptions
+	Rollout    RolloutStrategy
+	Retry      RetryPolicy
+	// OnSuccess the name of the step to run if this step succeeded on every host. If empty the next step is run, or
+	// WorkflowEnd to end the workflow.
+	OnSuccess string
+	// OnFailure the name of the step to run if this step failed on any host. If empty the workflow is stopped.
+	OnFailure string
+	// Output what output from this step is passed to the next step as environment variables
+	Output string
+	// Outputs the names of the declared outputs of this step, used when Output is WorkflowOutputDeclared
+	Outputs []string
+}
+
+// Validate returns an error if the workflow is not valid
+func (w Workflow) Validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow must have at least one step")
+	}
+	if err := w.Scope.validate(); err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i, step := range w.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d must have a name", i+1)
+		}
+		if step.Name == WorkflowEnd {
+			return fmt.Errorf("step name '%s' is reserved", WorkflowEnd)
+		}
+		if _, present := index[step.Name]; present {
+			return fmt.Errorf("duplicate step '%s'", step.Name)
+		}
+		index[step.Name] = i
+	}
+
+	for i, step := range w.Steps {
+		script := ScriptCache.ByID(step.ScriptID)
+		if script == nil {
+			return fmt.Errorf("step '%s': unknown script ID '%s'", step.Name, step.ScriptID)
+		}
+		if _, err := script.parameterVariables(step.Parameters); err != nil {
+			return fmt.Errorf("step '%s': %s", step.Name, err.Error())
+		}
+		if err := step.Scope.validate(); err != nil {
+			return fmt.Errorf("step '%s': %s", step.Name, err.Error())
+		}
+		if err := step.Execution.Validate(); err != nil {
+			return fmt.Errorf("step '%s': %s", step.Name, err.Error())
+		}
+		if err := step.Rollout.Validate(); err != nil {
+			return fmt.Errorf("step '%s': %s", step.Name, err.Error())
+		}
+		if err := step.Retry.Validate(); err != nil {
+			return fmt.Errorf("step '%s': %s", step.Name, err.Error())
+		}
+
+		// Branches may only go forward so that a workflow always ends
+		if step.OnSuccess != "" && step.OnSuccess != WorkflowEnd {
+			if next, present := index[step.OnSuccess]; !present || next <= i {
+				return fmt.Errorf("step '%s': on success must be a later step", step.Name)
+			}
+		}
+		if step.OnFailure != "" {
+			if next, present := index[step.OnFailure]; !present || next <= i {
+				return fmt.Errorf("step '%s': on failure must be a later step", step.Name)
+			}
+		}
+
+		if step.Output != "" && !IsWorkflowOutput(step.Output) {
+			return fmt.Errorf("step '%s': invalid output '%s'", step.Name, step.Output)
+		}
+		for _, name := range step.Outputs {
+			if !scriptParameterNamePattern.MatchString(name) || strings.HasPrefix(name, "OTTO_") {
+				return fmt.Errorf("step '%s': invalid output name '%s'", step.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate returns an error if the scope refers to unknown hosts or groups
+func (s ScheduleScope) validate() error {
+	if len(s.GroupIDs) > 0 && len(s.HostIDs) > 0 {
+		return fmt.Errorf("cannot specify both group IDs and host IDs")
+	}
+	return s.validateMembers()
+}
+
+// validateMembers returns an error if the scope refers to unknown hosts or groups, or has an invalid selector
+func (s ScheduleScope) validateMembers() error {
+	for _, groupID := range s.GroupIDs {
+		if group := GroupCache.ByID(groupID); group == nil {
+			return fmt.Errorf("unknown group ID '%s'", groupID)
+		}
+	}
+	for _, hostID := range s.HostIDs {
+		if host := HostCache.ByID(hostID); host == nil {
+			return fmt.Errorf("unknown host ID '%s'", hostID)
+		}
+	}
+	for _, groupID := range s.ExcludeGroupIDs {
+		if group := GroupCache.ByID(groupID); group == nil {
+			return fmt.Errorf("unknown excluded group ID '%s'", groupID)
+		}
+	}
+	for _, hostID := range s.ExcludeHostIDs {
+		if host := HostCache.ByID(hostID); host == nil {
+			return fmt.Errorf("unknown excluded host ID '%s'", hostID)
+		}
+	}
+	if s.Selector != "" {
+		if _, err := parseSelector(s.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %s", err.Error())
+		}
+	}
+	if s.ExcludeSelector != "" {
+		if _, err := parseSelector(s.ExcludeSelector); err != nil {
+			return fmt.Errorf("invalid exclude selector: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true if the scope doesn't include any hosts, groups, or selector. Exclusions are ignored.
+func (s ScheduleScope) isEmpty() bool {
+	return len(s.GroupIDs) == 0 && len(s.HostIDs) == 0 && s.Selector == ""
+}
+
+// RunLevel returns the highest run level of the scripts in this workflow
+func (w Workflow) RunLevel() int {
+	level := ScriptRunLevelNone
+	for _, step := range w.Steps {
+		if script := ScriptCache.ByID(step.ScriptID); script != nil && script.RunLevel > level {
+			level = script.RunLevel
+		}
+	}
+	return level
+}
+
+// hideSecrets masks the values of secret parameters of every step
+func (w *Workflow) hideSecrets() {
+	steps := make([]WorkflowStep, len(w.Steps))
+	for i, step := range w.Steps {
+		step.Parameters = ScriptCache.ByID(step.ScriptID).hideParameterSecrets(step.Parameters)
+		steps[i] = step
+	}
+	w.Steps = steps
+}
+
+// restoreSecrets replaces any masked value of a secret parameter with the value from the step with the same name and
+// script in the existing workflow. Masked values for new steps are removed.
+func (w *Workflow) restoreSecrets(existing *Workflow) {
+	stored := map[string]WorkflowStep{}
+	if existing != nil {
+		for _, step := range existing.Steps {
+			stored[step.Name] = step
+		}
+	}
+
+	for i, step := range w.Steps {
+		script := ScriptCache.ByID(step.ScriptID)
+		if script == nil {
+			continue
+		}
+		var values map[string]string
+		if previous, present := stored[step.Name]; present && previous.ScriptID == step.ScriptID {
+			values = previous.Parameters
+		}
+		w.Steps[i].Parameters = script.restoreParameterSecrets(step.Parameters, values)
+	}
+}
+
+// canView returns true if the user can view the workflow. Users can view workflows that only run scripts within their
+// run level, or any workflow if they can modify scripts.
+func (w Workflow) canView(user *User) bool {
+	return user.Permissions.CanModifyScripts || user.Permissions.ScriptRunLevel >= w.RunLevel()
+}
+
+// withVariables returns a copy of the script that will be run with the given variables in addition to its parameter
+// values
+func (s Script) withVariables(variables []environ.Variable) *Script {
+	s.parameterValues = environ.Merge(s.parameterValues, variables)
+	return &s
+}
+
+// workflowOutputs returns the values of the declared outputs from the script output. Outputs are lines of the form
+// NAME=value, if an output is written more than once the last value is used.
+func workflowOutputs(stdout string, names []string) []environ.Variable {
+	values := map[string]string{}
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimRight(line, "\r")
+		for _, name := range names {
+			if strings.HasPrefix(line, name+"=") {
+				values[name] = strings.TrimPrefix(line, name+"=")
+			}
+		}
+	}
+
+	variables := []environ.Variable{}
+	for _, name := range names {
+		if value, present := values[name]; present {
+			variables = append(variables, environ.New(name, value))
+		}
+	}
+	return variables
+}
+
+// workflowRun describes a single run of a workflow
+type workflowRun struct {
+	Workflow    Workflow
+	Scope       ScheduleScope
+	Schedule    *Schedule
+	TriggeredBy string
+	Report      *WorkflowReport
+}
+
+func newWorkflowRun(workflow Workflow, scope ScheduleScope, schedule *Schedule, triggeredBy string) *workflowRun {
+	if scope.isEmpty() {
+		scope = workflow.Scope
+	}
+	report := &WorkflowReport{
+		ID:          newID(),
+		WorkflowID:  workflow.ID,
+		TriggeredBy: triggeredBy,
+		Status:      JobStatusRunning,
+		Time: ScheduleReportTime{
+			Start: time.Now(),
+		},
+		Steps: []WorkflowStepReport{},
+	}
+	if schedule != nil {
+		report.ScheduleID = schedule.ID
+	}
+
+	return &workflowRun{
+		Workflow:    workflow,
+		Scope:       scope,
+		Schedule:    schedule,
+		TriggeredBy: triggeredBy,
+		Report:      report,
+	}
+}
+
+// Start will save the report for this run and run the workflow in the background
+func (r *workflowRun) Start() *Error {
+	if err := WorkflowReportStore.SaveReport(*r.Report); err != nil {
+		return err
+	}
+	go r.Run()
+	return nil
+}
+
+// Run will run each step of the workflow, following the branches of each step, and save the report. Blocks until the
+// workflow has finished.
+func (r *workflowRun) Run() {
+	start := r.Report.Time.Start
+	log.PInfo("Running workflow", map[string]interface{}{
+		"workflow_id":  r.Workflow.ID,
+		"report_id":    r.Report.ID,
+		"triggered_by": r.TriggeredBy,
+	})
+
+	index := map[string]int{}
+	for i, step := range r.Workflow.Steps {
+		index[step.Name] = i
+	}
+
+	// The variables passed from the previous step, keyed by host ID
+	passed := map[string][]environ.Variable{}
+	anyFailed := false
+	stopped := false
+	i := 0
+	for i < len(r.Workflow.Steps) {
+		step := r.Workflow.Steps[i]
+		stepReport, outputs := r.runStep(step, passed)
+		r.Report.Steps = append(r.Report.Steps, stepReport)
+		passed = outputs
+
+		next := i + 1
+		if stepReport.Result == ScheduleResultSuccess {
+			if step.OnSuccess == WorkflowEnd {
+				break
+			} else if step.OnSuccess != "" {
+				next = index[step.OnSuccess]
+			}
+		} else {
+			anyFailed = true
+			if step.OnFailure == "" {
+				stopped = true
+				break
+			}
+			next = index[step.OnFailure]
+		}
+		i = next
+	}
+
+	if stopped {
+		r.Report.Result = ScheduleResultFail
+	} else if anyFailed {
+		r.Report.Result = ScheduleResultPartialSuccess
+	} else {
+		r.Report.Result = ScheduleResultSuccess
+	}
+	r.Report.Status = JobStatusFinished
+	r.Report.Time = ScheduleReportTime{
+		Start:          start,
+		Finished:       time.Now(),
+		ElapsedSeconds: time.Since(start).Seconds(),
+	}
+	WorkflowReportStore.SaveReport(*r.Report)
+	EventStore.WorkflowRun(r.Workflow, *r.Report, r.TriggeredBy)
+
+	log.PInfo("Finished running workflow", map[string]interface{}{
+		"workflow_id": r.Workflow.ID,
+		"report_id":   r.Report.ID,
+		"result":      r.Report.Result,
+		"elapsed":     time.Since(start).String(),
+	})
+}
+
+// runStep will run the step on all of its hosts and return the report of the step and the variables to pass to the
+// next step for each host
+func (r *workflowRun) runStep(step WorkflowStep, passed map[string][]environ.Variable) (WorkflowStepReport, map[string][]environ.Variable) {
+	start := time.Now()
+	report := WorkflowStepReport{
+		Step:         step.Name,
+		ScriptID:     step.ScriptID,
+		HostIDs:      []string{},
+		HostResult:   map[string]int{},
+		HostBatch:    map[string]int{},
+		HostStatus:   map[string]string{},
+		HostAttempts: map[string][]HostAttempt{},
+		Result:       ScheduleResultFail,
+	}
+	finish := func() (WorkflowStepReport, map[string][]environ.Variable) {
+		report.Time = ScheduleReportTime{
+			Start:          start,
+			Finished:       time.Now(),
+			ElapsedSeconds: time.Since(start).Seconds(),
+		}
+		return report, map[string][]environ.Variable{}
+	}
+
+	script := ScriptCache.ByID(step.ScriptID)
+	if script == nil {
+		report.Error = fmt.Sprintf("unknown script ID '%s'", step.ScriptID)
+		return finish()
+	}
+	script, err := script.WithParameters(step.Parameters)
+	if err != nil {
+		// The script parameters may have changed since the workflow was saved
+		report.Error = err.Error()
+		return finish()
+	}
+	report.ScriptRevision = script.Revision
+	if r.Schedule != nil {
+		script.overlapPolicy = r.Schedule.overlapPolicy()
+	}
+
+	scope := step.Scope
+	if scope.isEmpty() {
+		scope = r.Scope
+	}
+	hosts, rerr := resolveHosts(scope, "")
+	if rerr != nil {
+		report.Error = rerr.Message
+		return finish()
+	}
+	for _, host := range hosts {
+		report.HostIDs = append(report.HostIDs, host.ID)
+	}
+	hosts, report.HostSkipReason = skipBlockedHosts(hosts, start)
+	for hostID := range report.HostSkipReason {
+		report.HostStatus[hostID] = ScriptStatusSkipped
+	}
+
+	// Variables from a previous step that ran on a single host are passed to every host
+	var shared []environ.Variable
+	if len(passed) == 1 {
+		for _, variables := range passed {
+			shared = variables
+		}
+	}
+
+	// Each host only writes to its own index, results are collected in order once all hosts have finished
+	hostResults := make([]int, len(hosts))
+	hostStatus := make([]string, len(hosts))
+	hostFailed := make([]bool, len(hosts))
+	hostOutputs := make([][]environ.Variable, len(hosts))
+	hostAttempts := make([][]HostAttempt, len(hosts))
+	hostLocked := make([]string, len(hosts))
+	rollout := executeRollout(hosts, step.Rollout, step.Execution, func(i int, host *Host) bool {
+		variables, present := passed[host.ID]
+		if !present {
+			variables = shared
+		}
+		hostScript := script.withVariables(variables)
+
+		result, attempts, err := host.runScriptWithRetry(hostScript, step.Retry, r.Schedule, r.TriggeredBy)
+		hostAttempts[i] = attempts
+		if isLockedError(err) {
+			hostLocked[i] = err.Error()
+			return true
+		}
+		if err != nil {
+			log.PError("Error running workflow step", map[string]interface{}{
+				"workflow_id": r.Workflow.ID,
+				"step":        step.Name,
+				"host_id":     host.ID,
+				"error":       err.Error(),
+			})
+			hostFailed[i] = true
+			hostResults[i] = 1
+			hostStatus[i] = ScriptStatusFailed
+			return false
+		}
+		hostResults[i] = result.Result.Code
+		hostStatus[i] = result.Status
+
+		switch step.Output {
+		case WorkflowOutputStdout:
+			hostOutputs[i] = []environ.Variable{environ.New("OTTO_WORKFLOW_STDOUT", result.Output.Stdout)}
+		case WorkflowOutputDeclared:
+			hostOutputs[i] = workflowOutputs(result.Output.Stdout, step.Outputs)
+		}
+
+		if !result.Succeeded() {
+			hostFailed[i] = true
+		}
+		return !hostFailed[i]
+	}, func(i int, host *Host) {
+		if err := host.CancelScript(script.Name); err != nil {
+			log.PError("Error cancelling workflow step", map[string]interface{}{
+				"workflow_id": r.Workflow.ID,
+				"step":        step.Name,
+				"host_id":     host.ID,
+				"error":       err.Error(),
+			})
+		}
+	})
+
+	outputs := map[string][]environ.Variable{}
+	success := 0
+	fail := 0
+	report.StopReason = rollout.StopReason
+	for i, host := range hosts {
+		if rollout.HostBatch[i] == 0 {
+			// Host was not run because the rollout was stopped
+			fail++
+			continue
+		}
+		if hostLocked[i] != "" {
+			report.HostSkipReason[host.ID] = hostLocked[i]
+			report.HostStatus[host.ID] = ScriptStatusSkipped
+			continue
+		}
+		report.HostResult[host.ID] = hostResults[i]
+		report.HostStatus[host.ID] = hostStatus[i]
+		report.HostAttempts[host.ID] = hostAttempts[i]
+		report.HostBatch[host.ID] = rollout.HostBatch[i]
+		if hostFailed[i] {
+			fail++
+		} else {
+			success++
+		}
+		if hostOutputs[i] != nil {
+			outputs[host.ID] = hostOutputs[i]
+		}
+	}
+
+	// A step where every host was skipped is not a success, so the workflow follows the failure branch
+	if success == 0 && fail == 0 && len(report.HostSkipReason) > 0 {
+		report.Result = ScheduleResultSkipped
+	} else if fail == 0 {
+		report.Result = ScheduleResultSuccess
+	} else if success > 0 {
+		report.Result = ScheduleResultPartialSuccess
+	}
+	stepReport, _ := finish()
+	return stepReport, outputs
+}
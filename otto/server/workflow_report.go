@@ -0,0 +1,134 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/ecnepsnai/ds"
+)
+
+// WorkflowReport describes a single run of a workflow
+type WorkflowReport struct {
+	ID         string `ds:"primary"`
+	WorkflowID string `ds:"index"`
+	// ScheduleID the ID of the schedule that ran the workflow, if it was run by a schedule
+	ScheduleID  string
+	TriggeredBy string
+	// Status either running or finished
+	Status string
+	Time   ScheduleReportTime
+	// Result the result of the workflow, only present once it has finished
+	Result int
+	// Steps the report of each step that was run, in the order they were run
+	Steps []WorkflowStepReport
+}
+
+// WorkflowStepReport describes the result of a single step of a workflow
+type WorkflowStepReport struct {
+	Step     string
+	ScriptID string
+	// ScriptRevision the revision of the script that was run
+	ScriptRevision int
+	HostIDs        []string
+	Time           ScheduleReportTime
+	Result         int
+	HostResult     map[string]int
+	// HostStatus the status of the result for each host that was run
+	HostStatus map[string]string
+	// HostSkipReason why each host that was skipped because of a maintenance window or a run already in progress was
+	// not run
+	HostSkipReason map[string]string
+	// HostAttempts every attempt at running the script on each host that was run, including retries
+	HostAttempts map[string][]HostAttempt
+	// HostBatch the rollout batch that each host ran in, starting at 1
+	HostBatch map[string]int
+	// StopReason why the rollout was stopped before all hosts were run, if it was
+	StopReason string
+	// Error why the step could not be run, if it was not
+	Error string
+}
+
+// SaveReport will add or update the workflow report
+func (s *workflowreportStoreObject) SaveReport(report WorkflowReport) (rerr *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		save := tx.Update
+		if existing, _ := tx.Get(report.ID); existing == nil {
+			save = tx.Add
+		}
+		if err := save(report); err != nil {
+			log.PError("Error saving workflow report", map[string]interface{}{
+				"report_id":   report.ID,
+				"workflow_id": report.WorkflowID,
+				"error":       err.Error(),
+			})
+			rerr = ErrorFrom(err)
+		}
+		return nil
+	})
+	return
+}
+
+// ReportWithID returns the workflow report with the given ID or nil
+func (s *workflowreportStoreObject) ReportWithID(id string) (report *WorkflowReport) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		object, err := tx.Get(id)
+		if err != nil {
+			log.Error("Error getting workflow report: id='%s' error='%s'", id, err.Error())
+			return nil
+		}
+		if object == nil {
+			return nil
+		}
+		r, ok := object.(WorkflowReport)
+		if !ok {
+			log.Error("Object is not of type 'WorkflowReport'")
+			return nil
+		}
+		report = &r
+		return nil
+	})
+	return
+}
+
+// GetReportsForWorkflow returns all reports for the workflow, most recent first
+func (s *workflowreportStoreObject) GetReportsForWorkflow(workflowID string) (reports []WorkflowReport) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		reports = s.getReportsForWorkflow(tx, workflowID)
+		return nil
+	})
+	return
+}
+
+func (s *workflowreportStoreObject) getReportsForWorkflow(tx ds.IReadTransaction, workflowID string) []WorkflowReport {
+	objs, err := tx.GetIndex("WorkflowID", workflowID, nil)
+	if err != nil {
+		log.Error("Error getting workflow reports: workflow_id='%s' error='%s'", workflowID, err.Error())
+		return []WorkflowReport{}
+	}
+
+	reports := make([]WorkflowReport, 0, len(objs))
+	for _, obj := range objs {
+		report, k := obj.(WorkflowReport)
+		if !k {
+			log.Error("Object is not of type 'WorkflowReport'")
+			return []WorkflowReport{}
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Time.Start.UnixNano() > reports[j].Time.Start.UnixNano()
+	})
+	return reports
+}
+
+// DeleteReportsForWorkflow will delete all reports for the workflow
+func (s *workflowreportStoreObject) DeleteReportsForWorkflow(workflowID string) (rerr *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		if err := tx.DeleteAllIndex("WorkflowID", workflowID); err != nil {
+			log.Error("Error deleting workflow reports: workflow_id='%s' error='%s'", workflowID, err.Error())
+			rerr = ErrorFrom(err)
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"github.com/ecnepsnai/ds"
+	"github.com/ecnepsnai/limits"
+)
+
+func (s *workflowStoreObject) AllWorkflows() (workflows []Workflow) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		workflows = s.allWorkflows(tx)
+		return nil
+	})
+	return
+}
+
+func (s *workflowStoreObject) allWorkflows(tx ds.IReadTransaction) []Workflow {
+	objects, err := tx.GetAll(&ds.GetOptions{Sorted: true, Ascending: true})
+	if err != nil {
+		log.Error("Error listing all workflows: error='%s'", err.Error())
+		return []Workflow{}
+	}
+	if len(objects) == 0 {
+		return []Workflow{}
+	}
+
+	workflows := make([]Workflow, len(objects))
+	for i, obj := range objects {
+		workflow, k := obj.(Workflow)
+		if !k {
+			log.Fatal("Error listing all workflows: error='%s'", "invalid type")
+		}
+		workflows[i] = workflow
+	}
+
+	return workflows
+}
+
+func (s *workflowStoreObject) WorkflowWithID(id string) (workflow *Workflow) {
+	s.Table.StartRead(func(tx ds.IReadTransaction) error {
+		workflow = s.workflowWithID(tx, id)
+		return nil
+	})
+	return
+}
+
+func (s *workflowStoreObject) workflowWithID(tx ds.IReadTransaction, id string) *Workflow {
+	object, err := tx.Get(id)
+	if err != nil {
+		log.Error("Error getting workflow: id='%s' error='%s'", id, err.Error())
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	workflow, ok := object.(Workflow)
+	if !ok {
+		log.Fatal("Error getting workflow: id='%s' error='%s'", id, "invalid type")
+	}
+	return &workflow
+}
+
+func (s *workflowStoreObject) workflowWithName(tx ds.IReadTransaction, name string) *Workflow {
+	object, err := tx.GetUnique("Name", name)
+	if err != nil {
+		log.Error("Error getting workflow: name='%s' error='%s'", name, err.Error())
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	workflow, ok := object.(Workflow)
+	if !ok {
+		log.Fatal("Error getting workflow: name='%s' error='%s'", name, "invalid type")
+	}
+	return &workflow
+}
+
+// AllWorkflowsForScript returns all workflows with a step that runs the given script
+func (s *workflowStoreObject) AllWorkflowsForScript(scriptID string) []Workflow {
+	matched := []Workflow{}
+	for _, workflow := range s.AllWorkflows() {
+		for _, step := range workflow.Steps {
+			if step.ScriptID == scriptID {
+				matched = append(matched, workflow)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// AllWorkflowsForScope returns all workflows where the workflow or any step is scoped to the given host or group
+func (s *workflowStoreObject) AllWorkflowsForScope(id string) []Workflow {
+	inScope := func(scope ScheduleScope) bool {
+		return scope.references(id)
+	}
+
+	matched := []Workflow{}
+	for _, workflow := range s.AllWorkflows() {
+		found := inScope(workflow.Scope)
+		for _, step := range workflow.Steps {
+			found = found || inScope(step.Scope)
+		}
+		if found {
+			matched = append(matched, workflow)
+		}
+	}
+	return matched
+}
+
+type newWorkflowParameters struct {
+	Name  string
+	Scope ScheduleScope
+	Steps []WorkflowStep
+}
+
+func (s *workflowStoreObject) NewWorkflow(params newWorkflowParameters) (workflow *Workflow, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		workflow, err = s.newWorkflow(tx, params)
+		return nil
+	})
+	return
+}
+
+func (s *workflowStoreObject) newWorkflow(tx ds.IReadWriteTransaction, params newWorkflowParameters) (*Workflow, *Error) {
+	if s.workflowWithName(tx, params.Name) != nil {
+		return nil, ErrorUser("Workflow with name '%s' already exists", params.Name)
+	}
+
+	workflow := Workflow{
+		ID:    newID(),
+		Name:  params.Name,
+		Scope: params.Scope,
+		Steps: params.Steps,
+	}
+	workflow.restoreSecrets(nil)
+	if err := limits.Check(workflow); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if err := tx.Add(workflow); err != nil {
+		log.Error("Error adding new workflow '%s': %s", params.Name, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.Info("Added new workflow '%s'", params.Name)
+	return &workflow, nil
+}
+
+type editWorkflowParameters struct {
+	Name  string
+	Scope ScheduleScope
+	Steps []WorkflowStep
+}
+
+func (s *workflowStoreObject) EditWorkflow(workflow *Workflow, params editWorkflowParameters) (newWorkflow *Workflow, err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		newWorkflow, err = s.editWorkflow(tx, workflow, params)
+		return nil
+	})
+	return
+}
+
+func (s *workflowStoreObject) editWorkflow(tx ds.IReadWriteTransaction, workflow *Workflow, params editWorkflowParameters) (*Workflow, *Error) {
+	if existing := s.workflowWithName(tx, params.Name); existing != nil && existing.ID != workflow.ID {
+		return nil, ErrorUser("Workflow with name '%s' already exists", params.Name)
+	}
+
+	existing := *workflow
+	workflow.Name = params.Name
+	workflow.Scope = params.Scope
+	workflow.Steps = params.Steps
+	workflow.restoreSecrets(&existing)
+	if err := limits.Check(workflow); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, ErrorUser(err.Error())
+	}
+
+	if err := tx.Update(*workflow); err != nil {
+		log.Error("Error updating workflow '%s': %s", workflow.ID, err.Error())
+		return nil, ErrorFrom(err)
+	}
+
+	log.Info("Updated workflow '%s'", workflow.ID)
+	return workflow, nil
+}
+
+func (s *workflowStoreObject) DeleteWorkflow(workflow *Workflow) (err *Error) {
+	s.Table.StartWrite(func(tx ds.IReadWriteTransaction) error {
+		err = s.deleteWorkflow(tx, workflow)
+		return nil
+	})
+	return
+}
+
+func (s *workflowStoreObject) deleteWorkflow(tx ds.IReadWriteTransaction, workflow *Workflow) *Error {
+	for _, schedule := range ScheduleCache.All() {
+		if schedule.WorkflowID == workflow.ID {
+			return ErrorUser("Workflow is used by schedule %s", schedule.Name)
+		}
+	}
+
+	if err := tx.Delete(*workflow); err != nil {
+		log.Error("Error deleting workflow '%s': %s", workflow.ID, err.Error())
+		return ErrorFrom(err)
+	}
+
+	if err := WorkflowReportStore.DeleteReportsForWorkflow(workflow.ID); err != nil {
+		return err
+	}
+
+	log.Info("Deleted workflow '%s'", workflow.ID)
+	return nil
+}
+
+// RunWorkflow will start running the workflow in the background and return the report of the run. If the scope is not
+// empty it replaces the scope of the workflow.
+func (s *workflowStoreObject) RunWorkflow(workflow Workflow, scope ScheduleScope, schedule *Schedule, triggeredBy string) (*WorkflowReport, *Error) {
+	run := newWorkflowRun(workflow, scope, schedule, triggeredBy)
+	report := *run.Report
+	if err := run.Start(); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ecnepsnai/otto/server/environ"
+)
+
+func TestWorkflowValidate(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+		Parameters: []ScriptParameter{
+			{Name: "SERVICE", Type: ScriptParameterTypeString, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	parameters := map[string]string{"SERVICE": "nginx"}
+
+	valid := Workflow{
+		Name: randomString(6),
+		Steps: []WorkflowStep{
+			{Name: "first", ScriptID: script.ID, Parameters: parameters, OnFailure: "third", Output: WorkflowOutputDeclared, Outputs: []string{"VERSION"}},
+			{Name: "second", ScriptID: script.ID, Parameters: parameters, OnSuccess: WorkflowEnd},
+			{Name: "third", ScriptID: script.ID, Parameters: parameters},
+		},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Unexpected error validating workflow: %s", err.Error())
+	}
+
+	invalid := [][]WorkflowStep{
+		{},
+		{{Name: "", ScriptID: script.ID, Parameters: parameters}},
+		{{Name: WorkflowEnd, ScriptID: script.ID, Parameters: parameters}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters}, {Name: "a", ScriptID: script.ID, Parameters: parameters}},
+		{{Name: "a", ScriptID: newID(), Parameters: parameters}},
+		{{Name: "a", ScriptID: script.ID}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters, OnSuccess: "b"}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters}, {Name: "b", ScriptID: script.ID, Parameters: parameters, OnFailure: "a"}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters, Output: "everything"}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters, Outputs: []string{"OTTO_HOST_ADDRESS"}}},
+		{{Name: "a", ScriptID: script.ID, Parameters: parameters, Scope: ScheduleScope{HostIDs: []string{newID()}}}},
+	}
+	for _, steps := range invalid {
+		workflow := Workflow{Name: randomString(6), Steps: steps}
+		if err := workflow.Validate(); err == nil {
+			t.Errorf("No error seen when one expected for steps %+v", steps)
+		}
+	}
+}
+
+func TestWorkflowOutputs(t *testing.T) {
+	stdout := "starting\nVERSION=1.0\nIGNORED=yes\nVERSION=1.1\r\nPATH=/usr/bin\n"
+	values := environ.Map(workflowOutputs(stdout, []string{"VERSION", "PATH", "MISSING"}))
+
+	if values["VERSION"] != "1.1" {
+		t.Errorf("Unexpected value for VERSION: '%s'", values["VERSION"])
+	}
+	if values["PATH"] != "/usr/bin" {
+		t.Errorf("Unexpected value for PATH: '%s'", values["PATH"])
+	}
+	if _, present := values["IGNORED"]; present {
+		t.Errorf("Undeclared output should not be included")
+	}
+	if _, present := values["MISSING"]; present {
+		t.Errorf("Output that was not written should not be included")
+	}
+}
+
+func TestWorkflowRunBranches(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "echo hello",
+		RunLevel:   ScriptRunLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+	host, err := HostStore.NewHost(newHostParameters{
+		Name:    randomString(6),
+		Address: randLocalhostIP(),
+		Port:    1,
+	})
+	if err != nil {
+		t.Fatalf("Error making host: %s", err.Message)
+	}
+
+	workflow, err := WorkflowStore.NewWorkflow(newWorkflowParameters{
+		Name:  randomString(6),
+		Scope: ScheduleScope{HostIDs: []string{host.ID}},
+		Steps: []WorkflowStep{
+			{Name: "deploy", ScriptID: script.ID, OnFailure: "rollback"},
+			{Name: "verify", ScriptID: script.ID},
+			{Name: "rollback", ScriptID: script.ID},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making workflow: %s", err.Message)
+	}
+
+	if err := ScriptStore.DeleteScript(script); err == nil {
+		t.Errorf("No error seen when one expected for deleting script used by workflow")
+	}
+
+	schedule, err := ScheduleStore.NewSchedule(newScheduleParameters{
+		WorkflowID: workflow.ID,
+		Name:       randomString(6),
+		Scope:      ScheduleScope{HostIDs: []string{host.ID}},
+		Pattern:    "* * * * *",
+	})
+	if err != nil {
+		t.Fatalf("Error making schedule: %s", err.Message)
+	}
+	schedule.RunNow()
+
+	reports := WorkflowReportStore.GetReportsForWorkflow(workflow.ID)
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of workflow reports: %d", len(reports))
+	}
+	report := reports[0]
+	if report.Status != JobStatusFinished {
+		t.Errorf("Unexpected report status: %s", report.Status)
+	}
+	if report.ScheduleID != schedule.ID {
+		t.Errorf("Report should be for the schedule")
+	}
+	// The host is not reachable so the deploy step fails and the rollback step is run, which also fails
+	if len(report.Steps) != 2 || report.Steps[0].Step != "deploy" || report.Steps[1].Step != "rollback" {
+		t.Fatalf("Unexpected steps in report: %+v", report.Steps)
+	}
+	if report.Result != ScheduleResultFail {
+		t.Errorf("Unexpected workflow result: %d", report.Result)
+	}
+
+	scheduleReports := ScheduleReportStore.GetReportsForSchedule(schedule.ID)
+	if len(scheduleReports) != 1 || scheduleReports[0].WorkflowReportID != report.ID {
+		t.Errorf("Schedule report should refer to the workflow report")
+	}
+
+	if err := WorkflowStore.DeleteWorkflow(workflow); err == nil {
+		t.Errorf("No error seen when one expected for deleting workflow used by schedule")
+	}
+}
+
+func TestWorkflowSecrets(t *testing.T) {
+	script, err := ScriptStore.NewScript(newScriptParameters{
+		Name:       randomString(6),
+		Executable: "/bin/sh",
+		Script:     "deploy",
+		RunLevel:   ScriptRunLevelReadWrite,
+		Parameters: []ScriptParameter{
+			{Name: "TOKEN", Type: ScriptParameterTypeSecret, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making script: %s", err.Message)
+	}
+
+	workflow, err := WorkflowStore.NewWorkflow(newWorkflowParameters{
+		Name: randomString(6),
+		Steps: []WorkflowStep{
+			{Name: "deploy", ScriptID: script.ID, Parameters: map[string]string{"TOKEN": "hunter2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error making workflow: %s", err.Message)
+	}
+
+	hidden := *workflow
+	hidden.hideSecrets()
+	if hidden.Steps[0].Parameters["TOKEN"] != secretParameterMask {
+		t.Errorf("Unexpected parameters: %+v", hidden.Steps[0].Parameters)
+	}
+	if workflow.Steps[0].Parameters["TOKEN"] != "hunter2" {
+		t.Errorf("Hiding secrets should not modify the original workflow")
+	}
+
+	// Sending the mask back keeps the stored value
+	edited, err := WorkflowStore.EditWorkflow(workflow, editWorkflowParameters{
+		Name:  workflow.Name,
+		Steps: hidden.Steps,
+	})
+	if err != nil {
+		t.Fatalf("Error editing workflow: %s", err.Message)
+	}
+	if edited.Steps[0].Parameters["TOKEN"] != "hunter2" {
+		t.Errorf("Secret parameter should be kept when the mask is sent back: %+v", edited.Steps[0].Parameters)
+	}
+
+	readOnly := &User{Permissions: UserPermissions{ScriptRunLevel: ScriptRunLevelReadOnly}}
+	if workflow.canView(readOnly) {
+		t.Errorf("User should not be able to view a workflow above their run level")
+	}
+	readOnly.Permissions.CanModifyScripts = true
+	if !workflow.canView(readOnly) {
+		t.Errorf("User who can modify scripts should be able to view any workflow")
+	}
+}
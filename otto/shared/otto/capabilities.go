@@ -0,0 +1,105 @@
+package otto
+
+import "fmt"
+
+// Otto protocol features
+const (
+	// FeatureMultiplexing multiple connections can be opened concurrently over a single SSH session
+	FeatureMultiplexing = "multiplexing"
+	// FeatureFileDownload files can be downloaded from the host
+	FeatureFileDownload = "file_download"
+)
+
+// SupportedFeatures the features supported by this version of otto
+var SupportedFeatures = []string{
+	FeatureMultiplexing,
+	FeatureFileDownload,
+}
+
+// Capabilities describes the protocol version and features supported by both peers of a connection
+type Capabilities struct {
+	ProtocolVersion uint32   `json:"protocol_version"`
+	Features        []string `json:"features"`
+}
+
+// Supports returns true if the given feature is supported by both peers
+func (c Capabilities) Supports(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateFeatures returns the features that are present in both the local and remote features
+func negotiateFeatures(local, remote []string) []string {
+	features := []string{}
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				features = append(features, l)
+				break
+			}
+		}
+	}
+	return features
+}
+
+// sendHello will send a hello message to the peer and wait for their hello in reply. The connections version and
+// features are updated to the common subset of both peers.
+func (c *Connection) sendHello(features []string) error {
+	if err := c.WriteMessage(MessageTypeHello, MessageHello{
+		ProtocolVersion: ProtocolVersion,
+		Features:        features,
+	}); err != nil {
+		return err
+	}
+
+	messageType, message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if messageType != MessageTypeHello {
+		return fmt.Errorf("incorrect message type %d", messageType)
+	}
+
+	return c.applyHello(message.(MessageHello), features)
+}
+
+// replyHello will wait for a hello message from the peer and send our hello in reply. The connections version and
+// features are updated to the common subset of both peers.
+func (c *Connection) replyHello(features []string) error {
+	messageType, message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if messageType != MessageTypeHello {
+		return fmt.Errorf("incorrect message type %d", messageType)
+	}
+
+	if err := c.WriteMessage(MessageTypeHello, MessageHello{
+		ProtocolVersion: ProtocolVersion,
+		Features:        features,
+	}); err != nil {
+		return err
+	}
+
+	return c.applyHello(message.(MessageHello), features)
+}
+
+func (c *Connection) applyHello(remote MessageHello, localFeatures []string) error {
+	version := negotiateProtocolVersion(remote.ProtocolVersion)
+	if version < MinimumProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d", remote.ProtocolVersion)
+	}
+
+	c.version = version
+	c.features = negotiateFeatures(localFeatures, remote.Features)
+	log.PDebug("Negotiated capabilities", map[string]interface{}{
+		"action_id":        c.actionID,
+		"protocol_version": c.version,
+		"features":         c.features,
+	})
+	return nil
+}
@@ -39,7 +39,7 @@ func (c *Connection) ReadMessage() (MessageType, interface{}, error) {
 		})
 		return 0, nil, fmt.Errorf("unsupported protocol version %d", version)
 	}
-	if version < ProtocolVersion {
+	if version < MinimumProtocolVersion {
 		log.Warn("Unsupported protocol version: %d, wanted: %d", version, ProtocolVersion)
 	}
 
@@ -132,6 +132,27 @@ func (c *Connection) ReadMessage() (MessageType, interface{}, error) {
 			return 0, nil, err
 		}
 		return messageType, message, nil
+	case MessageTypeHello:
+		message := MessageHello{}
+		if err := decoder.Decode(&message); err != nil {
+			log.Error("Error decoding MessageTypeHello: %s", err.Error())
+			return 0, nil, err
+		}
+		return messageType, message, nil
+	case MessageTypeTriggerActionDownloadFile:
+		message := MessageTriggerActionDownloadFile{}
+		if err := decoder.Decode(&message); err != nil {
+			log.Error("Error decoding MessageTypeTriggerActionDownloadFile: %s", err.Error())
+			return 0, nil, err
+		}
+		return messageType, message, nil
+	case MessageTypeDownloadFileInfo:
+		message := MessageDownloadFileInfo{}
+		if err := decoder.Decode(&message); err != nil {
+			log.Error("Error decoding MessageTypeDownloadFileInfo: %s", err.Error())
+			return 0, nil, err
+		}
+		return messageType, message, nil
 	}
 	log.Error("Unknown message type '%d'", messageType)
 	return messageType, nil, fmt.Errorf("unknown message type %d", messageType)
@@ -162,13 +183,17 @@ func (c *Connection) WriteMessage(messageType MessageType, message interface{})
 		messageLength = l
 	}
 
+	version := c.Version()
+	if messageType == MessageTypeHello {
+		version = helloProtocolVersion
+	}
 	headerBuf := make([]byte, 4*3)
-	binary.BigEndian.PutUint32(headerBuf[0:], ProtocolVersion)
+	binary.BigEndian.PutUint32(headerBuf[0:], version)
 	binary.BigEndian.PutUint32(headerBuf[4:], uint32(messageType))
 	binary.BigEndian.PutUint32(headerBuf[8:], messageLength)
 
 	log.PDebug("Preparing message", map[string]interface{}{
-		"protocol_version":    ProtocolVersion,
+		"protocol_version":    version,
 		"message_type":        messageType,
 		"message_data_length": messageLength,
 	})
@@ -231,6 +256,10 @@ type ReadWriteCloserFinisher interface {
 type Connection struct {
 	id             int
 	w              ReadWriteCloserFinisher
+	client         *Client
+	actionID       string
+	version        uint32
+	features       []string
 	remoteAddr     net.Addr
 	localAddr      net.Addr
 	remoteIdentity []byte
@@ -261,11 +290,39 @@ func (c *Connection) LocalIdentity() []byte {
 	return c.localIdentity
 }
 
+// ActionID returns the ID of the action this connection belongs to. Connections from peers that do not support
+// multiplexing will not have an action ID.
+func (c *Connection) ActionID() string {
+	return c.actionID
+}
+
+// Version returns the protocol version used for messages written to this connection, which is the highest version
+// supported by both peers.
+func (c *Connection) Version() uint32 {
+	if c.version == 0 {
+		return ProtocolVersion
+	}
+	return c.version
+}
+
+// Capabilities returns the protocol version and features supported by both peers of this connection
+func (c *Connection) Capabilities() Capabilities {
+	return Capabilities{
+		ProtocolVersion: c.Version(),
+		Features:        c.features,
+	}
+}
+
 func (c *Connection) Close() error {
 	log.PDebug("Connection closed", map[string]interface{}{
 		"id":          c.id,
+		"action_id":   c.actionID,
 		"local_addr":  c.localAddr.String(),
 		"remote_addr": c.remoteAddr.String(),
 	})
-	return c.w.Close()
+	err := c.w.Close()
+	if c.client != nil {
+		c.client.Close()
+	}
+	return err
 }
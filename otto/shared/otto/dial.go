@@ -17,10 +17,43 @@ type DialOptions struct {
 	Identity         ssh.Signer
 	TrustedPublicKey string
 	Timeout          time.Duration
+	// Features the features to advertise to the host, defaults to SupportedFeatures if nil
+	Features []string
 }
 
-// Dial will dial the host specified by the options and perform a SSH handshake with it.
+// Client describes an established SSH session with an Otto host. Hosts that support multiplexing can have multiple
+// connections open at once over a single client, with each connection belonging to a single action.
+type Client struct {
+	client         *ssh.Client
+	address        string
+	features       []string
+	remoteVersion  uint32
+	remoteIdentity []byte
+	localIdentity  []byte
+	capabilities   *Capabilities
+	lock           *sync.RWMutex
+}
+
+// Dial will dial the host specified by the options and perform a SSH handshake with it. The returned connection owns
+// the underlying SSH session, which is closed when the connection is closed.
 func Dial(options DialOptions) (*Connection, error) {
+	client, err := DialClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Open("")
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	conn.client = client
+	return conn, nil
+}
+
+// DialClient will dial the host specified by the options and perform a SSH handshake with it. No connections are opened
+// until you call Open().
+func DialClient(options DialOptions) (*Client, error) {
 	if base64.StdEncoding.EncodeToString(options.Identity.PublicKey().Marshal()) == options.TrustedPublicKey {
 		return nil, fmt.Errorf("server and agent identity cannot be the same")
 	}
@@ -48,7 +81,7 @@ func Dial(options DialOptions) (*Connection, error) {
 			return fmt.Errorf("unknown public key: %x", key.Marshal())
 		},
 		HostKeyAlgorithms: []string{ssh.KeyAlgoED25519},
-		ClientVersion:     fmt.Sprintf("SSH-2.0-OTTO-%d", ProtocolVersion),
+		ClientVersion:     fmt.Sprintf("%s%d", sshVersionPrefix, ProtocolVersion),
 		Timeout:           options.Timeout,
 	}
 
@@ -66,28 +99,123 @@ func Dial(options DialOptions) (*Connection, error) {
 		return nil, err
 	}
 
+	remoteVersion := protocolVersionFromSSHVersion(client.ServerVersion())
+	if remoteVersion < MinimumProtocolVersion {
+		log.PError("[DIAL] Unsupported protocol version", map[string]interface{}{
+			"address":        options.Address,
+			"remote_version": string(client.ServerVersion()),
+		})
+		client.Close()
+		return nil, fmt.Errorf("unsupported protocol version %s", client.ServerVersion())
+	}
+	log.PDebug("[DIAL] Connected to host", map[string]interface{}{
+		"address":          options.Address,
+		"protocol_version": remoteVersion,
+	})
+
+	return newClient(client, options.Address, remoteVersion, remoteIdentity, localIdentity, options.Features), nil
+}
+
+func newClient(client *ssh.Client, address string, remoteVersion uint32, remoteIdentity, localIdentity []byte, features []string) *Client {
+	if features == nil {
+		features = SupportedFeatures
+	}
+
+	return &Client{
+		client:         client,
+		address:        address,
+		features:       features,
+		remoteVersion:  remoteVersion,
+		remoteIdentity: remoteIdentity,
+		localIdentity:  localIdentity,
+		lock:           &sync.RWMutex{},
+	}
+}
+
+// Open will open a new connection for the given action ID and negotiate capabilities with the host. If the host does
+// not support multiplexing then only a single connection can be opened for this client.
+func (c *Client) Open(actionID string) (*Connection, error) {
 	log.PDebug("[DIAL] Opening channel", map[string]interface{}{
-		"address":      options.Address,
+		"address":      c.address,
 		"channel_name": sshChannelName,
+		"action_id":    actionID,
 	})
-	channel, _, err := client.OpenChannel(sshChannelName, nil)
+
+	var extraData []byte
+	if c.remoteVersion >= helloProtocolVersion {
+		extraData = []byte(actionID)
+	}
+	channel, reqs, err := c.client.OpenChannel(sshChannelName, extraData)
 	if err != nil {
-		log.PError("[DIAL] Error connecting to host", map[string]interface{}{
-			"address": options.Address,
-			"error":   err.Error(),
+		log.PError("[DIAL] Error opening channel", map[string]interface{}{
+			"address":   c.address,
+			"action_id": actionID,
+			"error":     err.Error(),
 		})
 		return nil, err
 	}
-	log.PDebug("[DIAL] Connected to host", map[string]interface{}{
-		"address": options.Address,
-	})
+	go ssh.DiscardRequests(reqs)
 
-	return &Connection{
+	conn := &Connection{
 		w:              channel,
-		remoteAddr:     client.RemoteAddr(),
-		localAddr:      client.LocalAddr(),
-		localIdentity:  localIdentity,
-		remoteIdentity: remoteIdentity,
+		actionID:       actionID,
+		version:        negotiateProtocolVersion(c.remoteVersion),
+		remoteAddr:     c.client.RemoteAddr(),
+		localAddr:      c.client.LocalAddr(),
+		localIdentity:  c.localIdentity,
+		remoteIdentity: c.remoteIdentity,
 		mutex:          sync.Mutex{},
-	}, nil
+	}
+
+	if c.remoteVersion >= helloProtocolVersion {
+		if err := conn.sendHello(c.features); err != nil {
+			log.PError("[DIAL] Error negotiating capabilities", map[string]interface{}{
+				"address":   c.address,
+				"action_id": actionID,
+				"error":     err.Error(),
+			})
+			channel.Close()
+			return nil, err
+		}
+	}
+
+	capabilities := conn.Capabilities()
+	c.lock.Lock()
+	c.capabilities = &capabilities
+	c.lock.Unlock()
+
+	return conn, nil
+}
+
+// Capabilities returns the capabilities negotiated with the host by the most recently opened connection, or nil if
+// no connection has been opened yet.
+func (c *Client) Capabilities() *Capabilities {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.capabilities
+}
+
+// Multiplexed returns true if the host supports multiple concurrent connections over this client. Always returns
+// false until a connection has been opened.
+func (c *Client) Multiplexed() bool {
+	capabilities := c.Capabilities()
+	return capabilities != nil && capabilities.Supports(FeatureMultiplexing)
+}
+
+// RemoteVersion returns the otto protocol version of the host
+func (c *Client) RemoteVersion() uint32 {
+	return c.remoteVersion
+}
+
+// Wait will block until the underlying SSH session is closed
+func (c *Client) Wait() error {
+	return c.client.Wait()
+}
+
+// Close will close the underlying SSH session and all connections opened on it
+func (c *Client) Close() error {
+	log.PDebug("[DIAL] Client closed", map[string]interface{}{
+		"address": c.address,
+	})
+	return c.client.Close()
 }
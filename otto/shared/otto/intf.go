@@ -1,6 +1,7 @@
 package otto
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io"
 )
@@ -196,6 +197,97 @@ func (conn *Connection) TriggerActionUploadFile(file FileInfo, fileReader io.Rea
 	return nil
 }
 
+// TriggerActionDownloadFile will download the file at the given path from the host, writing its contents to w. The
+// checksum of the downloaded data is verified against the checksum reported by the host. Returns information about the
+// file or an error. Note that if an error is returned, some data may have already been written to w.
+func (conn *Connection) TriggerActionDownloadFile(request MessageTriggerActionDownloadFile, w io.Writer) (*FileInfo, error) {
+	if !conn.Capabilities().Supports(FeatureFileDownload) {
+		return nil, fmt.Errorf("host does not support downloading files")
+	}
+
+	if err := conn.WriteMessage(MessageTypeTriggerActionDownloadFile, request); err != nil {
+		log.PError("Error writing message", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	messageType, message, err := conn.ReadMessage()
+	if err != nil {
+		log.PError("Error reading message", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+	switch messageType {
+	case MessageTypeDownloadFileInfo:
+		// Continue below
+	case MessageTypeActionResult:
+		result := message.(MessageActionResult)
+		if result.Error == "" {
+			return nil, fmt.Errorf("unexpected result from host")
+		}
+		return nil, fmt.Errorf("%s", result.Error)
+	case MessageTypeGeneralFailure:
+		result := message.(MessageGeneralFailure)
+		return nil, fmt.Errorf("%s", result.Error)
+	default:
+		return nil, fmt.Errorf("incorrect message type %d", messageType)
+	}
+
+	info := message.(MessageDownloadFileInfo).FileInfo
+	if request.MaxSize > 0 && info.Length > request.MaxSize {
+		return nil, fmt.Errorf("file is larger than maximum size")
+	}
+
+	if err := conn.WriteMessage(MessageTypeReadyForData, nil); err != nil {
+		log.PError("Error writing message", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	h := sha256.New()
+	read, err := io.CopyN(io.MultiWriter(w, h), conn.w, int64(info.Length))
+	if err != nil {
+		log.PError("Error reading file data", map[string]interface{}{
+			"error":    err.Error(),
+			"length":   info.Length,
+			"read_len": read,
+		})
+		return nil, err
+	}
+	log.PDebug("Read file data", map[string]interface{}{
+		"path":   info.Path,
+		"length": read,
+	})
+
+	messageType, message, err = conn.ReadMessage()
+	if err != nil {
+		log.PError("Error reading message", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+	if messageType != MessageTypeActionResult {
+		return nil, fmt.Errorf("incorrect message type %d", messageType)
+	}
+	if result := message.(MessageActionResult); result.Error != "" {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+
+	if checksum := fmt.Sprintf("%x", h.Sum(nil)); checksum != info.Checksum {
+		log.PError("File checksum validation failed", map[string]interface{}{
+			"path":              info.Path,
+			"expected_checksum": info.Checksum,
+			"actual_checksum":   checksum,
+		})
+		return nil, fmt.Errorf("checksum validation failed")
+	}
+
+	return &info, nil
+}
+
 func (conn *Connection) TriggerActionExitAgent() error {
 	if err := conn.WriteMessage(MessageTypeTriggerActionExitAgent, nil); err != nil {
 		log.PError("Error writing message", map[string]interface{}{
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -20,17 +21,24 @@ type ListenOptions struct {
 	AllowFrom            []net.IPNet
 	Identity             ssh.Signer
 	GetTrustedPublicKeys func() []string
+	// Features the features to advertise to the server, defaults to SupportedFeatures if nil
+	Features []string
 }
 
 // Listener describes an active listening Otto server
 type Listener struct {
-	options *ListenOptions
-	handle  func(conn *Connection)
-	l       net.Listener
+	options   *ListenOptions
+	handle    func(conn *Connection)
+	l         net.Listener
+	sessions  map[*ssh.ServerConn]*sync.WaitGroup
+	sessionsL *sync.Mutex
+	stopped   bool
+	keepalive time.Duration
 }
 
 // SetupListener will prepare a listening socket for incoming connections. No connections are accepted until you call
-// Accept().
+// Accept(). The handle method is called for each connection, and may be called concurrently for connections from peers
+// that support multiplexing.
 func SetupListener(options *ListenOptions, handle func(conn *Connection)) (*Listener, error) {
 	for _, trustedKey := range options.GetTrustedPublicKeys() {
 		log.PDebug("[LISTEN] Validate trusted public key", map[string]interface{}{
@@ -51,9 +59,11 @@ func SetupListener(options *ListenOptions, handle func(conn *Connection)) (*List
 	}
 	log.Info("Otto agent listening on %s", options.Address)
 	return &Listener{
-		options: options,
-		handle:  handle,
-		l:       l,
+		options:   options,
+		handle:    handle,
+		l:         l,
+		sessions:  map[*ssh.ServerConn]*sync.WaitGroup{},
+		sessionsL: &sync.Mutex{},
 	}, nil
 }
 
@@ -84,14 +94,38 @@ func (l *Listener) Accept() error {
 	}
 }
 
-// Close will stop the listener.
+// Stop will stop accepting new connections and channels. Open sessions are closed once all of their active channels
+// have finished, so that actions that are already running are not interrupted.
+func (l *Listener) Stop() {
+	if l.l != nil {
+		l.l.Close()
+	}
+
+	l.sessionsL.Lock()
+	defer l.sessionsL.Unlock()
+	l.stopped = true
+	for sc, active := range l.sessions {
+		go func(sc *ssh.ServerConn, active *sync.WaitGroup) {
+			active.Wait()
+			sc.Close()
+		}(sc, active)
+	}
+}
+
+// Close will stop the listener and close any open sessions, interrupting any active channels.
 func (l *Listener) Close() {
-	l.l.Close()
+	if l.l != nil {
+		l.l.Close()
+	}
+
+	l.sessionsL.Lock()
+	defer l.sessionsL.Unlock()
+	for sc := range l.sessions {
+		sc.Close()
+	}
 }
 
 func (l *Listener) accept(c net.Conn) {
-	connId := fdFromConn(c)
-
 	if len(l.options.AllowFrom) > 0 {
 		allow := false
 		for _, allowNet := range l.options.AllowFrom {
@@ -114,6 +148,13 @@ func (l *Listener) accept(c net.Conn) {
 		}
 	}
 
+	l.serve(c)
+}
+
+// serve will perform the SSH handshake on the connection and handle all channels opened by the peer until the session
+// is closed. Blocking.
+func (l *Listener) serve(c net.Conn) {
+	connId := fdFromConn(c)
 	localIdentity := l.options.Identity.PublicKey().Marshal()
 	var remoteIdentity []byte
 
@@ -142,7 +183,7 @@ func (l *Listener) accept(c net.Conn) {
 			})
 			return nil, fmt.Errorf("unknown public key %x", pubKey.Marshal())
 		},
-		ServerVersion: fmt.Sprintf("SSH-2.0-OTTO-%d", ProtocolVersion),
+		ServerVersion: fmt.Sprintf("%s%d", sshVersionPrefix, ProtocolVersion),
 	}
 	sshConfig.AddHostKey(l.options.Identity)
 
@@ -159,6 +200,40 @@ func (l *Listener) accept(c net.Conn) {
 	}
 
 	go ssh.DiscardRequests(reqs)
+	if l.keepalive > 0 {
+		go sendKeepalives(sc, l.keepalive)
+	}
+
+	remoteVersion := protocolVersionFromSSHVersion(sc.ClientVersion())
+	if remoteVersion < MinimumProtocolVersion {
+		log.PError("[LISTEN] Unsupported protocol version", map[string]interface{}{
+			"remote_addr":    c.RemoteAddr().String(),
+			"remote_version": string(sc.ClientVersion()),
+		})
+		sc.Close()
+		return
+	}
+	features := l.options.Features
+	if features == nil {
+		features = SupportedFeatures
+	}
+
+	// active counts the channels being handled on this session. Channels are only added while the listener is not
+	// stopped, so that Stop can wait for the count to reach zero.
+	active := &sync.WaitGroup{}
+	l.sessionsL.Lock()
+	if l.stopped {
+		l.sessionsL.Unlock()
+		sc.Close()
+		return
+	}
+	l.sessions[sc] = active
+	l.sessionsL.Unlock()
+	defer func() {
+		l.sessionsL.Lock()
+		delete(l.sessions, sc)
+		l.sessionsL.Unlock()
+	}()
 
 	for newChannel := range chans {
 		log.Debug("[LISTEN] ssh channel opened")
@@ -170,29 +245,77 @@ func (l *Listener) accept(c net.Conn) {
 			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
 			return
 		}
-		channel, _, err := newChannel.Accept()
+		l.sessionsL.Lock()
+		stopped := l.stopped
+		if !stopped {
+			active.Add(1)
+		}
+		l.sessionsL.Unlock()
+		if stopped {
+			log.PDebug("[LISTEN] Rejecting channel on stopped listener", map[string]interface{}{
+				"remote_addr": c.RemoteAddr().String(),
+			})
+			newChannel.Reject(ssh.ResourceShortage, "listener stopped")
+			continue
+		}
+		actionID := ""
+		if remoteVersion >= helloProtocolVersion {
+			actionID = string(newChannel.ExtraData())
+		}
+		channel, channelReqs, err := newChannel.Accept()
 		if err != nil {
 			log.PError("[LISTEN] SSH channel error", map[string]interface{}{
 				"remote_addr": c.RemoteAddr().String(),
 				"error":       err.Error(),
 			})
+			active.Done()
 			return
 		}
+		go ssh.DiscardRequests(channelReqs)
 		log.PDebug("[LISTEN] SSH handshake success", map[string]interface{}{
-			"id":          connId,
-			"remote_addr": c.RemoteAddr().String(),
+			"id":               connId,
+			"remote_addr":      c.RemoteAddr().String(),
+			"action_id":        actionID,
+			"protocol_version": remoteVersion,
 		})
-		l.handle(&Connection{
+		conn := &Connection{
 			id:             connId,
 			w:              channel,
+			actionID:       actionID,
+			version:        negotiateProtocolVersion(remoteVersion),
 			remoteAddr:     c.RemoteAddr(),
 			localAddr:      c.LocalAddr(),
 			localIdentity:  localIdentity,
 			remoteIdentity: remoteIdentity,
 			mutex:          sync.Mutex{},
-		})
-		c.Close()
-		channel.Close()
+		}
+
+		if remoteVersion < helloProtocolVersion {
+			// Peers before protocol version 6 don't exchange hello messages and expect the session to end once the action
+			// has finished
+			l.handle(conn)
+			c.Close()
+			channel.Close()
+			active.Done()
+			continue
+		}
+
+		go func() {
+			defer active.Done()
+			defer channel.Close()
+			if err := conn.replyHello(features); err != nil {
+				log.PError("[LISTEN] Error negotiating capabilities", map[string]interface{}{
+					"remote_addr": c.RemoteAddr().String(),
+					"action_id":   conn.actionID,
+					"error":       err.Error(),
+				})
+				return
+			}
+			l.handle(conn)
+			if !conn.Capabilities().Supports(FeatureMultiplexing) {
+				c.Close()
+			}
+		}()
 	}
 	sc.Close()
 }
@@ -7,6 +7,8 @@ package otto
 
 import (
 	"encoding/gob"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ecnepsnai/logtic"
@@ -16,7 +18,14 @@ import (
 var log = logtic.Log.Connect("libotto")
 
 // ProtocolVersion the version of the otto protocol
-const ProtocolVersion uint32 = 5
+const ProtocolVersion uint32 = 6
+
+// MinimumProtocolVersion the oldest version of the otto protocol that is still supported
+const MinimumProtocolVersion uint32 = 5
+
+// helloProtocolVersion the first version of the otto protocol that exchanges hello messages. Hello messages are always
+// written using this version so that they can be read by any peer, regardless of its version.
+const helloProtocolVersion uint32 = 6
 
 func init() {
 	gob.Register(ScriptInfo{})
@@ -32,6 +41,9 @@ func init() {
 	gob.Register(MessageActionOutput{})
 	gob.Register(MessageActionResult{})
 	gob.Register(MessageCancelAction{})
+	gob.Register(MessageHello{})
+	gob.Register(MessageTriggerActionDownloadFile{})
+	gob.Register(MessageDownloadFileInfo{})
 }
 
 type MessageType uint32
@@ -54,6 +66,9 @@ const (
 	MessageTypeActionOutput
 	MessageTypeActionResult
 	MessageTypeReadyForData
+	MessageTypeHello
+	MessageTypeTriggerActionDownloadFile
+	MessageTypeDownloadFileInfo
 )
 
 // MessageHeartbeatRequest describes a heartbeat request
@@ -79,6 +94,17 @@ type MessageTriggerActionUploadFile struct {
 	FileInfo
 }
 
+// MessageTriggerActionDownloadFile describes a request to download a file from the host
+type MessageTriggerActionDownloadFile struct {
+	Path    string `json:"path"`
+	MaxSize uint64 `json:"max_size"`
+}
+
+// MessageDownloadFileInfo describes the file that the host is about to send in reply to a download request
+type MessageDownloadFileInfo struct {
+	FileInfo
+}
+
 // MessageCancelAction describes a request to cancel a specific action
 type MessageCancelAction struct {
 	Name string `json:"name"`
@@ -108,6 +134,12 @@ type MessageRotateIdentityResponse struct {
 	Error     string `json:"error"`
 }
 
+// MessageHello describes the protocol version and features supported by one side of a connection
+type MessageHello struct {
+	ProtocolVersion uint32   `json:"protocol_version"`
+	Features        []string `json:"features"`
+}
+
 // MessageGeneralFailure describes a general failure
 type MessageGeneralFailure struct {
 	Error string `json:"error"`
@@ -121,6 +153,8 @@ type ScriptInfo struct {
 	WorkingDirectory string            `json:"working_directory"`
 	Executable       string            `json:"executable"`
 	Length           uint64            `json:"length"`
+	// TimeoutSeconds the number of seconds the script may run for before it is aborted, 0 uses the agent default
+	TimeoutSeconds int64 `json:"timeout_seconds,omitempty"`
 }
 
 // RunAs describes the user to run a script as
@@ -138,6 +172,8 @@ type ScriptResult struct {
 	StdoutLen uint32        `json:"stdout_len"`
 	StderrLen uint32        `json:"stderr_len"`
 	Elapsed   time.Duration `json:"elapsed"`
+	// TimedOut if the script was aborted because it ran for longer than its timeout
+	TimedOut bool `json:"timed_out"`
 }
 
 func (sr ScriptResult) String() string {
@@ -148,6 +184,7 @@ func (sr ScriptResult) String() string {
 		"stdout_len": sr.StdoutLen,
 		"stderr_len": sr.StderrLen,
 		"elapsed":    sr.Elapsed.String(),
+		"timed_out":  sr.TimedOut,
 	})
 }
 
@@ -209,6 +246,29 @@ type RegisterResponse struct {
 
 const sshChannelName = "otto"
 
+const sshVersionPrefix = "SSH-2.0-OTTO-"
+
+// protocolVersionFromSSHVersion parses the otto protocol version from the peers SSH version string. Returns 0 if the
+// version string is not from an otto peer.
+func protocolVersionFromSSHVersion(sshVersion []byte) uint32 {
+	if !strings.HasPrefix(string(sshVersion), sshVersionPrefix) {
+		return 0
+	}
+	version, err := strconv.ParseUint(strings.TrimPrefix(string(sshVersion), sshVersionPrefix), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(version)
+}
+
+// negotiateProtocolVersion returns the highest protocol version supported by both this side and the peer
+func negotiateProtocolVersion(remoteVersion uint32) uint32 {
+	if remoteVersion < ProtocolVersion {
+		return remoteVersion
+	}
+	return ProtocolVersion
+}
+
 var defaultSSHConfig = ssh.Config{
 	KeyExchanges: []string{"curve25519-sha256"},
 	Ciphers:      []string{"chacha20-poly1305@openssh.com"},
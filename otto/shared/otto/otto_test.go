@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"net"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -313,6 +315,213 @@ func TestConnection(t *testing.T) {
 	c.Close()
 }
 
+func TestConnectionMultiplexed(t *testing.T) {
+	listenerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	dialerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := otto.SetupListener(&otto.ListenOptions{
+		Address:  "127.0.0.1:0",
+		Identity: listenerIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{dialerIdentity.PublicKeyString()}
+		},
+	}, func(c *otto.Connection) {
+		messageType, _, err := c.ReadMessage()
+		if err != nil {
+			t.Errorf("Error reading message: %s", err.Error())
+		}
+		if messageType != otto.MessageTypeHeartbeatRequest {
+			t.Errorf("Unexpected message type")
+		}
+		// Hold the connection open to make sure that other actions are not blocked by this one
+		time.Sleep(50 * time.Millisecond)
+		c.WriteMessage(otto.MessageTypeHeartbeatResponse, otto.MessageHeartbeatResponse{Nonce: c.ActionID()})
+	})
+	if err != nil {
+		panic(err)
+	}
+	port := l.Port()
+	go l.Accept()
+	defer l.Close()
+	time.Sleep(5 * time.Millisecond)
+
+	client, err := otto.DialClient(otto.DialOptions{
+		Network:          "tcp",
+		Address:          fmt.Sprintf("127.0.0.1:%d", port),
+		Identity:         dialerIdentity.Signer(),
+		TrustedPublicKey: listenerIdentity.PublicKeyString(),
+	})
+	if err != nil {
+		t.Fatalf("Error dialing: %s", err.Error())
+	}
+	defer client.Close()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(actionID string) {
+			defer wg.Done()
+			c, err := client.Open(actionID)
+			if err != nil {
+				t.Errorf("Error opening connection: %s", err.Error())
+				return
+			}
+			defer c.Close()
+
+			reply, err := c.SendHeartbeat(otto.MessageHeartbeatRequest{})
+			if err != nil {
+				t.Errorf("Error sending heartbeat: %s", err.Error())
+				return
+			}
+			if reply.Nonce != actionID {
+				t.Errorf("Unexpected action ID. Expected '%s' got '%s'", actionID, reply.Nonce)
+			}
+		}(fmt.Sprintf("action%d", i))
+	}
+	wg.Wait()
+
+	if !client.Multiplexed() {
+		t.Errorf("Client should support multiplexing")
+	}
+}
+
+func TestListenerStop(t *testing.T) {
+	listenerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	dialerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	started := make(chan bool)
+	l, err := otto.SetupListener(&otto.ListenOptions{
+		Address:  "127.0.0.1:0",
+		Identity: listenerIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{dialerIdentity.PublicKeyString()}
+		},
+	}, func(c *otto.Connection) {
+		if _, _, err := c.ReadMessage(); err != nil {
+			t.Errorf("Error reading message: %s", err.Error())
+		}
+		started <- true
+		// Hold the connection open to make sure that the action is not interrupted by stopping the listener
+		time.Sleep(50 * time.Millisecond)
+		c.WriteMessage(otto.MessageTypeHeartbeatResponse, otto.MessageHeartbeatResponse{Nonce: c.ActionID()})
+	})
+	if err != nil {
+		panic(err)
+	}
+	port := l.Port()
+	accepted := make(chan error)
+	go func() {
+		accepted <- l.Accept()
+	}()
+	defer l.Close()
+	time.Sleep(5 * time.Millisecond)
+
+	client, err := otto.DialClient(otto.DialOptions{
+		Network:          "tcp",
+		Address:          fmt.Sprintf("127.0.0.1:%d", port),
+		Identity:         dialerIdentity.Signer(),
+		TrustedPublicKey: listenerIdentity.PublicKeyString(),
+	})
+	if err != nil {
+		t.Fatalf("Error dialing: %s", err.Error())
+	}
+	defer client.Close()
+
+	c, err := client.Open("running")
+	if err != nil {
+		t.Fatalf("Error opening connection: %s", err.Error())
+	}
+	defer c.Close()
+	if err := c.WriteMessage(otto.MessageTypeHeartbeatRequest, otto.MessageHeartbeatRequest{}); err != nil {
+		t.Fatalf("Error writing message: %s", err.Error())
+	}
+	<-started
+
+	l.Stop()
+	if err := <-accepted; err == nil {
+		t.Errorf("Accept should return once the listener is stopped")
+	}
+	if _, err := client.Open("new"); err == nil {
+		t.Errorf("No error seen opening a channel on a stopped listener")
+	}
+
+	messageType, message, err := c.ReadMessage()
+	if err != nil {
+		t.Fatalf("Active channel should not be closed when the listener is stopped: %s", err.Error())
+	}
+	if messageType != otto.MessageTypeHeartbeatResponse || message.(otto.MessageHeartbeatResponse).Nonce != "running" {
+		t.Errorf("Unexpected reply %d %+v", messageType, message)
+	}
+}
+
+func TestConnectionCapabilities(t *testing.T) {
+	listenerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	dialerIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := otto.SetupListener(&otto.ListenOptions{
+		Address:  "127.0.0.1:0",
+		Identity: listenerIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{dialerIdentity.PublicKeyString()}
+		},
+		Features: []string{"foo", otto.FeatureMultiplexing},
+	}, func(c *otto.Connection) {
+		if c.Capabilities().Supports(otto.FeatureMultiplexing) {
+			t.Errorf("Listener should not have negotiated multiplexing")
+		}
+		c.ReadMessage()
+		c.WriteMessage(otto.MessageTypeHeartbeatResponse, otto.MessageHeartbeatResponse{})
+	})
+	if err != nil {
+		panic(err)
+	}
+	port := l.Port()
+	go l.Accept()
+	defer l.Close()
+	time.Sleep(5 * time.Millisecond)
+
+	c, err := otto.Dial(otto.DialOptions{
+		Network:          "tcp",
+		Address:          fmt.Sprintf("127.0.0.1:%d", port),
+		Identity:         dialerIdentity.Signer(),
+		TrustedPublicKey: listenerIdentity.PublicKeyString(),
+		Features:         []string{"foo", "bar"},
+	})
+	if err != nil {
+		t.Fatalf("Error dialing: %s", err.Error())
+	}
+	defer c.Close()
+
+	capabilities := c.Capabilities()
+	if capabilities.ProtocolVersion != otto.ProtocolVersion {
+		t.Errorf("Unexpected protocol version. Expected %d got %d", otto.ProtocolVersion, capabilities.ProtocolVersion)
+	}
+	if len(capabilities.Features) != 1 || !capabilities.Supports("foo") {
+		t.Errorf("Unexpected features. Expected [foo] got %v", capabilities.Features)
+	}
+	if _, err := c.SendHeartbeat(otto.MessageHeartbeatRequest{}); err != nil {
+		t.Errorf("Error sending heartbeat: %s", err.Error())
+	}
+}
+
 func FuzzConnection_ReadMessage(f *testing.F) {
 	f.Add(secutil.RandomBytes(6))
 	f.Fuzz(func(t *testing.T, a []byte) {
@@ -327,3 +536,119 @@ func FuzzConnection_ReadMessage(f *testing.F) {
 		}
 	})
 }
+
+func TestReverseConnection(t *testing.T) {
+	agentIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	serverIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	serverListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer serverListener.Close()
+
+	l, err := otto.SetupReverseListener(&otto.ReverseListenOptions{
+		ServerAddress: serverListener.Addr().String(),
+		Identity:      agentIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{serverIdentity.PublicKeyString()}
+		},
+		Timeout: time.Second,
+	}, func(c *otto.Connection) {
+		messageType, _, err := c.ReadMessage()
+		if err != nil {
+			t.Errorf("Error reading message: %s", err.Error())
+		}
+		if messageType != otto.MessageTypeHeartbeatRequest {
+			t.Errorf("Unexpected message type")
+		}
+		c.WriteMessage(otto.MessageTypeHeartbeatResponse, otto.MessageHeartbeatResponse{})
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer l.Close()
+	go l.Accept()
+
+	c, err := serverListener.Accept()
+	if err != nil {
+		t.Fatalf("Error accepting connection: %s", err.Error())
+	}
+	client, err := otto.AcceptClient(c, otto.AcceptClientOptions{
+		GetIdentity: func(agentPublicKey string) (*otto.Identity, error) {
+			if agentPublicKey != agentIdentity.PublicKeyString() {
+				return nil, fmt.Errorf("unknown agent")
+			}
+			return serverIdentity, nil
+		},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Error accepting client: %s", err.Error())
+	}
+	defer client.Close()
+
+	// Open more than one connection to make sure the session stays open between actions
+	for i := 0; i < 2; i++ {
+		conn, err := client.Open(fmt.Sprintf("%d", i))
+		if err != nil {
+			t.Fatalf("Error opening connection: %s", err.Error())
+		}
+		if _, err := conn.SendHeartbeat(otto.MessageHeartbeatRequest{}); err != nil {
+			t.Fatalf("Error sending heartbeat: %s", err.Error())
+		}
+		conn.Close()
+	}
+}
+
+func TestReverseConnectionUntrusted(t *testing.T) {
+	agentIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+	serverIdentity, err := otto.NewIdentity()
+	if err != nil {
+		panic(err)
+	}
+
+	serverListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer serverListener.Close()
+
+	l, err := otto.SetupReverseListener(&otto.ReverseListenOptions{
+		ServerAddress: serverListener.Addr().String(),
+		Identity:      agentIdentity.Signer(),
+		GetTrustedPublicKeys: func() []string {
+			return []string{serverIdentity.PublicKeyString()}
+		},
+		Timeout: time.Second,
+	}, func(c *otto.Connection) {
+		t.Errorf("Connection should not have been opened")
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer l.Close()
+	go l.Accept()
+
+	c, err := serverListener.Accept()
+	if err != nil {
+		t.Fatalf("Error accepting connection: %s", err.Error())
+	}
+	if _, err := otto.AcceptClient(c, otto.AcceptClientOptions{
+		GetIdentity: func(agentPublicKey string) (*otto.Identity, error) {
+			return nil, fmt.Errorf("unknown agent")
+		},
+		Timeout: time.Second,
+	}); err == nil {
+		t.Fatalf("No error seen when one expected for untrusted agent")
+	}
+}
@@ -0,0 +1,214 @@
+package otto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Reverse connections allow agents that cannot accept incoming connections, such as those behind NAT, to be managed
+// by the Otto server. The agent dials the server, but the roles of the SSH session are unchanged: the agent is still
+// the SSH server and the Otto server is still the SSH client that opens channels for each action.
+
+// ReverseListenOptions describes options for an agent connecting to the Otto server
+type ReverseListenOptions struct {
+	// ServerAddress the address of the Otto server to connect to
+	ServerAddress        string
+	Identity             ssh.Signer
+	GetTrustedPublicKeys func() []string
+	Timeout              time.Duration
+	// Keepalive how often to check that the server is still connected, defaults to 30 seconds
+	Keepalive time.Duration
+	// Features the features to advertise to the server, defaults to SupportedFeatures if nil
+	Features []string
+}
+
+// ReverseListener describes an agent that connects to the Otto server rather than listening for incoming connections
+type ReverseListener struct {
+	options  *ReverseListenOptions
+	listener *Listener
+}
+
+// SetupReverseListener will prepare a reverse listener. No connection is made to the server until you call Accept().
+// The handle method is called for each connection opened by the server.
+func SetupReverseListener(options *ReverseListenOptions, handle func(conn *Connection)) (*ReverseListener, error) {
+	keepalive := options.Keepalive
+	if keepalive == 0 {
+		keepalive = 30 * time.Second
+	}
+
+	listenOptions := &ListenOptions{
+		Address:              options.ServerAddress,
+		Identity:             options.Identity,
+		GetTrustedPublicKeys: options.GetTrustedPublicKeys,
+		Features:             options.Features,
+	}
+	for _, trustedKey := range options.GetTrustedPublicKeys() {
+		if trustedKey == base64.StdEncoding.EncodeToString(options.Identity.PublicKey().Marshal()) {
+			return nil, fmt.Errorf("server and agent identity cannot be the same")
+		}
+	}
+
+	return &ReverseListener{
+		options: options,
+		listener: &Listener{
+			options:   listenOptions,
+			handle:    handle,
+			sessions:  map[*ssh.ServerConn]*sync.WaitGroup{},
+			sessionsL: &sync.Mutex{},
+			keepalive: keepalive,
+		},
+	}, nil
+}
+
+// Accept will connect to the Otto server and handle connections from it until the session is closed. Blocking.
+func (l *ReverseListener) Accept() error {
+	log.PDebug("[REVERSE] Connecting to server", map[string]interface{}{
+		"address": l.options.ServerAddress,
+	})
+	c, err := net.DialTimeout("tcp", l.options.ServerAddress, l.options.Timeout)
+	if err != nil {
+		log.PError("[REVERSE] Error connecting to server", map[string]interface{}{
+			"address": l.options.ServerAddress,
+			"error":   err.Error(),
+		})
+		return err
+	}
+	log.Info("Otto agent connected to server %s", l.options.ServerAddress)
+
+	l.listener.serve(c)
+	c.Close()
+	return fmt.Errorf("session closed")
+}
+
+// Stop will close the session with the server once all active channels have finished
+func (l *ReverseListener) Stop() {
+	l.listener.Stop()
+}
+
+// Close will close the session with the server
+func (l *ReverseListener) Close() {
+	l.listener.Close()
+}
+
+// AcceptClientOptions describes options for accepting a reverse connection from an agent
+type AcceptClientOptions struct {
+	// GetIdentity should return the identity to use for the agent with the given public key, or an error if the agent
+	// is not trusted
+	GetIdentity func(agentPublicKey string) (*Identity, error)
+	Timeout     time.Duration
+	// Features the features to advertise to the host, defaults to SupportedFeatures if nil
+	Features []string
+}
+
+// AcceptClient will perform a SSH handshake over a connection made by an agent to the Otto server. No connections are
+// opened until you call Open() on the returned client.
+func AcceptClient(c net.Conn, options AcceptClientOptions) (*Client, error) {
+	var identity ssh.Signer
+	var remoteIdentity []byte
+
+	clientConfig := &ssh.ClientConfig{
+		Config: defaultSSHConfig,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+				if identity == nil {
+					return nil, fmt.Errorf("no identity")
+				}
+				return []ssh.Signer{identity}, nil
+			}),
+		},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			agentPublicKey := base64.StdEncoding.EncodeToString(key.Marshal())
+			log.PDebug("[REVERSE] Handshake", map[string]interface{}{
+				"public_key": agentPublicKey,
+			})
+			id, err := options.GetIdentity(agentPublicKey)
+			if err == nil && id == nil {
+				err = fmt.Errorf("no identity")
+			}
+			if err != nil {
+				log.PWarn("[REVERSE] Rejecting connection from untrusted public key", map[string]interface{}{
+					"remote_addr": remote.String(),
+					"public_key":  agentPublicKey,
+					"error":       err.Error(),
+				})
+				return err
+			}
+			if id.PublicKeyString() == agentPublicKey {
+				return fmt.Errorf("server and agent identity cannot be the same")
+			}
+			identity = id.Signer()
+			remoteIdentity = key.Marshal()
+			return nil
+		},
+		HostKeyAlgorithms: []string{ssh.KeyAlgoED25519},
+		ClientVersion:     fmt.Sprintf("%s%d", sshVersionPrefix, ProtocolVersion),
+		Timeout:           options.Timeout,
+	}
+
+	if options.Timeout > 0 {
+		c.SetDeadline(time.Now().Add(options.Timeout))
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(c, c.RemoteAddr().String(), clientConfig)
+	if err != nil {
+		log.PError("[REVERSE] SSH handshake error", map[string]interface{}{
+			"remote_addr": c.RemoteAddr().String(),
+			"error":       err.Error(),
+		})
+		return nil, err
+	}
+	c.SetDeadline(time.Time{})
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	remoteVersion := protocolVersionFromSSHVersion(client.ServerVersion())
+	if remoteVersion < helloProtocolVersion {
+		log.PError("[REVERSE] Unsupported protocol version", map[string]interface{}{
+			"remote_addr":    c.RemoteAddr().String(),
+			"remote_version": string(client.ServerVersion()),
+		})
+		client.Close()
+		return nil, fmt.Errorf("unsupported protocol version %s", client.ServerVersion())
+	}
+	log.PDebug("[REVERSE] Accepted connection from host", map[string]interface{}{
+		"remote_addr":      c.RemoteAddr().String(),
+		"protocol_version": remoteVersion,
+	})
+
+	return newClient(client, c.RemoteAddr().String(), remoteVersion, remoteIdentity, identity.PublicKey().Marshal(), options.Features), nil
+}
+
+// sendKeepalives will periodically send a request over the SSH session, closing the session if the peer stops
+// responding
+func sendKeepalives(sc *ssh.ServerConn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		result := make(chan error, 1)
+		go func() {
+			_, _, err := sc.SendRequest("keepalive@otto", true, nil)
+			result <- err
+		}()
+
+		select {
+		case err := <-result:
+			if err != nil {
+				log.PDebug("[REVERSE] Keepalive failed", map[string]interface{}{
+					"remote_addr": sc.RemoteAddr().String(),
+					"error":       err.Error(),
+				})
+				sc.Close()
+				return
+			}
+		case <-time.After(interval):
+			log.PWarn("[REVERSE] Server did not respond to keepalive, closing session", map[string]interface{}{
+				"remote_addr": sc.RemoteAddr().String(),
+			})
+			sc.Close()
+			return
+		}
+	}
+}